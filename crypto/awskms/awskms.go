@@ -0,0 +1,587 @@
+// Package awskms implements a KeyManager backed by AWS Key Management Service asymmetric keys
+// (https://docs.aws.amazon.com/kms/latest/developerguide/symmetric-asymmetric.html)
+package awskms
+
+import (
+	"bytes"
+	"context"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+	_secp256k1 "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/google/uuid"
+)
+
+// keySpec describes how keys of an algorithm are created and used in KMS
+type keySpec struct {
+	algorithmID string
+	// name is the KMS key spec
+	name string
+	// signingAlgorithm is the KMS signing algorithm
+	signingAlgorithm string
+	// digest hashes the payload for the signing algorithm
+	digest func(payload []byte) []byte
+	// order is the order of the curve, used to normalize signatures to low-S
+	order *big.Int
+	// size is the size of the r and s signature values
+	size int
+}
+
+// keySpecs are the algorithms supported by both web5 and KMS.
+// https://docs.aws.amazon.com/kms/latest/developerguide/asymmetric-key-specs.html#key-spec-ecc
+var keySpecs = []keySpec{
+	{
+		algorithmID:      dsa.AlgorithmIDSECP256K1,
+		name:             "ECC_SECG_P256K1",
+		signingAlgorithm: "ECDSA_SHA_256",
+		digest:           sha256Digest,
+		order:            _secp256k1.S256().Params().N,
+		size:             32,
+	},
+	{
+		algorithmID:      dsa.AlgorithmIDSECP256R1,
+		name:             "ECC_NIST_P256",
+		signingAlgorithm: "ECDSA_SHA_256",
+		digest:           sha256Digest,
+		order:            elliptic.P256().Params().N,
+		size:             32,
+	},
+	{
+		algorithmID:      dsa.AlgorithmIDSECP384R1,
+		name:             "ECC_NIST_P384",
+		signingAlgorithm: "ECDSA_SHA_384",
+		digest:           sha384Digest,
+		order:            elliptic.P384().Params().N,
+		size:             48,
+	},
+}
+
+func sha256Digest(payload []byte) []byte {
+	digest := sha256.Sum256(payload)
+	return digest[:]
+}
+
+func sha384Digest(payload []byte) []byte {
+	digest := sha512.Sum384(payload)
+	return digest[:]
+}
+
+func keySpecByAlgorithmID(algorithmID string) (keySpec, bool) {
+	for _, spec := range keySpecs {
+		if spec.algorithmID == algorithmID {
+			return spec, true
+		}
+	}
+
+	return keySpec{}, false
+}
+
+func keySpecByName(name string) (keySpec, bool) {
+	for _, spec := range keySpecs {
+		if spec.name == name {
+			return spec, true
+		}
+	}
+
+	return keySpec{}, false
+}
+
+// Credentials are the AWS credentials used to sign requests to KMS
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is only set for temporary credentials
+	SessionToken string
+}
+
+// CredentialsFromEnv returns the credentials in the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables.
+func CredentialsFromEnv() (Credentials, error) {
+	credentials := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return Credentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+
+	return credentials, nil
+}
+
+// keyRef is a KMS key along with its public key
+type keyRef struct {
+	arn  string
+	spec keySpec
+	key  jwk.JWK
+}
+
+// KeyManager is a [github.com/decentralized-identity/web5-go/crypto.KeyManager] backed by AWS KMS.
+// Private keys never leave KMS.
+//
+// Like other key managers, the key id of a key is the thumbprint of its public key. KMS key ids can't be
+// chosen, so each key gets a KMS alias made of a prefix and the thumbprint, e.g.
+// alias/web5/cKaqZmuHXkcoz3Qu9l1BzqPO41aZTNtT6V8aWfh1Ioo, which is used to find it. Existing KMS keys can
+// be used after giving them an alias with [KeyManager.RegisterKey], or by using their KMS key id, key ARN
+// or alias as key id, e.g. in [github.com/decentralized-identity/web5-go/dids/did.BearerDID.KeyAliases].
+//
+// https://docs.aws.amazon.com/kms/latest/APIReference/Welcome.html
+type KeyManager struct {
+	endpoint    string
+	region      string
+	credentials Credentials
+	aliasPrefix string
+	client      *http.Client
+
+	mu   sync.RWMutex
+	keys map[string]keyRef
+}
+
+// options that NewKeyManager can take
+type keyManagerOpts struct {
+	endpoint    string
+	aliasPrefix string
+	client      *http.Client
+}
+
+// KeyManagerOpt is a type that represents an option that can be passed to [NewKeyManager].
+type KeyManagerOpt func(opts *keyManagerOpts)
+
+// Endpoint is an option that can be passed to [NewKeyManager]. It sets the URL of the KMS API, which
+// defaults to https://kms.<region>.amazonaws.com. Useful for VPC endpoints and local KMS stand-ins.
+func Endpoint(url string) KeyManagerOpt {
+	return func(opts *keyManagerOpts) {
+		opts.endpoint = url
+	}
+}
+
+// AliasPrefix is an option that can be passed to [NewKeyManager]. It sets the prefix of the KMS aliases of
+// keys, which defaults to "alias/web5/".
+func AliasPrefix(prefix string) KeyManagerOpt {
+	return func(opts *keyManagerOpts) {
+		opts.aliasPrefix = prefix
+	}
+}
+
+// HTTPClient is an option that can be passed to [NewKeyManager]. It sets the HTTP client used to call
+// KMS, which defaults to [http.DefaultClient].
+func HTTPClient(client *http.Client) KeyManagerOpt {
+	return func(opts *keyManagerOpts) {
+		opts.client = client
+	}
+}
+
+// NewKeyManager returns a KeyManager for KMS in the given region (e.g. us-east-1), signing requests with
+// the given credentials. The credentials need the kms:CreateKey, kms:CreateAlias, kms:GetPublicKey and
+// kms:Sign permissions.
+func NewKeyManager(region string, credentials Credentials, opts ...KeyManagerOpt) *KeyManager {
+	o := keyManagerOpts{
+		endpoint:    "https://kms." + region + ".amazonaws.com",
+		aliasPrefix: "alias/web5/",
+		client:      http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &KeyManager{
+		endpoint:    o.endpoint,
+		region:      region,
+		credentials: credentials,
+		aliasPrefix: o.aliasPrefix,
+		client:      o.client,
+		keys:        make(map[string]keyRef),
+	}
+}
+
+// GeneratePrivateKey creates a new key in KMS and returns its key id. Supported algorithms are
+// [dsa.AlgorithmIDSECP256K1], [dsa.AlgorithmIDSECP256R1] and [dsa.AlgorithmIDSECP384R1].
+func (k *KeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	return k.GeneratePrivateKeyWithContext(context.Background(), algorithmID)
+}
+
+// GeneratePrivateKeyWithContext is like [KeyManager.GeneratePrivateKey], with the given context
+func (k *KeyManager) GeneratePrivateKeyWithContext(ctx context.Context, algorithmID string) (string, error) {
+	spec, ok := keySpecByAlgorithmID(algorithmID)
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	}
+
+	req := map[string]any{
+		"KeySpec":     spec.name,
+		"KeyUsage":    "SIGN_VERIFY",
+		"Description": "web5 " + algorithmID + " key",
+	}
+
+	var res struct {
+		KeyMetadata struct {
+			Arn string `json:"Arn"`
+		} `json:"KeyMetadata"`
+	}
+
+	if err := k.do(ctx, "CreateKey", req, &res); err != nil {
+		return "", fmt.Errorf("failed to create key: %w", err)
+	}
+
+	keyID, err := k.RegisterKeyWithContext(ctx, res.KeyMetadata.Arn)
+	if err != nil {
+		return "", fmt.Errorf("failed to register key %s: %w", res.KeyMetadata.Arn, err)
+	}
+
+	return keyID, nil
+}
+
+// RegisterKey gives an existing KMS key, identified by its ARN or key id, the alias used to find it, and
+// returns its key id. The key must be an asymmetric signing key of a supported key spec.
+func (k *KeyManager) RegisterKey(keyARN string) (string, error) {
+	return k.RegisterKeyWithContext(context.Background(), keyARN)
+}
+
+// RegisterKeyWithContext is like [KeyManager.RegisterKey], with the given context
+func (k *KeyManager) RegisterKeyWithContext(ctx context.Context, keyARN string) (string, error) {
+	ref, err := k.getPublicKey(ctx, keyARN)
+	if err != nil {
+		return "", err
+	}
+
+	keyID, err := ref.key.ComputeThumbprint()
+	if err != nil {
+		return "", fmt.Errorf("failed to compute key alias: %w", err)
+	}
+
+	req := map[string]any{"AliasName": k.aliasPrefix + keyID, "TargetKeyId": ref.arn}
+	if err := k.do(ctx, "CreateAlias", req, nil); err != nil && !isErrorType(err, "AlreadyExistsException") {
+		return "", fmt.Errorf("failed to create alias: %w", err)
+	}
+
+	k.mu.Lock()
+	k.keys[keyID] = ref
+	k.mu.Unlock()
+
+	return keyID, nil
+}
+
+// KeyARN returns the ARN of the KMS key for the given key id
+func (k *KeyManager) KeyARN(keyID string) (string, error) {
+	return k.KeyARNWithContext(context.Background(), keyID)
+}
+
+// KeyARNWithContext is like [KeyManager.KeyARN], with the given context
+func (k *KeyManager) KeyARNWithContext(ctx context.Context, keyID string) (string, error) {
+	ref, err := k.lookup(ctx, keyID)
+	if err != nil {
+		return "", err
+	}
+
+	return ref.arn, nil
+}
+
+// GetPublicKey returns the public key for the given key id
+func (k *KeyManager) GetPublicKey(keyID string) (jwk.JWK, error) {
+	return k.GetPublicKeyWithContext(context.Background(), keyID)
+}
+
+// GetPublicKeyWithContext is like [KeyManager.GetPublicKey], with the given context
+func (k *KeyManager) GetPublicKeyWithContext(ctx context.Context, keyID string) (jwk.JWK, error) {
+	ref, err := k.lookup(ctx, keyID)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return ref.key, nil
+}
+
+// Sign signs the payload with the key for the given key id. Signatures are returned in the JOSE format, the
+// fixed size r and s values concatenated, with s normalized to the lower half of the curve order, like
+// [dsa.Sign].
+func (k *KeyManager) Sign(keyID string, payload []byte) ([]byte, error) {
+	return k.SignWithContext(context.Background(), keyID, payload)
+}
+
+// SignWithContext is like [KeyManager.Sign], with the given context
+func (k *KeyManager) SignWithContext(ctx context.Context, keyID string, payload []byte) ([]byte, error) {
+	ref, err := k.lookup(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// signing the digest lifts the 4096 byte limit on raw messages
+	return k.signDigest(ctx, ref, ref.spec.digest(payload))
+}
+
+// SignDigest signs a digest computed by the caller with the key for the given key id. The digest must be
+// of the hash of the key's signing algorithm: SHA-256 for secp256k1 and P-256, SHA-384 for P-384.
+func (k *KeyManager) SignDigest(keyID string, digest []byte) ([]byte, error) {
+	return k.SignDigestWithContext(context.Background(), keyID, digest)
+}
+
+// SignDigestWithContext is like [KeyManager.SignDigest], with the given context
+func (k *KeyManager) SignDigestWithContext(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	ref, err := k.lookup(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(digest) != ref.spec.size {
+		return nil, fmt.Errorf("digest must be %d bytes for %s, got %d", ref.spec.size, ref.spec.signingAlgorithm, len(digest))
+	}
+
+	return k.signDigest(ctx, ref, digest)
+}
+
+func (k *KeyManager) signDigest(ctx context.Context, ref keyRef, digest []byte) ([]byte, error) {
+	req := map[string]any{
+		"KeyId":            ref.arn,
+		"Message":          digest,
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": ref.spec.signingAlgorithm,
+	}
+
+	var res struct {
+		Signature []byte `json:"Signature"`
+	}
+
+	if err := k.do(ctx, "Sign", req, &res); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	signature, err := derToJOSE(res.Signature, ref.spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert signature: %w", err)
+	}
+
+	return signature, nil
+}
+
+// isKMSKeyID reports whether the key id is a KMS key id, key ARN, alias name or alias ARN rather than a
+// thumbprint. https://docs.aws.amazon.com/kms/latest/developerguide/concepts.html#key-id
+func isKMSKeyID(keyID string) bool {
+	if strings.HasPrefix(keyID, "arn:") || strings.HasPrefix(keyID, "alias/") || strings.HasPrefix(keyID, "mrk-") {
+		return true
+	}
+
+	_, err := uuid.Parse(keyID)
+	return err == nil && len(keyID) == 36
+}
+
+// lookup returns the KMS key for the given key id, finding it by its alias if it isn't cached yet
+func (k *KeyManager) lookup(ctx context.Context, keyID string) (keyRef, error) {
+	k.mu.RLock()
+	ref, ok := k.keys[keyID]
+	k.mu.RUnlock()
+
+	if ok {
+		return ref, nil
+	}
+
+	if isKMSKeyID(keyID) {
+		ref, err := k.getPublicKey(ctx, keyID)
+		if err != nil {
+			return keyRef{}, err
+		}
+
+		k.mu.Lock()
+		k.keys[keyID] = ref
+		k.mu.Unlock()
+
+		return ref, nil
+	}
+
+	ref, err := k.getPublicKey(ctx, k.aliasPrefix+keyID)
+	if isErrorType(err, "NotFoundException") {
+		return keyRef{}, fmt.Errorf("key with alias %s not found", keyID)
+	} else if err != nil {
+		return keyRef{}, err
+	}
+
+	// guard against aliases pointing at the wrong key
+	thumbprint, err := ref.key.ComputeThumbprint()
+	if err != nil {
+		return keyRef{}, fmt.Errorf("failed to compute key alias: %w", err)
+	}
+
+	if thumbprint != keyID {
+		return keyRef{}, fmt.Errorf("alias %s%s points at key %s with a different public key", k.aliasPrefix, keyID, ref.arn)
+	}
+
+	k.mu.Lock()
+	k.keys[keyID] = ref
+	k.mu.Unlock()
+
+	return ref, nil
+}
+
+// getPublicKey returns the public key of the KMS key with the given key id, ARN or alias
+func (k *KeyManager) getPublicKey(ctx context.Context, kmsKeyID string) (keyRef, error) {
+	var res struct {
+		KeyID     string `json:"KeyId"`
+		KeySpec   string `json:"KeySpec"`
+		KeyUsage  string `json:"KeyUsage"`
+		PublicKey []byte `json:"PublicKey"`
+	}
+
+	if err := k.do(ctx, "GetPublicKey", map[string]any{"KeyId": kmsKeyID}, &res); err != nil {
+		return keyRef{}, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	spec, ok := keySpecByName(res.KeySpec)
+	if !ok {
+		return keyRef{}, fmt.Errorf("unsupported key spec: %s", res.KeySpec)
+	}
+
+	if res.KeyUsage != "SIGN_VERIFY" {
+		return keyRef{}, fmt.Errorf("unsupported key usage: %s", res.KeyUsage)
+	}
+
+	key, err := spkiToJWK(res.PublicKey, spec)
+	if err != nil {
+		return keyRef{}, fmt.Errorf("failed to convert public key: %w", err)
+	}
+
+	return keyRef{arn: res.KeyID, spec: spec, key: key}, nil
+}
+
+// subjectPublicKeyInfo is the X.509 DER encoding of public keys returned by KMS.
+// https://datatracker.ietf.org/doc/html/rfc5480#section-2
+type subjectPublicKeyInfo struct {
+	Algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.RawValue `asn1:"optional"`
+	}
+	PublicKey asn1.BitString
+}
+
+// spkiToJWK converts a DER encoded subjectPublicKeyInfo to a JWK. It's parsed by hand, as the x509 package
+// doesn't support secp256k1.
+func spkiToJWK(der []byte, spec keySpec) (jwk.JWK, error) {
+	var spki subjectPublicKeyInfo
+	rest, err := asn1.Unmarshal(der, &spki)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	if len(rest) > 0 {
+		return jwk.JWK{}, errors.New("trailing data after public key")
+	}
+
+	return dsa.BytesToPublicKey(spec.algorithmID, spki.PublicKey.RightAlign())
+}
+
+// derToJOSE converts a DER encoded ECDSA signature to the fixed size r || s JOSE format, normalizing s to
+// the lower half of the curve order, as some verifiers (e.g. for secp256k1) reject high s values.
+// https://datatracker.ietf.org/doc/html/rfc7518#section-3.4
+func derToJOSE(der []byte, spec keySpec) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after signature")
+	}
+
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.Cmp(spec.order) >= 0 || sig.S.Cmp(spec.order) >= 0 {
+		return nil, errors.New("signature values out of range")
+	}
+
+	halfOrder := new(big.Int).Rsh(spec.order, 1)
+	if sig.S.Cmp(halfOrder) > 0 {
+		sig.S.Sub(spec.order, sig.S)
+	}
+
+	signature := make([]byte, 2*spec.size)
+	sig.R.FillBytes(signature[:spec.size])
+	sig.S.FillBytes(signature[spec.size:])
+
+	return signature, nil
+}
+
+// kmsError is the error returned by the KMS API
+type kmsError struct {
+	Type    string
+	Message string
+}
+
+func (e *kmsError) Error() string {
+	return e.Type + ": " + e.Message
+}
+
+// isErrorType reports whether err is a KMS error of the given type, e.g. NotFoundException
+func isErrorType(err error, errorType string) bool {
+	var kmsErr *kmsError
+	return errors.As(err, &kmsErr) && kmsErr.Type == errorType
+}
+
+// do calls the given KMS action, decoding the response into res, if not nil
+func (k *KeyManager) do(ctx context.Context, action string, body any, res any) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	signRequest(req, reqBody, k.credentials, k.region, "kms", time.Now())
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// the message member is named message or Message, depending on the error
+		var errRes struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+
+		if err := json.Unmarshal(respBody, &errRes); err != nil || errRes.Type == "" {
+			return fmt.Errorf("kms returned %s: %s", resp.Status, string(respBody))
+		}
+
+		// types may be namespaced, e.g. com.amazonaws.kms#NotFoundException
+		errorType := errRes.Type[strings.LastIndex(errRes.Type, "#")+1:]
+		return &kmsError{Type: errorType, Message: errRes.Message}
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, res); err != nil {
+		return fmt.Errorf("failed to decode kms response: %w", err)
+	}
+
+	return nil
+}
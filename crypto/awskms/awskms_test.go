@@ -0,0 +1,420 @@
+package awskms_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	web5crypto "github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/awskms"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/dids/didjwk"
+	"github.com/decentralized-identity/web5-go/jws"
+	_secp256k1 "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	_ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/google/uuid"
+)
+
+var testCredentials = awskms.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+
+// kmsKey is a key held by the stand-in KMS server
+type kmsKey struct {
+	arn     string
+	keySpec string
+	// one of them is set, depending on the key spec
+	nistKey *ecdsa.PrivateKey
+	k1Key   *_secp256k1.PrivateKey
+}
+
+// publicKey returns the DER encoded subjectPublicKeyInfo of the key
+func (k *kmsKey) publicKey(t *testing.T) []byte {
+	t.Helper()
+
+	if k.nistKey != nil {
+		der, err := x509.MarshalPKIXPublicKey(&k.nistKey.PublicKey)
+		assert.NoError(t, err)
+		return der
+	}
+
+	// x509 doesn't support secp256k1
+	curve, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+	assert.NoError(t, err)
+
+	der, err := asn1.Marshal(struct {
+		Algorithm struct {
+			Algorithm  asn1.ObjectIdentifier
+			Parameters asn1.RawValue
+		}
+		PublicKey asn1.BitString
+	}{
+		Algorithm: struct {
+			Algorithm  asn1.ObjectIdentifier
+			Parameters asn1.RawValue
+		}{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1},
+			Parameters: asn1.RawValue{FullBytes: curve},
+		},
+		PublicKey: asn1.BitString{Bytes: k.k1Key.PubKey().SerializeUncompressed(), BitLength: 65 * 8},
+	})
+	assert.NoError(t, err)
+
+	return der
+}
+
+// sign signs the digest, returning a DER signature. s is always flipped to the upper half of the curve
+// order, to check that the key manager normalizes it.
+func (k *kmsKey) sign(t *testing.T, digest []byte) []byte {
+	t.Helper()
+
+	var sig struct{ R, S *big.Int }
+	var order *big.Int
+	if k.nistKey != nil {
+		r, s, err := ecdsa.Sign(rand.Reader, k.nistKey, digest)
+		assert.NoError(t, err)
+		sig.R, sig.S, order = r, s, k.nistKey.Curve.Params().N
+	} else {
+		_, err := asn1.Unmarshal(_ecdsa.Sign(k.k1Key, digest).Serialize(), &sig)
+		assert.NoError(t, err)
+		order = _secp256k1.S256().Params().N
+	}
+
+	if sig.S.Cmp(new(big.Int).Rsh(order, 1)) <= 0 {
+		sig.S.Sub(order, sig.S)
+	}
+
+	der, err := asn1.Marshal(sig)
+	assert.NoError(t, err)
+
+	return der
+}
+
+// testKMS mimics the parts of the KMS API used by the KeyManager.
+// https://docs.aws.amazon.com/kms/latest/APIReference/Welcome.html
+type testKMS struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*kmsKey
+	aliases map[string]string
+}
+
+func newTestKMS(t *testing.T) *testKMS {
+	t.Helper()
+
+	kms := &testKMS{keys: map[string]*kmsKey{}, aliases: map[string]string{}}
+	kms.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError := func(errorType string, message string) {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"__type": errorType, "message": message})
+		}
+
+		writeResult := func(result any) {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			_ = json.NewEncoder(w).Encode(result)
+		}
+
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/us-east-1/kms/aws4_request") {
+			writeError("UnrecognizedClientException", "The security token included in the request is invalid.")
+			return
+		}
+
+		var req struct {
+			KeyID            string `json:"KeyId"`
+			KeySpec          string `json:"KeySpec"`
+			KeyUsage         string `json:"KeyUsage"`
+			AliasName        string `json:"AliasName"`
+			TargetKeyID      string `json:"TargetKeyId"`
+			Message          []byte `json:"Message"`
+			MessageType      string `json:"MessageType"`
+			SigningAlgorithm string `json:"SigningAlgorithm"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		kms.mu.Lock()
+		defer kms.mu.Unlock()
+
+		action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "TrentService.")
+		switch action {
+		case "CreateKey":
+			assert.Equal(t, "SIGN_VERIFY", req.KeyUsage)
+			key := kms.createKey(t, req.KeySpec)
+			if key == nil {
+				writeError("ValidationException", "unsupported key spec "+req.KeySpec)
+				return
+			}
+
+			writeResult(map[string]any{"KeyMetadata": map[string]any{"Arn": key.arn, "KeySpec": key.keySpec}})
+
+		case "CreateAlias":
+			if _, ok := kms.aliases[req.AliasName]; ok {
+				writeError("AlreadyExistsException", "An alias with the name "+req.AliasName+" already exists")
+				return
+			}
+
+			key, ok := kms.resolve(req.TargetKeyID)
+			if !ok {
+				writeError("NotFoundException", "Key '"+req.TargetKeyID+"' does not exist")
+				return
+			}
+
+			kms.aliases[req.AliasName] = key.arn
+			writeResult(map[string]any{})
+
+		case "GetPublicKey":
+			key, ok := kms.resolve(req.KeyID)
+			if !ok {
+				writeError("NotFoundException", "Key '"+req.KeyID+"' does not exist")
+				return
+			}
+
+			writeResult(map[string]any{
+				"KeyId":     key.arn,
+				"KeySpec":   key.keySpec,
+				"KeyUsage":  "SIGN_VERIFY",
+				"PublicKey": key.publicKey(t),
+			})
+
+		case "Sign":
+			key, ok := kms.resolve(req.KeyID)
+			if !ok {
+				writeError("NotFoundException", "Key '"+req.KeyID+"' does not exist")
+				return
+			}
+
+			expected := map[string]string{"ECC_SECG_P256K1": "ECDSA_SHA_256", "ECC_NIST_P256": "ECDSA_SHA_256", "ECC_NIST_P384": "ECDSA_SHA_384"}
+			if req.MessageType != "DIGEST" || req.SigningAlgorithm != expected[key.keySpec] {
+				writeError("ValidationException", "unexpected message type or signing algorithm")
+				return
+			}
+
+			writeResult(map[string]any{"KeyId": key.arn, "Signature": key.sign(t, req.Message), "SigningAlgorithm": req.SigningAlgorithm})
+
+		default:
+			writeError("UnknownOperationException", action)
+		}
+	}))
+	t.Cleanup(kms.Close)
+
+	return kms
+}
+
+// createKey creates a key of the given key spec, or returns nil if the key spec is not supported.
+// kms.mu must be held.
+func (kms *testKMS) createKey(t *testing.T, keySpec string) *kmsKey {
+	t.Helper()
+
+	key := &kmsKey{arn: "arn:aws:kms:us-east-1:111122223333:key/" + uuid.NewString(), keySpec: keySpec}
+
+	var err error
+	switch keySpec {
+	case "ECC_SECG_P256K1":
+		key.k1Key, err = _secp256k1.GeneratePrivateKey()
+	case "ECC_NIST_P256":
+		key.nistKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ECC_NIST_P384":
+		key.nistKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	default:
+		return nil
+	}
+	assert.NoError(t, err)
+
+	kms.keys[key.arn] = key
+	return key
+}
+
+// resolve returns the key with the given ARN or alias. kms.mu must be held.
+func (kms *testKMS) resolve(keyID string) (*kmsKey, bool) {
+	if arn, ok := kms.aliases[keyID]; ok {
+		keyID = arn
+	}
+
+	key, ok := kms.keys[keyID]
+	return key, ok
+}
+
+func TestKeyManager(t *testing.T) {
+	kms := newTestKMS(t)
+	keyManager := awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL))
+
+	algorithms := map[string]*big.Int{
+		dsa.AlgorithmIDSECP256K1: _secp256k1.S256().Params().N,
+		dsa.AlgorithmIDSECP256R1: elliptic.P256().Params().N,
+		dsa.AlgorithmIDSECP384R1: elliptic.P384().Params().N,
+	}
+
+	for algorithmID, order := range algorithms {
+		t.Run(algorithmID, func(t *testing.T) {
+			keyID, err := keyManager.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+
+			publicKey, err := keyManager.GetPublicKey(keyID)
+			assert.NoError(t, err)
+			assert.Zero(t, publicKey.D)
+
+			thumbprint, err := publicKey.ComputeThumbprint()
+			assert.NoError(t, err)
+			assert.Equal(t, keyID, thumbprint)
+
+			keyAlgorithmID, err := dsa.AlgorithmID(&publicKey)
+			assert.NoError(t, err)
+			assert.Equal(t, algorithmID, keyAlgorithmID)
+
+			arn, err := keyManager.KeyARN(keyID)
+			assert.NoError(t, err)
+
+			kms.mu.Lock()
+			assert.Equal(t, arn, kms.aliases["alias/web5/"+keyID])
+			kms.mu.Unlock()
+
+			payload := []byte("hello")
+			signature, err := keyManager.Sign(keyID, payload)
+			assert.NoError(t, err)
+
+			legit, err := dsa.Verify(payload, signature, publicKey)
+			assert.NoError(t, err)
+			assert.True(t, legit, "expected signature to be valid")
+
+			s := new(big.Int).SetBytes(signature[len(signature)/2:])
+			assert.True(t, s.Cmp(new(big.Int).Rsh(order, 1)) <= 0, "expected low-S signature")
+		})
+	}
+}
+
+func TestKeyManager_ExistingKeys(t *testing.T) {
+	kms := newTestKMS(t)
+
+	keyID, err := awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL)).
+		GeneratePrivateKey(dsa.AlgorithmIDSECP256K1)
+	assert.NoError(t, err)
+
+	// a new key manager, e.g. after a restart, finds keys by their alias
+	keyManager := awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL))
+
+	signature, err := keyManager.Sign(keyID, []byte("hello"))
+	assert.NoError(t, err)
+
+	publicKey, err := keyManager.GetPublicKey(keyID)
+	assert.NoError(t, err)
+
+	legit, err := dsa.Verify([]byte("hello"), signature, publicKey)
+	assert.NoError(t, err)
+	assert.True(t, legit, "expected signature to be valid")
+
+	// keys created outside of the key manager are registered by ARN
+	kms.mu.Lock()
+	existing := kms.createKey(t, "ECC_NIST_P256")
+	kms.mu.Unlock()
+
+	existingKeyID, err := keyManager.RegisterKey(existing.arn)
+	assert.NoError(t, err)
+
+	arn, err := awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL)).KeyARN(existingKeyID)
+	assert.NoError(t, err)
+	assert.Equal(t, existing.arn, arn)
+
+	// registering twice is fine
+	_, err = keyManager.RegisterKey(existing.arn)
+	assert.NoError(t, err)
+}
+
+func TestKeyManager_KMSKeyIDs(t *testing.T) {
+	kms := newTestKMS(t)
+
+	kms.mu.Lock()
+	key := kms.createKey(t, "ECC_NIST_P256")
+	kms.aliases["alias/issuer"] = key.arn
+	kms.mu.Unlock()
+
+	keyManager := awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL))
+	for _, keyID := range []string{key.arn, "alias/issuer"} {
+		publicKey, err := keyManager.GetPublicKey(keyID)
+		assert.NoError(t, err)
+
+		signature, err := keyManager.Sign(keyID, []byte("hello"))
+		assert.NoError(t, err)
+
+		legit, err := dsa.Verify([]byte("hello"), signature, publicKey)
+		assert.NoError(t, err)
+		assert.True(t, legit, "expected signature to be valid")
+	}
+}
+
+func TestKeyManager_Errors(t *testing.T) {
+	kms := newTestKMS(t)
+	keyManager := awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL))
+
+	_, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.EqualError(t, err, "unsupported algorithm: Ed25519")
+
+	_, err = keyManager.GetPublicKey("unknown")
+	assert.EqualError(t, err, "key with alias unknown not found")
+
+	_, err = awskms.NewKeyManager("eu-west-1", testCredentials, awskms.Endpoint(kms.URL)).GeneratePrivateKey(dsa.AlgorithmIDSECP256K1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "UnrecognizedClientException")
+
+	// an alias pointing at a different key is rejected
+	keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDSECP256K1)
+	assert.NoError(t, err)
+
+	kms.mu.Lock()
+	kms.aliases["alias/web5/"+keyID] = kms.createKey(t, "ECC_SECG_P256K1").arn
+	kms.mu.Unlock()
+
+	_, err = awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL)).Sign(keyID, []byte("hello"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "with a different public key")
+}
+
+func TestKeyManager_BearerDID(t *testing.T) {
+	kms := newTestKMS(t)
+
+	var keyManager web5crypto.KeyManager = awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL))
+	_, ok := keyManager.(web5crypto.KeyManagerWithContext)
+	assert.True(t, ok, "expected key manager to be context aware")
+
+	bearerDID, err := didjwk.Create(didjwk.KeyManager(keyManager), didjwk.AlgorithmID(dsa.AlgorithmIDSECP256K1))
+	assert.NoError(t, err)
+
+	compactJWS, err := jws.Sign([]byte("hello"), bearerDID)
+	assert.NoError(t, err)
+
+	decoded, err := jws.Verify(compactJWS)
+	assert.NoError(t, err)
+	assert.Equal(t, "ES256K", decoded.Header.ALG)
+}
+
+func TestKeyManager_Signer(t *testing.T) {
+	kms := newTestKMS(t)
+	keyManager := awskms.NewKeyManager("us-east-1", testCredentials, awskms.Endpoint(kms.URL))
+
+	keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	assert.NoError(t, err)
+
+	signer, err := web5crypto.NewSigner(keyManager, keyID)
+	assert.NoError(t, err)
+
+	template := &x509.CertificateRequest{Subject: pkix.Name{CommonName: "web5"}}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, signer)
+	assert.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(der)
+	assert.NoError(t, err)
+	assert.NoError(t, csr.CheckSignature())
+
+	_, err = keyManager.SignDigest(keyID, []byte("too short"))
+	assert.EqualError(t, err, "digest must be 32 bytes for ECDSA_SHA_256, got 9")
+}
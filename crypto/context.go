@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"context"
+
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// KeyManagerWithContext is a [KeyManager] whose operations honor the deadline and cancellation of a context.
+// It is typically implemented by key managers backed by a remote service, e.g. a cloud KMS.
+//
+// Use [WithContext] and [WithoutContext] to convert between the two.
+type KeyManagerWithContext interface {
+	// GeneratePrivateKeyWithContext generates a new private key, stores it in the key store and returns the key id
+	GeneratePrivateKeyWithContext(ctx context.Context, algorithmID string) (string, error)
+
+	// GetPublicKeyWithContext returns the public key for the given key id
+	GetPublicKeyWithContext(ctx context.Context, keyID string) (jwk.JWK, error)
+
+	// SignWithContext signs the given payload with the private key for the given key id
+	SignWithContext(ctx context.Context, keyID string, payload []byte) ([]byte, error)
+}
+
+// WithContext returns a [KeyManagerWithContext] for the given key manager. If the key manager already
+// implements [KeyManagerWithContext] it is returned as is. Otherwise, the returned key manager checks the
+// context before each call, as local key managers don't block.
+//
+// The returned adapter only has the methods of [KeyManager] and [KeyManagerWithContext]. Optional interfaces
+// of the key manager, e.g. [KeyExporter] or [KeyAgreer], are not forwarded, so check for them on the key
+// manager itself.
+func WithContext(keyManager KeyManager) KeyManagerWithContext {
+	if km, ok := keyManager.(KeyManagerWithContext); ok {
+		return km
+	}
+
+	return contextKeyManager{keyManager}
+}
+
+// WithoutContext returns a [KeyManager] for the given context aware key manager, which calls it with
+// [context.Background]. The returned key manager also implements [KeyManagerWithContext], so functions that
+// take a context, e.g. [github.com/decentralized-identity/web5-go/dids/did.BearerDID.GetSignerWithContext],
+// still pass theirs through. If the key manager already implements [KeyManager] it is returned as is.
+// Like [WithContext], the returned adapter does not forward optional interfaces such as [KeyExporter] or
+// [KeyAgreer].
+func WithoutContext(keyManager KeyManagerWithContext) KeyManager {
+	if km, ok := keyManager.(KeyManager); ok {
+		return km
+	}
+
+	return backgroundKeyManager{keyManager}
+}
+
+// contextKeyManager adapts a [KeyManager] to [KeyManagerWithContext]
+type contextKeyManager struct {
+	KeyManager
+}
+
+func (k contextKeyManager) GeneratePrivateKeyWithContext(ctx context.Context, algorithmID string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return k.GeneratePrivateKey(algorithmID)
+}
+
+func (k contextKeyManager) GetPublicKeyWithContext(ctx context.Context, keyID string) (jwk.JWK, error) {
+	if err := ctx.Err(); err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return k.GetPublicKey(keyID)
+}
+
+func (k contextKeyManager) SignWithContext(ctx context.Context, keyID string, payload []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return k.Sign(keyID, payload)
+}
+
+// backgroundKeyManager adapts a [KeyManagerWithContext] to [KeyManager]
+type backgroundKeyManager struct {
+	KeyManagerWithContext
+}
+
+func (k backgroundKeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	return k.GeneratePrivateKeyWithContext(context.Background(), algorithmID)
+}
+
+func (k backgroundKeyManager) GetPublicKey(keyID string) (jwk.JWK, error) {
+	return k.GetPublicKeyWithContext(context.Background(), keyID)
+}
+
+func (k backgroundKeyManager) Sign(keyID string, payload []byte) ([]byte, error) {
+	return k.SignWithContext(context.Background(), keyID, payload)
+}
@@ -0,0 +1,89 @@
+package crypto_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// remoteKeyManager is a context aware key manager that records the context it was called with
+type remoteKeyManager struct {
+	keyManager *crypto.LocalKeyManager
+	ctx        context.Context
+}
+
+func (k *remoteKeyManager) GeneratePrivateKeyWithContext(ctx context.Context, algorithmID string) (string, error) {
+	k.ctx = ctx
+	return k.keyManager.GeneratePrivateKey(algorithmID)
+}
+
+func (k *remoteKeyManager) GetPublicKeyWithContext(ctx context.Context, keyID string) (jwk.JWK, error) {
+	k.ctx = ctx
+	return k.keyManager.GetPublicKey(keyID)
+}
+
+func (k *remoteKeyManager) SignWithContext(ctx context.Context, keyID string, payload []byte) ([]byte, error) {
+	k.ctx = ctx
+	return k.keyManager.Sign(keyID, payload)
+}
+
+func TestWithContext(t *testing.T) {
+	keyManager := crypto.WithContext(crypto.NewLocalKeyManager())
+
+	keyID, err := keyManager.GeneratePrivateKeyWithContext(context.Background(), dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
+	publicKey, err := keyManager.GetPublicKeyWithContext(context.Background(), keyID)
+	assert.NoError(t, err)
+
+	payload := []byte("hi")
+	signature, err := keyManager.SignWithContext(context.Background(), keyID, payload)
+	assert.NoError(t, err)
+
+	legit, err := dsa.Verify(payload, signature, publicKey)
+	assert.NoError(t, err)
+	assert.True(t, legit, "expected signature to be valid")
+}
+
+func TestWithContext_Canceled(t *testing.T) {
+	keyManager := crypto.WithContext(crypto.NewLocalKeyManager())
+
+	keyID, err := keyManager.GeneratePrivateKeyWithContext(context.Background(), dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = keyManager.GeneratePrivateKeyWithContext(ctx, dsa.AlgorithmIDED25519)
+	assert.IsError(t, err, context.Canceled)
+
+	_, err = keyManager.GetPublicKeyWithContext(ctx, keyID)
+	assert.IsError(t, err, context.Canceled)
+
+	_, err = keyManager.SignWithContext(ctx, keyID, []byte("hi"))
+	assert.IsError(t, err, context.Canceled)
+}
+
+func TestWithoutContext(t *testing.T) {
+	remote := &remoteKeyManager{keyManager: crypto.NewLocalKeyManager()}
+	keyManager := crypto.WithoutContext(remote)
+
+	keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+	assert.Equal(t, context.Background(), remote.ctx)
+
+	_, err = keyManager.Sign(keyID, []byte("hi"))
+	assert.NoError(t, err)
+
+	// the context is passed through when converting back
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	_, err = crypto.WithContext(keyManager).SignWithContext(ctx, keyID, []byte("hi"))
+	assert.NoError(t, err)
+	assert.Equal(t, "value", remote.ctx.Value(ctxKey{}))
+}
@@ -1,6 +1,12 @@
 // Package crypto provides the following functionality:
-// * Key Generation: secp256k1, ed25519
-// * Signing: secp256k1, ed25519
-// * Verification: secp256k1, ed25519
+// * Key Generation: secp256k1, secp256r1 (P-256), secp384r1 (P-384), ed25519, x25519
+// * Signing: secp256k1, secp256r1 (P-256), secp384r1 (P-384), ed25519
+// * Verification: secp256k1, secp256r1 (P-256), secp384r1 (P-384), ed25519
+// * Key Agreement (ECDH): x25519, secp256k1, secp256r1 (P-256), secp384r1 (P-384)
 // * A KeyManager abstraction that can be leveraged to manage/use keys (create, sign etc) as desired per the given use case
+// * KeyManager implementations that store keys in memory, encrypted in a keystore file, in HashiCorp Vault or in AWS KMS
+// * A KeyManager implementation that derives keys from a BIP-39 mnemonic, so that DIDs can be recreated from it
+// * Optional KeyManager interfaces to list, delete, label and rotate keys
+// * A standard library crypto.Signer for KeyManager keys, and conversions between JWKs and standard library keys
+// * A context aware KeyManager variant for remote key managers, with adapters in both directions
 package crypto
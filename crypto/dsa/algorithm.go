@@ -0,0 +1,276 @@
+package dsa
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/eddsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/mldsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/schnorr"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// Algorithm is a digital signature algorithm. The functions of this package dispatch to the registered
+// algorithm whose ID matches the given algorithm ID, or which supports the given key. Additional algorithms
+// can be made available with [Register].
+type Algorithm interface {
+	// ID returns the algorithm ID, e.g. [AlgorithmIDED25519]
+	ID() string
+	// JWA returns the [JWA] used in the alg header of JWSs signed with the algorithm
+	//
+	// [JWA]: https://datatracker.ietf.org/doc/html/rfc7518#section-3.1
+	JWA() string
+	// SupportsKey informs as to whether or not the given public or private key belongs to the algorithm
+	SupportsKey(key jwk.JWK) bool
+	// GeneratePrivateKey generates a private key
+	GeneratePrivateKey() (jwk.JWK, error)
+	// GetPublicKey returns the public key corresponding to the given private key
+	GetPublicKey(privateKey jwk.JWK) jwk.JWK
+	// Sign signs the payload using the given private key
+	Sign(payload []byte, privateKey jwk.JWK) ([]byte, error)
+	// Verify verifies the signature of the payload using the given public key
+	Verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error)
+	// BytesToPublicKey deserializes the given bytes into a public key
+	BytesToPublicKey(input []byte) (jwk.JWK, error)
+	// PublicKeyToBytes serializes the given public key into bytes
+	PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error)
+}
+
+// KeyRecoverer is implemented by algorithms whose signatures allow recovering the public key of the signer,
+// like [AlgorithmIDSECP256K1Recoverable]
+type KeyRecoverer interface {
+	// RecoverPublicKey recovers the public key that signed the payload from the signature
+	RecoverPublicKey(payload []byte, signature []byte) (jwk.JWK, error)
+}
+
+// registry holds the registered algorithms, in the order they were registered
+var registry = struct {
+	sync.RWMutex
+	algorithms []Algorithm
+}{
+	// algorithms that require the alg of keys come before the algorithm using the same keys by default
+	algorithms: []Algorithm{
+		recoverableAlgorithm{
+			builtinAlgorithm: builtinAlgorithm{
+				id: AlgorithmIDSECP256K1Recoverable, jwa: ecdsa.SECP256K1RecoverableJWA, requiresAlg: true,
+				kty: ecdsa.KeyType, crv: ecdsa.SECP256K1JWACurve,
+				generate: ecdsa.SECP256K1GeneratePrivateKey, getPublicKey: ecdsa.GetPublicKey,
+				sign: ecdsa.SECP256K1RecoverableSign, verify: ecdsa.SECP256K1RecoverableVerify,
+				bytesToPublicKey: ecdsa.SECP256K1BytesToPublicKey, publicKeyToBytes: ecdsa.PublicKeyToBytes,
+			},
+			recoverPublicKey: ecdsa.SECP256K1RecoverPublicKey,
+		},
+		builtinAlgorithm{
+			id: AlgorithmIDSECP256K1Schnorr, jwa: schnorr.JWA, requiresAlg: true,
+			kty: schnorr.KeyType, crv: schnorr.SECP256K1JWACurve,
+			generate: schnorr.SECP256K1GeneratePrivateKey, getPublicKey: ecdsa.GetPublicKey,
+			sign: schnorr.SECP256K1Sign, verify: schnorr.SECP256K1Verify,
+			bytesToPublicKey: schnorr.SECP256K1BytesToPublicKey, publicKeyToBytes: schnorr.SECP256K1PublicKeyToBytes,
+		},
+		builtinAlgorithm{
+			id: AlgorithmIDSECP256K1, jwa: ecdsa.SECP256K1JWA, kty: ecdsa.KeyType, crv: ecdsa.SECP256K1JWACurve,
+			generate: ecdsa.SECP256K1GeneratePrivateKey, getPublicKey: ecdsa.GetPublicKey,
+			sign: ecdsa.Sign, verify: ecdsa.Verify,
+			bytesToPublicKey: ecdsa.SECP256K1BytesToPublicKey, publicKeyToBytes: ecdsa.PublicKeyToBytes,
+		},
+		builtinAlgorithm{
+			id: AlgorithmIDSECP256R1, jwa: ecdsa.SECP256R1JWA, kty: ecdsa.KeyType, crv: ecdsa.SECP256R1JWACurve,
+			generate: ecdsa.SECP256R1GeneratePrivateKey, getPublicKey: ecdsa.GetPublicKey,
+			sign: ecdsa.Sign, verify: ecdsa.Verify,
+			bytesToPublicKey: ecdsa.SECP256R1BytesToPublicKey, publicKeyToBytes: ecdsa.PublicKeyToBytes,
+		},
+		builtinAlgorithm{
+			id: AlgorithmIDSECP384R1, jwa: ecdsa.SECP384R1JWA, kty: ecdsa.KeyType, crv: ecdsa.SECP384R1JWACurve,
+			generate: ecdsa.SECP384R1GeneratePrivateKey, getPublicKey: ecdsa.GetPublicKey,
+			sign: ecdsa.Sign, verify: ecdsa.Verify,
+			bytesToPublicKey: ecdsa.SECP384R1BytesToPublicKey, publicKeyToBytes: ecdsa.PublicKeyToBytes,
+		},
+		builtinAlgorithm{
+			id: AlgorithmIDED25519, jwa: eddsa.JWA, kty: eddsa.KeyType, crv: eddsa.ED25519JWACurve,
+			generate: eddsa.ED25519GeneratePrivateKey, getPublicKey: eddsa.GetPublicKey,
+			sign: eddsa.Sign, verify: eddsa.Verify,
+			bytesToPublicKey: eddsa.ED25519BytesToPublicKey, publicKeyToBytes: eddsa.PublicKeyToBytes,
+		},
+		// AKP keys have no curve. their alg is their parameter set.
+		builtinAlgorithm{
+			id: AlgorithmIDMLDSA44, jwa: mldsa.MLDSA44JWA, requiresAlg: true, kty: mldsa.KeyType,
+			generate: mldsa.MLDSA44GeneratePrivateKey, getPublicKey: mldsa.GetPublicKey,
+			sign: mldsa.Sign, verify: mldsa.Verify,
+			bytesToPublicKey: mldsa.MLDSA44BytesToPublicKey, publicKeyToBytes: mldsa.PublicKeyToBytes,
+		},
+		builtinAlgorithm{
+			id: AlgorithmIDMLDSA65, jwa: mldsa.MLDSA65JWA, requiresAlg: true, kty: mldsa.KeyType,
+			generate: mldsa.MLDSA65GeneratePrivateKey, getPublicKey: mldsa.GetPublicKey,
+			sign: mldsa.Sign, verify: mldsa.Verify,
+			bytesToPublicKey: mldsa.MLDSA65BytesToPublicKey, publicKeyToBytes: mldsa.PublicKeyToBytes,
+		},
+		builtinAlgorithm{
+			id: AlgorithmIDMLDSA87, jwa: mldsa.MLDSA87JWA, requiresAlg: true, kty: mldsa.KeyType,
+			generate: mldsa.MLDSA87GeneratePrivateKey, getPublicKey: mldsa.GetPublicKey,
+			sign: mldsa.Sign, verify: mldsa.Verify,
+			bytesToPublicKey: mldsa.MLDSA87BytesToPublicKey, publicKeyToBytes: mldsa.PublicKeyToBytes,
+		},
+	},
+}
+
+// Register makes the given algorithm available to the functions of this package, and everything built on
+// them such as key managers, DIDs and JWSs. It returns an error if an algorithm with the same ID is already
+// registered. Register is typically called from the init function of the package implementing the algorithm.
+func Register(algorithm Algorithm) error {
+	if algorithm == nil {
+		return errors.New("algorithm must not be nil")
+	}
+
+	if algorithm.ID() == "" {
+		return errors.New("algorithm ID must not be empty")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, registered := range registry.algorithms {
+		if registered.ID() == algorithm.ID() {
+			return fmt.Errorf("algorithm already registered: %s", algorithm.ID())
+		}
+	}
+
+	registry.algorithms = append(registry.algorithms, algorithm)
+	return nil
+}
+
+// GetAlgorithm returns the registered algorithm with the given algorithm ID
+func GetAlgorithm(algorithmID string) (Algorithm, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, algorithm := range registry.algorithms {
+		if algorithm.ID() == algorithmID {
+			return algorithm, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported algorithm: %s", algorithmID)
+}
+
+// GetAlgorithmForKey returns the first registered algorithm that supports the given key
+func GetAlgorithmForKey(key jwk.JWK) (Algorithm, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, algorithm := range registry.algorithms {
+		if algorithm.SupportsKey(key) {
+			return algorithm, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported key type: %s %s", key.KTY, key.CRV)
+}
+
+// GetAlgorithmForJWA returns the first registered algorithm with the given JWA, e.g. the alg of a JWS
+func GetAlgorithmForJWA(jwa string) (Algorithm, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, algorithm := range registry.algorithms {
+		if algorithm.JWA() == jwa {
+			return algorithm, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported alg: %s", jwa)
+}
+
+// SupportsAlgorithmID informs as to whether or not an algorithm with the given ID is registered
+func SupportsAlgorithmID(algorithmID string) bool {
+	_, err := GetAlgorithm(algorithmID)
+	return err == nil
+}
+
+// builtinAlgorithm adapts the algorithms of the ecdsa, eddsa, schnorr and mldsa packages to [Algorithm]
+type builtinAlgorithm struct {
+	id  string
+	jwa string
+	// requiresAlg restricts the algorithm to keys whose alg is its JWA, for algorithms sharing their keys
+	// with another algorithm, e.g. ES256K-R and ES256K. Keys of the algorithm have their alg set.
+	requiresAlg      bool
+	kty              string
+	crv              string
+	generate         func() (jwk.JWK, error)
+	getPublicKey     func(privateKey jwk.JWK) jwk.JWK
+	sign             func(payload []byte, privateKey jwk.JWK) ([]byte, error)
+	verify           func(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error)
+	bytesToPublicKey func(input []byte) (jwk.JWK, error)
+	publicKeyToBytes func(publicKey jwk.JWK) ([]byte, error)
+}
+
+func (a builtinAlgorithm) ID() string {
+	return a.id
+}
+
+func (a builtinAlgorithm) JWA() string {
+	return a.jwa
+}
+
+func (a builtinAlgorithm) SupportsKey(key jwk.JWK) bool {
+	return key.KTY == a.kty && key.CRV == a.crv && (!a.requiresAlg || key.ALG == a.jwa)
+}
+
+func (a builtinAlgorithm) GeneratePrivateKey() (jwk.JWK, error) {
+	key, err := a.generate()
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return a.withAlg(key), nil
+}
+
+func (a builtinAlgorithm) GetPublicKey(privateKey jwk.JWK) jwk.JWK {
+	return a.withAlg(a.getPublicKey(privateKey))
+}
+
+func (a builtinAlgorithm) Sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
+	return a.sign(payload, privateKey)
+}
+
+func (a builtinAlgorithm) Verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	return a.verify(payload, signature, publicKey)
+}
+
+func (a builtinAlgorithm) BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	key, err := a.bytesToPublicKey(input)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return a.withAlg(key), nil
+}
+
+func (a builtinAlgorithm) PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	return a.publicKeyToBytes(publicKey)
+}
+
+// withAlg sets the alg of keys of algorithms that require it
+func (a builtinAlgorithm) withAlg(key jwk.JWK) jwk.JWK {
+	if a.requiresAlg {
+		key.ALG = a.jwa
+	}
+
+	return key
+}
+
+// recoverableAlgorithm is a [builtinAlgorithm] that implements [KeyRecoverer]
+type recoverableAlgorithm struct {
+	builtinAlgorithm
+	recoverPublicKey func(payload []byte, signature []byte) (jwk.JWK, error)
+}
+
+func (a recoverableAlgorithm) RecoverPublicKey(payload []byte, signature []byte) (jwk.JWK, error) {
+	key, err := a.recoverPublicKey(payload, signature)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return a.withAlg(key), nil
+}
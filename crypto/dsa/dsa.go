@@ -5,104 +5,123 @@ import (
 
 	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
 	"github.com/decentralized-identity/web5-go/crypto/dsa/eddsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/mldsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/schnorr"
 	"github.com/decentralized-identity/web5-go/jwk"
 )
 
 const (
 	AlgorithmIDSECP256K1 = ecdsa.SECP256K1AlgorithmID
+	AlgorithmIDSECP256R1 = ecdsa.SECP256R1AlgorithmID
+	AlgorithmIDSECP384R1 = ecdsa.SECP384R1AlgorithmID
 	AlgorithmIDED25519   = eddsa.ED25519AlgorithmID
+
+	// AlgorithmIDSECP256K1Recoverable signs with 65 byte recoverable ECDSA signatures (ES256K-R), from which
+	// the public key of the signer can be recovered with [RecoverPublicKey]
+	AlgorithmIDSECP256K1Recoverable = ecdsa.SECP256K1RecoverableAlgorithmID
+	// AlgorithmIDSECP256K1Schnorr signs with BIP-340 Schnorr signatures
+	AlgorithmIDSECP256K1Schnorr = schnorr.SECP256K1AlgorithmID
+
+	// AlgorithmIDMLDSA44, AlgorithmIDMLDSA65 and AlgorithmIDMLDSA87 sign with the post-quantum ML-DSA
+	// parameter sets of FIPS 204, in increasing order of security strength
+	AlgorithmIDMLDSA44 = mldsa.MLDSA44AlgorithmID
+	AlgorithmIDMLDSA65 = mldsa.MLDSA65AlgorithmID
+	AlgorithmIDMLDSA87 = mldsa.MLDSA87AlgorithmID
 )
 
 // GeneratePrivateKey generates a private key using the algorithm specified by algorithmID.
 func GeneratePrivateKey(algorithmID string) (jwk.JWK, error) {
-	if ecdsa.SupportsAlgorithmID(algorithmID) {
-		return ecdsa.GeneratePrivateKey(algorithmID)
-	} else if eddsa.SupportsAlgorithmID(algorithmID) {
-		return eddsa.GeneratePrivateKey(algorithmID)
+	algorithm, err := GetAlgorithm(algorithmID)
+	if err != nil {
+		return jwk.JWK{}, err
 	}
 
-	return jwk.JWK{}, fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	return algorithm.GeneratePrivateKey()
 }
 
 // GetPublicKey returns the public key corresponding to the given private key.
 func GetPublicKey(privateKey jwk.JWK) jwk.JWK {
-	switch privateKey.KTY {
-	case ecdsa.KeyType:
-		return ecdsa.GetPublicKey(privateKey)
-	case eddsa.KeyType:
-		return eddsa.GetPublicKey(privateKey)
-	default:
+	algorithm, err := GetAlgorithmForKey(privateKey)
+	if err != nil {
 		return jwk.JWK{}
 	}
+
+	return algorithm.GetPublicKey(privateKey)
 }
 
 // Sign signs the payload using the given private key.
 func Sign(payload []byte, jwk jwk.JWK) ([]byte, error) {
-	switch jwk.KTY {
-	case ecdsa.KeyType:
-		return ecdsa.Sign(payload, jwk)
-	case eddsa.KeyType:
-		return eddsa.Sign(payload, jwk)
-	default:
-		return nil, fmt.Errorf("unsupported key type: %s", jwk.KTY)
+	algorithm, err := GetAlgorithmForKey(jwk)
+	if err != nil {
+		return nil, err
 	}
+
+	return algorithm.Sign(payload, jwk)
 }
 
 // Verify verifies the signature of the payload using the given public key.
 func Verify(payload []byte, signature []byte, jwk jwk.JWK) (bool, error) {
-	switch jwk.KTY {
-	case ecdsa.KeyType:
-		return ecdsa.Verify(payload, signature, jwk)
-	case eddsa.KeyType:
-		return eddsa.Verify(payload, signature, jwk)
-	default:
-		return false, fmt.Errorf("unsupported key type: %s", jwk.KTY)
+	algorithm, err := GetAlgorithmForKey(jwk)
+	if err != nil {
+		return false, err
+	}
+
+	return algorithm.Verify(payload, signature, jwk)
+}
+
+// RecoverPublicKey recovers the public key that signed the payload from the signature, with the algorithm
+// specified by algorithmID. The algorithm must implement [KeyRecoverer]. A public key can be recovered from
+// any well formed signature, so the signature is only valid if the recovered key is the expected one.
+func RecoverPublicKey(algorithmID string, payload []byte, signature []byte) (jwk.JWK, error) {
+	algorithm, err := GetAlgorithm(algorithmID)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	recoverer, ok := algorithm.(KeyRecoverer)
+	if !ok {
+		return jwk.JWK{}, fmt.Errorf("algorithm %s does not support public key recovery", algorithmID)
 	}
+
+	return recoverer.RecoverPublicKey(payload, signature)
 }
 
 // GetJWA returns the JWA (JSON Web Algorithm) algorithm corresponding to the given key.
 func GetJWA(jwk jwk.JWK) (string, error) {
-	switch jwk.KTY {
-	case ecdsa.KeyType:
-		return ecdsa.GetJWA(jwk)
-	case eddsa.KeyType:
-		return eddsa.GetJWA(jwk)
-	default:
-		return "", fmt.Errorf("unsupported key type: %s", jwk.KTY)
+	algorithm, err := GetAlgorithmForKey(jwk)
+	if err != nil {
+		return "", err
 	}
+
+	return algorithm.JWA(), nil
 }
 
 // BytesToPublicKey converts the given bytes to a public key based on the algorithm specified by algorithmID.
 func BytesToPublicKey(algorithmID string, input []byte) (jwk.JWK, error) {
-	if ecdsa.SupportsAlgorithmID(algorithmID) {
-		return ecdsa.BytesToPublicKey(algorithmID, input)
-	} else if eddsa.SupportsAlgorithmID(algorithmID) {
-		return eddsa.BytesToPublicKey(algorithmID, input)
+	algorithm, err := GetAlgorithm(algorithmID)
+	if err != nil {
+		return jwk.JWK{}, err
 	}
 
-	return jwk.JWK{}, fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	return algorithm.BytesToPublicKey(input)
 }
 
 // PublicKeyToBytes converts the provided public key to bytes
 func PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
-	switch publicKey.KTY {
-	case ecdsa.KeyType:
-		return ecdsa.PublicKeyToBytes(publicKey)
-	case eddsa.KeyType:
-		return eddsa.PublicKeyToBytes(publicKey)
-	default:
-		return nil, fmt.Errorf("unsupported key type: %s", publicKey.KTY)
+	algorithm, err := GetAlgorithmForKey(publicKey)
+	if err != nil {
+		return nil, err
 	}
+
+	return algorithm.PublicKeyToBytes(publicKey)
 }
 
 // AlgorithmID returns the algorithm ID for the given jwk.JWK
 func AlgorithmID(jwk *jwk.JWK) (string, error) {
-	switch jwk.KTY {
-	case ecdsa.KeyType:
-		return ecdsa.AlgorithmID(jwk)
-	case eddsa.KeyType:
-		return eddsa.AlgorithmID(jwk)
-	default:
-		return "", fmt.Errorf("unsupported key type: %s", jwk.KTY)
+	algorithm, err := GetAlgorithmForKey(*jwk)
+	if err != nil {
+		return "", err
 	}
+
+	return algorithm.ID(), nil
 }
@@ -2,12 +2,15 @@ package dsa_test
 
 import (
 	"encoding/hex"
+	"sync"
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/decentralized-identity/web5-go/crypto/dsa"
 	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
 	"github.com/decentralized-identity/web5-go/crypto/dsa/eddsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/mldsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/schnorr"
 	"github.com/decentralized-identity/web5-go/jwk"
 )
 
@@ -147,3 +150,244 @@ func TestPublicKeyToBytes_UnsupportedKTY(t *testing.T) {
 	_, err := dsa.PublicKeyToBytes(jwk.JWK{KTY: "yolocrypto"})
 	assert.Error(t, err)
 }
+
+func TestSignVerifyNIST(t *testing.T) {
+	for _, algorithmID := range []string{dsa.AlgorithmIDSECP256R1, dsa.AlgorithmIDSECP384R1} {
+		privateJwk, err := dsa.GeneratePrivateKey(algorithmID)
+		assert.NoError(t, err)
+
+		payload := []byte("hello")
+		signature, err := dsa.Sign(payload, privateJwk)
+		assert.NoError(t, err)
+
+		publicJwk := dsa.GetPublicKey(privateJwk)
+		legit, err := dsa.Verify(payload, signature, publicJwk)
+		assert.NoError(t, err)
+		assert.True(t, legit)
+
+		publicKeyBytes, err := dsa.PublicKeyToBytes(publicJwk)
+		assert.NoError(t, err)
+
+		decoded, err := dsa.BytesToPublicKey(algorithmID, publicKeyBytes)
+		assert.NoError(t, err)
+		assert.Equal(t, publicJwk, decoded)
+	}
+}
+
+// testAlgorithm is Ed25519 under a different curve name, to test registering algorithms
+type testAlgorithm struct{}
+
+const testCurve = "TestEd25519"
+
+func (testAlgorithm) ID() string  { return "test-ed25519" }
+func (testAlgorithm) JWA() string { return "TestEdDSA" }
+
+func (testAlgorithm) SupportsKey(key jwk.JWK) bool {
+	return key.KTY == eddsa.KeyType && key.CRV == testCurve
+}
+
+func (testAlgorithm) GeneratePrivateKey() (jwk.JWK, error) {
+	privateKey, err := eddsa.ED25519GeneratePrivateKey()
+	privateKey.CRV = testCurve
+	return privateKey, err
+}
+
+func (testAlgorithm) GetPublicKey(privateKey jwk.JWK) jwk.JWK {
+	return jwk.JWK{KTY: privateKey.KTY, CRV: privateKey.CRV, X: privateKey.X}
+}
+
+func (testAlgorithm) Sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
+	privateKey.CRV = eddsa.ED25519JWACurve
+	return eddsa.ED25519Sign(payload, privateKey)
+}
+
+func (testAlgorithm) Verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	publicKey.CRV = eddsa.ED25519JWACurve
+	return eddsa.ED25519Verify(payload, signature, publicKey)
+}
+
+func (testAlgorithm) BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	publicKey, err := eddsa.ED25519BytesToPublicKey(input)
+	publicKey.CRV = testCurve
+	return publicKey, err
+}
+
+func (testAlgorithm) PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	publicKey.CRV = eddsa.ED25519JWACurve
+	return eddsa.ED25519PublicKeyToBytes(publicKey)
+}
+
+var registerTestAlgorithm sync.Once
+
+func TestRegister(t *testing.T) {
+	algorithm := testAlgorithm{}
+
+	// the registry is global, so the algorithm is only registered once
+	registerTestAlgorithm.Do(func() {
+		assert.False(t, dsa.SupportsAlgorithmID(algorithm.ID()))
+		assert.NoError(t, dsa.Register(algorithm))
+	})
+	assert.True(t, dsa.SupportsAlgorithmID(algorithm.ID()))
+
+	privateJwk, err := dsa.GeneratePrivateKey(algorithm.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, testCurve, privateJwk.CRV)
+
+	algorithmID, err := dsa.AlgorithmID(&privateJwk)
+	assert.NoError(t, err)
+	assert.Equal(t, algorithm.ID(), algorithmID)
+
+	jwa, err := dsa.GetJWA(privateJwk)
+	assert.NoError(t, err)
+	assert.Equal(t, "TestEdDSA", jwa)
+
+	payload := []byte("hello")
+	signature, err := dsa.Sign(payload, privateJwk)
+	assert.NoError(t, err)
+
+	publicJwk := dsa.GetPublicKey(privateJwk)
+	legit, err := dsa.Verify(payload, signature, publicJwk)
+	assert.NoError(t, err)
+	assert.True(t, legit)
+
+	publicKeyBytes, err := dsa.PublicKeyToBytes(publicJwk)
+	assert.NoError(t, err)
+
+	decoded, err := dsa.BytesToPublicKey(algorithm.ID(), publicKeyBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, publicJwk, decoded)
+
+	// algorithm IDs can only be registered once
+	err = dsa.Register(algorithm)
+	assert.Error(t, err)
+
+	builtin, err := dsa.GetAlgorithm(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+	err = dsa.Register(builtin)
+	assert.Error(t, err)
+
+	err = dsa.Register(nil)
+	assert.Error(t, err)
+}
+
+func TestSECP256K1Recoverable(t *testing.T) {
+	privateJwk, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256K1Recoverable)
+	assert.NoError(t, err)
+	assert.Equal(t, ecdsa.SECP256K1RecoverableJWA, privateJwk.ALG)
+
+	publicJwk := dsa.GetPublicKey(privateJwk)
+	assert.Equal(t, ecdsa.SECP256K1RecoverableJWA, publicJwk.ALG)
+
+	algorithmID, err := dsa.AlgorithmID(&publicJwk)
+	assert.NoError(t, err)
+	assert.Equal(t, dsa.AlgorithmIDSECP256K1Recoverable, algorithmID)
+
+	payload := []byte("hello world")
+	signature, err := dsa.Sign(payload, privateJwk)
+	assert.NoError(t, err)
+	assert.Equal(t, 65, len(signature))
+
+	legit, err := dsa.Verify(payload, signature, publicJwk)
+	assert.NoError(t, err)
+	assert.True(t, legit)
+
+	recovered, err := dsa.RecoverPublicKey(dsa.AlgorithmIDSECP256K1Recoverable, payload, signature)
+	assert.NoError(t, err)
+	assert.Equal(t, publicJwk, recovered)
+
+	// the same key without alg is an ES256K key
+	publicJwk.ALG = ""
+	jwa, err := dsa.GetJWA(publicJwk)
+	assert.NoError(t, err)
+	assert.Equal(t, ecdsa.SECP256K1JWA, jwa)
+
+	_, err = dsa.RecoverPublicKey(dsa.AlgorithmIDSECP256K1, payload, signature[:64])
+	assert.Error(t, err)
+}
+
+func TestSECP256K1Schnorr(t *testing.T) {
+	privateJwk, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256K1Schnorr)
+	assert.NoError(t, err)
+	assert.Equal(t, schnorr.JWA, privateJwk.ALG)
+
+	publicJwk := dsa.GetPublicKey(privateJwk)
+
+	payload := []byte("hello world")
+	signature, err := dsa.Sign(payload, privateJwk)
+	assert.NoError(t, err)
+	assert.Equal(t, 64, len(signature))
+
+	legit, err := dsa.Verify(payload, signature, publicJwk)
+	assert.NoError(t, err)
+	assert.True(t, legit)
+
+	// a Schnorr signature is not an ES256K signature of the same key
+	publicJwk.ALG = ""
+	legit, err = dsa.Verify(payload, signature, publicJwk)
+	assert.NoError(t, err)
+	assert.False(t, legit)
+
+	publicKeyBytes, err := dsa.PublicKeyToBytes(dsa.GetPublicKey(privateJwk))
+	assert.NoError(t, err)
+	assert.Equal(t, 32, len(publicKeyBytes))
+
+	decoded, err := dsa.BytesToPublicKey(dsa.AlgorithmIDSECP256K1Schnorr, publicKeyBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, schnorr.JWA, decoded.ALG)
+}
+
+func TestMLDSA(t *testing.T) {
+	for _, algorithmID := range []string{dsa.AlgorithmIDMLDSA44, dsa.AlgorithmIDMLDSA65, dsa.AlgorithmIDMLDSA87} {
+		t.Run(algorithmID, func(t *testing.T) {
+			privateJwk, err := dsa.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+			assert.Equal(t, mldsa.KeyType, privateJwk.KTY)
+
+			publicJwk := dsa.GetPublicKey(privateJwk)
+			assert.Equal(t, "", publicJwk.PRIV)
+
+			jwa, err := dsa.GetJWA(publicJwk)
+			assert.NoError(t, err)
+			assert.Equal(t, algorithmID, jwa)
+
+			payload := []byte("hello world")
+			signature, err := dsa.Sign(payload, privateJwk)
+			assert.NoError(t, err)
+
+			legit, err := dsa.Verify(payload, signature, publicJwk)
+			assert.NoError(t, err)
+			assert.True(t, legit)
+
+			publicKeyBytes, err := dsa.PublicKeyToBytes(publicJwk)
+			assert.NoError(t, err)
+
+			decoded, err := dsa.BytesToPublicKey(algorithmID, publicKeyBytes)
+			assert.NoError(t, err)
+			assert.Equal(t, publicJwk, decoded)
+		})
+	}
+}
+
+func TestGetAlgorithmForJWA(t *testing.T) {
+	algorithm, err := dsa.GetAlgorithmForJWA(ecdsa.SECP256K1RecoverableJWA)
+	assert.NoError(t, err)
+	assert.Equal(t, dsa.AlgorithmIDSECP256K1Recoverable, algorithm.ID())
+
+	algorithm, err = dsa.GetAlgorithmForJWA(ecdsa.SECP256K1JWA)
+	assert.NoError(t, err)
+	assert.Equal(t, dsa.AlgorithmIDSECP256K1, algorithm.ID())
+
+	_, err = dsa.GetAlgorithmForJWA("none")
+	assert.Error(t, err)
+}
+
+func TestGetAlgorithm_Unsupported(t *testing.T) {
+	_, err := dsa.GetAlgorithm("yolocrypto")
+	assert.Error(t, err)
+
+	_, err = dsa.GetAlgorithmForKey(jwk.JWK{KTY: "EC", CRV: "yolocrypto"})
+	assert.Error(t, err)
+
+	_, err = dsa.GeneratePrivateKey("yolocrypto")
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package ecdsa
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 
@@ -13,6 +14,8 @@ const (
 
 var algorithmIDs = map[string]bool{
 	SECP256K1AlgorithmID: true,
+	SECP256R1AlgorithmID: true,
+	SECP384R1AlgorithmID: true,
 }
 
 // GeneratePrivateKey generates an ECDSA private key for the given algorithm
@@ -20,6 +23,10 @@ func GeneratePrivateKey(algorithmID string) (jwk.JWK, error) {
 	switch algorithmID {
 	case SECP256K1AlgorithmID:
 		return SECP256K1GeneratePrivateKey()
+	case SECP256R1AlgorithmID:
+		return SECP256R1GeneratePrivateKey()
+	case SECP384R1AlgorithmID:
+		return SECP384R1GeneratePrivateKey()
 	default:
 		return jwk.JWK{}, fmt.Errorf("unsupported algorithm: %s", algorithmID)
 	}
@@ -48,6 +55,39 @@ func Sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
 	switch privateKey.CRV {
 	case SECP256K1JWACurve:
 		return SECP256K1Sign(payload, privateKey)
+	case SECP256R1JWACurve:
+		return SECP256R1Sign(payload, privateKey)
+	case SECP384R1JWACurve:
+		return SECP384R1Sign(payload, privateKey)
+	default:
+		return nil, fmt.Errorf("unsupported curve: %s", privateKey.CRV)
+	}
+}
+
+// SignDigest signs a digest computed by the caller, rather than the payload itself, with the given private
+// key. The signature is in the same format as [Sign]. This allows signing for APIs that hash the payload
+// themselves, like [crypto.Signer].
+func SignDigest(digest []byte, privateKey jwk.JWK) ([]byte, error) {
+	if privateKey.D == "" {
+		return nil, errors.New("d must be set")
+	}
+
+	if len(digest) == 0 {
+		return nil, errors.New("digest must not be empty")
+	}
+
+	switch privateKey.CRV {
+	case SECP256K1JWACurve:
+		d, err := base64.RawURLEncoding.DecodeString(privateKey.D)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode d %w", err)
+		}
+
+		return secp256k1SignDigest(digest, d), nil
+	case SECP256R1JWACurve:
+		return secp256r1.signDigest(digest, privateKey)
+	case SECP384R1JWACurve:
+		return secp384r1.signDigest(digest, privateKey)
 	default:
 		return nil, fmt.Errorf("unsupported curve: %s", privateKey.CRV)
 	}
@@ -62,6 +102,10 @@ func Verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
 	switch publicKey.CRV {
 	case SECP256K1JWACurve:
 		return SECP256K1Verify(payload, signature, publicKey)
+	case SECP256R1JWACurve:
+		return SECP256R1Verify(payload, signature, publicKey)
+	case SECP384R1JWACurve:
+		return SECP384R1Verify(payload, signature, publicKey)
 	default:
 		return false, fmt.Errorf("unsupported curve: %s", publicKey.CRV)
 	}
@@ -74,6 +118,10 @@ func GetJWA(jwk jwk.JWK) (string, error) {
 	switch jwk.CRV {
 	case SECP256K1JWACurve:
 		return SECP256K1JWA, nil
+	case SECP256R1JWACurve:
+		return SECP256R1JWA, nil
+	case SECP384R1JWACurve:
+		return SECP384R1JWA, nil
 	default:
 		return "", fmt.Errorf("unsupported curve: %s", jwk.CRV)
 	}
@@ -84,6 +132,10 @@ func BytesToPublicKey(algorithmID string, input []byte) (jwk.JWK, error) {
 	switch algorithmID {
 	case SECP256K1AlgorithmID:
 		return SECP256K1BytesToPublicKey(input)
+	case SECP256R1AlgorithmID:
+		return SECP256R1BytesToPublicKey(input)
+	case SECP384R1AlgorithmID:
+		return SECP384R1BytesToPublicKey(input)
 	default:
 		return jwk.JWK{}, fmt.Errorf("unsupported algorithm: %s", algorithmID)
 	}
@@ -94,6 +146,25 @@ func PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
 	switch publicKey.CRV {
 	case SECP256K1JWACurve:
 		return SECP256K1PublicKeyToBytes(publicKey)
+	case SECP256R1JWACurve:
+		return SECP256R1PublicKeyToBytes(publicKey)
+	case SECP384R1JWACurve:
+		return SECP384R1PublicKeyToBytes(publicKey)
+	default:
+		return nil, fmt.Errorf("unsupported curve: %s", publicKey.CRV)
+	}
+}
+
+// PublicKeyToCompressedBytes serializes the given public key into its compressed form, described in
+// https://www.secg.org/sec1-v2.pdf section 2.3.3
+func PublicKeyToCompressedBytes(publicKey jwk.JWK) ([]byte, error) {
+	switch publicKey.CRV {
+	case SECP256K1JWACurve:
+		return SECP256K1PublicKeyToCompressedBytes(publicKey)
+	case SECP256R1JWACurve:
+		return secp256r1.publicKeyToCompressedBytes(publicKey)
+	case SECP384R1JWACurve:
+		return secp384r1.publicKeyToCompressedBytes(publicKey)
 	default:
 		return nil, fmt.Errorf("unsupported curve: %s", publicKey.CRV)
 	}
@@ -109,6 +180,10 @@ func AlgorithmID(jwk *jwk.JWK) (string, error) {
 	switch jwk.CRV {
 	case SECP256K1JWACurve:
 		return SECP256K1AlgorithmID, nil
+	case SECP256R1JWACurve:
+		return SECP256R1AlgorithmID, nil
+	case SECP384R1JWACurve:
+		return SECP384R1AlgorithmID, nil
 	default:
 		return "", fmt.Errorf("unsupported curve: %s", jwk.CRV)
 	}
@@ -0,0 +1,48 @@
+package ecdsa_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+)
+
+func TestSignDigest(t *testing.T) {
+	payload := []byte("hello world")
+	sha256Digest := sha256.Sum256(payload)
+	sha384Digest := sha512.Sum384(payload)
+
+	digests := map[string][]byte{
+		ecdsa.SECP256K1AlgorithmID: sha256Digest[:],
+		ecdsa.SECP256R1AlgorithmID: sha256Digest[:],
+		ecdsa.SECP384R1AlgorithmID: sha384Digest[:],
+	}
+
+	for algorithmID, digest := range digests {
+		t.Run(algorithmID, func(t *testing.T) {
+			privateKey, err := ecdsa.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+
+			signature, err := ecdsa.SignDigest(digest, privateKey)
+			assert.NoError(t, err)
+
+			// signing the digest is the same as signing the payload
+			verified, err := ecdsa.Verify(payload, signature, ecdsa.GetPublicKey(privateKey))
+			assert.NoError(t, err)
+			assert.True(t, verified)
+		})
+	}
+}
+
+func TestSignDigest_Errors(t *testing.T) {
+	privateKey, err := ecdsa.GeneratePrivateKey(ecdsa.SECP256R1AlgorithmID)
+	assert.NoError(t, err)
+
+	_, err = ecdsa.SignDigest(nil, privateKey)
+	assert.Error(t, err)
+
+	_, err = ecdsa.SignDigest([]byte("digest"), ecdsa.GetPublicKey(privateKey))
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package ecdsa
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// nistCurve holds the parameters of a NIST prime curve (FIPS 186-5, also known as secp256r1 and secp384r1)
+type nistCurve struct {
+	crv   string
+	curve elliptic.Curve
+	ecdh  ecdh.Curve
+	hash  crypto.Hash
+	// size is the size of private keys, coordinates and the r and s signature values
+	size int
+}
+
+// generatePrivateKey generates a new private key on the curve
+func (c nistCurve) generatePrivateKey() (jwk.JWK, error) {
+	key, err := c.ecdh.GenerateKey(rand.Reader)
+	if err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	privateKey := c.publicKeyJWK(key.PublicKey().Bytes())
+	privateKey.D = base64.RawURLEncoding.EncodeToString(key.Bytes())
+
+	return privateKey, nil
+}
+
+// sign signs the digest of the payload, returning the signature in the JOSE format: the fixed size
+// big-endian r and s values, concatenated.
+// https://datatracker.ietf.org/doc/html/rfc7518#section-3.4
+func (c nistCurve) sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
+	return c.signDigest(c.digest(payload), privateKey)
+}
+
+// signDigest signs a digest computed by the caller, returning the signature in the JOSE format
+func (c nistCurve) signDigest(digest []byte, privateKey jwk.JWK) ([]byte, error) {
+	d, err := base64.RawURLEncoding.DecodeString(privateKey.D)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode d %w", err)
+	}
+
+	// ecdh validates that d is in range and derives the public key
+	ecdhKey, err := c.ecdh.NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	x, y := c.coordinates(ecdhKey.PublicKey().Bytes())
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: c.curve, X: x, Y: y},
+		D:         new(big.Int).SetBytes(d),
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	signature := make([]byte, 2*c.size)
+	r.FillBytes(signature[:c.size])
+	s.FillBytes(signature[c.size:])
+
+	return signature, nil
+}
+
+// verify verifies a JOSE format signature over the payload
+func (c nistCurve) verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	keyBytes, err := c.uncompressedBytes(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	if len(signature) != 2*c.size {
+		return false, fmt.Errorf("signature must be %d bytes", 2*c.size)
+	}
+
+	x, y := c.coordinates(keyBytes)
+	key := &ecdsa.PublicKey{Curve: c.curve, X: x, Y: y}
+
+	r := new(big.Int).SetBytes(signature[:c.size])
+	s := new(big.Int).SetBytes(signature[c.size:])
+
+	return ecdsa.Verify(key, c.digest(payload), r, s), nil
+}
+
+// bytesToPublicKey converts a compressed or uncompressed public key, as described in
+// https://www.secg.org/sec1-v2.pdf section 2.3.3, to a JWK
+func (c nistCurve) bytesToPublicKey(input []byte) (jwk.JWK, error) {
+	if len(input) == 1+c.size && (input[0] == 0x02 || input[0] == 0x03) {
+		x, y := elliptic.UnmarshalCompressed(c.curve, input)
+		if x == nil {
+			return jwk.JWK{}, errors.New("failed to parse public key: invalid compressed point")
+		}
+
+		uncompressed := make([]byte, 1+2*c.size)
+		uncompressed[0] = 0x04
+		x.FillBytes(uncompressed[1 : 1+c.size])
+		y.FillBytes(uncompressed[1+c.size:])
+		input = uncompressed
+	}
+
+	// ecdh rejects points that are not on the curve
+	if _, err := c.ecdh.NewPublicKey(input); err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return c.publicKeyJWK(input), nil
+}
+
+// publicKeyToBytes converts a public key JWK to its uncompressed form
+func (c nistCurve) publicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	return c.uncompressedBytes(publicKey)
+}
+
+// publicKeyToCompressedBytes converts a public key JWK to its compressed form
+func (c nistCurve) publicKeyToCompressedBytes(publicKey jwk.JWK) ([]byte, error) {
+	keyBytes, err := c.uncompressedBytes(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := c.coordinates(keyBytes)
+	return elliptic.MarshalCompressed(c.curve, x, y), nil
+}
+
+// uncompressedBytes returns the uncompressed form of a public key JWK, checking the point is on the curve
+func (c nistCurve) uncompressedBytes(publicKey jwk.JWK) ([]byte, error) {
+	if publicKey.X == "" || publicKey.Y == "" {
+		return nil, errors.New("x and y must be set")
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(publicKey.X)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode x: %w", err)
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(publicKey.Y)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode y: %w", err)
+	}
+
+	if len(x) != c.size || len(y) != c.size {
+		return nil, fmt.Errorf("x and y must be %d bytes", c.size)
+	}
+
+	keyBytes := append([]byte{0x04}, x...)
+	keyBytes = append(keyBytes, y...)
+
+	if _, err := c.ecdh.NewPublicKey(keyBytes); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return keyBytes, nil
+}
+
+// publicKeyJWK builds a public key JWK from an uncompressed public key
+func (c nistCurve) publicKeyJWK(uncompressed []byte) jwk.JWK {
+	return jwk.JWK{
+		KTY: KeyType,
+		CRV: c.crv,
+		X:   base64.RawURLEncoding.EncodeToString(uncompressed[1 : 1+c.size]),
+		Y:   base64.RawURLEncoding.EncodeToString(uncompressed[1+c.size:]),
+	}
+}
+
+// coordinates returns the x and y coordinates of an uncompressed public key
+func (c nistCurve) coordinates(uncompressed []byte) (*big.Int, *big.Int) {
+	x := new(big.Int).SetBytes(uncompressed[1 : 1+c.size])
+	y := new(big.Int).SetBytes(uncompressed[1+c.size:])
+
+	return x, y
+}
+
+// digest hashes the payload with the hash function of the curve's JWA
+func (c nistCurve) digest(payload []byte) []byte {
+	h := c.hash.New()
+	h.Write(payload)
+
+	return h.Sum(nil)
+}
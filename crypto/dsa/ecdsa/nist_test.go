@@ -0,0 +1,107 @@
+package ecdsa_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+var nistCurves = []struct {
+	algorithmID string
+	crv         string
+	jwa         string
+	size        int
+}{
+	{ecdsa.SECP256R1AlgorithmID, ecdsa.SECP256R1JWACurve, ecdsa.SECP256R1JWA, 32},
+	{ecdsa.SECP384R1AlgorithmID, ecdsa.SECP384R1JWACurve, ecdsa.SECP384R1JWA, 48},
+}
+
+func TestNIST_SignVerify(t *testing.T) {
+	for _, c := range nistCurves {
+		t.Run(c.algorithmID, func(t *testing.T) {
+			privateKey, err := ecdsa.GeneratePrivateKey(c.algorithmID)
+			assert.NoError(t, err)
+			assert.Equal(t, ecdsa.KeyType, privateKey.KTY)
+			assert.Equal(t, c.crv, privateKey.CRV)
+
+			jwa, err := ecdsa.GetJWA(privateKey)
+			assert.NoError(t, err)
+			assert.Equal(t, c.jwa, jwa)
+
+			payload := []byte("hello")
+			signature, err := ecdsa.Sign(payload, privateKey)
+			assert.NoError(t, err)
+			assert.Equal(t, 2*c.size, len(signature))
+
+			publicKey := ecdsa.GetPublicKey(privateKey)
+			ok, err := ecdsa.Verify(payload, signature, publicKey)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = ecdsa.Verify([]byte("goodbye"), signature, publicKey)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			_, err = ecdsa.Verify(payload, signature[1:], publicKey)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNIST_PublicKeyBytes(t *testing.T) {
+	for _, c := range nistCurves {
+		t.Run(c.algorithmID, func(t *testing.T) {
+			privateKey, err := ecdsa.GeneratePrivateKey(c.algorithmID)
+			assert.NoError(t, err)
+			publicKey := ecdsa.GetPublicKey(privateKey)
+
+			uncompressed, err := ecdsa.PublicKeyToBytes(publicKey)
+			assert.NoError(t, err)
+			assert.Equal(t, 1+2*c.size, len(uncompressed))
+			assert.Equal(t, byte(0x04), uncompressed[0])
+
+			compressed, err := ecdsa.PublicKeyToCompressedBytes(publicKey)
+			assert.NoError(t, err)
+			assert.Equal(t, 1+c.size, len(compressed))
+
+			for _, input := range [][]byte{uncompressed, compressed} {
+				decoded, err := ecdsa.BytesToPublicKey(c.algorithmID, input)
+				assert.NoError(t, err)
+				assert.Equal(t, publicKey, decoded)
+
+				algorithmID, err := ecdsa.AlgorithmID(&decoded)
+				assert.NoError(t, err)
+				assert.Equal(t, c.algorithmID, algorithmID)
+			}
+
+			// a point that is not on the curve
+			uncompressed[len(uncompressed)-1] ^= 0x01
+			_, err = ecdsa.BytesToPublicKey(c.algorithmID, uncompressed)
+			assert.Error(t, err)
+
+			_, err = ecdsa.BytesToPublicKey(c.algorithmID, []byte{0x02, 0x01})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSECP256R1Verify_RFC7515(t *testing.T) {
+	// vector taken from https://datatracker.ietf.org/doc/html/rfc7515#appendix-A.3
+	publicKey := jwk.JWK{
+		KTY: "EC",
+		CRV: ecdsa.SECP256R1JWACurve,
+		X:   "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",
+		Y:   "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",
+	}
+
+	payload := []byte("eyJhbGciOiJFUzI1NiJ9.eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ")
+	signature, err := base64.RawURLEncoding.DecodeString("DtEhU3ljbEg8L38VWAfUAqOyKAM6-Xx-F4GawxaepmXFCgfTjDxw5djxLa8ISlSApmWQxfKTUJqPP3-Kg6NU1Q")
+	assert.NoError(t, err)
+
+	ok, err := ecdsa.SECP256R1Verify(payload, signature, publicKey)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -26,8 +26,8 @@ func SECP256K1GeneratePrivateKey() (jwk.JWK, error) {
 
 	dBytes := keyPair.Key.Bytes()
 	pubKey := keyPair.PubKey()
-	xBytes := pubKey.X().Bytes()
-	yBytes := pubKey.Y().Bytes()
+	xBytes := pubKey.X().FillBytes(make([]byte, 32))
+	yBytes := pubKey.Y().FillBytes(make([]byte, 32))
 
 	privateKey := jwk.JWK{
 		KTY: KeyType,
@@ -47,12 +47,14 @@ func SECP256K1Sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
 		return nil, fmt.Errorf("failed to decode d %w", err)
 	}
 
-	key := _secp256k1.PrivKeyFromBytes(privateKeyBytes)
-
 	hash := sha256.Sum256(payload)
-	signature := ecdsa.SignCompact(key, hash[:], false)[1:]
+	return secp256k1SignDigest(hash[:], privateKeyBytes), nil
+}
 
-	return signature, nil
+// secp256k1SignDigest signs a digest computed by the caller with RFC 6979 deterministic nonces
+func secp256k1SignDigest(digest []byte, privateKey []byte) []byte {
+	key := _secp256k1.PrivKeyFromBytes(privateKey)
+	return ecdsa.SignCompact(key, digest, false)[1:]
 }
 
 // SECP256K1Verify verifies the given signature over the given payload with the given public key
@@ -101,15 +103,34 @@ func SECP256K1BytesToPublicKey(input []byte) (jwk.JWK, error) {
 	return jwk.JWK{
 		KTY: KeyType,
 		CRV: SECP256K1JWACurve,
-		X:   base64.RawURLEncoding.EncodeToString(pubKey.X().Bytes()),
-		Y:   base64.RawURLEncoding.EncodeToString(pubKey.Y().Bytes()),
+		X:   base64.RawURLEncoding.EncodeToString(pubKey.X().FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(pubKey.Y().FillBytes(make([]byte, 32))),
 	}, nil
 }
 
 // SECP256K1PublicKeyToBytes converts a secp256k1 public key JWK to bytes.
-// Note: this function returns the uncompressed public key. compressed is not
-// yet supported
+// Note: this function returns the uncompressed public key. Use
+// [SECP256K1PublicKeyToCompressedBytes] for the compressed form.
 func SECP256K1PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	key, err := secp256k1ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.SerializeUncompressed(), nil
+}
+
+// SECP256K1PublicKeyToCompressedBytes converts a secp256k1 public key JWK to its compressed form
+func SECP256K1PublicKeyToCompressedBytes(publicKey jwk.JWK) ([]byte, error) {
+	key, err := secp256k1ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.SerializeCompressed(), nil
+}
+
+func secp256k1ParsePublicKey(publicKey jwk.JWK) (*_secp256k1.PublicKey, error) {
 	uncheckedBytes, err := secp256k1PublicKeyToUncheckedBytes(publicKey)
 	if err != nil {
 		return nil, err
@@ -120,7 +141,7 @@ func SECP256K1PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
 		return nil, fmt.Errorf("invalid public key: %w", err)
 	}
 
-	return key.SerializeUncompressed(), nil
+	return key, nil
 }
 
 func secp256k1PublicKeyToUncheckedBytes(publicKey jwk.JWK) ([]byte, error) {
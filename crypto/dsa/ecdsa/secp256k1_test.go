@@ -40,6 +40,20 @@ func TestSECP256K1BytesToPublicKey_Uncompressed(t *testing.T) {
 	assert.Equal(t, "SDradyajxGVdpPv8DhEIqP0XtEimhVQZnEfQj_sQ1Lg", jwk.Y)
 }
 
+func TestSECP256K1BytesToPublicKey_LeadingZero(t *testing.T) {
+	// the y coordinate of this key starts with a zero byte, which must be kept
+	publicKeyHex := "04501e454bf00751f24b1b489aa925215d66af2234e3891c3b21a52bedb3cd711c008794c1df8131b9ad1e1359965b3f3ee2feef0866be693729772be14be881ab"
+	pubKeyBytes, err := hex.DecodeString(publicKeyHex)
+	assert.NoError(t, err)
+
+	jwk, err := ecdsa.SECP256K1BytesToPublicKey(pubKeyBytes)
+	assert.NoError(t, err)
+
+	roundTripped, err := ecdsa.SECP256K1PublicKeyToBytes(jwk)
+	assert.NoError(t, err)
+	assert.Equal(t, pubKeyBytes, roundTripped)
+}
+
 func TestSECP256K1PublicKeyToBytes(t *testing.T) {
 	// vector taken from https://github.com/decentralized-identity/web5-js/blob/dids-new-crypto/packages/crypto/tests/fixtures/test-vectors/secp256k1/bytes-to-public-key.json
 	jwk := jwk.JWK{
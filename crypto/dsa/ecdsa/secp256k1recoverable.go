@@ -0,0 +1,135 @@
+package ecdsa
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decentralized-identity/web5-go/jwk"
+	_secp256k1 "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// SECP256K1RecoverableJWA is the alg of JWSs signed with recoverable secp256k1 signatures, as used by
+	// EcdsaSecp256k1RecoverySignature2020. It is not registered with IANA.
+	SECP256K1RecoverableJWA         string = "ES256K-R"
+	SECP256K1RecoverableAlgorithmID string = "secp256k1-recoverable"
+)
+
+// compactRecoveryCode is the value added to the recovery id in the first byte of compact signatures of
+// [ecdsa.SignCompact], for uncompressed public keys
+const compactRecoveryCode = 27
+
+// SECP256K1RecoverableSign signs the given payload with the given private key. The signature is 65 bytes:
+// r || s || v, where v is the recovery id (0 or 1) that allows recovering the public key of the signer.
+func SECP256K1RecoverableSign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
+	if privateKey.D == "" {
+		return nil, errors.New("d must be set")
+	}
+
+	d, err := base64.RawURLEncoding.DecodeString(privateKey.D)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode d %w", err)
+	}
+
+	hash := sha256.Sum256(payload)
+	compact := ecdsa.SignCompact(_secp256k1.PrivKeyFromBytes(d), hash[:], false)
+
+	return append(compact[1:], compact[0]-compactRecoveryCode), nil
+}
+
+// SECP256K1RecoverableVerify verifies the given recoverable signature over the given payload with the given
+// public key, by checking that the public key recovered from the signature is the given one
+func SECP256K1RecoverableVerify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	expected, err := secp256k1ParsePublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := secp256k1RecoverPublicKey(payload, signature)
+	if err != nil {
+		// signatures that don't allow recovering a key are invalid rather than malformed
+		if len(signature) == 65 {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return key.IsEqual(expected), nil
+}
+
+// SECP256K1RecoverPublicKey recovers the public key of the signer of the given payload from a recoverable
+// signature, see [SECP256K1RecoverableSign]. v may also be 27 or 28, as in Ethereum signatures.
+//
+// # Note
+//
+// A public key can be recovered from any well formed signature. The signature is only valid if the
+// recovered public key, or its [SECP256K1EthereumAddress], is the expected one.
+func SECP256K1RecoverPublicKey(payload []byte, signature []byte) (jwk.JWK, error) {
+	key, err := secp256k1RecoverPublicKey(payload, signature)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return SECP256K1BytesToPublicKey(key.SerializeUncompressed())
+}
+
+func secp256k1RecoverPublicKey(payload []byte, signature []byte) (*_secp256k1.PublicKey, error) {
+	if len(signature) != 65 {
+		return nil, errors.New("signature must be 65 bytes")
+	}
+
+	v := signature[64]
+	if v >= compactRecoveryCode {
+		v -= compactRecoveryCode
+	}
+
+	if v > 1 {
+		return nil, fmt.Errorf("invalid recovery id: %d", signature[64])
+	}
+
+	compact := append([]byte{compactRecoveryCode + v}, signature[:64]...)
+	hash := sha256.Sum256(payload)
+
+	key, _, err := ecdsa.RecoverCompact(compact, hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover public key: %w", err)
+	}
+
+	return key, nil
+}
+
+// SECP256K1EthereumAddress returns the EIP-55 checksummed Ethereum address of the given public key
+// (https://eips.ethereum.org/EIPS/eip-55), e.g. to compare with the address of a recovered public key
+func SECP256K1EthereumAddress(publicKey jwk.JWK) (string, error) {
+	key, err := secp256k1ParsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(key.SerializeUncompressed()[1:])
+	address := hex.EncodeToString(hash.Sum(nil)[12:])
+
+	hash = sha3.NewLegacyKeccak256()
+	hash.Write([]byte(address))
+	checksum := hex.EncodeToString(hash.Sum(nil))
+
+	var checksummed strings.Builder
+	checksummed.WriteString("0x")
+	for i, c := range address {
+		if c >= 'a' && checksum[i] >= '8' {
+			checksummed.WriteRune(c - 'a' + 'A')
+		} else {
+			checksummed.WriteRune(c)
+		}
+	}
+
+	return checksummed.String(), nil
+}
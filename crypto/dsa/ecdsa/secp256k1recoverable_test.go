@@ -0,0 +1,84 @@
+package ecdsa_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+func TestSECP256K1RecoverableSign(t *testing.T) {
+	privateKey, err := ecdsa.SECP256K1GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	publicKey := ecdsa.GetPublicKey(privateKey)
+	payload := []byte("hello world")
+
+	signature, err := ecdsa.SECP256K1RecoverableSign(payload, privateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 65, len(signature))
+	assert.True(t, signature[64] <= 1, "recovery id must be 0 or 1")
+
+	recovered, err := ecdsa.SECP256K1RecoverPublicKey(payload, signature)
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, recovered)
+
+	legit, err := ecdsa.SECP256K1RecoverableVerify(payload, signature, publicKey)
+	assert.NoError(t, err)
+	assert.True(t, legit)
+
+	// the r || s part is a standard ES256K signature
+	legit, err = ecdsa.SECP256K1Verify(payload, signature[:64], publicKey)
+	assert.NoError(t, err)
+	assert.True(t, legit)
+
+	// Ethereum style recovery ids
+	ethereum := append(signature[:64:64], signature[64]+27)
+	recovered, err = ecdsa.SECP256K1RecoverPublicKey(payload, ethereum)
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, recovered)
+
+	legit, err = ecdsa.SECP256K1RecoverableVerify([]byte("other payload"), signature, publicKey)
+	assert.NoError(t, err)
+	assert.False(t, legit)
+
+	otherKey, err := ecdsa.SECP256K1GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	legit, err = ecdsa.SECP256K1RecoverableVerify(payload, signature, ecdsa.GetPublicKey(otherKey))
+	assert.NoError(t, err)
+	assert.False(t, legit)
+}
+
+func TestSECP256K1RecoverPublicKey_Errors(t *testing.T) {
+	privateKey, err := ecdsa.SECP256K1GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	signature, err := ecdsa.SECP256K1RecoverableSign([]byte("hello"), privateKey)
+	assert.NoError(t, err)
+
+	_, err = ecdsa.SECP256K1RecoverPublicKey([]byte("hello"), signature[:64])
+	assert.Error(t, err)
+
+	invalidRecoveryID := append(signature[:64:64], 2)
+	_, err = ecdsa.SECP256K1RecoverPublicKey([]byte("hello"), invalidRecoveryID)
+	assert.Error(t, err)
+
+	_, err = ecdsa.SECP256K1RecoverableVerify([]byte("hello"), signature[:64], ecdsa.GetPublicKey(privateKey))
+	assert.Error(t, err)
+}
+
+func TestSECP256K1EthereumAddress(t *testing.T) {
+	// the public key of the private key 1, which is the generator point
+	publicKey := jwk.JWK{
+		KTY: "EC",
+		CRV: "secp256k1",
+		X:   "eb5mfvncu6xVoGKVzocLBwKb_NstzijZWfKBWxb4F5g",
+		Y:   "SDradyajxGVdpPv8DhEIqP0XtEimhVQZnEfQj_sQ1Lg",
+	}
+
+	address, err := ecdsa.SECP256K1EthereumAddress(publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", address)
+}
@@ -0,0 +1,51 @@
+package ecdsa
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	_ "crypto/sha256" // registers SHA-256 for crypto.SHA256
+
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+const (
+	SECP256R1JWA         string = "ES256"
+	SECP256R1JWACurve    string = "P-256"
+	SECP256R1AlgorithmID string = "secp256r1"
+)
+
+var secp256r1 = nistCurve{
+	crv:   SECP256R1JWACurve,
+	curve: elliptic.P256(),
+	ecdh:  ecdh.P256(),
+	hash:  crypto.SHA256,
+	size:  32,
+}
+
+// SECP256R1GeneratePrivateKey generates a new P-256 private key
+func SECP256R1GeneratePrivateKey() (jwk.JWK, error) {
+	return secp256r1.generatePrivateKey()
+}
+
+// SECP256R1Sign signs the given payload with the given private key using ES256
+func SECP256R1Sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
+	return secp256r1.sign(payload, privateKey)
+}
+
+// SECP256R1Verify verifies the given ES256 signature over the given payload with the given public key
+func SECP256R1Verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	return secp256r1.verify(payload, signature, publicKey)
+}
+
+// SECP256R1BytesToPublicKey converts a P-256 public key to a JWK.
+// Supports both Compressed and Uncompressed public keys described in
+// https://www.secg.org/sec1-v2.pdf section 2.3.3
+func SECP256R1BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	return secp256r1.bytesToPublicKey(input)
+}
+
+// SECP256R1PublicKeyToBytes converts a P-256 public key JWK to its uncompressed form
+func SECP256R1PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	return secp256r1.publicKeyToBytes(publicKey)
+}
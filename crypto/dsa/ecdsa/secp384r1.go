@@ -0,0 +1,51 @@
+package ecdsa
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	_ "crypto/sha512" // registers SHA-384 for crypto.SHA384
+
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+const (
+	SECP384R1JWA         string = "ES384"
+	SECP384R1JWACurve    string = "P-384"
+	SECP384R1AlgorithmID string = "secp384r1"
+)
+
+var secp384r1 = nistCurve{
+	crv:   SECP384R1JWACurve,
+	curve: elliptic.P384(),
+	ecdh:  ecdh.P384(),
+	hash:  crypto.SHA384,
+	size:  48,
+}
+
+// SECP384R1GeneratePrivateKey generates a new P-384 private key
+func SECP384R1GeneratePrivateKey() (jwk.JWK, error) {
+	return secp384r1.generatePrivateKey()
+}
+
+// SECP384R1Sign signs the given payload with the given private key using ES384
+func SECP384R1Sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
+	return secp384r1.sign(payload, privateKey)
+}
+
+// SECP384R1Verify verifies the given ES384 signature over the given payload with the given public key
+func SECP384R1Verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	return secp384r1.verify(payload, signature, publicKey)
+}
+
+// SECP384R1BytesToPublicKey converts a P-384 public key to a JWK.
+// Supports both Compressed and Uncompressed public keys described in
+// https://www.secg.org/sec1-v2.pdf section 2.3.3
+func SECP384R1BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	return secp384r1.bytesToPublicKey(input)
+}
+
+// SECP384R1PublicKeyToBytes converts a P-384 public key JWK to its uncompressed form
+func SECP384R1PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	return secp384r1.publicKeyToBytes(publicKey)
+}
@@ -0,0 +1,355 @@
+package mldsa
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// seedSize is the size of the seed xi that private keys are generated from
+const seedSize = 32
+
+// parameters are the parameters of an ML-DSA parameter set (FIPS 204 Table 1)
+type parameters struct {
+	jwa    string
+	k      int
+	l      int
+	eta    int32
+	tau    int
+	lambda int
+	gamma1 int32
+	gamma2 int32
+	omega  int
+}
+
+var (
+	mlDSA44 = parameters{jwa: MLDSA44JWA, k: 4, l: 4, eta: 2, tau: 39, lambda: 128, gamma1: 1 << 17, gamma2: (q - 1) / 88, omega: 80}
+	mlDSA65 = parameters{jwa: MLDSA65JWA, k: 6, l: 5, eta: 4, tau: 49, lambda: 192, gamma1: 1 << 19, gamma2: (q - 1) / 32, omega: 55}
+	mlDSA87 = parameters{jwa: MLDSA87JWA, k: 8, l: 7, eta: 2, tau: 60, lambda: 256, gamma1: 1 << 19, gamma2: (q - 1) / 32, omega: 75}
+)
+
+// beta bounds the coefficients of c * s1 and c * s2
+func (p parameters) beta() int32 {
+	return int32(p.tau) * p.eta
+}
+
+// gamma1Bits is the number of bits of the packed coefficients of z
+func (p parameters) gamma1Bits() int {
+	if p.gamma1 == 1<<17 {
+		return 18
+	}
+
+	return 20
+}
+
+// w1Bits is the number of bits of the packed coefficients of w1
+func (p parameters) w1Bits() int {
+	if p.gamma2 == (q-1)/88 {
+		return 6
+	}
+
+	return 4
+}
+
+// challengeSize is the size of the commitment hash c~
+func (p parameters) challengeSize() int {
+	return p.lambda / 4
+}
+
+// publicKeySize is the size of encoded public keys
+func (p parameters) publicKeySize() int {
+	return 32 + 32*10*p.k
+}
+
+// signatureSize is the size of encoded signatures
+func (p parameters) signatureSize() int {
+	return p.challengeSize() + 32*p.gamma1Bits()*p.l + p.omega + p.k
+}
+
+// privateKey is an expanded private key. The matrix A and the secret vectors are in NTT form.
+type privateKey struct {
+	params    parameters
+	publicKey []byte
+	a         [][]poly
+	key       []byte
+	tr        []byte
+	s1        []poly
+	s2        []poly
+	t0        []poly
+}
+
+// newPrivateKey expands a private key from its seed xi (FIPS 204 Algorithm 6)
+func newPrivateKey(params parameters, seed []byte) (*privateKey, error) {
+	if len(seed) != seedSize {
+		return nil, errors.New("seed must be 32 bytes")
+	}
+
+	expanded := shake256(128, seed, []byte{byte(params.k), byte(params.l)})
+	rho, rhoPrime, key := expanded[:32], expanded[32:96], expanded[96:]
+
+	a := expandA(rho, params.k, params.l)
+	s1, s2 := expandS(rhoPrime, params.eta, params.k, params.l)
+
+	s1Hat := nttAll(s1)
+	t := matrixVectorNTT(a, s1Hat)
+
+	t1 := make([]poly, params.k)
+	t0 := make([]poly, params.k)
+	for i := range t {
+		t[i].add(&t[i], &s2[i])
+		for j, c := range t[i] {
+			r1, r0 := power2Round(c)
+			t1[i][j], t0[i][j] = r1, fieldReduce(r0)
+		}
+	}
+
+	publicKey := encodePublicKey(rho, t1)
+
+	return &privateKey{
+		params:    params,
+		publicKey: publicKey,
+		a:         a,
+		key:       key,
+		tr:        shake256(64, publicKey),
+		s1:        s1Hat,
+		s2:        nttAll(s2),
+		t0:        nttAll(t0),
+	}, nil
+}
+
+// sign signs the formatted message M' with the randomness rnd, which is zero for deterministic signatures
+// (FIPS 204 Algorithm 7)
+func (sk *privateKey) sign(message []byte, rnd []byte) []byte {
+	params := sk.params
+
+	mu := shake256(64, sk.tr, message)
+	rhoPrime := shake256(64, sk.key, rnd, mu)
+
+	for kappa := 0; ; kappa += params.l {
+		y := expandMask(rhoPrime, kappa, params.gamma1, params.gamma1Bits(), params.l)
+		w := matrixVectorNTT(sk.a, nttAll(y))
+
+		var w1Encoded []byte
+		for i := range w {
+			var w1 poly
+			for j, c := range w[i] {
+				w1[j], _ = decompose(c, params.gamma2)
+			}
+
+			w1Encoded = packBits(w1Encoded, &w1, params.w1Bits())
+		}
+
+		challenge := shake256(params.challengeSize(), mu, w1Encoded)
+		c := sampleInBall(challenge, params.tau)
+		c.ntt()
+
+		z := scalarVectorNTT(&c, sk.s1)
+		for i := range z {
+			z[i].add(&z[i], &y[i])
+		}
+
+		if infinityNorm(z) >= params.gamma1-params.beta() {
+			continue
+		}
+
+		// r = w - c * s2, whose low bits must be small enough for the hints to recover the high bits of w
+		r := scalarVectorNTT(&c, sk.s2)
+		for i := range r {
+			r[i].sub(&w[i], &r[i])
+		}
+
+		if !lowBitsBelow(r, params.gamma2-params.beta(), params.gamma2) {
+			continue
+		}
+
+		ct0 := scalarVectorNTT(&c, sk.t0)
+		if infinityNorm(ct0) >= params.gamma2 {
+			continue
+		}
+
+		hints := make([][n]bool, params.k)
+		count := 0
+		for i := range hints {
+			for j := range hints[i] {
+				// MakeHint(-c * t0, w - c * s2 + c * t0)
+				hints[i][j] = makeHint(fieldSub(0, ct0[i][j]), fieldAdd(r[i][j], ct0[i][j]), params.gamma2)
+				if hints[i][j] {
+					count++
+				}
+			}
+		}
+
+		if count > params.omega {
+			continue
+		}
+
+		return encodeSignature(params, challenge, z, hints)
+	}
+}
+
+// verify verifies the signature of the formatted message M' with the encoded public key
+// (FIPS 204 Algorithm 8)
+func verify(params parameters, publicKey []byte, message []byte, signature []byte) bool {
+	rho, t1, ok := decodePublicKey(params, publicKey)
+	if !ok {
+		return false
+	}
+
+	challenge, z, hints, ok := decodeSignature(params, signature)
+	if !ok {
+		return false
+	}
+
+	if infinityNorm(z) >= params.gamma1-params.beta() {
+		return false
+	}
+
+	a := expandA(rho, params.k, params.l)
+	mu := shake256(64, shake256(64, publicKey), message)
+
+	c := sampleInBall(challenge, params.tau)
+	c.ntt()
+
+	// w' = A * z - c * t1 * 2^d
+	w := matrixVectorNTT(a, nttAll(z))
+	for i := range t1 {
+		for j := range t1[i] {
+			t1[i][j] = fieldMul(t1[i][j], 1<<d)
+		}
+	}
+
+	ct1 := scalarVectorNTT(&c, nttAll(t1))
+
+	var w1Encoded []byte
+	for i := range w {
+		w[i].sub(&w[i], &ct1[i])
+
+		var w1 poly
+		for j, coefficient := range w[i] {
+			w1[j] = useHint(hints[i][j], coefficient, params.gamma2)
+		}
+
+		w1Encoded = packBits(w1Encoded, &w1, params.w1Bits())
+	}
+
+	return subtle.ConstantTimeCompare(challenge, shake256(params.challengeSize(), mu, w1Encoded)) == 1
+}
+
+// lowBitsBelow reports whether the absolute values of the low bits of all coefficients are below the bound
+func lowBitsBelow(polys []poly, bound int32, gamma2 int32) bool {
+	for i := range polys {
+		for _, c := range polys[i] {
+			_, r0 := decompose(c, gamma2)
+			if r0 >= bound || r0 <= -bound {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// encodePublicKey encodes a public key as rho || t1 (FIPS 204 Algorithm 22)
+func encodePublicKey(rho []byte, t1 []poly) []byte {
+	encoded := append([]byte{}, rho...)
+	for i := range t1 {
+		encoded = packBits(encoded, &t1[i], 10)
+	}
+
+	return encoded
+}
+
+// decodePublicKey reverses encodePublicKey (FIPS 204 Algorithm 23)
+func decodePublicKey(params parameters, encoded []byte) ([]byte, []poly, bool) {
+	if len(encoded) != params.publicKeySize() {
+		return nil, nil, false
+	}
+
+	t1 := make([]poly, params.k)
+	for i := range t1 {
+		t1[i] = unpackBits(encoded[32+320*i:32+320*(i+1)], 10)
+	}
+
+	return encoded[:32], t1, true
+}
+
+// encodeSignature encodes a signature as c~ || z || h (FIPS 204 Algorithms 26 and 20)
+func encodeSignature(params parameters, challenge []byte, z []poly, hints [][n]bool) []byte {
+	encoded := append(make([]byte, 0, params.signatureSize()), challenge...)
+	for i := range z {
+		encoded = packCentered(encoded, &z[i], params.gamma1, params.gamma1Bits())
+	}
+
+	packedHints := make([]byte, params.omega+params.k)
+	index := 0
+	for i := range hints {
+		for j, hint := range hints[i] {
+			if hint {
+				packedHints[index] = byte(j)
+				index++
+			}
+		}
+
+		packedHints[params.omega+i] = byte(index)
+	}
+
+	return append(encoded, packedHints...)
+}
+
+// decodeSignature reverses encodeSignature, rejecting malformed hints (FIPS 204 Algorithms 27 and 21)
+func decodeSignature(params parameters, encoded []byte) ([]byte, []poly, [][n]bool, bool) {
+	if len(encoded) != params.signatureSize() {
+		return nil, nil, nil, false
+	}
+
+	challenge := encoded[:params.challengeSize()]
+	encoded = encoded[params.challengeSize():]
+
+	zSize := 32 * params.gamma1Bits()
+	z := make([]poly, params.l)
+	for i := range z {
+		z[i] = unpackCentered(encoded[zSize*i:zSize*(i+1)], params.gamma1, params.gamma1Bits())
+	}
+
+	packedHints := encoded[zSize*params.l:]
+	hints := make([][n]bool, params.k)
+	index := 0
+	for i := range hints {
+		end := int(packedHints[params.omega+i])
+		if end < index || end > params.omega {
+			return nil, nil, nil, false
+		}
+
+		first := index
+		for ; index < end; index++ {
+			// hint positions are strictly increasing, so that each signature has a single encoding
+			if index > first && packedHints[index-1] >= packedHints[index] {
+				return nil, nil, nil, false
+			}
+
+			hints[i][packedHints[index]] = true
+		}
+	}
+
+	for ; index < params.omega; index++ {
+		if packedHints[index] != 0 {
+			return nil, nil, nil, false
+		}
+	}
+
+	return challenge, z, hints, true
+}
+
+// shake256 hashes the concatenation of the inputs to the given number of bytes
+func shake256(size int, inputs ...[]byte) []byte {
+	xof := sha3.NewShake256()
+	for _, input := range inputs {
+		xof.Write(input)
+	}
+
+	output := make([]byte, size)
+	xof.Read(output)
+
+	return output
+}
@@ -0,0 +1,443 @@
+package mldsa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestNTT(t *testing.T) {
+	var a, b poly
+	for j := range a {
+		a[j] = int32(j * 7919 % q)
+		b[j] = int32((j*j + 3) % q)
+	}
+
+	// schoolbook multiplication in Z_q[X]/(X^256 + 1)
+	var expected poly
+	for i := range a {
+		for j := range b {
+			product := fieldMul(a[i], b[j])
+			if i+j < n {
+				expected[i+j] = fieldAdd(expected[i+j], product)
+			} else {
+				expected[i+j-n] = fieldSub(expected[i+j-n], product)
+			}
+		}
+	}
+
+	aHat, bHat := a, b
+	aHat.ntt()
+	bHat.ntt()
+
+	var product poly
+	product.multiplyNTT(&aHat, &bHat)
+	product.inverseNTT()
+	assert.Equal(t, expected, product)
+
+	aHat.inverseNTT()
+	assert.Equal(t, a, aHat)
+}
+
+func TestDecompose(t *testing.T) {
+	for _, gamma2 := range []int32{(q - 1) / 88, (q - 1) / 32} {
+		for _, r := range []int32{0, 1, gamma2, gamma2 + 1, 2 * gamma2, q - gamma2, q - 2, q - 1} {
+			r1, r0 := decompose(r, gamma2)
+			assert.True(t, r0 > -gamma2-1 && r0 <= gamma2)
+			assert.Equal(t, r, fieldReduce((r1*2*gamma2+r0)%q))
+		}
+	}
+}
+
+func TestSign_ParameterSets(t *testing.T) {
+	parameterSets := []struct {
+		params        parameters
+		publicKeySize int
+		signatureSize int
+	}{
+		{params: mlDSA44, publicKeySize: 1312, signatureSize: 2420},
+		{params: mlDSA65, publicKeySize: 1952, signatureSize: 3309},
+		{params: mlDSA87, publicKeySize: 2592, signatureSize: 4627},
+	}
+
+	for _, v := range parameterSets {
+		t.Run(v.params.jwa, func(t *testing.T) {
+			sk, err := newPrivateKey(v.params, bytes.Repeat([]byte{0x2a}, seedSize))
+			assert.NoError(t, err)
+			assert.Equal(t, v.publicKeySize, len(sk.publicKey))
+
+			message := formatMessage([]byte("hello world"))
+			rnd := make([]byte, 32)
+
+			signature := sk.sign(message, rnd)
+			assert.Equal(t, v.signatureSize, len(signature))
+			assert.True(t, verify(v.params, sk.publicKey, message, signature))
+
+			// deterministic signatures only depend on the key and the message
+			assert.Equal(t, signature, sk.sign(message, rnd))
+
+			assert.False(t, verify(v.params, sk.publicKey, formatMessage([]byte("other message")), signature))
+
+			tampered := append([]byte{}, signature...)
+			tampered[0] ^= 1
+			assert.False(t, verify(v.params, sk.publicKey, message, tampered))
+
+			// the hint counts must not decrease
+			tampered = append([]byte{}, signature...)
+			tampered[len(tampered)-1] = 0
+			assert.False(t, verify(v.params, sk.publicKey, message, tampered))
+		})
+	}
+}
+
+func TestNewPrivateKey_Deterministic(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x01}, seedSize)
+
+	sk1, err := newPrivateKey(mlDSA44, seed)
+	assert.NoError(t, err)
+
+	sk2, err := newPrivateKey(mlDSA44, seed)
+	assert.NoError(t, err)
+	assert.Equal(t, sk1.publicKey, sk2.publicKey)
+
+	// the parameter set is part of the key generation, so the same seed gives unrelated keys
+	sk3, err := newPrivateKey(mlDSA65, seed)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sk1.publicKey[:32], sk3.publicKey[:32])
+
+	_, err = newPrivateKey(mlDSA44, seed[:16])
+	assert.Error(t, err)
+}
+
+// acvpVectors are the known answer tests for the rejection cases of ML-DSA.Sign_internal, from Tables 1 and 2
+// of https://pages.nist.gov/ACVP/draft-celi-acvp-ml-dsa.html. Each key is generated from seed, keyHash is
+// SHA-256(pk || sk), message is the message M' of ML-DSA.Sign_internal and sigHash is the SHA-256 of its
+// deterministic signature. The same vectors are used by the Go standard library.
+var acvpVectors = []struct {
+	name    string
+	params  parameters
+	seed    string
+	keyHash string
+	message string
+	sigHash string
+}{
+	{
+		name:    "Path/ML-DSA-44/1",
+		params:  mlDSA44,
+		seed:    "5C624FCC1862452452D0C665840D8237F43108E5499EDCDC108FBC49D596E4B7",
+		keyHash: "AC825C59D8A4C453A2C4EFEA8395741CA404F3000E28D56B25D03BB402E5CB2F",
+		message: "951FDF5473A4CBA6D9E5B5DB7E79FB8173921BA5B13E9271401B8F907B8B7D5B",
+		sigHash: "DCC71A421BC6FFAFB7DF0C7F6D018A19ADA154D1E2EE360ED533CECD5DC980AD",
+	},
+	{
+		name:    "Path/ML-DSA-44/2",
+		params:  mlDSA44,
+		seed:    "836EABEDB4D2CD9BE6A4D957CF5EE6BF489304136864C55C2C5F01DA5047D18B",
+		keyHash: "E1FF40D96E3552FAB531D1715084B7E38CCDBACC0A8AF94C30959FB4C7F5A445",
+		message: "199A0AB735E9004163DD02D319A61CFE81638E3BF47BB1E90E90D6E3EA545247",
+		sigHash: "A2608BC27E60541D27B6A14F460D54A48C0298DCC3F45999F29047A3135C4941",
+	},
+	{
+		name:    "Path/ML-DSA-44/3",
+		params:  mlDSA44,
+		seed:    "CA5A01E1EA6552CB5C9803462B94C2F1DC9D13BB17A6ACE510D157056A2C6114",
+		keyHash: "A4652DC4A271095268DD84A5B0744DFDBE2E642E4D41FBC4329C2FBA534C0E13",
+		message: "8C8CACA88FFF52B9330510537B3701B3993F3726136A650F48F8604551550832",
+		sigHash: "B4B142209137397DAD504CAED01D390ADAF49973D8D2414FC3457FB7AF775189",
+	},
+	{
+		name:    "Path/ML-DSA-44/4",
+		params:  mlDSA44,
+		seed:    "9C005F1550B4F31855C6B92F978736733F37791CB39DD182D7BA5732BDC2483E",
+		keyHash: "2485AA99345F1B334D4D94B610FBFFCCB626CBFD4E9FF0E1F6FC35093C423544",
+		message: "B744343F30F7FEE088998BA574E799F1BF3939C06C29BF9AC10F3588A57E21E2",
+		sigHash: "5B80A60BAA480B9D0C7D2C05B50928C4BF6808DDA693642058A3EB77EAA768FC",
+	},
+	{
+		name:    "Path/ML-DSA-44/5",
+		params:  mlDSA44,
+		seed:    "4FAB5485B009399E8AE6FC3D3EEFBFE8E09796E4477AABD5EB1CC908FA734DE3",
+		keyHash: "CB56909A7CF3008A662DC635EDCB79DC151CA7ACBAE17B544384ABD91BBBC1E9",
+		message: "7CAB0FDCF4BEA5F039137478AA45C9C48EF96D906FC49F6E2F138111BF1B4A4E",
+		sigHash: "6CC38D73D639682ABC556DC6DCF436DE24033091F34004F410FABC6887F77AB0",
+	},
+	{
+		name:    "Path/ML-DSA-65/1",
+		params:  mlDSA65,
+		seed:    "464756A985E5DF03739D95DD309C1ED9C5B04254CC294E7E7EB9B9365EE15117",
+		keyHash: "AE95EA0DAA80199E7B4A74EB5A1B1DC6C3805BD01D2FA78D7C4FBA8C255AA13D",
+		message: "491101BBA044DE6E44A63796C33CDA051BB05A60725B87AF4BA9DB940C03AC09",
+		sigHash: "8E08EA0C8DB941685B9905A73B0B57BAD3500B1F73490480B24375B41230CC04",
+	},
+	{
+		name:    "Path/ML-DSA-65/2",
+		params:  mlDSA65,
+		seed:    "235A48DB4CA7916B884F424A8586EFD517E87C64AECEC0FCE9A3CC212BA1522E",
+		keyHash: "1AC58A909DB4D7BC2473AB5E24AF768279C76F86A82D448258E24EEA4EA6B713",
+		message: "F8CE85CB2EC474FFBF5A3FFAE029CE6F4526B8D597655067F97F438B81071E9B",
+		sigHash: "AE9531A01738615B6D33C77B3FF618A86E101FDC4C8504681F0EDFA64511AD63",
+	},
+	{
+		name:    "Path/ML-DSA-65/3",
+		params:  mlDSA65,
+		seed:    "E13131B705A760305FEFFEBFE99082E2691A444BBEFCC3EDF67D909886200207",
+		keyHash: "B422093F95CC489C52F4FA2B8973A2FDDD44426D1D04D1AAEEFC8715D417181F",
+		message: "CD365512C7E61BBAA130800B37F3BB46AAF1BEEF3742EA8A9010A6DD4576ED0B",
+		sigHash: "3C55E604DECA7B89A99305D7A391C35F66A17C1923F467675EC951C0948D21C9",
+	},
+	{
+		name:    "Path/ML-DSA-65/4",
+		params:  mlDSA65,
+		seed:    "0A4793E040A4BC0D0F37643D12C1EA1F10648724609936C76E0EC83E37209E92",
+		keyHash: "622D26D536D4D66CD94956B33A74E2E830ED265D25C34FF7C3E5243403146ADF",
+		message: "6D9C7A795E48D80A892CBF4D4558429787277E3806EB5D0BCE1640EEBBBF9AEC",
+		sigHash: "3B141110B9F56540B2D49AACDE6399974A4EAC40621E367E68D4504F294DB21B",
+	},
+	{
+		name:    "Path/ML-DSA-65/5",
+		params:  mlDSA65,
+		seed:    "F865B889E5022D54BABC81CA67E7EB39F1AC42F92CF5295C3DA5C9667DB1B924",
+		keyHash: "45BC8EDD1A620C46E973E346844270721824D97888BC174281852D98B7E8F4A3",
+		message: "047AFAADBE020ED2D766DA85317DEDE80BE550545F0B21E3F555A990F8004258",
+		sigHash: "56308A3578360C41356BA9C97D3240E01767FA76BBBA9FD0CC6CFA9ADD088DB9",
+	},
+	{
+		name:    "Path/ML-DSA-87/1",
+		params:  mlDSA87,
+		seed:    "0D58219132746BE077DFE821E9F8FD87857B28AB91D6A567E312A73E2636032C",
+		keyHash: "4D261270341A7AC6B66900DDC2B8AB34AB483C897410DDF3B2C072BDDA416434",
+		message: "3AA49EF72D010AEC19383BA1E83EC2DD3DCC207A96FFCEB9FFA269E3E3D66400",
+		sigHash: "5049DC39045618B903C71595B3A3E07A731F95D37304623ACC98BCEF4258B4CA",
+	},
+	{
+		name:    "Path/ML-DSA-87/2",
+		params:  mlDSA87,
+		seed:    "146C47AB9F88408EB76A813294D533B29D7E0FDA75DA5A4E7C69EB61EFEEBB78",
+		keyHash: "05194438AF855B79DB8CCCCB647D6BA5C7AAF901BBD09D3B29395F0EA431D164",
+		message: "82C44F998A8D24F056084D0E80ECFD8434493385A284C69974923C270D397782",
+		sigHash: "CFFC5988A351E14A3EE1282F042A143679C4503814296B27993949A7FF966F57",
+	},
+	{
+		name:    "Path/ML-DSA-87/3",
+		params:  mlDSA87,
+		seed:    "049D9B0B646A2AC7F50B63CE5E4BFE44C9B87634F4FF6C14C513E388B8A1F808",
+		keyHash: "AC8FE6B2FE26591B129EA536A9A001C785D8ACBDD9489F6E51469A156E9E635D",
+		message: "FEBC9F8AE159002BE1A11D395959DD7FC20718135690CDAA2BCFB5801C02AB89",
+		sigHash: "FF4006089BDF7337E868F86DDF48F239D2A52EA1D0F686E0103BF19C3B571DB1",
+	},
+	{
+		name:    "Path/ML-DSA-87/4",
+		params:  mlDSA87,
+		seed:    "9823DDDE446A8EA883DAD3AC6477F79839FDC2D2DEF2416BE0A8B71CFBC3F5C6",
+		keyHash: "525010E307C4EA7667D54EE27007C219B01F4CF88DC3AB2DE8E9AAA59440A884",
+		message: "F7592C97C1A96A2F4053588F5CDAD4C50BF7C3752709854FA27779B445DD2BA2",
+		sigHash: "FD7757602B83B0A67A314CD5BCC880E7AE47ACDF4D6AF98269028EFB486838F7",
+	},
+	{
+		name:    "Path/ML-DSA-87/5",
+		params:  mlDSA87,
+		seed:    "AE213FE8589B414F53780D8B9B6837179967E13CB474C5AD365C043778D2BC90",
+		keyHash: "D4988E91064E5DF6D867434D1DED16DCD8533E39E420DC2B4EB9E40A84146F7D",
+		message: "19C1913BA76FF04596BB7CC80FD825A5AEDEF5D5AD61CEDB5203E6D7EDB18877",
+		sigHash: "23FE743EDD101970D499E7EB57A7AA245BAF417E851B260C55DD525A445F08DA",
+	},
+	{
+		name:    "Count/ML-DSA-44/77",
+		params:  mlDSA44,
+		seed:    "090D97C1F4166EB32CA67C5FB564ACBE0735DB4AF4B8DB3A7C2CE7402357CA44",
+		keyHash: "26D79E4068040E996BC9EB5034C20489C0AD38DC2FEC1918D0760C8621872408",
+		message: "E3838364B37F47EDFCA2B577B20B80C3CB51B9F56E0E4CDB7DF002C874039252",
+		sigHash: "CD91150C610FF02DE1DD7049C309EFE800CE5C1BC2E5A32D752AB62C5BF5E16F",
+	},
+	{
+		name:    "Count/ML-DSA-44/100",
+		params:  mlDSA44,
+		seed:    "CFC73D07A883543A804F770070861825143A62F2F97D05FCE00FD8B25D29A43F",
+		keyHash: "89142AB26D6EB6C01FA3F189A9C877597740D685983F29BBDD3596648266AE0E",
+		message: "0960C13E9BA467A938450120CC96FF6F04B7E557C99A838619A48F9A38738AB8",
+		sigHash: "B6296FFF0C1F23DE4906D58144B00A2DB13AD25E49B4B8573A62EFEECB544DD7",
+	},
+	{
+		name:    "Count/ML-DSA-65/64",
+		params:  mlDSA65,
+		seed:    "26B605C78AC762FA1634C6F91DD117C4FBFF7F3A7E7781F0CC83B6281F04AD7F",
+		keyHash: "5DA13E571DF80867A8F27E0FF81BE7252A1ABF89B3D6A03D4036AF643EFBB04B",
+		message: "C9B07E7DDC0274468F312F5C692A54AC73D1E34D8638E20A2CD3C788F27D4355",
+		sigHash: "12A4637E3A833A5A2A46F6A991399E544B62A230B7AA82F7366840FF6A88DE61",
+	},
+	{
+		name:    "Count/ML-DSA-65/73",
+		params:  mlDSA65,
+		seed:    "9191CF381BEE17475C011986EFB6AFB1EFA6997442FD33427353F1DA1AA39FC0",
+		keyHash: "7930D4E52BA03B61DAA57743B39E291D824DC156356C6B1A8232574D5C8BDD08",
+		message: "E616E36E81AA1EC39262109421AE0DDDA5E3B5A8F4A252BCA27AE882538DF618",
+		sigHash: "3D758ACE312433D780403B3D4273171FB93D008B395352142C6DC5173E517310",
+	},
+	{
+		name:    "Count/ML-DSA-65/66",
+		params:  mlDSA65,
+		seed:    "516912C7B90A3DBE009B7478DBCAF0F5C5C9ED9699A20D0CA56CC516E5A444CD",
+		keyHash: "0FD15951B93A4D19446B48D47D32D2CA2253FF43BB8CCCB34C07E5F1A3181B7A",
+		message: "9247CA75F9456226A0C783DABCC33FF5B4B489575ADED543E74B29B45F9C8EF2",
+		sigHash: "E5CE267800EDF33588451050F9B4A5BF97030D045132A7E3ED9210E74028D23B",
+	},
+	{
+		name:    "Count/ML-DSA-65/65",
+		params:  mlDSA65,
+		seed:    "D4B841F882D50AB9E590066BAFABA0F0D04D32641C0B978E54CCAA69A6E8D2C4",
+		keyHash: "0039C128DDE6923EA08FF14F5C5C66DCB282B471FD1917DBEBE07C8C45B73F8A",
+		message: "175231657B0F3C7065947999467C342064F29BFAEB553E97561407D5560E3AEB",
+		sigHash: "8830EA254AF2854BF67C2B907E2321C94FD6EFB2FDAA77669FC3A5C4426C57C9",
+	},
+	{
+		name:    "Count/ML-DSA-65/64",
+		params:  mlDSA65,
+		seed:    "5492EB8D811072C030A30CC66B23A173059EBA0D4868CCB92FBE2510B4A5915F",
+		keyHash: "573DCD99C86DAE81F6F80CB00AF40846028EA8F9FE63102FE4A78238BC7B660E",
+		message: "33D2753ED87D0003B44C1AF5F72EB931F559C6B4931AF7E249F65D3FA7613295",
+		sigHash: "84D4AF50933D6E13D4332B86AF0692A66F5030AB01C2EAC4131A5EEBF78CE9E5",
+	},
+	{
+		name:    "Count/ML-DSA-87/64",
+		params:  mlDSA87,
+		seed:    "B5C07ECEFE9E7C3B885FDEF032BDF9F807B4011E2DFE6806C088D2081631C8EB",
+		keyHash: "5D22F4C40F6EEB96BB891DB15884ED4B0009EA02A24D9D1E9ADFC81C7A42EA7F",
+		message: "D1D5C2D167D6E62906790A5FEDF5A0A754CFAF47E6A11AEB93FB8C41934C31F8",
+		sigHash: "54F0A9CB26F98B394A35918ECA6760EBD10753FC5CDBA8BE508873AD83538131",
+	},
+	{
+		name:    "Count/ML-DSA-87/65",
+		params:  mlDSA87,
+		seed:    "E8FC3C9FAD711DDA2946334FBBD331468D6E9AB48EB86DCD03F300A17AEBC5E5",
+		keyHash: "B6C4DC9B20CE5D0F445931EE316CF0676E806D1A6A98868881D060EA27CEB139",
+		message: "3B435F7A2CE431C7AB8EAE0991C5DAC610827C99D27803046FBC6C567D6B71F2",
+		sigHash: "E337495F08773F14FB26A3E229B9B26D086644C7FDC300267F9DCDD5D78DB849",
+	},
+	{
+		name:    "Count/ML-DSA-87/64",
+		params:  mlDSA87,
+		seed:    "151F80886D6CE8C3B428964FE02C40CA0C8EFFA100EE089E54D785344FCCF719",
+		keyHash: "127972C33323FEFBF6B69C19E0C86F41558D9AB2B1A8AD6F39BD0A0245DC8D7E",
+		message: "C628CE94D2AA99AA50CF15B147D4F9A9C62A3D4612152DE0A502C377F472D614",
+		sigHash: "99B552B21432544248BFF47AC8F24CB78DBB25C9683F3ADCB75614BED58A0358",
+	},
+	{
+		name:    "Count/ML-DSA-87/64",
+		params:  mlDSA87,
+		seed:    "48BEFFB4C97E59E474E1906F39888BE5AE62F6A011C05EF6A6B8D1E54F2171B7",
+		keyHash: "72DA77CF563CBB530129F60129AF989CA4036BA1058267BFBA34A2C70BE803C4",
+		message: "D2756A8FB4E47F796AF704ED0FC8C6E573D42DFAB443B329F00F8DB2FF12C465",
+		sigHash: "E643914B8556D05360C65EB3E7A06BE7C398B82D49973EEFDC711E65B11EB5E8",
+	},
+	{
+		name:    "Count/ML-DSA-87/69",
+		params:  mlDSA87,
+		seed:    "FE2DA9DD93A077FCB6452AC88D0A5762EB896BAAAC6CE7D01CB1370BA8322390",
+		keyHash: "7422DBE3F476FFE41A4EFB33F3DDFD8B328029BA3050603866C36CFBC2EE4B87",
+		message: "A86B29ADF2300D2636E21D4A350CD18E55A254379C3659A7A95D8734CEC1F005",
+		sigHash: "8D25818DD972FFF5B9E9B4CC534A95100A1340C1C81D1486A68939D340E0A58B",
+	},
+}
+
+func TestACVPVectors(t *testing.T) {
+	for _, v := range acvpVectors {
+		t.Run(v.name, func(t *testing.T) {
+			sk, err := newPrivateKey(v.params, decodeHex(t, v.seed))
+			assert.NoError(t, err)
+
+			keyHash := sha256.Sum256(append(append([]byte{}, sk.publicKey...), encodePrivateKey(sk)...))
+			assert.Equal(t, v.keyHash, strings.ToUpper(hex.EncodeToString(keyHash[:])))
+
+			message := decodeHex(t, v.message)
+			signature := sk.sign(message, make([]byte, 32))
+
+			sigHash := sha256.Sum256(signature)
+			assert.Equal(t, v.sigHash, strings.ToUpper(hex.EncodeToString(sigHash[:])))
+
+			assert.True(t, verify(v.params, sk.publicKey, message, signature))
+		})
+	}
+}
+
+// TestAccumulated generates keys from a SHAKE128 stream of seeds, signs an empty message deterministically with
+// each of them and checks the SHAKE128 hash of all public keys and signatures. The expected hashes are the
+// ones of the accumulated test of the Go standard library.
+func TestAccumulated(t *testing.T) {
+	vectors := []struct {
+		params   parameters
+		count    int
+		expected string
+	}{
+		{params: mlDSA44, count: 100, expected: "d51148e1f9f4fa1a723a6cf42e25f2a99eb5c1b378b3d2dbbd561b1203beeae4"},
+		{params: mlDSA65, count: 100, expected: "8358a1843220194417cadbc2651295cd8fc65125b5a5c1a239a16dc8b57ca199"},
+		{params: mlDSA87, count: 100, expected: "8c3ad714777622b8f21ce31bb35f71394f23bc0fcf3c78ace5d608990f3b061b"},
+	}
+
+	for _, v := range vectors {
+		t.Run(v.params.jwa, func(t *testing.T) {
+			seeds := sha3.NewShake128()
+			accumulated := sha3.NewShake128()
+			message := formatMessage(nil)
+
+			seed := make([]byte, seedSize)
+			for i := 0; i < v.count; i++ {
+				_, _ = seeds.Read(seed)
+
+				sk, err := newPrivateKey(v.params, seed)
+				assert.NoError(t, err)
+
+				signature := sk.sign(message, make([]byte, 32))
+				assert.True(t, verify(v.params, sk.publicKey, message, signature))
+
+				_, _ = accumulated.Write(sk.publicKey)
+				_, _ = accumulated.Write(signature)
+			}
+
+			sum := make([]byte, 32)
+			_, _ = accumulated.Read(sum)
+			assert.Equal(t, v.expected, hex.EncodeToString(sum))
+		})
+	}
+}
+
+// encodePrivateKey encodes the private key as rho || K || tr || s1 || s2 || t0 (FIPS 204 Algorithm 24). Keys
+// are stored as seeds, so the encoding is only needed to check the key hashes of the ACVP vectors.
+func encodePrivateKey(sk *privateKey) []byte {
+	etaBits := 3
+	if sk.params.eta == 4 {
+		etaBits = 4
+	}
+
+	encoded := append([]byte{}, sk.publicKey[:32]...)
+	encoded = append(encoded, sk.key...)
+	encoded = append(encoded, sk.tr...)
+
+	for _, s := range [][]poly{sk.s1, sk.s2} {
+		for i := range s {
+			p := s[i]
+			p.inverseNTT()
+			encoded = packCentered(encoded, &p, sk.params.eta, etaBits)
+		}
+	}
+
+	for i := range sk.t0 {
+		p := sk.t0[i]
+		p.inverseNTT()
+		encoded = packCentered(encoded, &p, 1<<(d-1), d)
+	}
+
+	return encoded
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	decoded, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return decoded
+}
@@ -0,0 +1,206 @@
+// Package mldsa implements ML-DSA, the post-quantum Module-Lattice-Based Digital Signature Algorithm of
+// FIPS 204 (https://csrc.nist.gov/pubs/fips/204/final), with the JWK representation of the IETF JOSE draft
+// (https://datatracker.ietf.org/doc/draft-ietf-cose-dilithium/): keys have the AKP key type, their alg is the
+// parameter set, pub is the encoded public key and priv is the 32 byte seed the key pair is generated from.
+//
+// Signatures are hedged and use the pure variant of ML-DSA with an empty context, as specified by the draft.
+package mldsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+const (
+	// KeyType is the Algorithm Key Pair key type of the draft
+	KeyType string = "AKP"
+
+	MLDSA44JWA         string = "ML-DSA-44"
+	MLDSA44AlgorithmID string = MLDSA44JWA
+	MLDSA65JWA         string = "ML-DSA-65"
+	MLDSA65AlgorithmID string = MLDSA65JWA
+	MLDSA87JWA         string = "ML-DSA-87"
+	MLDSA87AlgorithmID string = MLDSA87JWA
+)
+
+// MLDSA44GeneratePrivateKey generates a new ML-DSA-44 private key
+func MLDSA44GeneratePrivateKey() (jwk.JWK, error) {
+	return generatePrivateKey(mlDSA44)
+}
+
+// MLDSA65GeneratePrivateKey generates a new ML-DSA-65 private key
+func MLDSA65GeneratePrivateKey() (jwk.JWK, error) {
+	return generatePrivateKey(mlDSA65)
+}
+
+// MLDSA87GeneratePrivateKey generates a new ML-DSA-87 private key
+func MLDSA87GeneratePrivateKey() (jwk.JWK, error) {
+	return generatePrivateKey(mlDSA87)
+}
+
+func generatePrivateKey(params parameters) (jwk.JWK, error) {
+	seed := make([]byte, seedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to generate seed: %w", err)
+	}
+
+	privateKey, err := newPrivateKey(params, seed)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return jwk.JWK{
+		ALG:  params.jwa,
+		KTY:  KeyType,
+		PUB:  base64.RawURLEncoding.EncodeToString(privateKey.publicKey),
+		PRIV: base64.RawURLEncoding.EncodeToString(seed),
+	}, nil
+}
+
+// GetPublicKey builds an ML-DSA public key from the given ML-DSA private key
+func GetPublicKey(privateKey jwk.JWK) jwk.JWK {
+	return jwk.JWK{
+		ALG: privateKey.ALG,
+		KTY: privateKey.KTY,
+		PUB: privateKey.PUB,
+	}
+}
+
+// Sign signs the given payload with the given private key
+//
+// # Note
+//
+// The function will automatically detect the ML-DSA parameter set from the alg of the given private key
+func Sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
+	params, err := parametersOf(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if privateKey.PRIV == "" {
+		return nil, errors.New("priv must be set")
+	}
+
+	seed, err := base64.RawURLEncoding.DecodeString(privateKey.PRIV)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode priv %w", err)
+	}
+
+	key, err := newPrivateKey(params, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if privateKey.PUB != "" && privateKey.PUB != base64.RawURLEncoding.EncodeToString(key.publicKey) {
+		return nil, errors.New("priv does not match pub")
+	}
+
+	rnd := make([]byte, 32)
+	if _, err := rand.Read(rnd); err != nil {
+		return nil, fmt.Errorf("failed to generate randomness: %w", err)
+	}
+
+	return key.sign(formatMessage(payload), rnd), nil
+}
+
+// Verify verifies the given signature over the given payload with the given public key
+//
+// # Note
+//
+// The function will automatically detect the ML-DSA parameter set from the alg of the given public key
+func Verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	params, err := parametersOf(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	publicKeyBytes, err := PublicKeyToBytes(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	if len(signature) != params.signatureSize() {
+		return false, fmt.Errorf("%s signatures must be %d bytes", params.jwa, params.signatureSize())
+	}
+
+	return verify(params, publicKeyBytes, formatMessage(payload), signature), nil
+}
+
+// MLDSA44BytesToPublicKey converts an encoded ML-DSA-44 public key to a JWK
+func MLDSA44BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	return bytesToPublicKey(mlDSA44, input)
+}
+
+// MLDSA65BytesToPublicKey converts an encoded ML-DSA-65 public key to a JWK
+func MLDSA65BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	return bytesToPublicKey(mlDSA65, input)
+}
+
+// MLDSA87BytesToPublicKey converts an encoded ML-DSA-87 public key to a JWK
+func MLDSA87BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	return bytesToPublicKey(mlDSA87, input)
+}
+
+func bytesToPublicKey(params parameters, input []byte) (jwk.JWK, error) {
+	if len(input) != params.publicKeySize() {
+		return jwk.JWK{}, fmt.Errorf("%s public keys must be %d bytes", params.jwa, params.publicKeySize())
+	}
+
+	return jwk.JWK{
+		ALG: params.jwa,
+		KTY: KeyType,
+		PUB: base64.RawURLEncoding.EncodeToString(input),
+	}, nil
+}
+
+// PublicKeyToBytes converts the given ML-DSA public key to its encoded form
+func PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	params, err := parametersOf(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if publicKey.PUB == "" {
+		return nil, errors.New("pub must be set")
+	}
+
+	publicKeyBytes, err := base64.RawURLEncoding.DecodeString(publicKey.PUB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pub %w", err)
+	}
+
+	if len(publicKeyBytes) != params.publicKeySize() {
+		return nil, fmt.Errorf("%s public keys must be %d bytes", params.jwa, params.publicKeySize())
+	}
+
+	return publicKeyBytes, nil
+}
+
+// parametersOf returns the parameter set of the given key
+func parametersOf(key jwk.JWK) (parameters, error) {
+	if key.KTY != KeyType {
+		return parameters{}, fmt.Errorf("unsupported key type: %s", key.KTY)
+	}
+
+	switch key.ALG {
+	case MLDSA44JWA:
+		return mlDSA44, nil
+	case MLDSA65JWA:
+		return mlDSA65, nil
+	case MLDSA87JWA:
+		return mlDSA87, nil
+	default:
+		return parameters{}, fmt.Errorf("unsupported alg: %s", key.ALG)
+	}
+}
+
+// formatMessage formats the payload as the message M' of pure ML-DSA with an empty context
+// (FIPS 204 Algorithm 2)
+func formatMessage(payload []byte) []byte {
+	return bytes.Join([][]byte{{0, 0}, payload}, nil)
+}
@@ -0,0 +1,96 @@
+package mldsa_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/mldsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+func TestSign(t *testing.T) {
+	algorithms := []struct {
+		generate         func() (jwk.JWK, error)
+		bytesToPublicKey func(input []byte) (jwk.JWK, error)
+		jwa              string
+	}{
+		{generate: mldsa.MLDSA44GeneratePrivateKey, bytesToPublicKey: mldsa.MLDSA44BytesToPublicKey, jwa: mldsa.MLDSA44JWA},
+		{generate: mldsa.MLDSA65GeneratePrivateKey, bytesToPublicKey: mldsa.MLDSA65BytesToPublicKey, jwa: mldsa.MLDSA65JWA},
+		{generate: mldsa.MLDSA87GeneratePrivateKey, bytesToPublicKey: mldsa.MLDSA87BytesToPublicKey, jwa: mldsa.MLDSA87JWA},
+	}
+
+	for _, a := range algorithms {
+		t.Run(a.jwa, func(t *testing.T) {
+			privateKey, err := a.generate()
+			assert.NoError(t, err)
+			assert.Equal(t, mldsa.KeyType, privateKey.KTY)
+			assert.Equal(t, a.jwa, privateKey.ALG)
+
+			seed, err := base64.RawURLEncoding.DecodeString(privateKey.PRIV)
+			assert.NoError(t, err)
+			assert.Equal(t, 32, len(seed))
+
+			publicKey := mldsa.GetPublicKey(privateKey)
+			assert.False(t, publicKey.IsPrivate())
+
+			payload := []byte("hello world")
+			signature, err := mldsa.Sign(payload, privateKey)
+			assert.NoError(t, err)
+
+			legit, err := mldsa.Verify(payload, signature, publicKey)
+			assert.NoError(t, err)
+			assert.True(t, legit)
+
+			legit, err = mldsa.Verify([]byte("other payload"), signature, publicKey)
+			assert.NoError(t, err)
+			assert.False(t, legit)
+
+			publicKeyBytes, err := mldsa.PublicKeyToBytes(publicKey)
+			assert.NoError(t, err)
+
+			decoded, err := a.bytesToPublicKey(publicKeyBytes)
+			assert.NoError(t, err)
+			assert.Equal(t, publicKey, decoded)
+		})
+	}
+}
+
+func TestSign_Errors(t *testing.T) {
+	privateKey, err := mldsa.MLDSA44GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	otherKey, err := mldsa.MLDSA44GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	_, err = mldsa.Sign([]byte("hello"), mldsa.GetPublicKey(privateKey))
+	assert.Error(t, err)
+
+	mismatched := privateKey
+	mismatched.PUB = otherKey.PUB
+	_, err = mldsa.Sign([]byte("hello"), mismatched)
+	assert.Error(t, err)
+
+	wrongAlg := privateKey
+	wrongAlg.ALG = "ML-DSA-1"
+	_, err = mldsa.Sign([]byte("hello"), wrongAlg)
+	assert.Error(t, err)
+}
+
+func TestVerify_Errors(t *testing.T) {
+	privateKey, err := mldsa.MLDSA44GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	publicKey := mldsa.GetPublicKey(privateKey)
+
+	_, err = mldsa.Verify([]byte("hello"), make([]byte, 64), publicKey)
+	assert.Error(t, err)
+
+	// an ML-DSA-44 public key is not an ML-DSA-65 public key
+	publicKey.ALG = mldsa.MLDSA65JWA
+	_, err = mldsa.Verify([]byte("hello"), make([]byte, 3309), publicKey)
+	assert.Error(t, err)
+
+	_, err = mldsa.MLDSA44BytesToPublicKey(make([]byte, 32))
+	assert.Error(t, err)
+}
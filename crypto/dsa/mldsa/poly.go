@@ -0,0 +1,454 @@
+package mldsa
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// n is the degree of the polynomials of the ring R_q = Z_q[X]/(X^256 + 1)
+	n = 256
+	// q is the modulus of the ring
+	q = 8380417
+	// d is the number of bits dropped from t by Power2Round
+	d = 13
+	// zeta is a 512th root of unity mod q
+	zeta = 1753
+	// nInverse is 256^-1 mod q, which scales the inverse NTT
+	nInverse = 8347681
+)
+
+// poly is a polynomial of R_q, with coefficients in [0, q). Polynomials are either in their normal form or
+// in their NTT form, depending on where they are used.
+type poly [n]int32
+
+// zetas are the powers of zeta in bit reversed order used by the NTT, as per Appendix B of FIPS 204
+var zetas = func() [n]int32 {
+	var zetas [n]int32
+	for k := range zetas {
+		zetas[k] = int32(power(zeta, bitReverse8(k)))
+	}
+
+	return zetas
+}()
+
+func power(base int64, exponent int) int64 {
+	result := int64(1)
+	for i := 0; i < exponent; i++ {
+		result = result * base % q
+	}
+
+	return result
+}
+
+func bitReverse8(k int) int {
+	reversed := 0
+	for i := 0; i < 8; i++ {
+		reversed |= (k >> i & 1) << (7 - i)
+	}
+
+	return reversed
+}
+
+func fieldAdd(a int32, b int32) int32 {
+	r := a + b
+	if r >= q {
+		r -= q
+	}
+
+	return r
+}
+
+func fieldSub(a int32, b int32) int32 {
+	r := a - b
+	if r < 0 {
+		r += q
+	}
+
+	return r
+}
+
+func fieldMul(a int32, b int32) int32 {
+	return int32(int64(a) * int64(b) % q)
+}
+
+// fieldReduce maps a coefficient in (-q, q) to [0, q)
+func fieldReduce(a int32) int32 {
+	if a < 0 {
+		a += q
+	}
+
+	return a
+}
+
+// centered returns the representative of the coefficient in (-(q-1)/2, (q-1)/2]
+func centered(a int32) int32 {
+	if a > (q-1)/2 {
+		return a - q
+	}
+
+	return a
+}
+
+// infinityNorm returns the largest absolute value of the centered coefficients of the polynomials
+func infinityNorm(polys []poly) int32 {
+	var norm int32
+	for i := range polys {
+		for _, c := range polys[i] {
+			c = centered(c)
+			if c < 0 {
+				c = -c
+			}
+
+			if c > norm {
+				norm = c
+			}
+		}
+	}
+
+	return norm
+}
+
+// ntt computes the NTT of the polynomial in place (FIPS 204 Algorithm 41)
+func (p *poly) ntt() {
+	m := 0
+	for length := 128; length >= 1; length /= 2 {
+		for start := 0; start < n; start += 2 * length {
+			m++
+			z := zetas[m]
+			for j := start; j < start+length; j++ {
+				t := fieldMul(z, p[j+length])
+				p[j+length] = fieldSub(p[j], t)
+				p[j] = fieldAdd(p[j], t)
+			}
+		}
+	}
+}
+
+// inverseNTT computes the inverse NTT of the polynomial in place (FIPS 204 Algorithm 42)
+func (p *poly) inverseNTT() {
+	m := n
+	for length := 1; length < n; length *= 2 {
+		for start := 0; start < n; start += 2 * length {
+			m--
+			z := q - zetas[m]
+			for j := start; j < start+length; j++ {
+				t := p[j]
+				p[j] = fieldAdd(t, p[j+length])
+				p[j+length] = fieldMul(z, fieldSub(t, p[j+length]))
+			}
+		}
+	}
+
+	for j := range p {
+		p[j] = fieldMul(p[j], nInverse)
+	}
+}
+
+// add sets p to a + b
+func (p *poly) add(a *poly, b *poly) {
+	for j := range p {
+		p[j] = fieldAdd(a[j], b[j])
+	}
+}
+
+// sub sets p to a - b
+func (p *poly) sub(a *poly, b *poly) {
+	for j := range p {
+		p[j] = fieldSub(a[j], b[j])
+	}
+}
+
+// multiplyNTT sets p to the product of a and b, which are in NTT form
+func (p *poly) multiplyNTT(a *poly, b *poly) {
+	for j := range p {
+		p[j] = fieldMul(a[j], b[j])
+	}
+}
+
+// nttAll returns the NTT of each polynomial, leaving them unchanged
+func nttAll(polys []poly) []poly {
+	transformed := make([]poly, len(polys))
+	copy(transformed, polys)
+	for i := range transformed {
+		transformed[i].ntt()
+	}
+
+	return transformed
+}
+
+// matrixVectorNTT returns the inverse NTT of the product of the matrix and the vector, which are in NTT form
+func matrixVectorNTT(matrix [][]poly, vector []poly) []poly {
+	result := make([]poly, len(matrix))
+	for i, row := range matrix {
+		var product poly
+		for j := range row {
+			product.multiplyNTT(&row[j], &vector[j])
+			result[i].add(&result[i], &product)
+		}
+
+		result[i].inverseNTT()
+	}
+
+	return result
+}
+
+// scalarVectorNTT returns the inverse NTT of the product of the polynomial and the vector, which are in NTT
+// form
+func scalarVectorNTT(scalar *poly, vector []poly) []poly {
+	result := make([]poly, len(vector))
+	for i := range vector {
+		result[i].multiplyNTT(scalar, &vector[i])
+		result[i].inverseNTT()
+	}
+
+	return result
+}
+
+// expandA samples the matrix A in NTT form from the seed rho (FIPS 204 Algorithm 32)
+func expandA(rho []byte, k int, l int) [][]poly {
+	matrix := make([][]poly, k)
+	for r := range matrix {
+		matrix[r] = make([]poly, l)
+		for s := range matrix[r] {
+			seed := append(append([]byte{}, rho...), byte(s), byte(r))
+			matrix[r][s] = rejectionSampleNTT(seed)
+		}
+	}
+
+	return matrix
+}
+
+// rejectionSampleNTT samples a polynomial in NTT form with uniform coefficients (FIPS 204 Algorithm 30)
+func rejectionSampleNTT(seed []byte) poly {
+	xof := sha3.NewShake128()
+	xof.Write(seed)
+
+	var p poly
+	var buf [168]byte
+	for j := 0; j < n; {
+		xof.Read(buf[:])
+		for i := 0; i < len(buf) && j < n; i += 3 {
+			c := int32(buf[i]) | int32(buf[i+1])<<8 | int32(buf[i+2]&0x7f)<<16
+			if c < q {
+				p[j] = c
+				j++
+			}
+		}
+	}
+
+	return p
+}
+
+// expandS samples the secret vectors s1 and s2 with coefficients in [-eta, eta] from the seed rho'
+// (FIPS 204 Algorithm 33)
+func expandS(rho []byte, eta int32, k int, l int) ([]poly, []poly) {
+	s1 := make([]poly, l)
+	for r := range s1 {
+		s1[r] = rejectionBoundedPoly(rho, eta, r)
+	}
+
+	s2 := make([]poly, k)
+	for r := range s2 {
+		s2[r] = rejectionBoundedPoly(rho, eta, r+l)
+	}
+
+	return s1, s2
+}
+
+// rejectionBoundedPoly samples a polynomial with coefficients in [-eta, eta] (FIPS 204 Algorithm 31)
+func rejectionBoundedPoly(rho []byte, eta int32, nonce int) poly {
+	xof := sha3.NewShake256()
+	xof.Write(rho)
+	xof.Write([]byte{byte(nonce), byte(nonce >> 8)})
+
+	var p poly
+	var buf [136]byte
+	for j := 0; j < n; {
+		xof.Read(buf[:])
+		for i := 0; i < len(buf) && j < n; i++ {
+			for _, b := range []int32{int32(buf[i] & 0x0f), int32(buf[i] >> 4)} {
+				c, ok := coefficientFromHalfByte(b, eta)
+				if ok && j < n {
+					p[j] = fieldReduce(c)
+					j++
+				}
+			}
+		}
+	}
+
+	return p
+}
+
+// coefficientFromHalfByte maps a half byte to a coefficient in [-eta, eta] (FIPS 204 Algorithm 15)
+func coefficientFromHalfByte(b int32, eta int32) (int32, bool) {
+	switch {
+	case eta == 2 && b < 15:
+		return 2 - b%5, true
+	case eta == 4 && b < 9:
+		return 4 - b, true
+	default:
+		return 0, false
+	}
+}
+
+// expandMask samples the masking vector y with coefficients in [-gamma1 + 1, gamma1] (FIPS 204 Algorithm 34)
+func expandMask(rho []byte, kappa int, gamma1 int32, gamma1Bits int, l int) []poly {
+	y := make([]poly, l)
+	buf := make([]byte, 32*gamma1Bits)
+	for r := range y {
+		xof := sha3.NewShake256()
+		xof.Write(rho)
+		xof.Write([]byte{byte(kappa + r), byte((kappa + r) >> 8)})
+		xof.Read(buf)
+
+		y[r] = unpackCentered(buf, gamma1, gamma1Bits)
+	}
+
+	return y
+}
+
+// sampleInBall samples a polynomial with tau coefficients in {-1, 1} and the others 0 from the seed
+// (FIPS 204 Algorithm 29)
+func sampleInBall(seed []byte, tau int) poly {
+	xof := sha3.NewShake256()
+	xof.Write(seed)
+
+	var signBytes [8]byte
+	xof.Read(signBytes[:])
+
+	var signs uint64
+	for i, b := range signBytes {
+		signs |= uint64(b) << (8 * i)
+	}
+
+	var c poly
+	var j [1]byte
+	for i := n - tau; i < n; i++ {
+		for {
+			xof.Read(j[:])
+			if int(j[0]) <= i {
+				break
+			}
+		}
+
+		c[i] = c[j[0]]
+		if signs&1 == 1 {
+			c[j[0]] = q - 1
+		} else {
+			c[j[0]] = 1
+		}
+
+		signs >>= 1
+	}
+
+	return c
+}
+
+// power2Round splits a coefficient r into r1 and r0 such that r = r1 * 2^d + r0 with r0 in
+// (-2^(d-1), 2^(d-1)] (FIPS 204 Algorithm 35)
+func power2Round(r int32) (int32, int32) {
+	r0 := r & (1<<d - 1)
+	if r0 > 1<<(d-1) {
+		r0 -= 1 << d
+	}
+
+	return (r - r0) >> d, r0
+}
+
+// decompose splits a coefficient r into r1 and r0 such that r = r1 * 2 * gamma2 + r0 mod q with r0 in
+// (-gamma2, gamma2] (FIPS 204 Algorithm 36)
+func decompose(r int32, gamma2 int32) (int32, int32) {
+	r0 := r % (2 * gamma2)
+	if r0 > gamma2 {
+		r0 -= 2 * gamma2
+	}
+
+	if r-r0 == q-1 {
+		return 0, r0 - 1
+	}
+
+	return (r - r0) / (2 * gamma2), r0
+}
+
+// makeHint returns whether adding z to r changes the high bits of r (FIPS 204 Algorithm 39)
+func makeHint(z int32, r int32, gamma2 int32) bool {
+	r1, _ := decompose(r, gamma2)
+	v1, _ := decompose(fieldAdd(r, z), gamma2)
+	return r1 != v1
+}
+
+// useHint returns the high bits of r adjusted by the hint (FIPS 204 Algorithm 40)
+func useHint(hint bool, r int32, gamma2 int32) int32 {
+	m := (q - 1) / (2 * gamma2)
+	r1, r0 := decompose(r, gamma2)
+
+	switch {
+	case hint && r0 > 0:
+		return (r1 + 1) % m
+	case hint:
+		return (r1 - 1 + m) % m
+	default:
+		return r1
+	}
+}
+
+// packBits appends the coefficients of the polynomial, each of which must fit in the given number of bits,
+// in little endian bit order (FIPS 204 Algorithm 16)
+func packBits(dst []byte, p *poly, bits int) []byte {
+	var acc uint64
+	accBits := 0
+	for _, c := range p {
+		acc |= uint64(c) << accBits
+		accBits += bits
+		for accBits >= 8 {
+			dst = append(dst, byte(acc))
+			acc >>= 8
+			accBits -= 8
+		}
+	}
+
+	return dst
+}
+
+// unpackBits reads the coefficients of a polynomial packed by packBits from 32 * bits bytes
+// (FIPS 204 Algorithm 18)
+func unpackBits(src []byte, bits int) poly {
+	var p poly
+	var acc uint64
+	accBits := 0
+	j := 0
+	mask := uint64(1)<<bits - 1
+	for _, b := range src {
+		acc |= uint64(b) << accBits
+		accBits += 8
+		for accBits >= bits && j < n {
+			p[j] = int32(acc & mask)
+			acc >>= bits
+			accBits -= bits
+			j++
+		}
+	}
+
+	return p
+}
+
+// packCentered appends the coefficients of the polynomial, which must be in [b - 2^bits + 1, b], as b minus
+// the coefficient (FIPS 204 Algorithm 17)
+func packCentered(dst []byte, p *poly, b int32, bits int) []byte {
+	var shifted poly
+	for j, c := range p {
+		shifted[j] = fieldSub(b, c)
+	}
+
+	return packBits(dst, &shifted, bits)
+}
+
+// unpackCentered reverses packCentered (FIPS 204 Algorithm 19)
+func unpackCentered(src []byte, b int32, bits int) poly {
+	p := unpackBits(src, bits)
+	for j, c := range p {
+		p[j] = fieldSub(b, c)
+	}
+
+	return p
+}
@@ -0,0 +1,266 @@
+// Package schnorr implements BIP-340 Schnorr signatures over secp256k1
+// https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki
+package schnorr
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/decentralized-identity/web5-go/jwk"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+const (
+	// JWA is the alg of JWSs signed with BIP-340 Schnorr signatures. It is not registered with IANA.
+	JWA                  string = "SS256K"
+	KeyType              string = "EC"
+	SECP256K1JWACurve    string = "secp256k1"
+	SECP256K1AlgorithmID string = "secp256k1-schnorr"
+)
+
+// SECP256K1GeneratePrivateKey generates a new private key whose public key has an even y coordinate, so that
+// it is the public key BIP-340 derives from its 32 byte x only form
+func SECP256K1GeneratePrivateKey() (jwk.JWK, error) {
+	keyPair, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	// d and n - d have the same x coordinate, and opposite y coordinates
+	if keyPair.PubKey().Y().Bit(0) == 1 {
+		keyPair.Key.Negate()
+	}
+
+	dBytes := keyPair.Key.Bytes()
+	pubKey := keyPair.PubKey()
+
+	return jwk.JWK{
+		KTY: KeyType,
+		CRV: SECP256K1JWACurve,
+		D:   base64.RawURLEncoding.EncodeToString(dBytes[:]),
+		X:   base64.RawURLEncoding.EncodeToString(pubKey.X().FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(pubKey.Y().FillBytes(make([]byte, 32))),
+	}, nil
+}
+
+// SECP256K1Sign signs the SHA-256 digest of the given payload with the given private key. Signatures are
+// randomized with auxiliary randomness, as recommended by BIP-340.
+func SECP256K1Sign(payload []byte, privateKey jwk.JWK) ([]byte, error) {
+	digest := sha256.Sum256(payload)
+	return SECP256K1SignDigest(digest[:], privateKey)
+}
+
+// SECP256K1SignDigest signs a 32 byte message computed by the caller, e.g. a Bitcoin signature hash, with the
+// given private key
+func SECP256K1SignDigest(digest []byte, privateKey jwk.JWK) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, errors.New("digest must be 32 bytes")
+	}
+
+	if privateKey.D == "" {
+		return nil, errors.New("d must be set")
+	}
+
+	d, err := base64.RawURLEncoding.DecodeString(privateKey.D)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode d %w", err)
+	}
+
+	auxRand := make([]byte, 32)
+	if _, err := rand.Read(auxRand); err != nil {
+		return nil, fmt.Errorf("failed to generate auxiliary randomness: %w", err)
+	}
+
+	return sign(digest, d, auxRand)
+}
+
+// SECP256K1Verify verifies the given signature over the SHA-256 digest of the given payload with the given
+// public key. Only the x coordinate of the public key is used, as per BIP-340.
+func SECP256K1Verify(payload []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	digest := sha256.Sum256(payload)
+	return SECP256K1VerifyDigest(digest[:], signature, publicKey)
+}
+
+// SECP256K1VerifyDigest verifies the given signature over a 32 byte message computed by the caller
+func SECP256K1VerifyDigest(digest []byte, signature []byte, publicKey jwk.JWK) (bool, error) {
+	if len(digest) != 32 {
+		return false, errors.New("digest must be 32 bytes")
+	}
+
+	x, err := SECP256K1PublicKeyToBytes(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	return verify(digest, signature, x)
+}
+
+// SECP256K1BytesToPublicKey converts a 32 byte BIP-340 public key, or a compressed or uncompressed SEC 1
+// public key, to a JWK. The y coordinate of BIP-340 public keys is the even one.
+func SECP256K1BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	if len(input) == 32 {
+		input = append([]byte{0x02}, input...)
+	}
+
+	key, err := secp256k1.ParsePubKey(input)
+	if err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return jwk.JWK{
+		KTY: KeyType,
+		CRV: SECP256K1JWACurve,
+		X:   base64.RawURLEncoding.EncodeToString(key.X().FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y().FillBytes(make([]byte, 32))),
+	}, nil
+}
+
+// SECP256K1PublicKeyToBytes converts a public key JWK to its 32 byte BIP-340 form, which is its x coordinate
+func SECP256K1PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	if publicKey.X == "" {
+		return nil, errors.New("x must be set")
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(publicKey.X)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode x: %w", err)
+	}
+
+	if _, err := liftX(x); err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// sign signs a message of any size with a secret key and auxiliary randomness as per the Default Signing
+// algorithm of BIP-340
+func sign(message []byte, secretKey []byte, auxRand []byte) ([]byte, error) {
+	var d secp256k1.ModNScalar
+	if len(secretKey) != 32 || d.SetByteSlice(secretKey) || d.IsZero() {
+		return nil, errors.New("invalid private key")
+	}
+
+	var p secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&d, &p)
+	p.ToAffine()
+
+	// the public key is the point with an even y coordinate, so the secret key is negated if y is odd
+	if p.Y.IsOdd() {
+		d.Negate()
+	}
+
+	px := p.X.Bytes()
+	dBytes := d.Bytes()
+
+	t := taggedHash("BIP0340/aux", auxRand)
+	for i := range t {
+		t[i] ^= dBytes[i]
+	}
+
+	var k secp256k1.ModNScalar
+	k.SetByteSlice(taggedHash("BIP0340/nonce", t, px[:], message))
+	if k.IsZero() {
+		return nil, errors.New("failed to sign: nonce is zero")
+	}
+
+	var r secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&k, &r)
+	r.ToAffine()
+
+	if r.Y.IsOdd() {
+		k.Negate()
+	}
+
+	rx := r.X.Bytes()
+
+	var e secp256k1.ModNScalar
+	e.SetByteSlice(taggedHash("BIP0340/challenge", rx[:], px[:], message))
+
+	s := new(secp256k1.ModNScalar).Mul2(&e, &d).Add(&k)
+	sBytes := s.Bytes()
+
+	signature := append(rx[:], sBytes[:]...)
+
+	// verifying the signature guards against computation errors, as recommended by BIP-340
+	if ok, err := verify(message, signature, px[:]); err != nil || !ok {
+		return nil, errors.New("failed to sign: signature does not verify")
+	}
+
+	return signature, nil
+}
+
+// verify verifies a signature over a message of any size with a 32 byte public key as per the Verification
+// algorithm of BIP-340
+func verify(message []byte, signature []byte, publicKey []byte) (bool, error) {
+	p, err := liftX(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	if len(signature) != 64 {
+		return false, errors.New("signature must be 64 bytes")
+	}
+
+	var r secp256k1.FieldVal
+	if r.SetByteSlice(signature[:32]) {
+		return false, nil
+	}
+
+	var s secp256k1.ModNScalar
+	if s.SetByteSlice(signature[32:]) {
+		return false, nil
+	}
+
+	var e secp256k1.ModNScalar
+	e.SetByteSlice(taggedHash("BIP0340/challenge", signature[:32], publicKey, message))
+
+	// R = s⋅G - e⋅P
+	var sG, eP, point secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&s, &sG)
+	secp256k1.ScalarMultNonConst(e.Negate(), &p, &eP)
+	secp256k1.AddNonConst(&sG, &eP, &point)
+
+	if (point.X.IsZero() && point.Y.IsZero()) || point.Z.IsZero() {
+		return false, nil
+	}
+
+	point.ToAffine()
+	return !point.Y.IsOdd() && point.X.Equals(&r), nil
+}
+
+// liftX returns the point with the given x coordinate and an even y coordinate
+func liftX(x []byte) (secp256k1.JacobianPoint, error) {
+	if len(x) != 32 {
+		return secp256k1.JacobianPoint{}, errors.New("public key must be 32 bytes")
+	}
+
+	var fx, fy secp256k1.FieldVal
+	if fx.SetByteSlice(x) || !secp256k1.DecompressY(&fx, false, &fy) {
+		return secp256k1.JacobianPoint{}, errors.New("invalid public key")
+	}
+
+	fy.Normalize()
+
+	var one secp256k1.FieldVal
+	one.SetInt(1)
+
+	return secp256k1.MakeJacobianPoint(&fx, &fy, &one), nil
+}
+
+// taggedHash computes the BIP-340 tagged hash SHA256(SHA256(tag) || SHA256(tag) || data)
+func taggedHash(tag string, data ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
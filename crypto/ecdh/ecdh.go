@@ -0,0 +1,160 @@
+// Package ecdh implements Elliptic Curve Diffie-Hellman key agreement (https://www.rfc-editor.org/rfc/rfc7748
+// and https://www.secg.org/sec1-v2.pdf section 3.3.1) over the keys managed by web5-go.
+//
+// X25519 keys are key agreement only, so they are generated by this package. secp256k1, secp256r1 and
+// secp384r1 keys generated by [github.com/decentralized-identity/web5-go/crypto/dsa] can be used for both
+// signing and key agreement.
+package ecdh
+
+import (
+	_ecdh "crypto/ecdh"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+	_secp256k1 "github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+var algorithmIDs = map[string]bool{
+	X25519AlgorithmID: true,
+}
+
+// GeneratePrivateKey generates a key agreement only private key for the given algorithm
+func GeneratePrivateKey(algorithmID string) (jwk.JWK, error) {
+	switch algorithmID {
+	case X25519AlgorithmID:
+		return X25519GeneratePrivateKey()
+	default:
+		return jwk.JWK{}, fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	}
+}
+
+// GetPublicKey builds the public key for the given key agreement only private key
+func GetPublicKey(privateKey jwk.JWK) jwk.JWK {
+	return jwk.JWK{
+		KTY: privateKey.KTY,
+		CRV: privateKey.CRV,
+		X:   privateKey.X,
+	}
+}
+
+// BytesToPublicKey deserializes the given byte array into a jwk.JWK for the given key agreement only algorithm
+func BytesToPublicKey(algorithmID string, input []byte) (jwk.JWK, error) {
+	switch algorithmID {
+	case X25519AlgorithmID:
+		return X25519BytesToPublicKey(input)
+	default:
+		return jwk.JWK{}, fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	}
+}
+
+// PublicKeyToBytes serializes the given key agreement only public key into a byte array
+func PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	switch publicKey.CRV {
+	case X25519JWACurve:
+		return X25519PublicKeyToBytes(publicKey)
+	default:
+		return nil, fmt.Errorf("unsupported curve: %s", publicKey.CRV)
+	}
+}
+
+// SupportsAlgorithmID informs as to whether or not the given algorithm ID is a key agreement only
+// algorithm supported by this package
+func SupportsAlgorithmID(id string) bool {
+	return algorithmIDs[id]
+}
+
+// AlgorithmID returns the algorithm ID for the given key agreement only jwk.JWK
+func AlgorithmID(jwk *jwk.JWK) (string, error) {
+	switch jwk.CRV {
+	case X25519JWACurve:
+		return X25519AlgorithmID, nil
+	default:
+		return "", fmt.Errorf("unsupported curve: %s", jwk.CRV)
+	}
+}
+
+// DeriveSharedSecret computes the raw ECDH shared secret between the given private key and the public key
+// of the other party, which must be on the same curve. For the NIST curves and secp256k1 the secret is the
+// x-coordinate of the shared point, as specified by SEC 1. The secret is not uniformly random and should be
+// passed through a key derivation function, e.g. Concat KDF or HKDF, before use.
+func DeriveSharedSecret(privateKey jwk.JWK, peerPublicKey jwk.JWK) ([]byte, error) {
+	if privateKey.D == "" {
+		return nil, errors.New("d must be set")
+	}
+
+	if privateKey.KTY != peerPublicKey.KTY || privateKey.CRV != peerPublicKey.CRV {
+		return nil, fmt.Errorf("key types do not match: %s %s and %s %s", privateKey.KTY, privateKey.CRV, peerPublicKey.KTY, peerPublicKey.CRV)
+	}
+
+	d, err := base64.RawURLEncoding.DecodeString(privateKey.D)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode d %w", err)
+	}
+
+	switch privateKey.CRV {
+	case X25519JWACurve:
+		return deriveSharedSecret(_ecdh.X25519(), d, peerPublicKey, X25519PublicKeyToBytes)
+	case ecdsa.SECP256R1JWACurve:
+		return deriveSharedSecret(_ecdh.P256(), d, peerPublicKey, dsa.PublicKeyToBytes)
+	case ecdsa.SECP384R1JWACurve:
+		return deriveSharedSecret(_ecdh.P384(), d, peerPublicKey, dsa.PublicKeyToBytes)
+	case ecdsa.SECP256K1JWACurve:
+		return secp256k1SharedSecret(d, peerPublicKey)
+	default:
+		return nil, fmt.Errorf("unsupported curve: %s", privateKey.CRV)
+	}
+}
+
+// deriveSharedSecret computes a shared secret on a curve implemented by crypto/ecdh, which rejects
+// invalid public keys and, for X25519, low order points that would produce an all zero secret
+func deriveSharedSecret(curve _ecdh.Curve, d []byte, peerPublicKey jwk.JWK, toBytes func(jwk.JWK) ([]byte, error)) ([]byte, error) {
+	key, err := curve.NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	peerBytes, err := toBytes(peerPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	peer, err := curve.NewPublicKey(peerBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	secret, err := key.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// secp256k1SharedSecret computes a shared secret on secp256k1
+func secp256k1SharedSecret(d []byte, peerPublicKey jwk.JWK) ([]byte, error) {
+	if len(d) != 32 {
+		return nil, errors.New("invalid private key: d must be 32 bytes")
+	}
+
+	peerBytes, err := ecdsa.SECP256K1PublicKeyToBytes(peerPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	peer, err := _secp256k1.ParsePubKey(peerBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	key := _secp256k1.PrivKeyFromBytes(d)
+	if key.Key.IsZero() {
+		return nil, errors.New("invalid private key")
+	}
+
+	return _secp256k1.GenerateSharedSecret(key, peer), nil
+}
@@ -0,0 +1,55 @@
+package ecdh_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+)
+
+func TestDeriveSharedSecret(t *testing.T) {
+	sizes := map[string]int{
+		dsa.AlgorithmIDSECP256K1: 32,
+		dsa.AlgorithmIDSECP256R1: 32,
+		dsa.AlgorithmIDSECP384R1: 48,
+	}
+
+	for algorithmID, size := range sizes {
+		t.Run(algorithmID, func(t *testing.T) {
+			alice, err := dsa.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+
+			bob, err := dsa.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+
+			aliceSecret, err := ecdh.DeriveSharedSecret(alice, dsa.GetPublicKey(bob))
+			assert.NoError(t, err)
+			assert.Equal(t, size, len(aliceSecret))
+
+			bobSecret, err := ecdh.DeriveSharedSecret(bob, dsa.GetPublicKey(alice))
+			assert.NoError(t, err)
+			assert.Equal(t, aliceSecret, bobSecret)
+
+			_, err = ecdh.DeriveSharedSecret(dsa.GetPublicKey(alice), dsa.GetPublicKey(bob))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDeriveSharedSecret_MismatchedCurves(t *testing.T) {
+	alice, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	assert.NoError(t, err)
+
+	bob, err := ecdh.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	assert.NoError(t, err)
+
+	_, err = ecdh.DeriveSharedSecret(alice, ecdh.GetPublicKey(bob))
+	assert.Error(t, err)
+
+	ed, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
+	_, err = ecdh.DeriveSharedSecret(ed, dsa.GetPublicKey(ed))
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package ecdh
+
+import (
+	_ecdh "crypto/ecdh"
+	_ed25519 "crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa/eddsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+const (
+	KeyType           string = "OKP"
+	X25519JWACurve    string = "X25519"
+	X25519AlgorithmID string = X25519JWACurve
+)
+
+// x25519KeySize is the size of X25519 private and public keys
+const x25519KeySize = 32
+
+// curve25519P is the prime 2^255 - 19 that both Curve25519 and Edwards25519 are defined over
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// X25519GeneratePrivateKey generates a new X25519 private key
+func X25519GeneratePrivateKey() (jwk.JWK, error) {
+	key, err := _ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	return x25519JWK(key), nil
+}
+
+// X25519BytesToPublicKey deserializes the byte array into a jwk.JWK public key
+func X25519BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	if len(input) != x25519KeySize {
+		return jwk.JWK{}, errors.New("invalid public key")
+	}
+
+	return jwk.JWK{
+		KTY: KeyType,
+		CRV: X25519JWACurve,
+		X:   base64.RawURLEncoding.EncodeToString(input),
+	}, nil
+}
+
+// X25519PublicKeyToBytes serializes the given public key into a byte array
+func X25519PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	if publicKey.X == "" {
+		return nil, errors.New("x must be set")
+	}
+
+	publicKeyBytes, err := base64.RawURLEncoding.DecodeString(publicKey.X)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode x %w", err)
+	}
+
+	if len(publicKeyBytes) != x25519KeySize {
+		return nil, errors.New("invalid public key")
+	}
+
+	return publicKeyBytes, nil
+}
+
+// ED25519ToX25519PrivateKey converts an Ed25519 private key to the X25519 private key of the same key pair,
+// as described in https://www.rfc-editor.org/rfc/rfc8032#section-5.1.5 and RFC 7748 section 5. This allows
+// a single Ed25519 key to be used for both signing and key agreement, though using separate keys is preferred.
+func ED25519ToX25519PrivateKey(privateKey jwk.JWK) (jwk.JWK, error) {
+	if privateKey.CRV != eddsa.ED25519JWACurve || privateKey.D == "" {
+		return jwk.JWK{}, errors.New("an Ed25519 private key is required")
+	}
+
+	d, err := base64.RawURLEncoding.DecodeString(privateKey.D)
+	if err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to decode d %w", err)
+	}
+
+	// d is either the 32 byte seed, or the seed followed by the public key
+	if len(d) != _ed25519.SeedSize && len(d) != _ed25519.SeedSize+_ed25519.PublicKeySize {
+		return jwk.JWK{}, errors.New("invalid private key")
+	}
+
+	h := sha512.Sum512(d[:_ed25519.SeedSize])
+
+	// clamping is applied by X25519 itself, so the first half of the hash is used as is
+	key, err := _ecdh.X25519().NewPrivateKey(h[:x25519KeySize])
+	if err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to convert private key: %w", err)
+	}
+
+	return x25519JWK(key), nil
+}
+
+// ED25519ToX25519PublicKey converts an Ed25519 public key to the X25519 public key of the same key pair,
+// by mapping the Edwards y-coordinate to the Montgomery u-coordinate u = (1 + y) / (1 - y), as described in
+// RFC 7748 section 4.1.
+func ED25519ToX25519PublicKey(publicKey jwk.JWK) (jwk.JWK, error) {
+	if publicKey.CRV != eddsa.ED25519JWACurve {
+		return jwk.JWK{}, errors.New("an Ed25519 public key is required")
+	}
+
+	x, err := eddsa.ED25519PublicKeyToBytes(publicKey)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	if len(x) != _ed25519.PublicKeySize {
+		return jwk.JWK{}, errors.New("invalid public key")
+	}
+
+	// the encoding is the little-endian y-coordinate, with the sign of x in the most significant bit
+	be := make([]byte, _ed25519.PublicKeySize)
+	for i := range x {
+		be[i] = x[_ed25519.PublicKeySize-1-i]
+	}
+	be[0] &= 0x7f
+	y := new(big.Int).SetBytes(be)
+
+	one := big.NewInt(1)
+	if y.Cmp(curve25519P) >= 0 || y.Cmp(one) == 0 {
+		return jwk.JWK{}, errors.New("invalid public key")
+	}
+
+	numerator := new(big.Int).Add(one, y)
+	denominator := new(big.Int).Sub(one, y)
+	denominator.Mod(denominator, curve25519P)
+	denominator.ModInverse(denominator, curve25519P)
+
+	u := numerator.Mul(numerator, denominator)
+	u.Mod(u, curve25519P)
+
+	le := make([]byte, x25519KeySize)
+	u.FillBytes(le)
+	for i, j := 0, len(le)-1; i < j; i, j = i+1, j-1 {
+		le[i], le[j] = le[j], le[i]
+	}
+
+	return X25519BytesToPublicKey(le)
+}
+
+func x25519JWK(key *_ecdh.PrivateKey) jwk.JWK {
+	return jwk.JWK{
+		KTY: KeyType,
+		CRV: X25519JWACurve,
+		D:   base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		X:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+	}
+}
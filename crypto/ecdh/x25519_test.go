@@ -0,0 +1,103 @@
+package ecdh_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+func TestX25519DeriveSharedSecret_RFC7748(t *testing.T) {
+	// vector taken from https://www.rfc-editor.org/rfc/rfc7748#section-6.1
+	alice := x25519Key(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a", "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")
+	bob := x25519Key(t, "5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb", "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+
+	secret, err := ecdh.DeriveSharedSecret(alice, ecdh.GetPublicKey(bob))
+	assert.NoError(t, err)
+	assert.Equal(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(secret))
+
+	secret, err = ecdh.DeriveSharedSecret(bob, ecdh.GetPublicKey(alice))
+	assert.NoError(t, err)
+	assert.Equal(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(secret))
+}
+
+func TestX25519DeriveSharedSecret_LowOrderPoint(t *testing.T) {
+	alice, err := ecdh.X25519GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	// the point of order 1 produces an all zero shared secret
+	lowOrder, err := ecdh.X25519BytesToPublicKey(make([]byte, 32))
+	assert.NoError(t, err)
+
+	_, err = ecdh.DeriveSharedSecret(alice, lowOrder)
+	assert.Error(t, err)
+}
+
+func TestX25519PublicKeyBytes(t *testing.T) {
+	privateKey, err := ecdh.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	assert.NoError(t, err)
+
+	publicKey := ecdh.GetPublicKey(privateKey)
+	publicKeyBytes, err := ecdh.PublicKeyToBytes(publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, len(publicKeyBytes))
+
+	decoded, err := ecdh.BytesToPublicKey(ecdh.X25519AlgorithmID, publicKeyBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, decoded)
+
+	algorithmID, err := ecdh.AlgorithmID(&decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, ecdh.X25519AlgorithmID, algorithmID)
+
+	_, err = ecdh.BytesToPublicKey(ecdh.X25519AlgorithmID, publicKeyBytes[1:])
+	assert.Error(t, err)
+}
+
+func TestED25519ToX25519(t *testing.T) {
+	edPrivateKey, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
+	xPrivateKey, err := ecdh.ED25519ToX25519PrivateKey(edPrivateKey)
+	assert.NoError(t, err)
+
+	xPublicKey, err := ecdh.ED25519ToX25519PublicKey(dsa.GetPublicKey(edPrivateKey))
+	assert.NoError(t, err)
+
+	// converting each half of the key pair produces the same X25519 key pair
+	assert.Equal(t, ecdh.GetPublicKey(xPrivateKey), xPublicKey)
+
+	other, err := ecdh.X25519GeneratePrivateKey()
+	assert.NoError(t, err)
+
+	secret, err := ecdh.DeriveSharedSecret(xPrivateKey, ecdh.GetPublicKey(other))
+	assert.NoError(t, err)
+
+	otherSecret, err := ecdh.DeriveSharedSecret(other, xPublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, otherSecret)
+
+	_, err = ecdh.ED25519ToX25519PublicKey(xPublicKey)
+	assert.Error(t, err)
+}
+
+func x25519Key(t *testing.T, d, x string) jwk.JWK {
+	t.Helper()
+
+	dBytes, err := hex.DecodeString(d)
+	assert.NoError(t, err)
+
+	xBytes, err := hex.DecodeString(x)
+	assert.NoError(t, err)
+
+	return jwk.JWK{
+		KTY: ecdh.KeyType,
+		CRV: ecdh.X25519JWACurve,
+		D:   base64.RawURLEncoding.EncodeToString(dBytes),
+		X:   base64.RawURLEncoding.EncodeToString(xBytes),
+	}
+}
@@ -0,0 +1,742 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/jwk"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Key derivation functions that can be used to derive the encryption key of a keystore from its passphrase
+const (
+	KDFArgon2id = "argon2id"
+	KDFScrypt   = "scrypt"
+)
+
+// keystoreVersion is the version of the keystore file format
+const keystoreVersion = 1
+
+// keystoreCheckAAD is the additional authenticated data of the check value of a keystore, which is used to
+// verify the passphrase of keystores that hold no keys
+const keystoreCheckAAD = "web5 keystore"
+
+// keystoreMetadataAADSuffix is appended to the alias of a key to form the additional authenticated data of its
+// metadata, so the metadata of a key cannot be swapped with the key itself
+const keystoreMetadataAADSuffix = "#metadata"
+
+// ErrIncorrectPassphrase is returned when the passphrase of a keystore is incorrect
+var ErrIncorrectPassphrase = errors.New("incorrect passphrase")
+
+// FileKeyManager is an implementation of KeyManager that stores keys in a keystore file. Private keys are
+// encrypted with AES-256-GCM, under a key derived from a passphrase with Argon2id or scrypt.
+//
+// The keystore file is replaced atomically on every change, and changes are serialized across processes with
+// an exclusive lock on a lock file next to it. Every operation reads the keystore file, so changes made by other
+// processes are picked up.
+type FileKeyManager struct {
+	path string
+	kdf  string
+
+	mu  sync.Mutex
+	kek []byte
+	// salt of the keystore kek was derived for, to detect the passphrase being changed by another process
+	salt []byte
+}
+
+// options that NewFileKeyManager can take
+type fileKeyManagerOpts struct {
+	kdf string
+}
+
+// FileKeyManagerOpt is a type that represents an option that can be passed to [NewFileKeyManager].
+type FileKeyManagerOpt func(opts *fileKeyManagerOpts)
+
+// KeystoreKDF is an option that can be passed to [NewFileKeyManager]. It sets the key derivation function
+// used when creating a keystore or changing its passphrase. Defaults to [KDFArgon2id].
+func KeystoreKDF(kdf string) FileKeyManagerOpt {
+	return func(opts *fileKeyManagerOpts) {
+		opts.kdf = kdf
+	}
+}
+
+// NewFileKeyManager opens the keystore file at the given path with the given passphrase, creating it if it
+// does not exist. [ErrIncorrectPassphrase] is returned if the keystore was created with a different passphrase.
+func NewFileKeyManager(path string, passphrase []byte, opts ...FileKeyManagerOpt) (*FileKeyManager, error) {
+	o := fileKeyManagerOpts{kdf: KDFArgon2id}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if _, err := newKeystoreKDF(o.kdf); err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase must not be empty")
+	}
+
+	k := &FileKeyManager{path: path, kdf: o.kdf}
+
+	err := k.update(func(ks *keystore) (bool, error) {
+		if ks == nil {
+			return false, nil
+		}
+
+		return false, k.unlock(ks, passphrase)
+	}, func() (*keystore, error) {
+		return k.create(passphrase)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// GeneratePrivateKey generates a new private key using the algorithm provided,
+// stores it in the keystore and returns the key id
+// Supported algorithms are the same as those of [LocalKeyManager.GeneratePrivateKey]
+func (k *FileKeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	return k.GeneratePrivateKeyWithOptions(algorithmID)
+}
+
+// GeneratePrivateKeyWithOptions generates a new private key like [FileKeyManager.GeneratePrivateKey], with
+// the metadata set by the given options
+func (k *FileKeyManager) GeneratePrivateKeyWithOptions(algorithmID string, opts ...KeyOpt) (string, error) {
+	key, err := generatePrivateKey(algorithmID)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	keyAlias, err := key.ComputeThumbprint()
+	if err != nil {
+		return "", fmt.Errorf("failed to compute key alias: %w", err)
+	}
+
+	return keyAlias, k.putKey(key, newKeyMetadata(keyAlias, key, opts...), false)
+}
+
+// GetPublicKey returns the public key for the given key id
+func (k *FileKeyManager) GetPublicKey(keyID string) (jwk.JWK, error) {
+	key, _, err := k.getPrivateJWK(keyID)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return getPublicKey(key), nil
+}
+
+// Sign signs the payload with the private key for the given key id
+func (k *FileKeyManager) Sign(keyID string, payload []byte) ([]byte, error) {
+	key, _, err := k.getPrivateJWK(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsa.Sign(payload, key)
+}
+
+// SignDigest signs the digest with the ECDSA private key for the given key id
+func (k *FileKeyManager) SignDigest(keyID string, digest []byte) ([]byte, error) {
+	key, _, err := k.getPrivateJWK(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return signDigest(digest, key)
+}
+
+// DeriveSharedSecret derives the raw ECDH shared secret between the private key for the given key id and
+// the given public key of the other party
+func (k *FileKeyManager) DeriveSharedSecret(keyID string, peerPublicKey jwk.JWK) ([]byte, error) {
+	key, _, err := k.getPrivateJWK(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdh.DeriveSharedSecret(key, peerPublicKey)
+}
+
+// ExportKey exports the key specific by the key ID from the [FileKeyManager]. Keys generated with
+// KeyExportable(false) cannot be exported.
+func (k *FileKeyManager) ExportKey(keyID string) (jwk.JWK, error) {
+	key, metadata, err := k.getPrivateJWK(keyID)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	if !metadata.Exportable {
+		return jwk.JWK{}, fmt.Errorf("%w: %s", ErrKeyNotExportable, keyID)
+	}
+
+	return key, nil
+}
+
+// ImportKey imports the private key into the [FileKeyManager] and returns the key alias
+func (k *FileKeyManager) ImportKey(key jwk.JWK) (string, error) {
+	if key.D == "" {
+		return "", errors.New("key must be a private key")
+	}
+
+	keyAlias, err := key.ComputeThumbprint()
+	if err != nil {
+		return "", fmt.Errorf("failed to compute key alias: %w", err)
+	}
+
+	return keyAlias, k.putKey(key, newKeyMetadata(keyAlias, key), true)
+}
+
+// ListKeys returns the metadata of all keys, ordered by creation time
+func (k *FileKeyManager) ListKeys() ([]KeyMetadata, error) {
+	ks, err := readKeystore(k.path)
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := k.currentKEK(ks)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]KeyMetadata, 0, len(ks.Keys))
+	for alias, entry := range ks.Keys {
+		_, metadata, err := entry.open(kek, alias)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, metadata)
+	}
+
+	sortKeyMetadata(keys)
+	return keys, nil
+}
+
+// DeleteKey removes the key for the given key id
+func (k *FileKeyManager) DeleteKey(keyID string) error {
+	return k.update(func(ks *keystore) (bool, error) {
+		if ks == nil {
+			return false, fmt.Errorf("keystore %s does not exist", k.path)
+		}
+
+		if _, err := k.currentKEK(ks); err != nil {
+			return false, err
+		}
+
+		if _, ok := ks.Keys[keyID]; !ok {
+			return false, fmt.Errorf("key with alias %s not found", keyID)
+		}
+
+		delete(ks.Keys, keyID)
+		return true, nil
+	}, nil)
+}
+
+// GetKeyMetadata returns the metadata of the key for the given key id
+func (k *FileKeyManager) GetKeyMetadata(keyID string) (KeyMetadata, error) {
+	_, metadata, err := k.getPrivateJWK(keyID)
+	return metadata, err
+}
+
+// SetKeyLabels replaces the labels of the key for the given key id
+func (k *FileKeyManager) SetKeyLabels(keyID string, labels map[string]string) error {
+	return k.update(func(ks *keystore) (bool, error) {
+		if ks == nil {
+			return false, fmt.Errorf("keystore %s does not exist", k.path)
+		}
+
+		kek, err := k.currentKEK(ks)
+		if err != nil {
+			return false, err
+		}
+
+		entry, ok := ks.Keys[keyID]
+		if !ok {
+			return false, fmt.Errorf("key with alias %s not found", keyID)
+		}
+
+		key, metadata, err := entry.open(kek, keyID)
+		if err != nil {
+			return false, err
+		}
+
+		metadata.Labels = copyLabels(labels)
+		if ks.Keys[keyID], err = sealKeystoreEntry(kek, key, metadata); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}, nil)
+}
+
+// ChangePassphrase re-encrypts all keys of the keystore under a key derived from the new passphrase, with a
+// new salt. Other processes need to open the keystore again with the new passphrase.
+func (k *FileKeyManager) ChangePassphrase(newPassphrase []byte) error {
+	if len(newPassphrase) == 0 {
+		return errors.New("passphrase must not be empty")
+	}
+
+	var updated *keystore
+	var newKEK []byte
+
+	err := k.update(func(ks *keystore) (bool, error) {
+		if ks == nil {
+			return false, fmt.Errorf("keystore %s does not exist", k.path)
+		}
+
+		kek, err := k.currentKEK(ks)
+		if err != nil {
+			return false, err
+		}
+
+		updated, newKEK, err = newKeystore(k.kdf, newPassphrase)
+		if err != nil {
+			return false, err
+		}
+
+		for alias, entry := range ks.Keys {
+			key, metadata, err := entry.open(kek, alias)
+			if err != nil {
+				return false, err
+			}
+
+			if updated.Keys[alias], err = sealKeystoreEntry(newKEK, key, metadata); err != nil {
+				return false, err
+			}
+		}
+
+		*ks = *updated
+		return true, nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	// the new key is only used once the keystore was written with it
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.kek = newKEK
+	k.salt = updated.KDF.Salt
+	return nil
+}
+
+// putKey stores the key in the keystore. If keepMetadata is set and the key is already stored, its existing
+// metadata is kept.
+func (k *FileKeyManager) putKey(key jwk.JWK, metadata KeyMetadata, keepMetadata bool) error {
+	return k.update(func(ks *keystore) (bool, error) {
+		if ks == nil {
+			return false, fmt.Errorf("keystore %s does not exist", k.path)
+		}
+
+		kek, err := k.currentKEK(ks)
+		if err != nil {
+			return false, err
+		}
+
+		if existing, ok := ks.Keys[metadata.KeyID]; ok && keepMetadata {
+			if _, metadata, err = existing.open(kek, metadata.KeyID); err != nil {
+				return false, err
+			}
+		}
+
+		if ks.Keys[metadata.KeyID], err = sealKeystoreEntry(kek, key, metadata); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}, nil)
+}
+
+func (k *FileKeyManager) getPrivateJWK(keyID string) (jwk.JWK, KeyMetadata, error) {
+	ks, err := readKeystore(k.path)
+	if err != nil {
+		return jwk.JWK{}, KeyMetadata{}, err
+	}
+
+	kek, err := k.currentKEK(ks)
+	if err != nil {
+		return jwk.JWK{}, KeyMetadata{}, err
+	}
+
+	entry, ok := ks.Keys[keyID]
+	if !ok {
+		return jwk.JWK{}, KeyMetadata{}, fmt.Errorf("key with alias %s not found", keyID)
+	}
+
+	return entry.open(kek, keyID)
+}
+
+// currentKEK returns the key encryption key for the given keystore, which must not have been re-keyed by
+// another process since it was unlocked
+func (k *FileKeyManager) currentKEK(ks *keystore) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if subtle.ConstantTimeCompare(k.salt, ks.KDF.Salt) != 1 {
+		return nil, fmt.Errorf("%w: the passphrase of keystore %s was changed", ErrIncorrectPassphrase, k.path)
+	}
+
+	return k.kek, nil
+}
+
+// unlock derives the key encryption key of an existing keystore and verifies the passphrase
+func (k *FileKeyManager) unlock(ks *keystore, passphrase []byte) error {
+	kdf, err := newKeystoreKDF(ks.KDF.Name)
+	if err != nil {
+		return err
+	}
+
+	kek, err := kdf.deriveKey(passphrase, ks.KDF)
+	if err != nil {
+		return err
+	}
+
+	if _, err := openBox(kek, ks.Check, []byte(keystoreCheckAAD)); err != nil {
+		return ErrIncorrectPassphrase
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.kek = kek
+	k.salt = ks.KDF.Salt
+	return nil
+}
+
+// create returns a new empty keystore, unlocked with the given passphrase
+func (k *FileKeyManager) create(passphrase []byte) (*keystore, error) {
+	ks, kek, err := newKeystore(k.kdf, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.kek = kek
+	k.salt = ks.KDF.Salt
+	return ks, nil
+}
+
+// update reads the keystore while holding the lock of the keystore file, calls fn with it, and writes it back
+// if fn reports it was modified. If the keystore does not exist, fn is called with nil, and the keystore
+// returned by create is written if create is not nil.
+func (k *FileKeyManager) update(fn func(ks *keystore) (bool, error), create func() (*keystore, error)) error {
+	if err := os.MkdirAll(filepath.Dir(k.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+
+	unlock, err := lockFile(k.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock keystore: %w", err)
+	}
+	defer unlock()
+
+	ks, err := readKeystore(k.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		ks = nil
+	} else if err != nil {
+		return err
+	}
+
+	modified, err := fn(ks)
+	if err != nil {
+		return err
+	}
+
+	if ks == nil && create != nil {
+		if ks, err = create(); err != nil {
+			return err
+		}
+		modified = true
+	}
+
+	if !modified {
+		return nil
+	}
+
+	return writeKeystore(k.path, ks)
+}
+
+// keystore is the contents of a keystore file
+type keystore struct {
+	Version int                      `json:"version"`
+	KDF     keystoreKDFParams        `json:"kdf"`
+	Check   sealedBox                `json:"check"`
+	Keys    map[string]keystoreEntry `json:"keys"`
+}
+
+// keystoreEntry is a key stored in a keystore, sealed with the alias of the key as additional authenticated
+// data, along with its sealed metadata
+type keystoreEntry struct {
+	sealedBox
+	Metadata *sealedBox `json:"metadata"`
+}
+
+// sealKeystoreEntry encrypts the key and its metadata
+func sealKeystoreEntry(kek []byte, key jwk.JWK, metadata KeyMetadata) (keystoreEntry, error) {
+	plaintext, err := json.Marshal(key)
+	if err != nil {
+		return keystoreEntry{}, fmt.Errorf("failed to serialize key: %w", err)
+	}
+
+	sealed, err := sealBox(kek, plaintext, []byte(metadata.KeyID))
+	if err != nil {
+		return keystoreEntry{}, fmt.Errorf("failed to encrypt key %s: %w", metadata.KeyID, err)
+	}
+
+	plaintext, err = json.Marshal(metadata)
+	if err != nil {
+		return keystoreEntry{}, fmt.Errorf("failed to serialize key metadata: %w", err)
+	}
+
+	sealedMetadata, err := sealBox(kek, plaintext, []byte(metadata.KeyID+keystoreMetadataAADSuffix))
+	if err != nil {
+		return keystoreEntry{}, fmt.Errorf("failed to encrypt key metadata %s: %w", metadata.KeyID, err)
+	}
+
+	return keystoreEntry{sealedBox: sealed, Metadata: &sealedMetadata}, nil
+}
+
+// open decrypts the key and its metadata
+func (e keystoreEntry) open(kek []byte, alias string) (jwk.JWK, KeyMetadata, error) {
+	plaintext, err := openBox(kek, e.sealedBox, []byte(alias))
+	if err != nil {
+		return jwk.JWK{}, KeyMetadata{}, fmt.Errorf("failed to decrypt key with alias %s: %w", alias, err)
+	}
+
+	var key jwk.JWK
+	if err := json.Unmarshal(plaintext, &key); err != nil {
+		return jwk.JWK{}, KeyMetadata{}, fmt.Errorf("failed to deserialize key with alias %s: %w", alias, err)
+	}
+
+	// the metadata holds whether the key is exportable, so an entry without it must not fall back to defaults
+	if e.Metadata == nil {
+		return jwk.JWK{}, KeyMetadata{}, fmt.Errorf("key with alias %s has no metadata", alias)
+	}
+
+	plaintext, err = openBox(kek, *e.Metadata, []byte(alias+keystoreMetadataAADSuffix))
+	if err != nil {
+		return jwk.JWK{}, KeyMetadata{}, fmt.Errorf("failed to decrypt metadata of key with alias %s: %w", alias, err)
+	}
+
+	var metadata KeyMetadata
+	if err := json.Unmarshal(plaintext, &metadata); err != nil {
+		return jwk.JWK{}, KeyMetadata{}, fmt.Errorf("failed to deserialize metadata of key with alias %s: %w", alias, err)
+	}
+
+	return key, metadata, nil
+}
+
+// keystoreKDFParams are the parameters of the key derivation function of a keystore
+type keystoreKDFParams struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+
+	// Argon2id parameters
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+
+	// scrypt parameters
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+}
+
+// sealedBox is a value encrypted with AES-256-GCM
+type sealedBox struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// newKeystore returns a new empty keystore with a random salt, along with its key encryption key
+func newKeystore(kdfName string, passphrase []byte) (*keystore, []byte, error) {
+	kdf, err := newKeystoreKDF(kdfName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := kdf.defaultParams()
+	params.Salt = make([]byte, 16)
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	kek, err := kdf.deriveKey(passphrase, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	check, err := sealBox(kek, nil, []byte(keystoreCheckAAD))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ks := &keystore{
+		Version: keystoreVersion,
+		KDF:     params,
+		Check:   check,
+		Keys:    make(map[string]keystoreEntry),
+	}
+
+	return ks, kek, nil
+}
+
+func readKeystore(path string) (*keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	var ks keystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version: %d", ks.Version)
+	}
+
+	if ks.Keys == nil {
+		ks.Keys = make(map[string]keystoreEntry)
+	}
+
+	return &ks, nil
+}
+
+// writeKeystore atomically replaces the keystore file, by writing to a temporary file in the same directory
+// and renaming it
+func writeKeystore(path string, ks *keystore) error {
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize keystore: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// keystoreKDF derives the key encryption key of a keystore from its passphrase
+type keystoreKDF interface {
+	defaultParams() keystoreKDFParams
+	deriveKey(passphrase []byte, params keystoreKDFParams) ([]byte, error)
+}
+
+func newKeystoreKDF(name string) (keystoreKDF, error) {
+	switch name {
+	case KDFArgon2id:
+		return argon2idKDF{}, nil
+	case KDFScrypt:
+		return scryptKDF{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function: %s", name)
+	}
+}
+
+// argon2idKDF uses the second recommended option of https://datatracker.ietf.org/doc/html/rfc9106#section-4
+type argon2idKDF struct{}
+
+func (argon2idKDF) defaultParams() keystoreKDFParams {
+	return keystoreKDFParams{Name: KDFArgon2id, Time: 3, Memory: 64 * 1024, Threads: 4}
+}
+
+func (argon2idKDF) deriveKey(passphrase []byte, params keystoreKDFParams) ([]byte, error) {
+	// bound the parameters so a tampered keystore cannot exhaust memory
+	if params.Time == 0 || params.Time > 16 || params.Memory < 8*1024 || params.Memory > 4*1024*1024 || params.Threads == 0 {
+		return nil, errors.New("invalid argon2id parameters")
+	}
+
+	return argon2.IDKey(passphrase, params.Salt, params.Time, params.Memory, params.Threads, 32), nil
+}
+
+// scryptKDF uses the parameters recommended for interactive logins in https://pkg.go.dev/golang.org/x/crypto/scrypt
+type scryptKDF struct{}
+
+func (scryptKDF) defaultParams() keystoreKDFParams {
+	return keystoreKDFParams{Name: KDFScrypt, N: 1 << 15, R: 8, P: 1}
+}
+
+func (scryptKDF) deriveKey(passphrase []byte, params keystoreKDFParams) ([]byte, error) {
+	// bound the parameters so a tampered keystore cannot exhaust memory
+	if params.N < 1<<10 || params.N > 1<<22 || params.R == 0 || params.R > 32 || params.P == 0 || params.P > 16 {
+		return nil, errors.New("invalid scrypt parameters")
+	}
+
+	return scrypt.Key(passphrase, params.Salt, params.N, params.R, params.P, 32)
+}
+
+// sealBox encrypts the plaintext with AES-256-GCM under a random nonce
+func sealBox(key, plaintext, aad []byte) (sealedBox, error) {
+	gcm, err := newKeystoreGCM(key)
+	if err != nil {
+		return sealedBox{}, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return sealedBox{}, err
+	}
+
+	return sealedBox{Nonce: nonce, Ciphertext: gcm.Seal(nil, nonce, plaintext, aad)}, nil
+}
+
+// openBox decrypts a value encrypted with [sealBox]
+func openBox(key []byte, box sealedBox, aad []byte) ([]byte, error) {
+	gcm, err := newKeystoreGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(box.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+
+	return gcm.Open(nil, box.Nonce, box.Ciphertext, aad)
+}
+
+func newKeystoreGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,212 @@
+package crypto_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+)
+
+func TestFileKeyManager(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "keystore.json")
+	passphrase := []byte("correct horse battery staple")
+
+	keyManager, err := crypto.NewFileKeyManager(path, passphrase)
+	assert.NoError(t, err)
+
+	keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
+	publicKey, err := keyManager.GetPublicKey(keyID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", publicKey.D)
+
+	thumbprint, err := publicKey.ComputeThumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, keyID, thumbprint)
+
+	payload := []byte("hello world")
+	signature, err := keyManager.Sign(keyID, payload)
+	assert.NoError(t, err)
+
+	legit, err := dsa.Verify(payload, signature, publicKey)
+	assert.NoError(t, err)
+	assert.True(t, legit)
+
+	// private keys are not stored in the clear
+	exported, err := keyManager.ExportKey(keyID)
+	assert.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(contents), exported.D))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// keys persist across key managers
+	reopened, err := crypto.NewFileKeyManager(path, passphrase)
+	assert.NoError(t, err)
+
+	reopenedPublicKey, err := reopened.GetPublicKey(keyID)
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, reopenedPublicKey)
+
+	_, err = crypto.NewFileKeyManager(path, []byte("wrong"))
+	assert.IsError(t, err, crypto.ErrIncorrectPassphrase)
+
+	_, err = keyManager.GetPublicKey("unknown")
+	assert.Error(t, err)
+}
+
+func TestFileKeyManager_Scrypt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	passphrase := []byte("passphrase")
+
+	keyManager, err := crypto.NewFileKeyManager(path, passphrase, crypto.KeystoreKDF(crypto.KDFScrypt))
+	assert.NoError(t, err)
+
+	keyID, err := keyManager.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	assert.NoError(t, err)
+
+	// the kdf of an existing keystore is read from the keystore
+	reopened, err := crypto.NewFileKeyManager(path, passphrase)
+	assert.NoError(t, err)
+
+	_, err = reopened.GetPublicKey(keyID)
+	assert.NoError(t, err)
+
+	_, err = crypto.NewFileKeyManager(path, passphrase, crypto.KeystoreKDF("pbkdf1"))
+	assert.Error(t, err)
+}
+
+func TestFileKeyManager_ImportKey(t *testing.T) {
+	keyManager, err := crypto.NewFileKeyManager(filepath.Join(t.TempDir(), "keystore.json"), []byte("passphrase"))
+	assert.NoError(t, err)
+
+	privateKey, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256K1)
+	assert.NoError(t, err)
+
+	keyID, err := keyManager.ImportKey(privateKey)
+	assert.NoError(t, err)
+
+	exported, err := keyManager.ExportKey(keyID)
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey, exported)
+
+	_, err = keyManager.ImportKey(dsa.GetPublicKey(privateKey))
+	assert.Error(t, err)
+}
+
+func TestFileKeyManager_ChangePassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+
+	keyManager, err := crypto.NewFileKeyManager(path, []byte("old"))
+	assert.NoError(t, err)
+
+	other, err := crypto.NewFileKeyManager(path, []byte("old"))
+	assert.NoError(t, err)
+
+	keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	assert.NoError(t, err)
+
+	privateKey, err := keyManager.ExportKey(keyID)
+	assert.NoError(t, err)
+
+	err = keyManager.ChangePassphrase([]byte("new"))
+	assert.NoError(t, err)
+
+	exported, err := keyManager.ExportKey(keyID)
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey, exported)
+
+	_, err = crypto.NewFileKeyManager(path, []byte("old"))
+	assert.IsError(t, err, crypto.ErrIncorrectPassphrase)
+
+	reopened, err := crypto.NewFileKeyManager(path, []byte("new"))
+	assert.NoError(t, err)
+
+	exported, err = reopened.ExportKey(keyID)
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey, exported)
+
+	// key managers opened with the old passphrase can no longer be used
+	_, err = other.ExportKey(keyID)
+	assert.IsError(t, err, crypto.ErrIncorrectPassphrase)
+}
+
+func TestFileKeyManager_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	passphrase := []byte("passphrase")
+
+	keyManager, err := crypto.NewFileKeyManager(path, passphrase, crypto.KeystoreKDF(crypto.KDFScrypt))
+	assert.NoError(t, err)
+
+	// separate key managers lock the keystore file like separate processes would
+	other, err := crypto.NewFileKeyManager(path, passphrase)
+	assert.NoError(t, err)
+
+	keyIDs := make([]string, 20)
+	var wg sync.WaitGroup
+	for i := range keyIDs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			km := keyManager
+			if i%2 == 0 {
+				km = other
+			}
+
+			keyID, err := km.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+			assert.NoError(t, err)
+			keyIDs[i] = keyID
+		}(i)
+	}
+	wg.Wait()
+
+	for _, keyID := range keyIDs {
+		_, err := keyManager.GetPublicKey(keyID)
+		assert.NoError(t, err)
+	}
+}
+
+func TestFileKeyManager_MissingMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	passphrase := []byte("correct horse battery staple")
+
+	keyManager, err := crypto.NewFileKeyManager(path, passphrase)
+	assert.NoError(t, err)
+
+	keyID, err := keyManager.GeneratePrivateKeyWithOptions(dsa.AlgorithmIDED25519, crypto.KeyExportable(false))
+	assert.NoError(t, err)
+
+	// removing the sealed metadata must not make the key exportable
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var keystore map[string]any
+	assert.NoError(t, json.Unmarshal(contents, &keystore))
+	delete(keystore["keys"].(map[string]any)[keyID].(map[string]any), "metadata") //nolint:forcetypeassert
+
+	contents, err = json.Marshal(keystore)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, contents, 0o600))
+
+	reopened, err := crypto.NewFileKeyManager(path, passphrase)
+	assert.NoError(t, err)
+
+	_, err = reopened.ExportKey(keyID)
+	assert.Error(t, err)
+
+	_, err = reopened.GetPublicKey(keyID)
+	assert.Error(t, err)
+}
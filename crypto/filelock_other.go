@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package crypto
+
+// lockFile is a no-op on platforms without file locking, where a keystore must not be shared between processes
+func lockFile(string) (func(), error) {
+	return func() {}, nil
+}
+
+// syncDir is a no-op on platforms without file locking
+func syncDir(string) error {
+	return nil
+}
@@ -0,0 +1,266 @@
+package hd
+
+import (
+	_ecdh "crypto/ecdh"
+	_ed25519 "crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/eddsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+	_secp256k1 "github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Hardened is added to a child index to derive a hardened child
+const Hardened uint32 = 0x80000000
+
+// Path is a derivation path, as a list of child indexes from the master key
+type Path []uint32
+
+// ParsePath parses a derivation path like m/44'/0'/0 where ' or h marks a hardened index
+func ParsePath(path string) (Path, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %q: must start with m", path)
+	}
+
+	parsed := make(Path, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		var offset uint32
+		if trimmed := strings.TrimRight(segment, "'hH"); trimmed != segment {
+			if len(segment)-len(trimmed) != 1 {
+				return nil, fmt.Errorf("invalid derivation path %q: invalid index %q", path, segment)
+			}
+
+			segment, offset = trimmed, Hardened
+		}
+
+		index, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil || uint32(index) >= Hardened {
+			return nil, fmt.Errorf("invalid derivation path %q: invalid index %q", path, segment)
+		}
+
+		parsed = append(parsed, uint32(index)+offset)
+	}
+
+	return parsed, nil
+}
+
+// String formats the path like m/44'/0'/0
+func (p Path) String() string {
+	var path strings.Builder
+	path.WriteString("m")
+	for _, index := range p {
+		if index >= Hardened {
+			path.WriteString("/" + strconv.FormatUint(uint64(index-Hardened), 10) + "'")
+		} else {
+			path.WriteString("/" + strconv.FormatUint(uint64(index), 10))
+		}
+	}
+
+	return path.String()
+}
+
+// curve is the derivation scheme of a signature algorithm
+type curve struct {
+	// seedKey is the HMAC key of the master key derivation
+	seedKey string
+	// order is the order of the curve, nil for ed25519 which only supports hardened derivation
+	order *big.Int
+	// retry is true if invalid children are derived again from the HMAC output as in SLIP-0010, rather
+	// than being rejected as in BIP-32
+	retry bool
+	// publicKey returns the compressed public key of a private key
+	publicKey func(privateKey []byte) ([]byte, error)
+	// privateJWK returns the private key as a JWK
+	privateJWK func(privateKey []byte) (jwk.JWK, error)
+}
+
+var curves = map[string]curve{
+	// https://github.com/satoshilabs/slips/blob/master/slip-0010.md
+	dsa.AlgorithmIDED25519: {
+		seedKey:    "ed25519 seed",
+		privateJWK: ed25519PrivateJWK,
+	},
+	// https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+	dsa.AlgorithmIDSECP256K1: {
+		seedKey:    "Bitcoin seed",
+		order:      _secp256k1.S256().N,
+		publicKey:  secp256k1PublicKey,
+		privateJWK: secp256k1PrivateJWK,
+	},
+	// https://github.com/satoshilabs/slips/blob/master/slip-0010.md
+	dsa.AlgorithmIDSECP256R1: {
+		seedKey:    "Nist256p1 seed",
+		order:      elliptic.P256().Params().N,
+		retry:      true,
+		publicKey:  secp256r1PublicKey,
+		privateJWK: secp256r1PrivateJWK,
+	},
+}
+
+// SupportsAlgorithmID returns true if keys of the given algorithm can be derived
+func SupportsAlgorithmID(algorithmID string) bool {
+	_, ok := curves[algorithmID]
+	return ok
+}
+
+// DeriveKey derives the private key at the given path from a BIP-39 seed. Ed25519 keys are derived with
+// SLIP-0010 and only support hardened indexes, secp256k1 keys with BIP-32 and secp256r1 keys with SLIP-0010.
+func DeriveKey(seed []byte, algorithmID string, path Path) (jwk.JWK, error) {
+	c, ok := curves[algorithmID]
+	if !ok {
+		return jwk.JWK{}, fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	}
+
+	key, chainCode, err := c.master(seed)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	for _, index := range path {
+		key, chainCode, err = c.child(key, chainCode, index)
+		if err != nil {
+			return jwk.JWK{}, fmt.Errorf("failed to derive %s: %w", path, err)
+		}
+	}
+
+	return c.privateJWK(key)
+}
+
+// master derives the master private key and chain code from the seed
+func (c curve) master(seed []byte) ([]byte, []byte, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, nil, fmt.Errorf("seed must be 16 to 64 bytes, got %d", len(seed))
+	}
+
+	data := seed
+	for {
+		key, chainCode := hmacSHA512([]byte(c.seedKey), data)
+		if c.order == nil || c.isValid(key) {
+			return key, chainCode, nil
+		}
+
+		// SLIP-0010 retries with the HMAC output, this happens with negligible probability
+		data = append(append([]byte{}, key...), chainCode...)
+	}
+}
+
+// child derives the child private key and chain code at the given index
+func (c curve) child(key []byte, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	data := make([]byte, 0, 37)
+	if index >= Hardened {
+		data = append(data, 0)
+		data = append(data, key...)
+	} else {
+		if c.order == nil {
+			return nil, nil, errors.New("only hardened indexes are supported")
+		}
+
+		publicKey, err := c.publicKey(key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		data = append(data, publicKey...)
+	}
+
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	for {
+		childKey, childChainCode := hmacSHA512(chainCode, data)
+		if c.order == nil {
+			return childKey, childChainCode, nil
+		}
+
+		if c.isValid(childKey) {
+			k := new(big.Int).SetBytes(childKey)
+			k.Add(k, new(big.Int).SetBytes(key))
+			k.Mod(k, c.order)
+			if k.Sign() != 0 {
+				return k.FillBytes(make([]byte, 32)), childChainCode, nil
+			}
+		}
+
+		// BIP-32 skips to the next index while SLIP-0010 retries with the HMAC output,
+		// both happen with negligible probability
+		if !c.retry {
+			return nil, nil, fmt.Errorf("invalid child at index %d", index)
+		}
+
+		data = append([]byte{1}, childChainCode...)
+		data = binary.BigEndian.AppendUint32(data, index)
+	}
+}
+
+// isValid returns true if the key is a valid private key of the curve
+func (c curve) isValid(key []byte) bool {
+	k := new(big.Int).SetBytes(key)
+	return k.Sign() != 0 && k.Cmp(c.order) < 0
+}
+
+func hmacSHA512(key []byte, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+func ed25519PrivateJWK(privateKey []byte) (jwk.JWK, error) {
+	key := _ed25519.NewKeyFromSeed(privateKey)
+	return jwk.JWK{
+		KTY: eddsa.KeyType,
+		CRV: eddsa.ED25519JWACurve,
+		D:   base64.RawURLEncoding.EncodeToString(key),
+		X:   base64.RawURLEncoding.EncodeToString(key.Public().(_ed25519.PublicKey)),
+	}, nil
+}
+
+func secp256k1PublicKey(privateKey []byte) ([]byte, error) {
+	return _secp256k1.PrivKeyFromBytes(privateKey).PubKey().SerializeCompressed(), nil
+}
+
+func secp256k1PrivateJWK(privateKey []byte) (jwk.JWK, error) {
+	publicKey := _secp256k1.PrivKeyFromBytes(privateKey).PubKey().SerializeUncompressed()
+	return withPrivateKey(dsa.AlgorithmIDSECP256K1, publicKey, privateKey)
+}
+
+func secp256r1PublicKey(privateKey []byte) ([]byte, error) {
+	key, err := _ecdh.P256().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	uncompressed := key.PublicKey().Bytes()
+	x, y := new(big.Int).SetBytes(uncompressed[1:33]), new(big.Int).SetBytes(uncompressed[33:])
+	return elliptic.MarshalCompressed(elliptic.P256(), x, y), nil
+}
+
+func secp256r1PrivateJWK(privateKey []byte) (jwk.JWK, error) {
+	key, err := _ecdh.P256().NewPrivateKey(privateKey)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return withPrivateKey(dsa.AlgorithmIDSECP256R1, key.PublicKey().Bytes(), privateKey)
+}
+
+// withPrivateKey returns the JWK of the uncompressed public key with d set to the private key
+func withPrivateKey(algorithmID string, publicKey []byte, privateKey []byte) (jwk.JWK, error) {
+	key, err := dsa.BytesToPublicKey(algorithmID, publicKey)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	key.D = base64.RawURLEncoding.EncodeToString(privateKey)
+	return key, nil
+}
@@ -0,0 +1,102 @@
+package hd_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/hd"
+)
+
+type derivationVector struct {
+	path       string
+	privateKey string
+}
+
+// seed of test vector 1 of BIP-32 and SLIP-0010
+const testSeed = "000102030405060708090a0b0c0d0e0f"
+
+func TestDeriveKey_Vectors(t *testing.T) {
+	vectors := map[string][]derivationVector{
+		// https://github.com/satoshilabs/slips/blob/master/slip-0010.md#test-vector-1-for-ed25519
+		dsa.AlgorithmIDED25519: {
+			{"m", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7"},
+			{"m/0'", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"},
+			{"m/0'/1'", "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2"},
+			{"m/0'/1'/2'", "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9"},
+			{"m/0'/1'/2'/2'", "30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662"},
+			{"m/0'/1'/2'/2'/1000000000'", "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793"},
+		},
+		// https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#test-vector-1
+		dsa.AlgorithmIDSECP256K1: {
+			{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+			{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+			{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+			{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+			{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+			{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+		},
+		// https://github.com/satoshilabs/slips/blob/master/slip-0010.md#test-vector-1-for-nist256p1
+		dsa.AlgorithmIDSECP256R1: {
+			{"m", "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2"},
+			{"m/0'", "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c"},
+		},
+	}
+
+	seed, err := hex.DecodeString(testSeed)
+	assert.NoError(t, err)
+
+	for algorithmID, derivations := range vectors {
+		for _, v := range derivations {
+			t.Run(algorithmID+" "+v.path, func(t *testing.T) {
+				path, err := hd.ParsePath(v.path)
+				assert.NoError(t, err)
+				assert.Equal(t, v.path, path.String())
+
+				key, err := hd.DeriveKey(seed, algorithmID, path)
+				assert.NoError(t, err)
+
+				d, err := base64.RawURLEncoding.DecodeString(key.D)
+				assert.NoError(t, err)
+
+				// ed25519 keys hold the seed followed by the public key
+				assert.Equal(t, v.privateKey, hex.EncodeToString(d[:32]))
+
+				signature, err := dsa.Sign([]byte("payload"), key)
+				assert.NoError(t, err)
+
+				verified, err := dsa.Verify([]byte("payload"), signature, dsa.GetPublicKey(key))
+				assert.NoError(t, err)
+				assert.True(t, verified)
+			})
+		}
+	}
+}
+
+func TestDeriveKey_Errors(t *testing.T) {
+	seed, err := hex.DecodeString(testSeed)
+	assert.NoError(t, err)
+
+	_, err = hd.DeriveKey(seed, dsa.AlgorithmIDED25519, hd.Path{0})
+	assert.Error(t, err)
+
+	_, err = hd.DeriveKey(seed, dsa.AlgorithmIDSECP384R1, hd.Path{})
+	assert.Error(t, err)
+
+	_, err = hd.DeriveKey(seed[:8], dsa.AlgorithmIDSECP256K1, hd.Path{})
+	assert.Error(t, err)
+}
+
+func TestParsePath(t *testing.T) {
+	path, err := hd.ParsePath("m/44h/0H/7")
+	assert.NoError(t, err)
+	assert.Equal(t, hd.Path{44 + hd.Hardened, hd.Hardened, 7}, path)
+	assert.Equal(t, "m/44'/0'/7", path.String())
+
+	for _, invalid := range []string{"", "44'/0'", "m/", "m/x", "m/1''", "m/2147483648"} {
+		_, err := hd.ParsePath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
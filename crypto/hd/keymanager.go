@@ -0,0 +1,156 @@
+package hd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// Purpose is the first index of the derivation paths of [KeyManager], "WEB5" in ASCII. It keeps web5 keys
+// apart from the keys that wallets derive from the same mnemonic.
+const Purpose uint32 = 0x57454235
+
+// algorithmIndexes are the third index of the derivation paths of [KeyManager], matching the did:dht key
+// type indexes.
+// https://did-dht.com/registry/#key-type-index
+var algorithmIndexes = map[string]uint32{
+	dsa.AlgorithmIDED25519:   0,
+	dsa.AlgorithmIDSECP256K1: 1,
+	dsa.AlgorithmIDSECP256R1: 2,
+}
+
+// KeyManager is a [github.com/decentralized-identity/web5-go/crypto.KeyManager] that derives its keys from a
+// BIP-39 mnemonic, so that the same DIDs can be recreated from the mnemonic alone. It is safe for
+// concurrent use.
+//
+// The nth key generated for an algorithm is derived at
+//
+//	m/1464156725'/<account>'/<algorithm>'/<n>'
+//
+// where algorithm is 0 for Ed25519, 1 for secp256k1 and 2 for secp256r1. Creating a DID with a new
+// KeyManager therefore always derives the same keys, and so recreates the same DID. Use a different
+// [Account] for each DID to derive from the same mnemonic.
+type KeyManager struct {
+	seed    []byte
+	account uint32
+	keys    *crypto.LocalKeyManager
+
+	mu      sync.Mutex
+	indexes map[string]uint32
+	paths   map[string]Path
+}
+
+// options that NewKeyManager can take
+type keyManagerOpts struct {
+	account uint32
+}
+
+// KeyManagerOpt is a type that represents an option that can be passed to [NewKeyManager].
+type KeyManagerOpt func(opts *keyManagerOpts)
+
+// Account is an option that can be passed to [NewKeyManager]. It sets the account index of the derivation
+// paths, which defaults to 0.
+func Account(account uint32) KeyManagerOpt {
+	return func(opts *keyManagerOpts) {
+		opts.account = account
+	}
+}
+
+// NewKeyManager returns a KeyManager that derives keys from the given BIP-39 mnemonic and optional
+// passphrase. See [MnemonicToSeed] for the passphrase.
+func NewKeyManager(mnemonic string, passphrase string, opts ...KeyManagerOpt) (*KeyManager, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+
+	o := keyManagerOpts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.account >= Hardened {
+		return nil, fmt.Errorf("account must be less than %d, got %d", Hardened, o.account)
+	}
+
+	return &KeyManager{
+		seed:    MnemonicToSeed(mnemonic, passphrase),
+		account: o.account,
+		keys:    crypto.NewLocalKeyManager(),
+		indexes: make(map[string]uint32),
+		paths:   make(map[string]Path),
+	}, nil
+}
+
+// GeneratePrivateKey derives the next key of the given algorithm and returns its key id
+func (k *KeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	algorithmIndex, ok := algorithmIndexes[algorithmID]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	index := k.indexes[algorithmID]
+	if index >= Hardened {
+		return "", fmt.Errorf("all keys of algorithm %s have been derived", algorithmID)
+	}
+
+	path := Path{Purpose + Hardened, k.account + Hardened, algorithmIndex + Hardened, index + Hardened}
+	key, err := DeriveKey(k.seed, algorithmID, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive private key: %w", err)
+	}
+
+	keyID, err := k.keys.ImportKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	k.indexes[algorithmID] = index + 1
+	k.paths[keyID] = path
+
+	return keyID, nil
+}
+
+// GetPublicKey returns the public key for the given key id
+func (k *KeyManager) GetPublicKey(keyID string) (jwk.JWK, error) {
+	return k.keys.GetPublicKey(keyID)
+}
+
+// Sign signs the payload with the private key for the given key id
+func (k *KeyManager) Sign(keyID string, payload []byte) ([]byte, error) {
+	return k.keys.Sign(keyID, payload)
+}
+
+// SignDigest signs the digest with the ECDSA private key for the given key id
+func (k *KeyManager) SignDigest(keyID string, digest []byte) ([]byte, error) {
+	return k.keys.SignDigest(keyID, digest)
+}
+
+// DeriveSharedSecret derives the raw ECDH shared secret between the private key for the given key id and
+// the given public key of the other party
+func (k *KeyManager) DeriveSharedSecret(keyID string, peerPublicKey jwk.JWK) ([]byte, error) {
+	return k.keys.DeriveSharedSecret(keyID, peerPublicKey)
+}
+
+// ExportKey exports the private key for the given key id
+func (k *KeyManager) ExportKey(keyID string) (jwk.JWK, error) {
+	return k.keys.ExportKey(keyID)
+}
+
+// KeyPath returns the derivation path of the key for the given key id
+func (k *KeyManager) KeyPath(keyID string) (Path, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	path, ok := k.paths[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key with alias %s not found", keyID)
+	}
+
+	return append(Path{}, path...), nil
+}
@@ -0,0 +1,103 @@
+package hd_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/hd"
+	"github.com/decentralized-identity/web5-go/dids/did"
+	"github.com/decentralized-identity/web5-go/dids/diddht"
+	"github.com/decentralized-identity/web5-go/dids/didjwk"
+	"github.com/decentralized-identity/web5-go/dids/didweb"
+	"github.com/decentralized-identity/web5-go/jws"
+)
+
+const testMnemonic = "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+func TestKeyManager(t *testing.T) {
+	km, err := hd.NewKeyManager(testMnemonic, "")
+	assert.NoError(t, err)
+
+	for i, algorithmID := range []string{dsa.AlgorithmIDED25519, dsa.AlgorithmIDSECP256K1, dsa.AlgorithmIDSECP256R1} {
+		t.Run(algorithmID, func(t *testing.T) {
+			first, err := km.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+
+			second, err := km.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+			assert.NotEqual(t, first, second)
+
+			path, err := km.KeyPath(second)
+			assert.NoError(t, err)
+			assert.Equal(t, hd.Path{hd.Purpose + hd.Hardened, hd.Hardened, uint32(i) + hd.Hardened, 1 + hd.Hardened}, path)
+
+			seed := hd.MnemonicToSeed(testMnemonic, "")
+			derived, err := hd.DeriveKey(seed, algorithmID, path)
+			assert.NoError(t, err)
+
+			publicKey, err := km.GetPublicKey(second)
+			assert.NoError(t, err)
+			assert.Equal(t, dsa.GetPublicKey(derived), publicKey)
+
+			signature, err := km.Sign(second, []byte("payload"))
+			assert.NoError(t, err)
+
+			verified, err := dsa.Verify([]byte("payload"), signature, publicKey)
+			assert.NoError(t, err)
+			assert.True(t, verified)
+		})
+	}
+
+	_, err = km.GeneratePrivateKey(dsa.AlgorithmIDSECP384R1)
+	assert.Error(t, err)
+
+	_, err = km.KeyPath("unknown")
+	assert.Error(t, err)
+}
+
+func TestKeyManager_InvalidMnemonic(t *testing.T) {
+	_, err := hd.NewKeyManager("legal winner thank year wave sausage worth useful legal winner thank thank", "")
+	assert.IsError(t, err, hd.ErrInvalidMnemonic)
+}
+
+func TestKeyManager_RecreatesDIDs(t *testing.T) {
+	create := func(t *testing.T, opts ...hd.KeyManagerOpt) []did.BearerDID {
+		t.Helper()
+
+		km, err := hd.NewKeyManager(testMnemonic, "passphrase", opts...)
+		assert.NoError(t, err)
+
+		jwkDID, err := didjwk.Create(didjwk.KeyManager(km))
+		assert.NoError(t, err)
+
+		webDID, err := didweb.Create("example.com", didweb.KeyManager(km))
+		assert.NoError(t, err)
+
+		dhtDID, _, err := diddht.CreateUnpublished(
+			diddht.KeyManager(km),
+			diddht.PrivateKey(dsa.AlgorithmIDSECP256K1),
+		)
+		assert.NoError(t, err)
+
+		return []did.BearerDID{jwkDID, webDID, dhtDID}
+	}
+
+	original := create(t)
+	recreated := create(t)
+	otherAccount := create(t, hd.Account(1))
+
+	for i := range original {
+		assert.Equal(t, original[i].URI, recreated[i].URI)
+		assert.Equal(t, original[i].Document, recreated[i].Document)
+		assert.NotEqual(t, original[i].Document, otherAccount[i].Document)
+	}
+
+	// the recreated DIDs can sign with the same keys
+	compactJWS, err := jws.Sign([]byte("payload"), recreated[0])
+	assert.NoError(t, err)
+
+	decoded, err := jws.Verify(compactJWS)
+	assert.NoError(t, err)
+	assert.Equal(t, original[0].URI, decoded.SignerDID.URI)
+}
@@ -0,0 +1,131 @@
+// Package hd derives keys from BIP-39 mnemonics with SLIP-0010 and BIP-32, and provides a KeyManager that
+// uses them so that DIDs can be recreated from a mnemonic.
+// https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
+package hd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// english is the BIP-39 English wordlist.
+// https://github.com/bitcoin/bips/blob/master/bip-0039/english.txt
+//
+//go:embed english.txt
+var english string
+
+var (
+	wordlist    = strings.Fields(english)
+	wordIndexes = func() map[string]int {
+		indexes := make(map[string]int, len(wordlist))
+		for i, word := range wordlist {
+			indexes[word] = i
+		}
+
+		return indexes
+	}()
+)
+
+// ErrInvalidMnemonic is returned for mnemonics with unknown words, a wrong number of words or a wrong checksum
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// NewMnemonic generates a BIP-39 mnemonic with the given number of bits of entropy: 128 (12 words), 160,
+// 192, 224 or 256 (24 words).
+// https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
+func NewMnemonic(entropyBits int) (string, error) {
+	if entropyBits < 128 || entropyBits > 256 || entropyBits%32 != 0 {
+		return "", fmt.Errorf("entropy must be 128 to 256 bits in steps of 32, got %d", entropyBits)
+	}
+
+	entropy := make([]byte, entropyBits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", fmt.Errorf("failed to generate entropy: %w", err)
+	}
+
+	return EntropyToMnemonic(entropy)
+}
+
+// EntropyToMnemonic encodes entropy of 16 to 32 bytes, in steps of 4, as a BIP-39 mnemonic
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	entropyBits := len(entropy) * 8
+	if entropyBits < 128 || entropyBits > 256 || entropyBits%32 != 0 {
+		return "", fmt.Errorf("entropy must be 128 to 256 bits in steps of 32, got %d", entropyBits)
+	}
+
+	// the checksum is the first entropyBits/32 bits of the SHA-256 of the entropy
+	checksumBits := entropyBits / 32
+	checksum := sha256.Sum256(entropy)
+
+	bits := new(big.Int).SetBytes(entropy)
+	bits.Lsh(bits, uint(checksumBits))
+	bits.Or(bits, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+
+	// each word encodes 11 bits
+	words := make([]string, (entropyBits+checksumBits)/11)
+	mask := big.NewInt(2047)
+	for i := len(words) - 1; i >= 0; i-- {
+		index := new(big.Int).And(bits, mask)
+		words[i] = wordlist[index.Int64()]
+		bits.Rsh(bits, 11)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes a BIP-39 mnemonic, verifying its checksum
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("%w: expected 12, 15, 18, 21 or 24 words, got %d", ErrInvalidMnemonic, len(words))
+	}
+
+	bits := new(big.Int)
+	for _, word := range words {
+		index, ok := wordIndexes[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %q", ErrInvalidMnemonic, word)
+		}
+
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(index)))
+	}
+
+	checksumBits := len(words) * 11 / 33
+	entropyBits := len(words)*11 - checksumBits
+
+	checksum := new(big.Int).And(bits, big.NewInt(int64(1<<checksumBits-1)))
+	bits.Rsh(bits, uint(checksumBits))
+
+	entropy := make([]byte, entropyBits/8)
+	bits.FillBytes(entropy)
+
+	expected := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(expected[0]>>(8-checksumBits)) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+
+	return entropy, nil
+}
+
+// ValidateMnemonic returns an error wrapping [ErrInvalidMnemonic] if the mnemonic is not a valid BIP-39
+// English mnemonic
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicToSeed returns the 64 byte BIP-39 seed of the mnemonic and an optional passphrase. The passphrase
+// must be NFKD normalized, which ASCII passphrases are. The mnemonic is not validated, see
+// [ValidateMnemonic].
+func MnemonicToSeed(mnemonic string, passphrase string) []byte {
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
@@ -0,0 +1,83 @@
+package hd_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/hd"
+)
+
+// https://github.com/trezor/python-mnemonic/blob/master/vectors.json
+func TestMnemonic_Vectors(t *testing.T) {
+	vectors := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			entropy:  "00000000000000000000000000000000",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			seed:     "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			entropy:  "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			mnemonic: "legal winner thank year wave sausage worth useful legal winner thank yellow",
+			seed:     "2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			entropy:  "ffffffffffffffffffffffffffffffff",
+			mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+			seed:     "ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+		},
+		{
+			entropy:  "0000000000000000000000000000000000000000000000000000000000000000",
+			mnemonic: strings.Repeat("abandon ", 23) + "art",
+			seed:     "bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+		},
+	}
+
+	for _, v := range vectors {
+		t.Run(v.mnemonic, func(t *testing.T) {
+			entropy, err := hex.DecodeString(v.entropy)
+			assert.NoError(t, err)
+
+			mnemonic, err := hd.EntropyToMnemonic(entropy)
+			assert.NoError(t, err)
+			assert.Equal(t, v.mnemonic, mnemonic)
+
+			decoded, err := hd.MnemonicToEntropy(mnemonic)
+			assert.NoError(t, err)
+			assert.Equal(t, entropy, decoded)
+
+			assert.Equal(t, v.seed, hex.EncodeToString(hd.MnemonicToSeed(mnemonic, "TREZOR")))
+		})
+	}
+}
+
+func TestNewMnemonic(t *testing.T) {
+	for bits, words := range map[int]int{128: 12, 160: 15, 192: 18, 224: 21, 256: 24} {
+		mnemonic, err := hd.NewMnemonic(bits)
+		assert.NoError(t, err)
+		assert.Equal(t, words, len(strings.Fields(mnemonic)))
+		assert.NoError(t, hd.ValidateMnemonic(mnemonic))
+	}
+
+	_, err := hd.NewMnemonic(100)
+	assert.Error(t, err)
+}
+
+func TestValidateMnemonic_Invalid(t *testing.T) {
+	mnemonics := map[string]string{
+		"bad checksum": "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"unknown word": "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon web5",
+		"wrong length": "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+	}
+
+	for name, mnemonic := range mnemonics {
+		t.Run(name, func(t *testing.T) {
+			assert.IsError(t, hd.ValidateMnemonic(mnemonic), hd.ErrInvalidMnemonic)
+		})
+	}
+}
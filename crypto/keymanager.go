@@ -1,9 +1,14 @@
 package crypto
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/schnorr"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
 	"github.com/decentralized-identity/web5-go/jwk"
 )
 
@@ -30,25 +35,56 @@ type KeyImporter interface {
 	ImportKey(key jwk.JWK) (string, error)
 }
 
-// LocalKeyManager is an implementation of KeyManager that stores keys in memory
+// KeyAgreer is an abstraction that can be leveraged to implement types which can perform key agreement
+// with the keys they manage, without exporting them
+type KeyAgreer interface {
+	// DeriveSharedSecret derives the raw ECDH shared secret between the private key for the given key id
+	// and the given public key of the other party
+	DeriveSharedSecret(keyID string, peerPublicKey jwk.JWK) ([]byte, error)
+}
+
+// DigestSigner is an abstraction that can be leveraged to implement types which can sign a digest computed
+// by the caller with their ECDSA keys, rather than the payload itself. It allows their keys to be used
+// through [Signer], as APIs like TLS and x509 hash what they sign themselves.
+type DigestSigner interface {
+	// SignDigest signs the given digest with the ECDSA private key for the given key id. The signature is
+	// always the plain JOSE r||s encoding, also for ES256K-R keys, whose Sign appends the recovery id.
+	SignDigest(keyID string, digest []byte) ([]byte, error)
+}
+
+// LocalKeyManager is an implementation of KeyManager that stores keys in memory. It is safe for concurrent use.
 type LocalKeyManager struct {
-	keys map[string]jwk.JWK
+	mu   sync.RWMutex
+	keys map[string]localKey
+}
+
+// localKey is a key held by a [LocalKeyManager]
+type localKey struct {
+	jwk      jwk.JWK
+	metadata KeyMetadata
 }
 
 // NewLocalKeyManager returns a new instance of InMemoryKeyManager
 func NewLocalKeyManager() *LocalKeyManager {
 	return &LocalKeyManager{
-		keys: make(map[string]jwk.JWK),
+		keys: make(map[string]localKey),
 	}
 }
 
 // GeneratePrivateKey generates a new private key using the algorithm provided,
 // stores it in the key store and returns the key id
-// Supported algorithms are available in [github.com/decentralized-identity/web5-go/crypto/dsa.AlgorithmID]
+// Supported algorithms are available in [github.com/decentralized-identity/web5-go/crypto/dsa.AlgorithmID],
+// along with the key agreement only algorithms of [github.com/decentralized-identity/web5-go/crypto/ecdh]
 func (k *LocalKeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	return k.GeneratePrivateKeyWithOptions(algorithmID)
+}
+
+// GeneratePrivateKeyWithOptions generates a new private key like [LocalKeyManager.GeneratePrivateKey], with
+// the metadata set by the given options
+func (k *LocalKeyManager) GeneratePrivateKeyWithOptions(algorithmID string, opts ...KeyOpt) (string, error) {
 	var keyAlias string
 
-	key, err := dsa.GeneratePrivateKey(algorithmID)
+	key, err := generatePrivateKey(algorithmID)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate private key: %w", err)
 	}
@@ -58,7 +94,10 @@ func (k *LocalKeyManager) GeneratePrivateKey(algorithmID string) (string, error)
 		return "", fmt.Errorf("failed to compute key alias: %w", err)
 	}
 
-	k.keys[keyAlias] = key
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[keyAlias] = localKey{jwk: key, metadata: newKeyMetadata(keyAlias, key, opts...)}
 
 	return keyAlias, nil
 }
@@ -70,8 +109,7 @@ func (k *LocalKeyManager) GetPublicKey(keyID string) (jwk.JWK, error) {
 		return jwk.JWK{}, err
 	}
 
-	return dsa.GetPublicKey(key), nil
-
+	return getPublicKey(key.jwk), nil
 }
 
 // Sign signs the payload with the private key for the given key id
@@ -81,27 +119,56 @@ func (k *LocalKeyManager) Sign(keyID string, payload []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return dsa.Sign(payload, key)
+	return dsa.Sign(payload, key.jwk)
+}
+
+// SignDigest signs the digest with the ECDSA private key for the given key id
+func (k *LocalKeyManager) SignDigest(keyID string, digest []byte) ([]byte, error) {
+	key, err := k.getPrivateJWK(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return signDigest(digest, key.jwk)
 }
 
-func (k *LocalKeyManager) getPrivateJWK(keyID string) (jwk.JWK, error) {
+// DeriveSharedSecret derives the raw ECDH shared secret between the private key for the given key id and
+// the given public key of the other party
+func (k *LocalKeyManager) DeriveSharedSecret(keyID string, peerPublicKey jwk.JWK) ([]byte, error) {
+	key, err := k.getPrivateJWK(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdh.DeriveSharedSecret(key.jwk, peerPublicKey)
+}
+
+func (k *LocalKeyManager) getPrivateJWK(keyID string) (localKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
 	key, ok := k.keys[keyID]
 
 	if !ok {
-		return jwk.JWK{}, fmt.Errorf("key with alias %s not found", keyID)
+		return localKey{}, fmt.Errorf("key with alias %s not found", keyID)
 	}
 
 	return key, nil
 }
 
-// ExportKey exports the key specific by the key ID from the [LocalKeyManager]
+// ExportKey exports the key specific by the key ID from the [LocalKeyManager]. Keys generated with
+// KeyExportable(false) cannot be exported.
 func (k *LocalKeyManager) ExportKey(keyID string) (jwk.JWK, error) {
 	key, err := k.getPrivateJWK(keyID)
 	if err != nil {
 		return jwk.JWK{}, err
 	}
 
-	return key, nil
+	if !key.metadata.Exportable {
+		return jwk.JWK{}, fmt.Errorf("%w: %s", ErrKeyNotExportable, keyID)
+	}
+
+	return key.jwk, nil
 }
 
 // ImportKey imports the key into the [LocalKeyManager] and returns the key alias
@@ -111,7 +178,100 @@ func (k *LocalKeyManager) ImportKey(key jwk.JWK) (string, error) {
 		return "", fmt.Errorf("failed to compute key alias: %w", err)
 	}
 
-	k.keys[keyAlias] = key
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// importing a key that is already held keeps its metadata
+	metadata := newKeyMetadata(keyAlias, key)
+	if existing, ok := k.keys[keyAlias]; ok {
+		metadata = existing.metadata
+	}
+
+	k.keys[keyAlias] = localKey{jwk: key, metadata: metadata}
 
 	return keyAlias, nil
 }
+
+// ListKeys returns the metadata of all keys, ordered by creation time
+func (k *LocalKeyManager) ListKeys() ([]KeyMetadata, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]KeyMetadata, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, copyKeyMetadata(key.metadata))
+	}
+
+	sortKeyMetadata(keys)
+	return keys, nil
+}
+
+// DeleteKey removes the key for the given key id
+func (k *LocalKeyManager) DeleteKey(keyID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[keyID]; !ok {
+		return fmt.Errorf("key with alias %s not found", keyID)
+	}
+
+	delete(k.keys, keyID)
+	return nil
+}
+
+// GetKeyMetadata returns the metadata of the key for the given key id
+func (k *LocalKeyManager) GetKeyMetadata(keyID string) (KeyMetadata, error) {
+	key, err := k.getPrivateJWK(keyID)
+	if err != nil {
+		return KeyMetadata{}, err
+	}
+
+	return copyKeyMetadata(key.metadata), nil
+}
+
+// SetKeyLabels replaces the labels of the key for the given key id
+func (k *LocalKeyManager) SetKeyLabels(keyID string, labels map[string]string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, ok := k.keys[keyID]
+	if !ok {
+		return fmt.Errorf("key with alias %s not found", keyID)
+	}
+
+	key.metadata.Labels = copyLabels(labels)
+	k.keys[keyID] = key
+	return nil
+}
+
+// generatePrivateKey generates a private key with either a signature algorithm, or a key agreement only
+// algorithm of [github.com/decentralized-identity/web5-go/crypto/ecdh]
+func generatePrivateKey(algorithmID string) (jwk.JWK, error) {
+	if ecdh.SupportsAlgorithmID(algorithmID) {
+		return ecdh.GeneratePrivateKey(algorithmID)
+	}
+
+	return dsa.GeneratePrivateKey(algorithmID)
+}
+
+// signDigest signs a digest computed by the caller with an ECDSA private key
+func signDigest(digest []byte, privateKey jwk.JWK) ([]byte, error) {
+	if privateKey.KTY != ecdsa.KeyType {
+		return nil, fmt.Errorf("signing digests requires an ECDSA key, got key type %s", privateKey.KTY)
+	}
+
+	if privateKey.ALG == schnorr.JWA {
+		return nil, errors.New("signing digests requires an ECDSA key, got a Schnorr key")
+	}
+
+	return ecdsa.SignDigest(digest, privateKey)
+}
+
+// getPublicKey returns the public key of a private key generated by [generatePrivateKey]
+func getPublicKey(privateKey jwk.JWK) jwk.JWK {
+	if privateKey.CRV == ecdh.X25519JWACurve {
+		return ecdh.GetPublicKey(privateKey)
+	}
+
+	return dsa.GetPublicKey(privateKey)
+}
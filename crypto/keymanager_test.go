@@ -1,11 +1,13 @@
 package crypto_test
 
 import (
+	"crypto/sha256"
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/decentralized-identity/web5-go/crypto"
 	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
 )
 
 func TestGeneratePrivateKey(t *testing.T) {
@@ -48,3 +50,54 @@ func TestSign(t *testing.T) {
 
 	assert.True(t, signature != nil, "signature is nil")
 }
+
+func TestDeriveSharedSecret(t *testing.T) {
+	keyManager := crypto.NewLocalKeyManager()
+
+	aliceKeyID, err := keyManager.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	assert.NoError(t, err)
+
+	bobKeyID, err := keyManager.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	assert.NoError(t, err)
+
+	alicePublicKey, err := keyManager.GetPublicKey(aliceKeyID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", alicePublicKey.D)
+
+	bobPublicKey, err := keyManager.GetPublicKey(bobKeyID)
+	assert.NoError(t, err)
+
+	var agreer crypto.KeyAgreer = keyManager
+	aliceSecret, err := agreer.DeriveSharedSecret(aliceKeyID, bobPublicKey)
+	assert.NoError(t, err)
+
+	bobSecret, err := agreer.DeriveSharedSecret(bobKeyID, alicePublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, aliceSecret, bobSecret)
+
+	// key agreement keys cannot sign
+	_, err = keyManager.Sign(aliceKeyID, []byte("hello"))
+	assert.Error(t, err)
+
+	_, err = agreer.DeriveSharedSecret("missing", bobPublicKey)
+	assert.Error(t, err)
+}
+
+func TestSignDigest_Recoverable(t *testing.T) {
+	keyManager := crypto.NewLocalKeyManager()
+
+	keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDSECP256K1Recoverable)
+	assert.NoError(t, err)
+
+	payload := []byte("hello world")
+	signature, err := keyManager.Sign(keyID, payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 65, len(signature))
+
+	// digests are signed without the recovery id, as plain r||s
+	digest := sha256.Sum256(payload)
+	digestSignature, err := keyManager.SignDigest(keyID, digest[:])
+	assert.NoError(t, err)
+	assert.Equal(t, 64, len(digestSignature))
+	assert.Equal(t, signature[:64], digestSignature)
+}
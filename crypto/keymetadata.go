@@ -0,0 +1,172 @@
+package crypto
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// ErrKeyNotExportable is returned when exporting a key that was generated as not exportable
+var ErrKeyNotExportable = errors.New("key is not exportable")
+
+// KeyMetadata describes a key held by a [KeyManager]
+type KeyMetadata struct {
+	// KeyID is the alias of the key in the key manager
+	KeyID string `json:"keyId"`
+	// AlgorithmID is the algorithm the key was generated with, e.g. [dsa.AlgorithmIDED25519]
+	AlgorithmID string `json:"algorithmId"`
+	// CreatedAt is the time the key was generated or imported
+	CreatedAt time.Time `json:"createdAt"`
+	// Labels are arbitrary annotations of the key
+	Labels map[string]string `json:"labels,omitempty"`
+	// Exportable informs as to whether or not the private key can be exported
+	Exportable bool `json:"exportable"`
+}
+
+// KeyLister is an abstraction that can be leveraged to implement types which can enumerate the keys they hold
+type KeyLister interface {
+	// ListKeys returns the metadata of all keys, ordered by creation time
+	ListKeys() ([]KeyMetadata, error)
+}
+
+// KeyDeleter is an abstraction that can be leveraged to implement types which can remove keys
+type KeyDeleter interface {
+	// DeleteKey removes the key for the given key id
+	DeleteKey(keyID string) error
+}
+
+// KeyDescriber is an abstraction that can be leveraged to implement types which keep metadata about their keys
+type KeyDescriber interface {
+	// GetKeyMetadata returns the metadata of the key for the given key id
+	GetKeyMetadata(keyID string) (KeyMetadata, error)
+}
+
+// KeyLabeler is an abstraction that can be leveraged to implement types which can annotate their keys
+type KeyLabeler interface {
+	// SetKeyLabels replaces the labels of the key for the given key id
+	SetKeyLabels(keyID string, labels map[string]string) error
+}
+
+// KeyGenerator is an abstraction that can be leveraged to implement types which can generate keys with
+// metadata. See [KeyLabels] and [KeyExportable].
+type KeyGenerator interface {
+	// GeneratePrivateKeyWithOptions generates a new private key, stores it along with its metadata and
+	// returns the key id
+	GeneratePrivateKeyWithOptions(algorithmID string, opts ...KeyOpt) (string, error)
+}
+
+// options that GeneratePrivateKeyWithOptions can take
+type keyOpts struct {
+	labels     map[string]string
+	exportable bool
+}
+
+// KeyOpt is a type that represents an option that can be passed to
+// [KeyGenerator.GeneratePrivateKeyWithOptions].
+type KeyOpt func(opts *keyOpts)
+
+// KeyLabels is an option that can be passed to [KeyGenerator.GeneratePrivateKeyWithOptions]. It sets the
+// labels of the generated key.
+func KeyLabels(labels map[string]string) KeyOpt {
+	return func(opts *keyOpts) {
+		opts.labels = labels
+	}
+}
+
+// KeyExportable is an option that can be passed to [KeyGenerator.GeneratePrivateKeyWithOptions]. It sets
+// whether the generated key can be exported, which defaults to true.
+func KeyExportable(exportable bool) KeyOpt {
+	return func(opts *keyOpts) {
+		opts.exportable = exportable
+	}
+}
+
+// RotateKey generates a replacement for the key for the given key id, with the same algorithm, labels and
+// exportability, and returns the id of the new key. The old key is kept, so that it can be deleted once
+// everything referencing it (e.g. DID documents) has been updated. The key manager must implement
+// [KeyDescriber], and [KeyGenerator] to carry over the labels and exportability.
+func RotateKey(keyManager KeyManager, keyID string) (string, error) {
+	describer, ok := keyManager.(KeyDescriber)
+	if !ok {
+		return "", errors.New("key manager does not support key metadata")
+	}
+
+	metadata, err := describer.GetKeyMetadata(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	generator, ok := keyManager.(KeyGenerator)
+	if !ok {
+		return keyManager.GeneratePrivateKey(metadata.AlgorithmID)
+	}
+
+	return generator.GeneratePrivateKeyWithOptions(
+		metadata.AlgorithmID,
+		KeyLabels(metadata.Labels),
+		KeyExportable(metadata.Exportable),
+	)
+}
+
+// newKeyMetadata returns the metadata of a newly generated or imported key
+func newKeyMetadata(keyID string, key jwk.JWK, opts ...KeyOpt) KeyMetadata {
+	o := keyOpts{exportable: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return KeyMetadata{
+		KeyID:       keyID,
+		AlgorithmID: keyAlgorithmID(key),
+		CreatedAt:   time.Now().UTC(),
+		Labels:      copyLabels(o.labels),
+		Exportable:  o.exportable,
+	}
+}
+
+// keyAlgorithmID returns the algorithm ID of a key, or an empty string for imported keys of algorithms that
+// are not registered
+func keyAlgorithmID(key jwk.JWK) string {
+	var algorithmID string
+	if key.CRV == ecdh.X25519JWACurve {
+		algorithmID, _ = ecdh.AlgorithmID(&key)
+	} else {
+		algorithmID, _ = dsa.AlgorithmID(&key)
+	}
+
+	return algorithmID
+}
+
+// sortKeyMetadata orders key metadata by creation time, then key id
+func sortKeyMetadata(keys []KeyMetadata) {
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+
+		return keys[i].KeyID < keys[j].KeyID
+	})
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+
+	return copied
+}
+
+// copyKeyMetadata returns a copy of the metadata that does not share its labels
+func copyKeyMetadata(metadata KeyMetadata) KeyMetadata {
+	metadata.Labels = copyLabels(metadata.Labels)
+	return metadata
+}
@@ -0,0 +1,171 @@
+package crypto_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+)
+
+// lifecycleKeyManager is a key manager that supports all key lifecycle operations
+type lifecycleKeyManager interface {
+	crypto.KeyManager
+	crypto.KeyExporter
+	crypto.KeyImporter
+	crypto.KeyGenerator
+	crypto.KeyLister
+	crypto.KeyDeleter
+	crypto.KeyDescriber
+	crypto.KeyLabeler
+}
+
+func lifecycleKeyManagers(t *testing.T) map[string]lifecycleKeyManager {
+	t.Helper()
+
+	fileKeyManager, err := crypto.NewFileKeyManager(
+		filepath.Join(t.TempDir(), "keystore.json"),
+		[]byte("passphrase"),
+		crypto.KeystoreKDF(crypto.KDFScrypt),
+	)
+	assert.NoError(t, err)
+
+	return map[string]lifecycleKeyManager{
+		"LocalKeyManager": crypto.NewLocalKeyManager(),
+		"FileKeyManager":  fileKeyManager,
+	}
+}
+
+func TestKeyLifecycle(t *testing.T) {
+	for name, keyManager := range lifecycleKeyManagers(t) {
+		t.Run(name, func(t *testing.T) {
+			signingKeyID, err := keyManager.GeneratePrivateKeyWithOptions(
+				dsa.AlgorithmIDED25519,
+				crypto.KeyLabels(map[string]string{"purpose": "signing"}),
+				crypto.KeyExportable(false),
+			)
+			assert.NoError(t, err)
+
+			agreementKeyID, err := keyManager.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+			assert.NoError(t, err)
+
+			metadata, err := keyManager.GetKeyMetadata(signingKeyID)
+			assert.NoError(t, err)
+			assert.Equal(t, signingKeyID, metadata.KeyID)
+			assert.Equal(t, dsa.AlgorithmIDED25519, metadata.AlgorithmID)
+			assert.Equal(t, map[string]string{"purpose": "signing"}, metadata.Labels)
+			assert.False(t, metadata.Exportable)
+			assert.False(t, metadata.CreatedAt.IsZero())
+
+			// non-exportable keys can be used but not exported
+			_, err = keyManager.Sign(signingKeyID, []byte("hello"))
+			assert.NoError(t, err)
+
+			_, err = keyManager.ExportKey(signingKeyID)
+			assert.IsError(t, err, crypto.ErrKeyNotExportable)
+
+			_, err = keyManager.ExportKey(agreementKeyID)
+			assert.NoError(t, err)
+
+			keys, err := keyManager.ListKeys()
+			assert.NoError(t, err)
+			assert.Equal(t, 2, len(keys))
+
+			listed := make(map[string]crypto.KeyMetadata)
+			for _, key := range keys {
+				listed[key.KeyID] = key
+			}
+			assert.Equal(t, dsa.AlgorithmIDED25519, listed[signingKeyID].AlgorithmID)
+			assert.Equal(t, ecdh.X25519AlgorithmID, listed[agreementKeyID].AlgorithmID)
+			assert.True(t, listed[agreementKeyID].Exportable)
+
+			err = keyManager.SetKeyLabels(agreementKeyID, map[string]string{"purpose": "agreement"})
+			assert.NoError(t, err)
+
+			metadata, err = keyManager.GetKeyMetadata(agreementKeyID)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"purpose": "agreement"}, metadata.Labels)
+
+			// rotation carries over the algorithm and metadata
+			rotatedKeyID, err := crypto.RotateKey(keyManager, signingKeyID)
+			assert.NoError(t, err)
+			assert.NotEqual(t, signingKeyID, rotatedKeyID)
+
+			rotated, err := keyManager.GetKeyMetadata(rotatedKeyID)
+			assert.NoError(t, err)
+			assert.Equal(t, dsa.AlgorithmIDED25519, rotated.AlgorithmID)
+			assert.Equal(t, map[string]string{"purpose": "signing"}, rotated.Labels)
+			assert.False(t, rotated.Exportable)
+
+			err = keyManager.DeleteKey(signingKeyID)
+			assert.NoError(t, err)
+
+			_, err = keyManager.GetPublicKey(signingKeyID)
+			assert.Error(t, err)
+
+			err = keyManager.DeleteKey(signingKeyID)
+			assert.Error(t, err)
+
+			keys, err = keyManager.ListKeys()
+			assert.NoError(t, err)
+			assert.Equal(t, 2, len(keys))
+
+			err = keyManager.SetKeyLabels("unknown", nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeyLifecycle_ImportKeepsMetadata(t *testing.T) {
+	for name, keyManager := range lifecycleKeyManagers(t) {
+		t.Run(name, func(t *testing.T) {
+			keyID, err := keyManager.GeneratePrivateKeyWithOptions(
+				dsa.AlgorithmIDSECP256K1,
+				crypto.KeyLabels(map[string]string{"owner": "alice"}),
+			)
+			assert.NoError(t, err)
+
+			privateKey, err := keyManager.ExportKey(keyID)
+			assert.NoError(t, err)
+
+			_, err = keyManager.ImportKey(privateKey)
+			assert.NoError(t, err)
+
+			metadata, err := keyManager.GetKeyMetadata(keyID)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"owner": "alice"}, metadata.Labels)
+		})
+	}
+}
+
+func TestLocalKeyManager_Concurrent(t *testing.T) {
+	keyManager := crypto.NewLocalKeyManager()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+			assert.NoError(t, err)
+
+			_, err = keyManager.Sign(keyID, []byte("hello"))
+			assert.NoError(t, err)
+
+			_, err = keyManager.ListKeys()
+			assert.NoError(t, err)
+
+			assert.NoError(t, keyManager.SetKeyLabels(keyID, map[string]string{"i": "j"}))
+			assert.NoError(t, keyManager.DeleteKey(keyID))
+		}()
+	}
+	wg.Wait()
+
+	keys, err := keyManager.ListKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(keys))
+}
@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	_crypto "crypto"
+	_ed25519 "crypto/ed25519"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/eddsa"
+)
+
+// Signer is a [crypto.Signer] for a key held by a KeyManager, so that the key can be used with APIs of the
+// standard library and beyond, like TLS client certificates, x509 certificate requests and SSH.
+//
+// Ed25519 keys sign the message itself and must be passed [crypto.Hash](0) as options, as with
+// [crypto/ed25519.PrivateKey]. ECDSA keys sign the digest computed by the caller, which requires the
+// KeyManager to implement [DigestSigner], and return ASN.1 DER signatures, as with
+// [crypto/ecdsa.PrivateKey].
+type Signer struct {
+	keyManager KeyManager
+	keyID      string
+	publicKey  _crypto.PublicKey
+	crv        string
+}
+
+// NewSigner returns a [Signer] for the key with the given key id
+func NewSigner(keyManager KeyManager, keyID string) (*Signer, error) {
+	key, err := keyManager.GetPublicKey(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	if key.CRV != eddsa.ED25519JWACurve && key.KTY != ecdsa.KeyType {
+		return nil, fmt.Errorf("unsupported signing key: %s", key.CRV)
+	}
+
+	publicKey, err := JWKToPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert public key: %w", err)
+	}
+
+	return &Signer{keyManager: keyManager, keyID: keyID, publicKey: publicKey, crv: key.CRV}, nil
+}
+
+// Public returns the public key, of the type documented by [JWKToPublicKey]
+func (s *Signer) Public() _crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign signs the message for Ed25519 keys, or the digest for ECDSA keys. rand is not used: the
+// KeyManager provides its own randomness.
+func (s *Signer) Sign(_ io.Reader, digest []byte, opts _crypto.SignerOpts) ([]byte, error) {
+	hash := _crypto.Hash(0)
+	if opts != nil {
+		hash = opts.HashFunc()
+	}
+
+	if s.crv == eddsa.ED25519JWACurve {
+		if hash != 0 {
+			return nil, errors.New("ed25519: Ed25519ph is not supported, opts.HashFunc() must be 0")
+		}
+
+		if o, ok := opts.(*_ed25519.Options); ok && o.Context != "" {
+			return nil, errors.New("ed25519: Ed25519ctx is not supported")
+		}
+
+		return s.keyManager.Sign(s.keyID, digest)
+	}
+
+	if hash != 0 && len(digest) != hash.Size() {
+		return nil, fmt.Errorf("digest must be %d bytes for %s, got %d", hash.Size(), hash, len(digest))
+	}
+
+	digestSigner, ok := s.keyManager.(DigestSigner)
+	if !ok {
+		return nil, errors.New("key manager does not support signing digests")
+	}
+
+	signature, err := digestSigner.SignDigest(s.keyID, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return joseToDER(signature)
+}
+
+// joseToDER converts a JOSE format ECDSA signature, r and s concatenated, to ASN.1 DER.
+// https://datatracker.ietf.org/doc/html/rfc7518#section-3.4
+func joseToDER(signature []byte) ([]byte, error) {
+	if len(signature) == 0 || len(signature)%2 != 0 {
+		return nil, fmt.Errorf("invalid signature length %d", len(signature))
+	}
+
+	size := len(signature) / 2
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{
+		R: new(big.Int).SetBytes(signature[:size]),
+		S: new(big.Int).SetBytes(signature[size:]),
+	})
+}
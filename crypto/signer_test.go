@@ -0,0 +1,142 @@
+package crypto_test
+
+import (
+	_crypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+func TestSigner_ECDSA(t *testing.T) {
+	message := []byte("hello world")
+	sha256Digest := sha256.Sum256(message)
+	sha384Digest := sha512.Sum384(message)
+
+	digests := []struct {
+		algorithmID string
+		hash        _crypto.Hash
+		digest      []byte
+	}{
+		{dsa.AlgorithmIDSECP256K1, _crypto.SHA256, sha256Digest[:]},
+		{dsa.AlgorithmIDSECP256R1, _crypto.SHA256, sha256Digest[:]},
+		{dsa.AlgorithmIDSECP384R1, _crypto.SHA384, sha384Digest[:]},
+	}
+
+	keyManager := crypto.NewLocalKeyManager()
+	for _, d := range digests {
+		t.Run(d.algorithmID, func(t *testing.T) {
+			keyID, err := keyManager.GeneratePrivateKey(d.algorithmID)
+			assert.NoError(t, err)
+
+			signer, err := crypto.NewSigner(keyManager, keyID)
+			assert.NoError(t, err)
+
+			signature, err := signer.Sign(rand.Reader, d.digest, d.hash)
+			assert.NoError(t, err)
+
+			publicKey, ok := signer.Public().(*ecdsa.PublicKey)
+			assert.True(t, ok)
+			assert.True(t, ecdsa.VerifyASN1(publicKey, d.digest, signature))
+
+			_, err = signer.Sign(rand.Reader, d.digest[:20], d.hash)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSigner_Ed25519(t *testing.T) {
+	keyManager := crypto.NewLocalKeyManager()
+	keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
+	signer, err := crypto.NewSigner(keyManager, keyID)
+	assert.NoError(t, err)
+
+	message := []byte("hello world")
+	signature, err := signer.Sign(rand.Reader, message, _crypto.Hash(0))
+	assert.NoError(t, err)
+
+	publicKey, ok := signer.Public().(ed25519.PublicKey)
+	assert.True(t, ok)
+	assert.True(t, ed25519.Verify(publicKey, message, signature))
+
+	digest := sha512.Sum512(message)
+	_, err = signer.Sign(rand.Reader, digest[:], _crypto.SHA512)
+	assert.Error(t, err)
+
+	_, err = signer.Sign(rand.Reader, message, &ed25519.Options{Context: "context"})
+	assert.Error(t, err)
+}
+
+func TestSigner_CertificateRequest(t *testing.T) {
+	keyManager := crypto.NewLocalKeyManager()
+
+	for _, algorithmID := range []string{dsa.AlgorithmIDSECP256R1, dsa.AlgorithmIDED25519} {
+		t.Run(algorithmID, func(t *testing.T) {
+			keyID, err := keyManager.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+
+			signer, err := crypto.NewSigner(keyManager, keyID)
+			assert.NoError(t, err)
+
+			template := &x509.CertificateRequest{Subject: pkix.Name{CommonName: "web5"}}
+			der, err := x509.CreateCertificateRequest(rand.Reader, template, signer)
+			assert.NoError(t, err)
+
+			csr, err := x509.ParseCertificateRequest(der)
+			assert.NoError(t, err)
+			assert.NoError(t, csr.CheckSignature())
+		})
+	}
+}
+
+// signOnlyKeyManager is a KeyManager that can't sign digests
+type signOnlyKeyManager struct {
+	keyManager *crypto.LocalKeyManager
+}
+
+func (k signOnlyKeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	return k.keyManager.GeneratePrivateKey(algorithmID)
+}
+
+func (k signOnlyKeyManager) GetPublicKey(keyID string) (jwk.JWK, error) {
+	return k.keyManager.GetPublicKey(keyID)
+}
+
+func (k signOnlyKeyManager) Sign(keyID string, payload []byte) ([]byte, error) {
+	return k.keyManager.Sign(keyID, payload)
+}
+
+func TestSigner_Errors(t *testing.T) {
+	keyManager := signOnlyKeyManager{keyManager: crypto.NewLocalKeyManager()}
+
+	keyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	assert.NoError(t, err)
+
+	signer, err := crypto.NewSigner(keyManager, keyID)
+	assert.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("hello world"))
+	_, err = signer.Sign(rand.Reader, digest[:], _crypto.SHA256)
+	assert.EqualError(t, err, "key manager does not support signing digests")
+
+	keyID, err = keyManager.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	assert.NoError(t, err)
+
+	_, err = crypto.NewSigner(keyManager, keyID)
+	assert.Error(t, err)
+
+	_, err = crypto.NewSigner(keyManager, "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package crypto
+
+import (
+	_crypto "crypto"
+
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// JWKToPublicKey converts a public key JWK to the standard library type of its curve:
+// [crypto/ed25519.PublicKey] for Ed25519, [*crypto/ecdsa.PublicKey] for secp256k1, P-256, P-384 and P-521,
+// [*crypto/ecdh.PublicKey] for X25519 and [*crypto/rsa.PublicKey] for RSA. secp256k1 keys use the curve of
+// [github.com/decred/dcrd/dcrec/secp256k1/v4.S256], as the standard library has none. See
+// [jwk.JWK.ToPublicKey].
+func JWKToPublicKey(key jwk.JWK) (_crypto.PublicKey, error) {
+	return key.ToPublicKey()
+}
+
+// JWKToPrivateKey converts a private key JWK to the standard library type of its curve:
+// [crypto/ed25519.PrivateKey] for Ed25519, [*crypto/ecdsa.PrivateKey] for secp256k1, P-256, P-384 and P-521,
+// [*crypto/ecdh.PrivateKey] for X25519 and [*crypto/rsa.PrivateKey] for RSA. See [jwk.JWK.ToPrivateKey].
+func JWKToPrivateKey(key jwk.JWK) (_crypto.PrivateKey, error) {
+	return key.ToPrivateKey()
+}
+
+// PublicKeyToJWK converts a public key of a type returned by [JWKToPublicKey] to a JWK. See
+// [jwk.FromPublicKey].
+func PublicKeyToJWK(publicKey _crypto.PublicKey) (jwk.JWK, error) {
+	return jwk.FromPublicKey(publicKey)
+}
+
+// PrivateKeyToJWK converts a private key of a type returned by [JWKToPrivateKey] to a JWK. See
+// [jwk.FromPrivateKey].
+func PrivateKeyToJWK(privateKey _crypto.PrivateKey) (jwk.JWK, error) {
+	return jwk.FromPrivateKey(privateKey)
+}
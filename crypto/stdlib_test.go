@@ -0,0 +1,75 @@
+package crypto_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// the conversions themselves are tested in the jwk package, this covers the adapters with keys generated by web5
+func TestJWKToPrivateKey_RoundTrip(t *testing.T) {
+	algorithmIDs := []string{
+		dsa.AlgorithmIDED25519,
+		dsa.AlgorithmIDSECP256K1,
+		dsa.AlgorithmIDSECP256R1,
+		dsa.AlgorithmIDSECP384R1,
+		ecdh.X25519AlgorithmID,
+	}
+
+	for _, algorithmID := range algorithmIDs {
+		t.Run(algorithmID, func(t *testing.T) {
+			var privateJWK jwk.JWK
+			var err error
+			if algorithmID == ecdh.X25519AlgorithmID {
+				privateJWK, err = ecdh.GeneratePrivateKey(algorithmID)
+			} else {
+				privateJWK, err = dsa.GeneratePrivateKey(algorithmID)
+			}
+			assert.NoError(t, err)
+
+			privateKey, err := crypto.JWKToPrivateKey(privateJWK)
+			assert.NoError(t, err)
+
+			roundTripped, err := crypto.PrivateKeyToJWK(privateKey)
+			assert.NoError(t, err)
+			assert.Equal(t, privateJWK, roundTripped)
+
+			publicJWK := jwk.JWK{KTY: privateJWK.KTY, CRV: privateJWK.CRV, X: privateJWK.X, Y: privateJWK.Y}
+			publicKey, err := crypto.JWKToPublicKey(publicJWK)
+			assert.NoError(t, err)
+
+			roundTripped, err = crypto.PublicKeyToJWK(publicKey)
+			assert.NoError(t, err)
+			assert.Equal(t, publicJWK, roundTripped)
+
+			// the private key and public key of the standard library types match
+			signer, ok := privateKey.(interface{ Public() any })
+			if ok {
+				assert.True(t, publicKey.(interface{ Equal(any) bool }).Equal(signer.Public()))
+			}
+		})
+	}
+}
+
+func TestJWKToPublicKey_VerifiesSignatures(t *testing.T) {
+	privateJWK, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	assert.NoError(t, err)
+
+	payload := []byte("hello world")
+	signature, err := dsa.Sign(payload, privateJWK)
+	assert.NoError(t, err)
+
+	publicKey, err := crypto.JWKToPublicKey(dsa.GetPublicKey(privateJWK))
+	assert.NoError(t, err)
+
+	digest := sha256.Sum256(payload)
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	assert.True(t, ecdsa.Verify(publicKey.(*ecdsa.PublicKey), digest[:], r, s))
+}
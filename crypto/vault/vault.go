@@ -0,0 +1,523 @@
+// Package vault implements a KeyManager backed by the HashiCorp Vault Transit secrets engine
+// (https://developer.hashicorp.com/vault/docs/secrets/transit)
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/jwk"
+	"github.com/google/uuid"
+)
+
+// keyNamePrefix is prepended to the names of the keys created in Vault
+const keyNamePrefix = "web5-"
+
+// defaultRefreshInterval is how often keys are listed again by default when a key id is not found
+const defaultRefreshInterval = time.Minute
+
+// keyType describes how keys of an algorithm are created and used in Vault
+type keyType struct {
+	algorithmID string
+	// name is the Transit key type
+	name string
+	// hash is the hash algorithm used to sign, empty for ed25519 which signs the payload itself
+	hash string
+}
+
+// keyTypes are the algorithms supported by both web5 and Vault Transit.
+// https://developer.hashicorp.com/vault/api-docs/secret/transit#type
+var keyTypes = []keyType{
+	{algorithmID: dsa.AlgorithmIDED25519, name: "ed25519"},
+	{algorithmID: dsa.AlgorithmIDSECP256R1, name: "ecdsa-p256", hash: "sha2-256"},
+	{algorithmID: dsa.AlgorithmIDSECP384R1, name: "ecdsa-p384", hash: "sha2-384"},
+}
+
+func keyTypeByAlgorithmID(algorithmID string) (keyType, bool) {
+	for _, kt := range keyTypes {
+		if kt.algorithmID == algorithmID {
+			return kt, true
+		}
+	}
+
+	return keyType{}, false
+}
+
+func keyTypeByName(name string) (keyType, bool) {
+	for _, kt := range keyTypes {
+		if kt.name == name {
+			return kt, true
+		}
+	}
+
+	return keyType{}, false
+}
+
+// keyRef identifies a version of a key in Vault
+type keyRef struct {
+	name    string
+	version int
+	keyType keyType
+	key     jwk.JWK
+}
+
+// KeyManager is a [github.com/decentralized-identity/web5-go/crypto.KeyManager] backed by the
+// HashiCorp Vault Transit secrets engine. Private keys never leave Vault.
+//
+// Like other key managers, the key id of a key is the thumbprint of its public key. Vault key names can't
+// be chosen after the fact, so keys are created under a random name and looked up by thumbprint. Keys
+// created in Vault by other means (including new versions from rotating a key in Vault) can be used as
+// long as they are of a supported type: when a key id is not found, every key in the mount is read again,
+// at most once per [RefreshInterval]. Vault key names can be used as key ids too, e.g. in
+// [github.com/decentralized-identity/web5-go/dids/did.BearerDID.KeyAliases], and refer to the latest
+// version of the key at the time of each call, which costs a request to Vault.
+//
+// https://developer.hashicorp.com/vault/api-docs/secret/transit
+type KeyManager struct {
+	address   string
+	token     string
+	mount     string
+	namespace string
+	client    *http.Client
+
+	mu   sync.RWMutex
+	keys map[string]keyRef
+
+	refreshMu       sync.Mutex
+	refreshInterval time.Duration
+	refreshedAt     time.Time
+}
+
+// options that NewKeyManager can take
+type keyManagerOpts struct {
+	mount           string
+	namespace       string
+	client          *http.Client
+	refreshInterval time.Duration
+}
+
+// KeyManagerOpt is a type that represents an option that can be passed to [NewKeyManager].
+type KeyManagerOpt func(opts *keyManagerOpts)
+
+// Mount is an option that can be passed to [NewKeyManager]. It sets the path the Transit secrets engine is
+// mounted at, which defaults to "transit".
+func Mount(path string) KeyManagerOpt {
+	return func(opts *keyManagerOpts) {
+		opts.mount = path
+	}
+}
+
+// Namespace is an option that can be passed to [NewKeyManager]. It sets the Vault Enterprise namespace of
+// the Transit secrets engine.
+func Namespace(namespace string) KeyManagerOpt {
+	return func(opts *keyManagerOpts) {
+		opts.namespace = namespace
+	}
+}
+
+// HTTPClient is an option that can be passed to [NewKeyManager]. It sets the HTTP client used to call
+// Vault, which defaults to [http.DefaultClient].
+func HTTPClient(client *http.Client) KeyManagerOpt {
+	return func(opts *keyManagerOpts) {
+		opts.client = client
+	}
+}
+
+// RefreshInterval is an option that can be passed to [NewKeyManager]. It sets how long key ids that are not
+// found are answered without asking Vault again, as finding them reads every key in the mount. Defaults to
+// 1 minute.
+func RefreshInterval(interval time.Duration) KeyManagerOpt {
+	return func(opts *keyManagerOpts) {
+		opts.refreshInterval = interval
+	}
+}
+
+// NewKeyManager returns a KeyManager for the Vault server at the given address (e.g. https://vault:8200),
+// authenticating with the given token. The token needs the create, read, update and list capabilities on
+// the keys and sign paths of the Transit mount.
+func NewKeyManager(address string, token string, opts ...KeyManagerOpt) *KeyManager {
+	o := keyManagerOpts{mount: "transit", client: http.DefaultClient, refreshInterval: defaultRefreshInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &KeyManager{
+		address:   strings.TrimSuffix(address, "/"),
+		token:     token,
+		mount:     strings.Trim(o.mount, "/"),
+		namespace: o.namespace,
+		client:    o.client,
+		keys:      make(map[string]keyRef),
+
+		refreshInterval: o.refreshInterval,
+	}
+}
+
+// GeneratePrivateKey creates a new key in Vault and returns its key id.
+// Supported algorithms are [dsa.AlgorithmIDED25519], [dsa.AlgorithmIDSECP256R1] and [dsa.AlgorithmIDSECP384R1].
+func (k *KeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	return k.GeneratePrivateKeyWithContext(context.Background(), algorithmID)
+}
+
+// GeneratePrivateKeyWithContext is like [KeyManager.GeneratePrivateKey], with the given context
+func (k *KeyManager) GeneratePrivateKeyWithContext(ctx context.Context, algorithmID string) (string, error) {
+	kt, ok := keyTypeByAlgorithmID(algorithmID)
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	}
+
+	name := keyNamePrefix + uuid.NewString()
+	err := k.do(ctx, http.MethodPost, "keys/"+name, map[string]any{"type": kt.name}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create key: %w", err)
+	}
+
+	refs, err := k.readKey(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(refs) == 0 {
+		return "", fmt.Errorf("vault returned no public key for %s", name)
+	}
+
+	return k.cache(refs), nil
+}
+
+// GetPublicKey returns the public key for the given key id
+func (k *KeyManager) GetPublicKey(keyID string) (jwk.JWK, error) {
+	return k.GetPublicKeyWithContext(context.Background(), keyID)
+}
+
+// GetPublicKeyWithContext is like [KeyManager.GetPublicKey], with the given context
+func (k *KeyManager) GetPublicKeyWithContext(ctx context.Context, keyID string) (jwk.JWK, error) {
+	ref, err := k.lookup(ctx, keyID)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return ref.key, nil
+}
+
+// Sign signs the payload with the key for the given key id. ECDSA signatures are returned in the JOSE
+// format, the fixed size r and s values concatenated, like [dsa.Sign].
+func (k *KeyManager) Sign(keyID string, payload []byte) ([]byte, error) {
+	return k.SignWithContext(context.Background(), keyID, payload)
+}
+
+// SignWithContext is like [KeyManager.Sign], with the given context
+func (k *KeyManager) SignWithContext(ctx context.Context, keyID string, payload []byte) ([]byte, error) {
+	ref, err := k.lookup(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := map[string]any{
+		"input":       base64.StdEncoding.EncodeToString(payload),
+		"key_version": ref.version,
+	}
+
+	if ref.keyType.hash != "" {
+		req["hash_algorithm"] = ref.keyType.hash
+		// jws marshaling returns r || s, base64url encoded, instead of ASN.1 DER
+		req["marshaling_algorithm"] = "jws"
+	}
+
+	var res struct {
+		Signature string `json:"signature"`
+	}
+
+	if err := k.do(ctx, http.MethodPost, "sign/"+ref.name, req, &res); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	// signatures are formatted as vault:v<version>:<signature>
+	i := strings.LastIndex(res.Signature, ":")
+	if !strings.HasPrefix(res.Signature, "vault:") || i < 0 {
+		return nil, fmt.Errorf("unexpected signature format: %s", res.Signature)
+	}
+
+	var signature []byte
+	if ref.keyType.hash != "" {
+		signature, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(res.Signature[i+1:], "="))
+	} else {
+		signature, err = base64.StdEncoding.DecodeString(res.Signature[i+1:])
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	return signature, nil
+}
+
+// lookup returns the Vault key for the given key id or name. Keys that are not cached yet are looked up by
+// refreshing the cache. Only key ids are cached, as the version a name refers to changes when the key is
+// rotated.
+func (k *KeyManager) lookup(ctx context.Context, keyID string) (keyRef, error) {
+	k.mu.RLock()
+	ref, ok := k.keys[keyID]
+	k.mu.RUnlock()
+
+	if ok {
+		return ref, nil
+	}
+
+	// key ids can also be Vault key names, which refer to the latest version of the key. names are read
+	// from Vault on every use rather than cached, so that a rotation in Vault is picked up right away
+	refs, err := k.readKey(ctx, keyID)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return keyRef{}, err
+	}
+
+	if len(refs) > 0 {
+		k.cache(refs)
+
+		latest := refs[0]
+		for _, ref := range refs {
+			if ref.version > latest.version {
+				latest = ref
+			}
+		}
+
+		return latest, nil
+	}
+
+	if err := k.refresh(ctx); err != nil {
+		return keyRef{}, err
+	}
+
+	k.mu.RLock()
+	ref, ok = k.keys[keyID]
+	k.mu.RUnlock()
+
+	if !ok {
+		return keyRef{}, fmt.Errorf("key with alias %s not found", keyID)
+	}
+
+	return ref, nil
+}
+
+// refresh reads every key in the Transit mount into the cache, unless it was done less than the refresh
+// interval ago
+func (k *KeyManager) refresh(ctx context.Context) error {
+	k.refreshMu.Lock()
+	defer k.refreshMu.Unlock()
+
+	if !k.refreshedAt.IsZero() && time.Since(k.refreshedAt) < k.refreshInterval {
+		return nil
+	}
+
+	var res struct {
+		Keys []string `json:"keys"`
+	}
+
+	if err := k.do(ctx, "LIST", "keys", nil, &res); err != nil && !errors.Is(err, errNotFound) {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	for _, name := range res.Keys {
+		refs, err := k.readKey(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		k.cache(refs)
+	}
+
+	k.refreshedAt = time.Now()
+	return nil
+}
+
+// cache stores the versions of a key by thumbprint, and returns the key id of the latest version
+func (k *KeyManager) cache(refs []keyRef) string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var keyID string
+	latest := 0
+	for _, ref := range refs {
+		thumbprint, err := ref.key.ComputeThumbprint()
+		if err != nil {
+			continue
+		}
+
+		k.keys[thumbprint] = ref
+		if ref.version > latest {
+			keyID, latest = thumbprint, ref.version
+		}
+	}
+
+	return keyID
+}
+
+// readKey returns all versions of the key with the given name. Keys of unsupported types are skipped.
+// https://developer.hashicorp.com/vault/api-docs/secret/transit#read-key
+func (k *KeyManager) readKey(ctx context.Context, name string) ([]keyRef, error) {
+	var res struct {
+		Type string                     `json:"type"`
+		Keys map[string]json.RawMessage `json:"keys"`
+	}
+
+	if err := k.do(ctx, http.MethodGet, "keys/"+name, nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", name, err)
+	}
+
+	kt, ok := keyTypeByName(res.Type)
+	if !ok {
+		return nil, nil
+	}
+
+	refs := make([]keyRef, 0, len(res.Keys))
+	for v, raw := range res.Keys {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected version of key %s: %s", name, v)
+		}
+
+		var keyVersion struct {
+			PublicKey string `json:"public_key"`
+		}
+
+		if err := json.Unmarshal(raw, &keyVersion); err != nil {
+			return nil, fmt.Errorf("failed to decode key %s: %w", name, err)
+		}
+
+		key, err := publicKeyToJWK(kt, keyVersion.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert public key of %s: %w", name, err)
+		}
+
+		refs = append(refs, keyRef{name: name, version: version, keyType: kt, key: key})
+	}
+
+	return refs, nil
+}
+
+// publicKeyToJWK converts a public key as returned by Vault: base64 encoded for ed25519, PEM encoded
+// PKIX for ECDSA.
+func publicKeyToJWK(kt keyType, publicKey string) (jwk.JWK, error) {
+	if kt.hash == "" {
+		keyBytes, err := base64.StdEncoding.DecodeString(publicKey)
+		if err != nil {
+			return jwk.JWK{}, err
+		}
+
+		return dsa.BytesToPublicKey(kt.algorithmID, keyBytes)
+	}
+
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return jwk.JWK{}, errors.New("invalid PEM")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	ecdsaKey, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return jwk.JWK{}, fmt.Errorf("expected ECDSA public key, got %T", parsed)
+	}
+
+	ecdhKey, err := ecdsaKey.ECDH()
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	return dsa.BytesToPublicKey(kt.algorithmID, ecdhKey.Bytes())
+}
+
+// errNotFound is returned by do when Vault responds with 404, e.g. when listing a mount without keys
+var errNotFound = errors.New("not found")
+
+// do calls the Transit API at the given path, relative to the mount, and decodes the data of the
+// response into res, if not nil
+func (k *KeyManager) do(ctx context.Context, method string, path string, body any, res any) error {
+	endpoint, err := url.JoinPath(k.address, "v1", k.mount, path)
+	if err != nil {
+		return err
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reqBody = bytes.NewReader(reqBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Vault-Token", k.token)
+	if k.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", k.namespace)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errRes struct {
+			Errors []string `json:"errors"`
+		}
+
+		_ = json.Unmarshal(respBody, &errRes)
+		if resp.StatusCode == http.StatusNotFound && len(errRes.Errors) == 0 {
+			return errNotFound
+		}
+
+		return fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(errRes.Errors, ", "))
+	}
+
+	if res == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	var data struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(respBody, &data); err != nil {
+		return fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	if len(data.Data) == 0 {
+		return errors.New("vault response has no data")
+	}
+
+	return json.Unmarshal(data.Data, res)
+}
@@ -0,0 +1,488 @@
+package vault_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	web5crypto "github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/vault"
+	"github.com/decentralized-identity/web5-go/dids/didjwk"
+	"github.com/decentralized-identity/web5-go/jws"
+)
+
+const testToken = "test-token"
+
+// transitKey is a key held by the stand-in Transit server, with one private key per version
+type transitKey struct {
+	Type     string
+	Versions []crypto.Signer
+}
+
+// newTestVault starts a server that mimics the parts of the Vault Transit API used by the KeyManager.
+// https://developer.hashicorp.com/vault/api-docs/secret/transit
+func newTestVault(t *testing.T) (*httptest.Server, *sync.Map) {
+	t.Helper()
+
+	keys := &sync.Map{}
+	writeError := func(w http.ResponseWriter, status int, errs ...string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"errors": errs})
+	}
+
+	writeData := func(w http.ResponseWriter, data any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != testToken {
+			writeError(w, http.StatusForbidden, "permission denied")
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/v1/transit/")
+		switch {
+		case path == "keys" && (r.Method == "LIST" || r.URL.Query().Get("list") == "true"):
+			var names []string
+			keys.Range(func(name, _ any) bool {
+				names = append(names, name.(string))
+				return true
+			})
+
+			if len(names) == 0 {
+				writeError(w, http.StatusNotFound)
+				return
+			}
+
+			writeData(w, map[string]any{"keys": names})
+
+		case strings.HasPrefix(path, "keys/") && strings.HasSuffix(path, "/rotate") && r.Method == http.MethodPost:
+			name := strings.TrimSuffix(strings.TrimPrefix(path, "keys/"), "/rotate")
+			value, ok := keys.Load(name)
+			if !ok {
+				writeError(w, http.StatusNotFound, "key not found")
+				return
+			}
+
+			key := value.(*transitKey)
+			key.Versions = append(key.Versions, generateKey(t, key.Type))
+			w.WriteHeader(http.StatusNoContent)
+
+		case strings.HasPrefix(path, "keys/") && r.Method == http.MethodPost:
+			var req struct {
+				Type string `json:"type"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+			if req.Type != "ed25519" && req.Type != "ecdsa-p256" && req.Type != "ecdsa-p384" && req.Type != "aes256-gcm96" {
+				writeError(w, http.StatusBadRequest, "unknown key type "+req.Type)
+				return
+			}
+
+			keys.Store(strings.TrimPrefix(path, "keys/"), &transitKey{
+				Type:     req.Type,
+				Versions: []crypto.Signer{generateKey(t, req.Type)},
+			})
+			w.WriteHeader(http.StatusNoContent)
+
+		case strings.HasPrefix(path, "keys/") && r.Method == http.MethodGet:
+			value, ok := keys.Load(strings.TrimPrefix(path, "keys/"))
+			if !ok {
+				writeError(w, http.StatusNotFound)
+				return
+			}
+
+			key := value.(*transitKey)
+			versions := map[string]any{}
+			for i, signer := range key.Versions {
+				if key.Type == "aes256-gcm96" {
+					versions[strconv.Itoa(i+1)] = 1700000000
+					continue
+				}
+
+				versions[strconv.Itoa(i+1)] = map[string]any{"public_key": encodePublicKey(t, signer.Public())}
+			}
+
+			writeData(w, map[string]any{"type": key.Type, "latest_version": len(key.Versions), "keys": versions})
+
+		case strings.HasPrefix(path, "sign/") && r.Method == http.MethodPost:
+			value, ok := keys.Load(strings.TrimPrefix(path, "sign/"))
+			if !ok {
+				writeError(w, http.StatusBadRequest, "signing key not found")
+				return
+			}
+
+			var req struct {
+				Input               string `json:"input"`
+				KeyVersion          int    `json:"key_version"`
+				HashAlgorithm       string `json:"hash_algorithm"`
+				MarshalingAlgorithm string `json:"marshaling_algorithm"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+			key := value.(*transitKey)
+			if req.KeyVersion < 1 || req.KeyVersion > len(key.Versions) {
+				writeError(w, http.StatusBadRequest, "invalid key version")
+				return
+			}
+
+			input, err := base64.StdEncoding.DecodeString(req.Input)
+			assert.NoError(t, err)
+
+			writeData(w, map[string]any{
+				"signature":   fmt.Sprintf("vault:v%d:%s", req.KeyVersion, sign(t, key, req.KeyVersion, input, req.HashAlgorithm, req.MarshalingAlgorithm)),
+				"key_version": req.KeyVersion,
+			})
+
+		default:
+			writeError(w, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, keys
+}
+
+func generateKey(t *testing.T, keyType string) crypto.Signer {
+	t.Helper()
+
+	var signer crypto.Signer
+	var err error
+	switch keyType {
+	case "ed25519", "aes256-gcm96":
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case "ecdsa-p256":
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ecdsa-p384":
+		signer, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	}
+	assert.NoError(t, err)
+
+	return signer
+}
+
+func encodePublicKey(t *testing.T, publicKey crypto.PublicKey) string {
+	t.Helper()
+
+	if key, ok := publicKey.(ed25519.PublicKey); ok {
+		return base64.StdEncoding.EncodeToString(key)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	assert.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func sign(t *testing.T, key *transitKey, version int, input []byte, hashAlgorithm string, marshaling string) string {
+	t.Helper()
+
+	signer := key.Versions[version-1]
+	if key.Type == "ed25519" {
+		signature, err := signer.Sign(rand.Reader, input, crypto.Hash(0))
+		assert.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(signature)
+	}
+
+	hash := map[string]crypto.Hash{"sha2-256": crypto.SHA256, "sha2-384": crypto.SHA384}[hashAlgorithm]
+	assert.NotZero(t, hash, "unexpected hash algorithm %s", hashAlgorithm)
+
+	h := hash.New()
+	h.Write(input)
+	digest := h.Sum(nil)
+
+	privateKey := signer.(*ecdsa.PrivateKey)
+	if marshaling != "jws" {
+		signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest)
+		assert.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(signature)
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest)
+	assert.NoError(t, err)
+
+	size := (privateKey.Curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+
+	return base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestKeyManager(t *testing.T) {
+	server, _ := newTestVault(t)
+	keyManager := vault.NewKeyManager(server.URL, testToken)
+
+	algorithmIDs := []string{dsa.AlgorithmIDED25519, dsa.AlgorithmIDSECP256R1, dsa.AlgorithmIDSECP384R1}
+	for _, algorithmID := range algorithmIDs {
+		t.Run(algorithmID, func(t *testing.T) {
+			keyID, err := keyManager.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+
+			publicKey, err := keyManager.GetPublicKey(keyID)
+			assert.NoError(t, err)
+			assert.Zero(t, publicKey.D)
+
+			thumbprint, err := publicKey.ComputeThumbprint()
+			assert.NoError(t, err)
+			assert.Equal(t, keyID, thumbprint)
+
+			keyAlgorithmID, err := dsa.AlgorithmID(&publicKey)
+			assert.NoError(t, err)
+			assert.Equal(t, algorithmID, keyAlgorithmID)
+
+			payload := []byte("hello")
+			signature, err := keyManager.Sign(keyID, payload)
+			assert.NoError(t, err)
+
+			legit, err := dsa.Verify(payload, signature, publicKey)
+			assert.NoError(t, err)
+			assert.True(t, legit, "expected signature to be valid")
+		})
+	}
+}
+
+func TestKeyManager_ExistingKeys(t *testing.T) {
+	server, keys := newTestVault(t)
+
+	keyID, err := vault.NewKeyManager(server.URL, testToken).GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	assert.NoError(t, err)
+
+	_, err = vault.NewKeyManager(server.URL, testToken).GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
+	// keys of unsupported types are skipped
+	keys.Store("encryption", &transitKey{Type: "aes256-gcm96", Versions: []crypto.Signer{generateKey(t, "aes256-gcm96")}})
+
+	// a new key manager, e.g. after a restart, finds keys by listing them
+	keyManager := vault.NewKeyManager(server.URL, testToken)
+
+	publicKey, err := keyManager.GetPublicKey(keyID)
+	assert.NoError(t, err)
+
+	payload := []byte("hello")
+	signature, err := keyManager.Sign(keyID, payload)
+	assert.NoError(t, err)
+
+	legit, err := dsa.Verify(payload, signature, publicKey)
+	assert.NoError(t, err)
+	assert.True(t, legit, "expected signature to be valid")
+
+	_, err = keyManager.GetPublicKey("unknown")
+	assert.EqualError(t, err, "key with alias unknown not found")
+}
+
+func TestKeyManager_KeyNames(t *testing.T) {
+	server, keys := newTestVault(t)
+
+	keyID, err := vault.NewKeyManager(server.URL, testToken).GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	assert.NoError(t, err)
+
+	var name string
+	keys.Range(func(key, _ any) bool {
+		name = key.(string)
+		return false
+	})
+
+	keyManager := vault.NewKeyManager(server.URL, testToken)
+
+	publicKey, err := keyManager.GetPublicKey(name)
+	assert.NoError(t, err)
+
+	thumbprint, err := publicKey.ComputeThumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, keyID, thumbprint)
+
+	signature, err := keyManager.Sign(name, []byte("hello"))
+	assert.NoError(t, err)
+
+	legit, err := dsa.Verify([]byte("hello"), signature, publicKey)
+	assert.NoError(t, err)
+	assert.True(t, legit, "expected signature to be valid")
+
+	// the name refers to the latest version after the key is rotated in Vault
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/transit/keys/"+name+"/rotate", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Vault-Token", testToken)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	rotated, err := keyManager.GetPublicKey(name)
+	assert.NoError(t, err)
+	assert.NotEqual(t, publicKey.X, rotated.X)
+
+	signature, err = keyManager.Sign(name, []byte("hello"))
+	assert.NoError(t, err)
+
+	legit, err = dsa.Verify([]byte("hello"), signature, rotated)
+	assert.NoError(t, err)
+	assert.True(t, legit, "expected signature to be valid")
+}
+
+func TestKeyManager_RotatedInVault(t *testing.T) {
+	server, keys := newTestVault(t)
+
+	keyID, err := vault.NewKeyManager(server.URL, testToken).GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
+	var name string
+	keys.Range(func(key, _ any) bool {
+		name = key.(string)
+		return false
+	})
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/transit/keys/"+name+"/rotate", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Vault-Token", testToken)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	value, _ := keys.Load(name)
+	rotated, err := dsa.BytesToPublicKey(dsa.AlgorithmIDED25519, value.(*transitKey).Versions[1].Public().(ed25519.PublicKey))
+	assert.NoError(t, err)
+
+	rotatedKeyID, err := rotated.ComputeThumbprint()
+	assert.NoError(t, err)
+
+	keyManager := vault.NewKeyManager(server.URL, testToken)
+
+	// every version can be used by its own key id
+	for _, id := range []string{keyID, rotatedKeyID} {
+		publicKey, err := keyManager.GetPublicKey(id)
+		assert.NoError(t, err)
+
+		signature, err := keyManager.Sign(id, []byte("hello"))
+		assert.NoError(t, err)
+
+		legit, err := dsa.Verify([]byte("hello"), signature, publicKey)
+		assert.NoError(t, err)
+		assert.True(t, legit, "expected signature to be valid")
+	}
+}
+
+// countingTransport counts the requests sent to Vault
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestKeyManager_UnknownKeyIDs(t *testing.T) {
+	server, keys := newTestVault(t)
+
+	for i := 0; i < 5; i++ {
+		_, err := vault.NewKeyManager(server.URL, testToken).GeneratePrivateKey(dsa.AlgorithmIDED25519)
+		assert.NoError(t, err)
+	}
+
+	transport := &countingTransport{}
+	keyManager := vault.NewKeyManager(server.URL, testToken, vault.HTTPClient(&http.Client{Transport: transport}))
+
+	// the first miss reads every key: the name lookup, the list and one read per key
+	_, err := keyManager.GetPublicKey("unknown")
+	assert.Error(t, err)
+	assert.Equal(t, int32(7), transport.requests.Load())
+
+	// further misses within the refresh interval only look the id up as a name
+	_, err = keyManager.GetPublicKey("unknown")
+	assert.Error(t, err)
+	_, err = keyManager.Sign("other", []byte("hello"))
+	assert.Error(t, err)
+	assert.Equal(t, int32(9), transport.requests.Load())
+
+	// keys created in Vault by other means are found once the interval has passed
+	created := generateKey(t, "ed25519")
+	keys.Store("created-elsewhere", &transitKey{Type: "ed25519", Versions: []crypto.Signer{created}})
+
+	createdKey, err := dsa.BytesToPublicKey(dsa.AlgorithmIDED25519, created.Public().(ed25519.PublicKey))
+	assert.NoError(t, err)
+	createdKeyID, err := createdKey.ComputeThumbprint()
+	assert.NoError(t, err)
+
+	_, err = keyManager.GetPublicKey(createdKeyID)
+	assert.Error(t, err)
+
+	refreshing := vault.NewKeyManager(server.URL, testToken, vault.RefreshInterval(0))
+	publicKey, err := refreshing.GetPublicKey(createdKeyID)
+	assert.NoError(t, err)
+	assert.Equal(t, createdKey, publicKey)
+}
+
+func TestKeyManager_Errors(t *testing.T) {
+	server, _ := newTestVault(t)
+
+	_, err := vault.NewKeyManager(server.URL, testToken).GeneratePrivateKey(dsa.AlgorithmIDSECP256K1)
+	assert.EqualError(t, err, "unsupported algorithm: secp256k1")
+
+	_, err = vault.NewKeyManager(server.URL, "wrong").GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+
+	_, err = vault.NewKeyManager(server.URL, testToken).GetPublicKey("unknown")
+	assert.EqualError(t, err, "key with alias unknown not found")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = vault.NewKeyManager(server.URL, testToken).GeneratePrivateKeyWithContext(ctx, dsa.AlgorithmIDED25519)
+	assert.IsError(t, err, context.Canceled)
+}
+
+func TestKeyManager_Options(t *testing.T) {
+	var path, namespace string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, namespace = r.URL.Path, r.Header.Get("X-Vault-Namespace")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"errors":["Vault is sealed"]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	keyManager := vault.NewKeyManager(server.URL+"/", testToken, vault.Mount("/secrets/transit/"), vault.Namespace("team"))
+
+	_, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.EqualError(t, err, "failed to create key: vault returned 503 Service Unavailable: Vault is sealed")
+	assert.True(t, strings.HasPrefix(path, "/v1/secrets/transit/keys/web5-"), "unexpected path %s", path)
+	assert.Equal(t, "team", namespace)
+}
+
+func TestKeyManager_BearerDID(t *testing.T) {
+	server, _ := newTestVault(t)
+
+	var keyManager web5crypto.KeyManager = vault.NewKeyManager(server.URL, testToken)
+	_, ok := keyManager.(web5crypto.KeyManagerWithContext)
+	assert.True(t, ok, "expected key manager to be context aware")
+
+	bearerDID, err := didjwk.Create(didjwk.KeyManager(keyManager), didjwk.AlgorithmID(dsa.AlgorithmIDSECP256R1))
+	assert.NoError(t, err)
+
+	compactJWS, err := jws.Sign([]byte("hello"), bearerDID)
+	assert.NoError(t, err)
+
+	decoded, err := jws.Verify(compactJWS)
+	assert.NoError(t, err)
+	assert.Equal(t, "ES256", decoded.Header.ALG)
+}
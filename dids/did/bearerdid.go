@@ -1,6 +1,8 @@
 package did
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/decentralized-identity/web5-go/crypto"
@@ -15,13 +17,20 @@ type BearerDID struct {
 	DID
 	crypto.KeyManager
 	Document didcore.Document
+	// KeyAliases maps verification method IDs to the aliases of their keys in the KeyManager. Verification
+	// methods without an entry use the thumbprint of their public key, which is the alias key managers
+	// assign by default. Set it for key managers where keys have their own IDs, e.g. KMS key ARNs.
+	KeyAliases map[string]string
 }
 
 // DIDSigner is a function returned by GetSigner that can be used to sign a payload with a key
 // associated to a BearerDID.
 type DIDSigner func(payload []byte) ([]byte, error)
 
-// ToPortableDID exports a BearerDID to a portable format
+// ToPortableDID exports a BearerDID to a portable format. The private keys of the verification methods are
+// exported if the KeyManager implements [crypto.KeyExporter]. Keys that can't be exported are referenced
+// by their alias instead, so that the BearerDID can be inflated against the same KeyManager with
+// [FromPortableDID] and [KeyManager].
 func (d *BearerDID) ToPortableDID() (PortableDID, error) {
 	portableDID := PortableDID{
 		URI:      d.URI,
@@ -30,29 +39,52 @@ func (d *BearerDID) ToPortableDID() (PortableDID, error) {
 
 	exporter, ok := d.KeyManager.(crypto.KeyExporter)
 	if ok {
-		privateKeys := make([]jwk.JWK, 0)
+		portableDID.PrivateKeys = make([]jwk.JWK, 0)
+	}
 
-		for _, vm := range d.Document.VerificationMethod {
-			keyAlias, err := vm.PublicKeyJwk.ComputeThumbprint()
-			if err != nil {
-				continue
-			}
+	for _, vm := range d.Document.VerificationMethod {
+		keyAlias, err := d.KeyAlias(vm)
+		if err != nil {
+			continue
+		}
 
+		if exporter != nil {
 			key, err := exporter.ExportKey(keyAlias)
-			if err != nil {
-				// TODO: decide if we want to blow up or continue
+			if err == nil {
+				portableDID.PrivateKeys = append(portableDID.PrivateKeys, key)
 				continue
 			}
+		}
 
-			privateKeys = append(privateKeys, key)
+		if portableDID.KeyAliases == nil {
+			portableDID.KeyAliases = make(map[string]string)
 		}
 
-		portableDID.PrivateKeys = privateKeys
+		portableDID.KeyAliases[vm.ID] = keyAlias
 	}
 
 	return portableDID, nil
 }
 
+// KeyAlias returns the alias of the key of the given verification method in the KeyManager: the entry of
+// the verification method in KeyAliases if there is one, or else the thumbprint of its public key.
+func (d *BearerDID) KeyAlias(vm didcore.VerificationMethod) (string, error) {
+	if vm.ID != "" {
+		vmID := d.Document.GetAbsoluteResourceID(vm.ID)
+		for id, alias := range d.KeyAliases {
+			if id != "" && d.Document.GetAbsoluteResourceID(id) == vmID {
+				return alias, nil
+			}
+		}
+	}
+
+	if vm.PublicKeyJwk == nil {
+		return "", fmt.Errorf("verification method %s has no public key", vm.ID)
+	}
+
+	return vm.PublicKeyJwk.ComputeThumbprint()
+}
+
 // GetSigner returns a sign method that can be used to sign a payload using a key associated to the DID.
 // This function also returns the verification method needed to verify the signature.
 //
@@ -72,36 +104,128 @@ func (d *BearerDID) ToPortableDID() (PortableDID, error) {
 //
 // The returned signer is a function that takes a byte payload and returns a byte signature.
 func (d *BearerDID) GetSigner(selector didcore.VMSelector) (DIDSigner, didcore.VerificationMethod, error) {
+	return d.GetSignerWithContext(context.Background(), selector)
+}
+
+// GetSignerWithContext is like [BearerDID.GetSigner], but the returned signer signs with the given context,
+// so that signing with a remote key manager honors its deadline and cancellation. See
+// [crypto.KeyManagerWithContext].
+func (d *BearerDID) GetSignerWithContext(ctx context.Context, selector didcore.VMSelector) (DIDSigner, didcore.VerificationMethod, error) {
 	vm, err := d.Document.SelectVerificationMethod(selector)
 	if err != nil {
 		return nil, didcore.VerificationMethod{}, err
 	}
 
-	keyAlias, err := vm.PublicKeyJwk.ComputeThumbprint()
+	keyAlias, err := d.KeyAlias(vm)
 	if err != nil {
 		return nil, didcore.VerificationMethod{}, fmt.Errorf("failed to compute key alias: %s", err.Error())
 	}
 
+	keyManager := crypto.WithContext(d.KeyManager)
 	signer := func(payload []byte) ([]byte, error) {
-		return d.Sign(keyAlias, payload)
+		return keyManager.SignWithContext(ctx, keyAlias, payload)
+	}
+
+	return signer, vm, nil
+}
+
+// GetCryptoSigner returns a [crypto.Signer] for the key of the selected verification method, so that it
+// can be used with APIs of the standard library like TLS and x509. The verification method is selected as
+// with [BearerDID.GetSigner]. See [crypto.Signer] for how messages and digests are signed.
+func (d *BearerDID) GetCryptoSigner(selector didcore.VMSelector) (*crypto.Signer, didcore.VerificationMethod, error) {
+	vm, err := d.Document.SelectVerificationMethod(selector)
+	if err != nil {
+		return nil, didcore.VerificationMethod{}, err
+	}
+
+	keyAlias, err := d.KeyAlias(vm)
+	if err != nil {
+		return nil, didcore.VerificationMethod{}, fmt.Errorf("failed to compute key alias: %w", err)
+	}
+
+	signer, err := crypto.NewSigner(d.KeyManager, keyAlias)
+	if err != nil {
+		return nil, didcore.VerificationMethod{}, err
 	}
 
 	return signer, vm, nil
 }
 
+// options that FromPortableDID can take
+type portableDIDOpts struct {
+	keyManager crypto.KeyManager
+}
+
+// PortableDIDOpt is a type that represents an option that can be passed to [FromPortableDID].
+type PortableDIDOpt func(opts *portableDIDOpts)
+
+// KeyManager is an option that can be passed to [FromPortableDID]. It sets the KeyManager of the BearerDID,
+// which holds the keys referenced by the PortableDID, and into which its private keys are imported. The
+// KeyManager must implement [crypto.KeyImporter] if the PortableDID contains private keys. Defaults to a
+// new [crypto.LocalKeyManager].
+func KeyManager(keyManager crypto.KeyManager) PortableDIDOpt {
+	return func(opts *portableDIDOpts) {
+		opts.keyManager = keyManager
+	}
+}
+
 // FromPortableDID inflates a BearerDID from a portable format.
-func FromPortableDID(portableDID PortableDID) (BearerDID, error) {
+func FromPortableDID(portableDID PortableDID, opts ...PortableDIDOpt) (BearerDID, error) {
+	o := portableDIDOpts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	did, err := Parse(portableDID.URI)
 	if err != nil {
 		return BearerDID{}, err
 	}
 
-	keyManager := crypto.NewLocalKeyManager()
-	for _, key := range portableDID.PrivateKeys {
-		_, err := keyManager.ImportKey(key)
-		if err != nil {
-			// todo what should we do here?
-			return BearerDID{}, err
+	keyManager := o.keyManager
+	if keyManager == nil {
+		keyManager = crypto.NewLocalKeyManager()
+	}
+
+	var keyAliases map[string]string
+	if len(portableDID.KeyAliases) > 0 {
+		keyAliases = make(map[string]string, len(portableDID.KeyAliases))
+		for id, alias := range portableDID.KeyAliases {
+			keyAliases[id] = alias
+		}
+	}
+
+	if len(portableDID.PrivateKeys) > 0 {
+		importer, ok := keyManager.(crypto.KeyImporter)
+		if !ok {
+			return BearerDID{}, errors.New("key manager does not support importing keys")
+		}
+
+		for _, key := range portableDID.PrivateKeys {
+			keyAlias, err := importer.ImportKey(key)
+			if err != nil {
+				// todo what should we do here?
+				return BearerDID{}, err
+			}
+
+			// key managers that don't use thumbprints as aliases need an explicit mapping
+			thumbprint, err := key.ComputeThumbprint()
+			if err != nil || thumbprint == keyAlias {
+				continue
+			}
+
+			for _, vm := range portableDID.Document.VerificationMethod {
+				if vm.PublicKeyJwk == nil {
+					continue
+				}
+
+				if vmThumbprint, err := vm.PublicKeyJwk.ComputeThumbprint(); err == nil && vmThumbprint == thumbprint {
+					if keyAliases == nil {
+						keyAliases = make(map[string]string)
+					}
+
+					keyAliases[vm.ID] = keyAlias
+				}
+			}
 		}
 	}
 
@@ -109,5 +233,6 @@ func FromPortableDID(portableDID PortableDID) (BearerDID, error) {
 		DID:        did,
 		KeyManager: keyManager,
 		Document:   portableDID.Document,
+		KeyAliases: keyAliases,
 	}, nil
 }
@@ -1,9 +1,15 @@
 package did_test
 
 import (
+	"context"
+	_crypto "crypto"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto"
 	"github.com/decentralized-identity/web5-go/crypto/dsa"
 	"github.com/decentralized-identity/web5-go/dids/did"
 	"github.com/decentralized-identity/web5-go/dids/didcore"
@@ -66,3 +72,199 @@ func TestGetSigner(t *testing.T) {
 
 	assert.True(t, legit, "expected signature to be valid")
 }
+
+func TestGetCryptoSigner(t *testing.T) {
+	bearerDID, err := didjwk.Create()
+	assert.NoError(t, err)
+
+	signer, vm, err := bearerDID.GetCryptoSigner(nil)
+	assert.NoError(t, err)
+
+	publicKey, err := crypto.JWKToPublicKey(*vm.PublicKeyJwk)
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, signer.Public())
+
+	payload := []byte("hi")
+	signature, err := signer.Sign(nil, payload, _crypto.Hash(0))
+	assert.NoError(t, err)
+
+	legit, err := dsa.Verify(payload, signature, *vm.PublicKeyJwk)
+	assert.NoError(t, err)
+	assert.True(t, legit, "expected signature to be valid")
+}
+
+func TestGetSignerWithContext_Canceled(t *testing.T) {
+	bearerDID, err := didjwk.Create()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sign, _, err := bearerDID.GetSignerWithContext(ctx, nil)
+	assert.NoError(t, err)
+
+	_, err = sign([]byte("hi"))
+	assert.IsError(t, err, context.Canceled)
+}
+
+// namedKeyManager is a key manager that assigns its own names to keys, like a remote KMS, and doesn't export them
+type namedKeyManager struct {
+	keyManager *crypto.LocalKeyManager
+	names      map[string]string
+}
+
+func newNamedKeyManager() *namedKeyManager {
+	return &namedKeyManager{keyManager: crypto.NewLocalKeyManager(), names: map[string]string{}}
+}
+
+func (k *namedKeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	keyID, err := k.keyManager.GeneratePrivateKey(algorithmID)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("key-%d", len(k.names)+1)
+	k.names[name] = keyID
+	return name, nil
+}
+
+func (k *namedKeyManager) GetPublicKey(name string) (jwk.JWK, error) {
+	keyID, ok := k.names[name]
+	if !ok {
+		return jwk.JWK{}, fmt.Errorf("key %s not found", name)
+	}
+
+	return k.keyManager.GetPublicKey(keyID)
+}
+
+func (k *namedKeyManager) Sign(name string, payload []byte) ([]byte, error) {
+	keyID, ok := k.names[name]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", name)
+	}
+
+	return k.keyManager.Sign(keyID, payload)
+}
+
+// newNamedDID returns a did:jwk BearerDID whose key is held by a namedKeyManager under the name key-1
+func newNamedDID(t *testing.T) (did.BearerDID, *namedKeyManager) {
+	t.Helper()
+
+	keyManager := newNamedKeyManager()
+	bearerDID, err := didjwk.Create(didjwk.KeyManager(keyManager.keyManager))
+	assert.NoError(t, err)
+
+	vm := bearerDID.Document.VerificationMethod[0]
+	keyID, err := vm.PublicKeyJwk.ComputeThumbprint()
+	assert.NoError(t, err)
+	keyManager.names["key-1"] = keyID
+
+	bearerDID.KeyManager = keyManager
+	bearerDID.KeyAliases = map[string]string{vm.ID: "key-1"}
+
+	return bearerDID, keyManager
+}
+
+func TestKeyAliases(t *testing.T) {
+	bearerDID, _ := newNamedDID(t)
+	vm := bearerDID.Document.VerificationMethod[0]
+
+	keyAlias, err := bearerDID.KeyAlias(vm)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", keyAlias)
+
+	// relative verification method IDs match too
+	vm.ID = vm.ID[strings.Index(vm.ID, "#"):]
+	keyAlias, err = bearerDID.KeyAlias(vm)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", keyAlias)
+
+	sign, vm, err := bearerDID.GetSigner(nil)
+	assert.NoError(t, err)
+
+	signature, err := sign([]byte("hi"))
+	assert.NoError(t, err)
+
+	legit, err := dsa.Verify([]byte("hi"), signature, *vm.PublicKeyJwk)
+	assert.NoError(t, err)
+	assert.True(t, legit, "expected signature to be valid")
+
+	// without the mapping, the thumbprint is used, which the key manager doesn't know
+	bearerDID.KeyAliases = nil
+	sign, _, err = bearerDID.GetSigner(nil)
+	assert.NoError(t, err)
+
+	_, err = sign([]byte("hi"))
+	assert.Error(t, err)
+}
+
+func TestPortableDID_KeyAliases(t *testing.T) {
+	bearerDID, keyManager := newNamedDID(t)
+
+	portableDID, err := bearerDID.ToPortableDID()
+	assert.NoError(t, err)
+	assert.Zero(t, portableDID.PrivateKeys)
+	assert.Equal(t, map[string]string{bearerDID.Document.VerificationMethod[0].ID: "key-1"}, portableDID.KeyAliases)
+
+	portableDIDBytes, err := json.Marshal(portableDID)
+	assert.NoError(t, err)
+
+	var decoded did.PortableDID
+	err = json.Unmarshal(portableDIDBytes, &decoded)
+	assert.NoError(t, err)
+
+	inflatedDID, err := did.FromPortableDID(decoded, did.KeyManager(keyManager))
+	assert.NoError(t, err)
+	assert.Equal(t, bearerDID.KeyAliases, inflatedDID.KeyAliases)
+
+	compactJWS, err := jws.Sign([]byte("hi"), inflatedDID)
+	assert.NoError(t, err)
+
+	_, err = jws.Verify(compactJWS)
+	assert.NoError(t, err)
+
+	// private keys can only be imported into key managers that support it
+	exportedDID, err := didjwk.Create()
+	assert.NoError(t, err)
+
+	portableDID, err = exportedDID.ToPortableDID()
+	assert.NoError(t, err)
+	assert.Zero(t, portableDID.KeyAliases)
+
+	_, err = did.FromPortableDID(portableDID, did.KeyManager(keyManager))
+	assert.EqualError(t, err, "key manager does not support importing keys")
+}
+
+// importingKeyManager is a namedKeyManager that can import keys
+type importingKeyManager struct {
+	*namedKeyManager
+}
+
+func (k importingKeyManager) ImportKey(key jwk.JWK) (string, error) {
+	keyID, err := k.keyManager.ImportKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("imported-%d", len(k.names)+1)
+	k.names[name] = keyID
+	return name, nil
+}
+
+func TestFromPortableDID_ImportedKeyAliases(t *testing.T) {
+	bearerDID, err := didjwk.Create()
+	assert.NoError(t, err)
+
+	portableDID, err := bearerDID.ToPortableDID()
+	assert.NoError(t, err)
+
+	inflatedDID, err := did.FromPortableDID(portableDID, did.KeyManager(importingKeyManager{newNamedKeyManager()}))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{bearerDID.Document.VerificationMethod[0].ID: "imported-1"}, inflatedDID.KeyAliases)
+
+	compactJWS, err := jws.Sign([]byte("hi"), inflatedDID)
+	assert.NoError(t, err)
+
+	_, err = jws.Verify(compactJWS)
+	assert.NoError(t, err)
+}
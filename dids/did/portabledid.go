@@ -14,6 +14,9 @@ type PortableDID struct {
 	// Note: PrivateKeys will be empty if the BearerDID was created using a KeyManager that does not
 	// support exporting private keys (e.g. HSM based KeyManagers)
 	PrivateKeys []jwk.JWK `json:"privateKeys"`
+	// KeyAliases maps verification method IDs to the aliases of keys that were not exported, e.g. because
+	// they are held by a remote KeyManager. See [BearerDID.KeyAliases].
+	KeyAliases map[string]string `json:"keyAliases,omitempty"`
 	// Document is the DID Document associated to the BearerDID
 	Document didcore.Document `json:"document"`
 	// Metadata is a map that can be used to store additional method specific data
@@ -253,6 +253,16 @@ type DocumentMetadata struct {
 	//   * the DID is defined to be the canonical ID for the DID subject within
 	//     the scope of the containing DID document.
 	CanonicalID string `json:"canonicalId,omitempty"`
+	// Types are the types the DID has been indexed under, as listed in the did:dht type index.
+	// Only set by did:dht resolution.
+	//
+	// Spec: https://did-dht.com/#type-indexing
+	Types []int `json:"types,omitempty"`
+	// PreviousDID is the DID that the resolved DID replaces. It is only set if the previous DID's
+	// controller authorized the link. Only set by did:dht resolution.
+	//
+	// Spec: https://did-dht.com/#previous-did
+	PreviousDID string `json:"previousDid,omitempty"`
 }
 
 // Service is used in DID documents to express ways of communicating with
@@ -13,9 +13,10 @@ import (
 	"github.com/decentralized-identity/web5-go/crypto/dsa"
 	"github.com/decentralized-identity/web5-go/dids/did"
 	"github.com/decentralized-identity/web5-go/dids/didcore"
+	"github.com/decentralized-identity/web5-go/dids/diddht/dnscodec"
 	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
-	"github.com/decentralized-identity/web5-go/dids/diddht/internal/dns"
 	"github.com/decentralized-identity/web5-go/dids/diddht/internal/pkarr"
+	"github.com/decentralized-identity/web5-go/dids/diddht/mainline"
 	"github.com/tv42/zbase32"
 )
 
@@ -48,12 +49,15 @@ type CreateOption func(*createOptions)
 // createOptions is a struct to hold options for creating a new 'did:dht' BearerDID.
 // Each option has a corresponding function that can be used by the caller to set the value of the option.
 type createOptions struct {
-	services    []didcore.Service
-	privateKeys []verificationMethodOption
-	keyManager  crypto.KeyManager
-	alsoKnownAs []string
-	controllers []string
-	gateway     gateway
+	services     []didcore.Service
+	privateKeys  []verificationMethodOption
+	keyManager   crypto.KeyManager
+	alsoKnownAs  []string
+	controllers  []string
+	gateway      gateway
+	types        []int
+	previousDID  *did.BearerDID
+	uncompressed bool
 }
 
 // verificationMethodOption is a struct to hold options for creating a new private key.
@@ -123,6 +127,33 @@ func Controllers(controllers ...string) CreateOption {
 	}
 }
 
+// Types is used to index the DID being created under the given entity types, e.g. [TypeFinancialInstitution].
+// Indexed DIDs can be looked up by type using [FetchDIDsByType].
+// more details here: https://did-dht.com/#type-indexing
+func Types(types ...int) CreateOption {
+	return func(o *createOptions) {
+		o.types = types
+	}
+}
+
+// PreviousDID is used to link the DID being created to a `did:dht` DID it replaces. The identity key of the
+// previous DID signs the new DID, proving that the controller of the previous DID authorized the link.
+// more details here: https://did-dht.com/#previous-did
+func PreviousDID(previous did.BearerDID) CreateOption {
+	return func(o *createOptions) {
+		o.previousDID = &previous
+	}
+}
+
+// DNSCompression enables or disables DNS name compression in the DNS packet the DID Document is mapped to.
+// Compression is enabled by default, and leaves more of the 1000 byte record budget for the DID Document.
+// See [EstimateSize] for how much each part of a DID Document costs.
+func DNSCompression(enabled bool) CreateOption {
+	return func(o *createOptions) {
+		o.uncompressed = !enabled
+	}
+}
+
 // Gateway sets the gateway to use for publishing the DID to the DHT.
 func Gateway(gatewayURL string, client *http.Client) CreateOption {
 	return func(o *createOptions) {
@@ -130,6 +161,20 @@ func Gateway(gatewayURL string, client *http.Client) CreateOption {
 	}
 }
 
+// Gateways sets a pool of gateways to use for publishing the DID to the DHT.
+func Gateways(pool *GatewayPool) CreateOption {
+	return func(o *createOptions) {
+		o.gateway = pool
+	}
+}
+
+// DHT sets a mainline DHT client to publish the DID directly to the DHT, without going through a Pkarr gateway.
+func DHT(client *mainline.Client) CreateOption {
+	return func(o *createOptions) {
+		o.gateway = client
+	}
+}
+
 // Create creates a new `did:dht` DID and publishes it to the DHT network via a Pkarr gateway.
 //
 // If no gateway is passed in the options, Create uses a default Pkarr gateway. (https://diddht.tbddev.org)
@@ -157,22 +202,173 @@ func CreateWithContext(ctx context.Context, opts ...CreateOption) (did.BearerDID
 		return did.BearerDID{}, errors.New("no gateway provided")
 	}
 
+	bdid, bep44Msg, err := create(ctx, o)
+	if err != nil {
+		return did.BearerDID{}, err
+	}
+
+	// 7. Submit the result of to the DHT via a Pkarr relay, or a Gateway, with the identifier created in step 1.
+	if err := o.gateway.PutWithContext(ctx, bdid.ID, bep44Msg); err != nil {
+		return did.BearerDID{}, fmt.Errorf("failed to publish bep44 message to relay: %w", err)
+	}
+
+	return bdid, nil
+}
+
+// PendingPublication is a signed `did:dht` record that has not been published to the DHT yet.
+// It is returned by [CreateUnpublished] and can be serialized, moved to a different process or
+// host, and published later with [Publish].
+type PendingPublication struct {
+	// URI is the DID the record belongs to
+	URI string `json:"uri"`
+	// Message is the signed BEP44 message, encoded as expected by a Pkarr relay
+	Message []byte `json:"message"`
+}
+
+// CreateUnpublished creates a new `did:dht` DID and signs its DNS packet without publishing it to the DHT
+// network. This allows DIDs to be created on hosts without network access, or while the gateway is
+// unavailable. The returned [PendingPublication] must be published with [Publish] before the DID
+// can be resolved. Gateway options are rejected, as the gateway is passed to [Publish].
+func CreateUnpublished(opts ...CreateOption) (did.BearerDID, PendingPublication, error) {
+	return CreateUnpublishedWithContext(context.Background(), opts...)
+}
+
+// CreateUnpublishedWithContext creates a new `did:dht` DID and signs its DNS packet without publishing it
+// to the DHT network.
+func CreateUnpublishedWithContext(ctx context.Context, opts ...CreateOption) (did.BearerDID, PendingPublication, error) {
+	o := createOptions{
+		keyManager:  crypto.NewLocalKeyManager(),
+		privateKeys: []verificationMethodOption{},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.gateway != nil {
+		return did.BearerDID{}, PendingPublication{}, errors.New("gateway options are not supported when creating an unpublished did, pass the gateway to Publish")
+	}
+
+	bdid, bep44Msg, err := create(ctx, o)
+	if err != nil {
+		return did.BearerDID{}, PendingPublication{}, err
+	}
+
+	msgBytes, err := bep44Msg.Marshal()
+	if err != nil {
+		return did.BearerDID{}, PendingPublication{}, fmt.Errorf("failed to encode bep44 message: %w", err)
+	}
+
+	return bdid, PendingPublication{URI: bdid.URI, Message: msgBytes}, nil
+}
+
+// Publish submits a [PendingPublication] produced by [CreateUnpublished] to the DHT network via a Pkarr gateway.
+//
+// If no gateway is passed in the options, Publish uses a default Pkarr gateway. (https://diddht.tbddev.org)
+func Publish(pending PendingPublication, opts ...PublishOption) error {
+	return PublishWithContext(context.Background(), pending, opts...)
+}
+
+// PublishWithContext submits a [PendingPublication] produced by [CreateUnpublished] to the DHT network
+// via a Pkarr gateway.
+func PublishWithContext(ctx context.Context, pending PendingPublication, opts ...PublishOption) error {
+	o := publishOptions{
+		gateway: getDefaultGateway(),
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.gateway == nil {
+		return errors.New("no gateway provided")
+	}
+
+	pendingDID, err := did.Parse(pending.URI)
+	if err != nil {
+		return fmt.Errorf("invalid did: %w", err)
+	}
+
+	if pendingDID.Method != "dht" {
+		return fmt.Errorf("unsupported did method: %s", pendingDID.Method)
+	}
+
+	publicKeyBytes, err := zbase32.DecodeString(pendingDID.ID)
+	if err != nil {
+		return fmt.Errorf("failed to decode identity key: %w", err)
+	}
+
+	var bep44Msg bep44.Message
+	if err := bep44.UnmarshalMessage(pending.Message, &bep44Msg); err != nil {
+		return fmt.Errorf("failed to decode bep44 message: %w", err)
+	}
+
+	// catch corrupted artifacts before the relay does
+	if err := bep44Msg.Verify(publicKeyBytes); err != nil {
+		return fmt.Errorf("failed to verify bep44 message: %w", err)
+	}
+
+	if err := o.gateway.PutWithContext(ctx, pendingDID.ID, &bep44Msg); err != nil {
+		return fmt.Errorf("failed to publish bep44 message to relay: %w", err)
+	}
+
+	return nil
+}
+
+// create generates the keys and DID Document of a new `did:dht` DID, and signs the BEP44 message
+// containing its DNS packet representation.
+func create(ctx context.Context, o createOptions) (did.BearerDID, *bep44.Message, error) {
+	bdid, keyID, marshalOpts, err := newDocument(ctx, o)
+	if err != nil {
+		return did.BearerDID{}, nil, err
+	}
+
+	// 5. Map the output DID Document to a DNS packet
+	msgBytes, err := dnscodec.MarshalDIDDocument(&bdid.Document, marshalOpts...)
+	if err != nil {
+		return did.BearerDID{}, nil, fmt.Errorf("failed to marshal did document to dns packet: %w", err)
+	}
+
+	// 6. Construct a signed BEP44 put message with the v value as a bencoded DNS packet from the prior step.
+	seq := time.Now().Unix()
+
+	publicKeyBytes, err := zbase32.DecodeString(bdid.ID)
+	if err != nil {
+		return did.BearerDID{}, nil, fmt.Errorf("failed to decode identity key: %w", err)
+	}
+
+	keyMgr := crypto.WithContext(o.keyManager)
+	signer := func(payload []byte) ([]byte, error) {
+		return keyMgr.SignWithContext(ctx, keyID, payload)
+	}
+
+	bep44Msg, err := bep44.NewMessage(msgBytes, seq, publicKeyBytes, signer)
+	if err != nil {
+		return did.BearerDID{}, nil, fmt.Errorf("failed to create signed bep44 message: %w", err)
+	}
+
+	return bdid, bep44Msg, nil
+}
+
+// newDocument generates the keys and DID Document of a new `did:dht` DID. It returns the key ID of the
+// identity key in the key manager, along with the options needed to map the document to a DNS packet.
+func newDocument(ctx context.Context, o createOptions) (did.BearerDID, string, []dnscodec.MarshalOption, error) {
 	// 1. Generate an Ed25519 keypair (identity key)
-	keyMgr := o.keyManager
+	keyMgr := crypto.WithContext(o.keyManager)
 
-	keyID, err := keyMgr.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	keyID, err := keyMgr.GeneratePrivateKeyWithContext(ctx, dsa.AlgorithmIDED25519)
 	if err != nil {
-		return did.BearerDID{}, fmt.Errorf("failed to generate private key: %w", err)
+		return did.BearerDID{}, "", nil, fmt.Errorf("failed to generate private key: %w", err)
 	}
 
-	publicKey, err := keyMgr.GetPublicKey(keyID)
+	publicKey, err := keyMgr.GetPublicKeyWithContext(ctx, keyID)
 	if err != nil {
-		return did.BearerDID{}, fmt.Errorf("failed to get public key: %w", err)
+		return did.BearerDID{}, "", nil, fmt.Errorf("failed to get public key: %w", err)
 	}
 
 	publicKeyBytes, err := dsa.PublicKeyToBytes(publicKey)
 	if err != nil {
-		return did.BearerDID{}, fmt.Errorf("failed to convert public key to bytes: %w", err)
+		return did.BearerDID{}, "", nil, fmt.Errorf("failed to convert public key to bytes: %w", err)
 	}
 
 	// 2. Encode public key in zbase32 - the identitfier
@@ -185,7 +381,7 @@ func CreateWithContext(ctx context.Context, opts ...CreateOption) (did.BearerDID
 			URI:    "did:dht:" + zbase32Encoded,
 			ID:     zbase32Encoded,
 		},
-		KeyManager: keyMgr,
+		KeyManager: o.keyManager,
 	}
 
 	document := didcore.Document{
@@ -203,6 +399,12 @@ func CreateWithContext(ctx context.Context, opts ...CreateOption) (did.BearerDID
 		PublicKeyJwk: &publicKey,
 	}
 
+	identityThumbprint, err := publicKey.ComputeThumbprint()
+	if err != nil {
+		return did.BearerDID{}, "", nil, fmt.Errorf("failed to compute thumbprint: %w", err)
+	}
+	addKeyAlias(&bdid, identifierVM.ID, keyID, identityThumbprint)
+
 	document.AddVerificationMethod(identifierVM, didcore.Purposes(
 		didcore.PurposeAssertion,
 		didcore.PurposeAuthentication,
@@ -213,19 +415,14 @@ func CreateWithContext(ctx context.Context, opts ...CreateOption) (did.BearerDID
 	// create verification methods for each private key
 	for _, pk := range o.privateKeys {
 		// create private keys for the verification methods
-		vmKeyID, err := keyMgr.GeneratePrivateKey(pk.algorithmID)
+		vmKeyID, err := keyMgr.GeneratePrivateKeyWithContext(ctx, pk.algorithmID)
 		if err != nil {
-			return did.BearerDID{}, fmt.Errorf("failed to generate private key for verification method: %w", err)
+			return did.BearerDID{}, "", nil, fmt.Errorf("failed to generate private key for verification method: %w", err)
 		}
 
-		vmPublicKey, err := keyMgr.GetPublicKey(vmKeyID)
+		vmPublicKey, err := keyMgr.GetPublicKeyWithContext(ctx, vmKeyID)
 		if err != nil {
-			return did.BearerDID{}, fmt.Errorf("failed to get public key for verification method: %w", err)
-		}
-
-		vmPublicKeyBytes, err := dsa.PublicKeyToBytes(vmPublicKey)
-		if err != nil {
-			return did.BearerDID{}, fmt.Errorf("failed to convert public key to bytes for verification method: %w", err)
+			return did.BearerDID{}, "", nil, fmt.Errorf("failed to get public key for verification method: %w", err)
 		}
 
 		controller := func() string {
@@ -236,14 +433,21 @@ func CreateWithContext(ctx context.Context, opts ...CreateOption) (did.BearerDID
 			return bdid.ID
 		}()
 
-		vmZbase32Encoded := zbase32.EncodeToString(vmPublicKeyBytes)
+		// key manager aliases, e.g. KMS key ARNs, aren't valid DID URL fragments, the thumbprint always is
+		thumbprint, err := vmPublicKey.ComputeThumbprint()
+		if err != nil {
+			return did.BearerDID{}, "", nil, fmt.Errorf("failed to compute thumbprint for verification method: %w", err)
+		}
+
 		newVM := didcore.VerificationMethod{
-			ID:           "did:dht:" + vmZbase32Encoded + "#" + vmKeyID,
+			ID:           bdid.URI + "#" + thumbprint,
 			Type:         "JsonWebKey",
 			Controller:   controller,
 			PublicKeyJwk: &vmPublicKey,
 		}
 
+		addKeyAlias(&bdid, newVM.ID, vmKeyID, thumbprint)
+
 		document.AddVerificationMethod(newVM, didcore.Purposes(pk.purposes...))
 	}
 
@@ -251,29 +455,237 @@ func CreateWithContext(ctx context.Context, opts ...CreateOption) (did.BearerDID
 		document.AddService(service)
 	}
 
-	// 5. Map the output DID Document to a DNS packet
-	msgBytes, err := dns.MarshalDIDDocument(&document)
+	var marshalOpts []dnscodec.MarshalOption
+	if len(o.types) > 0 {
+		marshalOpts = append(marshalOpts, dnscodec.Types(o.types...))
+	}
+
+	if o.previousDID != nil {
+		previous, err := signPreviousDID(ctx, *o.previousDID, bdid.URI)
+		if err != nil {
+			return did.BearerDID{}, "", nil, err
+		}
+		marshalOpts = append(marshalOpts, dnscodec.Previous(previous))
+	}
+
+	if o.uncompressed {
+		marshalOpts = append(marshalOpts, dnscodec.Compression(false))
+	}
+
+	bdid.Document = document
+	return bdid, keyID, marshalOpts, nil
+}
+
+// addKeyAlias maps a verification method to the alias of its key in KeyAliases, unless the alias is the
+// thumbprint of the key, which BearerDID falls back to
+func addKeyAlias(bdid *did.BearerDID, vmID string, keyAlias string, thumbprint string) {
+	if keyAlias == thumbprint {
+		return
+	}
+
+	if bdid.KeyAliases == nil {
+		bdid.KeyAliases = make(map[string]string)
+	}
+
+	bdid.KeyAliases[vmID] = keyAlias
+}
+
+// PublishOption is the type returned from each individual option function accepted by [Update],
+// [Republish] and [Deactivate]
+type PublishOption func(*publishOptions)
+
+// publishOptions is a struct to hold options for publishing a new version of an existing 'did:dht' BearerDID.
+type publishOptions struct {
+	gateway gateway
+}
+
+// PublishGateway sets the gateway to use for publishing the new version of the DID to the DHT.
+func PublishGateway(gatewayURL string, client *http.Client) PublishOption {
+	return func(o *publishOptions) {
+		o.gateway = pkarr.NewClient(gatewayURL, client)
+	}
+}
+
+// PublishGateways sets a pool of gateways to use for publishing the new version of the DID to the DHT.
+func PublishGateways(pool *GatewayPool) PublishOption {
+	return func(o *publishOptions) {
+		o.gateway = pool
+	}
+}
+
+// PublishDHT sets a mainline DHT client to publish the new version of the DID directly to the DHT.
+func PublishDHT(client *mainline.Client) PublishOption {
+	return func(o *publishOptions) {
+		o.gateway = client
+	}
+}
+
+// Update replaces the DID Document of an existing `did:dht` BearerDID with the document provided and
+// publishes it to the DHT network via a Pkarr gateway. Any key referenced by a verification method of the
+// new document must already be present in the BearerDID's KeyManager.
+//
+// Spec: https://did-dht.com/#update
+func Update(bearerDID did.BearerDID, document didcore.Document, opts ...PublishOption) (did.BearerDID, error) {
+	return UpdateWithContext(context.Background(), bearerDID, document, opts...)
+}
+
+// UpdateWithContext replaces the DID Document of an existing `did:dht` BearerDID with the document provided
+// and publishes it to the DHT network via a Pkarr gateway.
+func UpdateWithContext(ctx context.Context, bearerDID did.BearerDID, document didcore.Document, opts ...PublishOption) (did.BearerDID, error) {
+	if document.ID != bearerDID.URI {
+		return did.BearerDID{}, fmt.Errorf("document id %s does not match did %s", document.ID, bearerDID.URI)
+	}
+
+	encode := func(current dnscodec.Properties) ([]byte, error) {
+		return dnscodec.MarshalDIDDocument(&document, marshalOptions(current)...)
+	}
+
+	if err := publish(ctx, bearerDID, encode, opts...); err != nil {
+		return did.BearerDID{}, err
+	}
+
+	bearerDID.Document = document
+	return bearerDID, nil
+}
+
+// Republish publishes the current DID Document of an existing `did:dht` BearerDID to the DHT network
+// again. DHT nodes drop records that are not refreshed, so Republish should be called periodically
+// (every ~2 hours) to keep a DID resolvable.
+//
+// Spec: https://did-dht.com/#republishing-data
+func Republish(bearerDID did.BearerDID, opts ...PublishOption) error {
+	return RepublishWithContext(context.Background(), bearerDID, opts...)
+}
+
+// RepublishWithContext publishes the current DID Document of an existing `did:dht` BearerDID to the DHT
+// network again.
+func RepublishWithContext(ctx context.Context, bearerDID did.BearerDID, opts ...PublishOption) error {
+	encode := func(current dnscodec.Properties) ([]byte, error) {
+		return dnscodec.MarshalDIDDocument(&bearerDID.Document, marshalOptions(current)...)
+	}
+
+	return publish(ctx, bearerDID, encode, opts...)
+}
+
+// Deactivate publishes the tombstone form of the DID Document of an existing `did:dht` BearerDID.
+// Resolving a deactivated DID returns a document without verification methods and
+// [didcore.DocumentMetadata].Deactivated set to true.
+//
+// Spec: https://did-dht.com/#deactivate
+func Deactivate(bearerDID did.BearerDID, opts ...PublishOption) error {
+	return DeactivateWithContext(context.Background(), bearerDID, opts...)
+}
+
+// DeactivateWithContext publishes the tombstone form of the DID Document of an existing `did:dht` BearerDID.
+func DeactivateWithContext(ctx context.Context, bearerDID did.BearerDID, opts ...PublishOption) error {
+	encode := func(dnscodec.Properties) ([]byte, error) {
+		return dnscodec.MarshalDeactivatedDIDDocument(bearerDID.URI)
+	}
+
+	return publish(ctx, bearerDID, encode, opts...)
+}
+
+// publish signs the DNS packet returned by encode with the identity key of the BearerDID using a sequence
+// number higher than that of the currently published record, and submits it to the DHT via a Pkarr gateway.
+// encode receives the did:dht properties of the currently published record, so that they can be carried over.
+func publish(ctx context.Context, bearerDID did.BearerDID, encode func(current dnscodec.Properties) ([]byte, error), opts ...PublishOption) error {
+	o := publishOptions{
+		gateway: getDefaultGateway(),
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.gateway == nil {
+		return errors.New("no gateway provided")
+	}
+
+	if bearerDID.Method != "dht" {
+		return fmt.Errorf("unsupported did method: %s", bearerDID.Method)
+	}
+
+	publicKeyBytes, err := zbase32.DecodeString(bearerDID.ID)
 	if err != nil {
-		return did.BearerDID{}, fmt.Errorf("failed to marshal did document to dns packet: %w", err)
+		return fmt.Errorf("failed to decode identity key: %w", err)
 	}
 
-	// 6. Construct a signed BEP44 put message with the v value as a bencoded DNS packet from the prior step.
-	seq := time.Now().Unix() / 1000
+	// the identity key is always the #0 verification method
+	signer, _, err := bearerDID.GetSignerWithContext(ctx, didcore.ID(bearerDID.URI+"#0"))
+	if err != nil {
+		return fmt.Errorf("failed to get identity key signer: %w", err)
+	}
 
-	signer := func(payload []byte) ([]byte, error) {
-		return keyMgr.Sign(keyID, payload)
+	// DHT nodes only accept a record if its seq is higher than the one they already store. Only a missing
+	// record is safe to ignore: publishing without the current record could lower seq or drop its properties.
+	seq := time.Now().Unix()
+	var currentProps dnscodec.Properties
+	current, err := o.gateway.FetchWithContext(ctx, bearerDID.ID)
+	switch {
+	case errors.Is(err, pkarr.ErrNotFound):
+	case err != nil:
+		return fmt.Errorf("failed to fetch current record: %w", err)
+	default:
+		if err := current.Verify(publicKeyBytes); err != nil {
+			return fmt.Errorf("failed to verify current record: %w", err)
+		}
+
+		if current.Seq >= seq {
+			seq = current.Seq + 1
+		}
+
+		_, currentProps, err = dnscodec.UnmarshalDIDDocument(current.V)
+		if err != nil {
+			return fmt.Errorf("failed to parse current record: %w", err)
+		}
 	}
 
-	bep44Msg, err := bep44.NewMessage(msgBytes, seq, publicKeyBytes, signer)
+	msgBytes, err := encode(currentProps)
 	if err != nil {
-		return did.BearerDID{}, fmt.Errorf("failed to create signed bep44 message: %w", err)
+		return fmt.Errorf("failed to marshal did document to dns packet: %w", err)
 	}
 
-	// 7. Submit the result of to the DHT via a Pkarr relay, or a Gateway, with the identifier created in step 1.
-	if err := o.gateway.PutWithContext(ctx, bdid.ID, bep44Msg); err != nil {
-		return did.BearerDID{}, fmt.Errorf("failed to punlish bep44 message to relay: %w", err)
+	bep44Msg, err := bep44.NewMessage(msgBytes, seq, publicKeyBytes, bep44.Signer(signer))
+	if err != nil {
+		return fmt.Errorf("failed to create signed bep44 message: %w", err)
 	}
 
-	bdid.Document = document
-	return bdid, nil
+	if err := o.gateway.PutWithContext(ctx, bearerDID.ID, bep44Msg); err != nil {
+		return fmt.Errorf("failed to publish bep44 message to relay: %w", err)
+	}
+
+	return nil
+}
+
+// marshalOptions returns the options needed to encode the given did:dht properties
+func marshalOptions(props dnscodec.Properties) []dnscodec.MarshalOption {
+	var opts []dnscodec.MarshalOption
+	if len(props.Types) > 0 {
+		opts = append(opts, dnscodec.Types(props.Types...))
+	}
+
+	if props.PreviousDID != nil {
+		opts = append(opts, dnscodec.Previous(*props.PreviousDID))
+	}
+
+	return opts
+}
+
+// signPreviousDID signs the URI of a new DID with the identity key of the previous DID
+func signPreviousDID(ctx context.Context, previous did.BearerDID, didURI string) (dnscodec.PreviousDID, error) {
+	if previous.Method != "dht" {
+		return dnscodec.PreviousDID{}, fmt.Errorf("previous did must be a did:dht, got: %s", previous.Method)
+	}
+
+	signer, _, err := previous.GetSignerWithContext(ctx, didcore.ID(previous.URI+"#0"))
+	if err != nil {
+		return dnscodec.PreviousDID{}, fmt.Errorf("failed to get previous did identity key signer: %w", err)
+	}
+
+	signature, err := signer([]byte(didURI))
+	if err != nil {
+		return dnscodec.PreviousDID{}, fmt.Errorf("failed to sign previous did link: %w", err)
+	}
+
+	return dnscodec.PreviousDID{DID: previous.URI, Signature: signature}, nil
 }
@@ -1,6 +1,7 @@
 package diddht
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -8,15 +9,25 @@ import (
 	"github.com/decentralized-identity/web5-go/dids/did"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"io"
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/decentralized-identity/web5-go/crypto"
 	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
 	"github.com/decentralized-identity/web5-go/dids/didcore"
+	"github.com/decentralized-identity/web5-go/dids/diddht/dnscodec"
 	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+	"github.com/decentralized-identity/web5-go/dids/diddht/mainline"
+	"github.com/decentralized-identity/web5-go/jwk"
+	"github.com/tv42/zbase32"
 	"golang.org/x/net/dns/dnsmessage"
 )
 
@@ -64,15 +75,25 @@ func TestDHTResolve(t *testing.T) {
 	privateKeyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDED25519)
 	assert.NoError(t, err)
 
+	publicKey, err := keyManager.GetPublicKey(privateKeyID)
+	assert.NoError(t, err)
+	publicKeyBytes, err := dsa.PublicKeyToBytes(publicKey)
+	assert.NoError(t, err)
+	didURI := "did:dht:" + zbase32.EncodeToString(publicKeyBytes)
+
+	otherKeyID, err := keyManager.GeneratePrivateKey(dsa.AlgorithmIDED25519)
+	assert.NoError(t, err)
+
 	tests := map[string]struct {
 		didURI               string
 		msg                  dnsmessage.Message
 		expectedErrorMessage string
 		assertResult         func(t *testing.T, d *didcore.Document)
 		signer               bep44.Signer
+		tamper               func(body []byte) []byte
 	}{
 		"did with valid key and no service": {
-			didURI: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+			didURI: didURI,
 			msg: makeDNSMessage(
 				WithDNSRecord("_did.", "vm=k0;auth=k0;asm=k0;inv=k0;del=k0"),
 				WithDNSRecord("_k0._did.", "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"),
@@ -90,7 +111,7 @@ func TestDHTResolve(t *testing.T) {
 			},
 		},
 		"did with multiple valid keys and no service - out of order verification methods": {
-			didURI: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+			didURI: didURI,
 			msg: makeDNSMessage(
 				WithDNSRecord("_did.", "vm=k0,k1,k2;auth=k0;asm=k1;inv=k2;del=k0"),
 				WithDNSRecord("_k0._did.", "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"),
@@ -111,7 +132,7 @@ func TestDHTResolve(t *testing.T) {
 			},
 		},
 		"did with key controller and services": {
-			didURI: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+			didURI: didURI,
 			msg: makeDNSMessage(
 				WithDNSRecord("_did.", "vm=k0;auth=k0;asm=k0;inv=k0;del=k0;srv=s0,s1"),
 				WithDNSRecord("_k0._did.", "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"),
@@ -131,6 +152,77 @@ func TestDHTResolve(t *testing.T) {
 				return keyManager.Sign(privateKeyID, payload)
 			},
 		},
+		"record signed by a key other than the identity key": {
+			didURI: didURI,
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;auth=k0;asm=k0;inv=k0;del=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"),
+			),
+			expectedErrorMessage: ErrorCodeInvalidSignature,
+			assertResult: func(t *testing.T, d *didcore.Document) {
+				t.Helper()
+				assert.Zero(t, d.ID, "Expected no document")
+			},
+			signer: func(payload []byte) ([]byte, error) {
+				return keyManager.Sign(otherKeyID, payload)
+			},
+		},
+		"tampered record": {
+			didURI: didURI,
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;auth=k0;asm=k0;inv=k0;del=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"),
+			),
+			expectedErrorMessage: ErrorCodeInvalidSignature,
+			assertResult: func(t *testing.T, d *didcore.Document) {
+				t.Helper()
+				assert.Zero(t, d.ID, "Expected no document")
+			},
+			signer: func(payload []byte) ([]byte, error) {
+				return keyManager.Sign(privateKeyID, payload)
+			},
+			tamper: func(body []byte) []byte {
+				// bump the sequence number without re-signing
+				body[71]++
+				return body
+			},
+		},
+		"truncated record": {
+			didURI: didURI,
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;auth=k0;asm=k0;inv=k0;del=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"),
+			),
+			expectedErrorMessage: ErrorCodeInvalidSignature,
+			assertResult: func(t *testing.T, d *didcore.Document) {
+				t.Helper()
+				assert.Zero(t, d.ID, "Expected no document")
+			},
+			signer: func(payload []byte) ([]byte, error) {
+				return keyManager.Sign(privateKeyID, payload)
+			},
+			tamper: func(body []byte) []byte {
+				return body[:len(body)-10]
+			},
+		},
+		"record truncated below the minimum message size": {
+			didURI: didURI,
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;auth=k0;asm=k0;inv=k0;del=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"),
+			),
+			expectedErrorMessage: ErrorCodeInvalidSignature,
+			assertResult: func(t *testing.T, d *didcore.Document) {
+				t.Helper()
+				assert.Zero(t, d.ID, "Expected no document")
+			},
+			signer: func(payload []byte) ([]byte, error) {
+				return keyManager.Sign(privateKeyID, payload)
+			},
+			tamper: func(body []byte) []byte {
+				return body[:64]
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -139,15 +231,14 @@ func TestDHTResolve(t *testing.T) {
 			assert.NoError(t, err)
 			// test setup
 			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				pkey, err := keyManager.GetPublicKey(privateKeyID)
-				assert.NoError(t, err)
-				publicKeyBytes, err := dsa.PublicKeyToBytes(pkey)
-				assert.NoError(t, err)
 				// create signed bep44 message
-				msg, err := bep44.NewMessage(buf, 0, publicKeyBytes, test.signer)
+				msg, err := bep44.NewMessage(buf, 1, publicKeyBytes, test.signer)
 				assert.NoError(t, err)
 
 				body, _ := msg.Marshal()
+				if test.tamper != nil {
+					body = test.tamper(body)
+				}
 
 				// send signed bep44 message
 				_, err = w.Write(body)
@@ -158,7 +249,13 @@ func TestDHTResolve(t *testing.T) {
 			r := NewResolver(ts.URL, http.DefaultClient)
 			result, err := r.Resolve(test.didURI)
 
-			assert.EqualError(t, err, test.expectedErrorMessage)
+			if test.expectedErrorMessage != "" {
+				assert.EqualError(t, err, test.expectedErrorMessage)
+				assert.Equal(t, test.expectedErrorMessage, result.GetError())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "1", result.DocumentMetadata.VersionID)
+			}
 
 			test.assertResult(t, &result.Document)
 
@@ -293,3 +390,528 @@ func TestCreate(t *testing.T) {
 		})
 	}
 }
+
+// newTestRelay starts a fake relay that stores bep44 messages on publish and returns them on resolve
+func newTestRelay(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	records := map[string][]byte{}
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[1:]
+		defer r.Body.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodPut {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			records[id] = body
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		body, ok := records[id]
+		if !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		_, err := w.Write(body)
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(relay.Close)
+
+	return relay
+}
+
+func TestCreateWithContext_Canceled(t *testing.T) {
+	relay := newTestRelay(t)
+	keyMgr := crypto.NewLocalKeyManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := CreateWithContext(ctx, Gateway(relay.URL, http.DefaultClient), KeyManager(keyMgr))
+	assert.IsError(t, err, context.Canceled)
+
+	keys, err := keyMgr.ListKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(keys), "expected no keys to be generated")
+}
+
+func TestUpdate(t *testing.T) {
+	relay := newTestRelay(t)
+	resolver := NewResolver(relay.URL, http.DefaultClient)
+
+	bearerDID, err := Create(Gateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	created, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(created.Document.Service))
+
+	document := bearerDID.Document
+	document.AddService(didcore.Service{ID: "#dwn", Type: "DecentralizedWebNode", ServiceEndpoint: []string{"https://example.com/dwn"}})
+
+	updatedDID, err := Update(bearerDID, document, PublishGateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(updatedDID.Document.Service))
+
+	updated, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, updatedDID.Document.Service, updated.Document.Service)
+	assert.False(t, updated.DocumentMetadata.Deactivated)
+	assertHigherVersion(t, created.DocumentMetadata.VersionID, updated.DocumentMetadata.VersionID)
+
+	// updating twice within the same second must still produce a higher seq
+	_, err = Update(updatedDID, document, PublishGateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	again, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assertHigherVersion(t, updated.DocumentMetadata.VersionID, again.DocumentMetadata.VersionID)
+}
+
+func TestUpdate_MismatchedDocument(t *testing.T) {
+	relay := newTestRelay(t)
+
+	bearerDID, err := Create(Gateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	otherDID, err := Create(Gateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	_, err = Update(bearerDID, otherDID.Document, PublishGateway(relay.URL, http.DefaultClient))
+	assert.Error(t, err)
+}
+
+func TestRepublish(t *testing.T) {
+	relay := newTestRelay(t)
+	resolver := NewResolver(relay.URL, http.DefaultClient)
+
+	bearerDID, err := Create(Gateway(relay.URL, http.DefaultClient), Service("dwn", "DecentralizedWebNode", "https://example.com/dwn"))
+	assert.NoError(t, err)
+
+	created, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+
+	err = Republish(bearerDID, PublishGateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	republished, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, created.Document.Service, republished.Document.Service)
+	assertHigherVersion(t, created.DocumentMetadata.VersionID, republished.DocumentMetadata.VersionID)
+}
+
+func TestRepublish_GatewayError(t *testing.T) {
+	relay := newTestRelay(t)
+
+	bearerDID, err := Create(Gateway(relay.URL, http.DefaultClient), Types(1))
+	assert.NoError(t, err)
+
+	var puts atomic.Int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			puts.Add(1)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		http.Error(w, "unavailable", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	// publishing without the current record would drop its types and could lower its seq
+	err = Republish(bearerDID, PublishGateway(failing.URL, http.DefaultClient))
+	assert.Error(t, err)
+
+	_, err = Update(bearerDID, bearerDID.Document, PublishGateway(failing.URL, http.DefaultClient))
+	assert.Error(t, err)
+	assert.Equal(t, int32(0), puts.Load())
+}
+
+func TestDeactivate(t *testing.T) {
+	relay := newTestRelay(t)
+	resolver := NewResolver(relay.URL, http.DefaultClient)
+
+	bearerDID, err := Create(Gateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	err = Deactivate(bearerDID, PublishGateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	result, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.True(t, result.DocumentMetadata.Deactivated)
+	assert.Equal(t, bearerDID.URI, result.Document.ID)
+	assert.Equal(t, 0, len(result.Document.VerificationMethod))
+}
+
+func assertHigherVersion(t *testing.T, previous, current string) {
+	t.Helper()
+
+	prev, err := strconv.ParseInt(previous, 10, 64)
+	assert.NoError(t, err)
+	curr, err := strconv.ParseInt(current, 10, 64)
+	assert.NoError(t, err)
+	assert.True(t, curr > prev, "expected version %d to be higher than %d", curr, prev)
+}
+
+func TestCreateUnpublished(t *testing.T) {
+	relay := newTestRelay(t)
+	resolver := NewResolver(relay.URL, http.DefaultClient)
+
+	bearerDID, pending, err := CreateUnpublished(Service("dwn", "DecentralizedWebNode", "https://example.com/dwn"))
+	assert.NoError(t, err)
+	assert.Equal(t, bearerDID.URI, pending.URI)
+
+	_, err = resolver.Resolve(bearerDID.URI)
+	assert.EqualError(t, err, "notFound")
+
+	// the pending publication is handed over to another process in serialized form
+	serialized, err := json.Marshal(pending)
+	assert.NoError(t, err)
+
+	var deserialized PendingPublication
+	assert.NoError(t, json.Unmarshal(serialized, &deserialized))
+
+	err = Publish(deserialized, PublishGateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	result, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, bearerDID.Document.Service, result.Document.Service)
+	assert.Equal(t, len(bearerDID.Document.VerificationMethod), len(result.Document.VerificationMethod))
+}
+
+func TestCreateUnpublished_Gateway(t *testing.T) {
+	relay := newTestRelay(t)
+
+	_, _, err := CreateUnpublished(Gateway(relay.URL, http.DefaultClient))
+	assert.Error(t, err)
+}
+
+func TestCreateUnpublishedWithContext_Canceled(t *testing.T) {
+	keyMgr := crypto.NewLocalKeyManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := CreateUnpublishedWithContext(ctx, KeyManager(keyMgr))
+	assert.IsError(t, err, context.Canceled)
+
+	keys, err := keyMgr.ListKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(keys), "expected no keys to be generated")
+}
+
+func TestPublish_Corrupted(t *testing.T) {
+	relay := newTestRelay(t)
+
+	_, pending, err := CreateUnpublished()
+	assert.NoError(t, err)
+
+	pending.Message[len(pending.Message)-1]++
+
+	err = Publish(pending, PublishGateway(relay.URL, http.DefaultClient))
+	assert.Error(t, err)
+}
+
+func TestCreate_TypesAndPreviousDID(t *testing.T) {
+	relay := newTestRelay(t)
+	resolver := NewResolver(relay.URL, http.DefaultClient)
+
+	previousDID, err := Create(Gateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	bearerDID, err := Create(
+		Gateway(relay.URL, http.DefaultClient),
+		Types(TypeOrganization, TypeFinancialInstitution),
+		PreviousDID(previousDID),
+	)
+	assert.NoError(t, err)
+
+	result, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{TypeOrganization, TypeFinancialInstitution}, result.DocumentMetadata.Types)
+	assert.Equal(t, previousDID.URI, result.DocumentMetadata.PreviousDID)
+
+	// updating the document carries over the type index and previous did
+	document := bearerDID.Document
+	document.AddService(didcore.Service{ID: "#pfi", Type: "PFI", ServiceEndpoint: []string{"https://example.com/pfi"}})
+	_, err = Update(bearerDID, document, PublishGateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	result, err = resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Document.Service))
+	assert.Equal(t, []int{TypeOrganization, TypeFinancialInstitution}, result.DocumentMetadata.Types)
+	assert.Equal(t, previousDID.URI, result.DocumentMetadata.PreviousDID)
+}
+
+func TestResolve_UnauthorizedPreviousDID(t *testing.T) {
+	relay := newTestRelay(t)
+
+	previousDID, err := Create(Gateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	// claims previousDID as its predecessor, but the link is signed by its own identity key
+	bearerDID, err := Create(Gateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+
+	signer, _, err := bearerDID.GetSigner(nil)
+	assert.NoError(t, err)
+	signature, err := signer([]byte(bearerDID.URI))
+	assert.NoError(t, err)
+
+	encode := func(dnscodec.Properties) ([]byte, error) {
+		return dnscodec.MarshalDIDDocument(&bearerDID.Document, dnscodec.Previous(dnscodec.PreviousDID{DID: previousDID.URI, Signature: signature}))
+	}
+	assert.NoError(t, publish(context.Background(), bearerDID, encode, PublishGateway(relay.URL, http.DefaultClient)))
+
+	result, err := NewResolver(relay.URL, http.DefaultClient).Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Zero(t, result.DocumentMetadata.PreviousDID)
+}
+
+func TestFetchDIDsByType(t *testing.T) {
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/did/types/7" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		_, err := w.Write([]byte(`["did:dht:1wiaaaoagzceggsnwfzmx5cweog5msg4u536mby8sqy3mkp3wyko"]`))
+		assert.NoError(t, err)
+	}))
+	defer gateway.Close()
+
+	dids, err := FetchDIDsByType(gateway.URL, http.DefaultClient, TypeFinancialInstitution)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"did:dht:1wiaaaoagzceggsnwfzmx5cweog5msg4u536mby8sqy3mkp3wyko"}, dids)
+
+	dids, err = FetchDIDsByType(gateway.URL, http.DefaultClient, TypeWebApp)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(dids))
+}
+
+func TestEstimateSize(t *testing.T) {
+	report, err := EstimateSize(Service("dwn", "DecentralizedWebNode", "https://example.com/dwn"))
+	assert.NoError(t, err)
+	assert.True(t, report.Fits())
+	assert.Equal(t, "header", report.Parts[0].Part)
+	assert.Equal(t, "service #dwn", report.Parts[len(report.Parts)-1].Part)
+
+	uncompressed, err := EstimateSize(Service("dwn", "DecentralizedWebNode", "https://example.com/dwn"), DNSCompression(false))
+	assert.NoError(t, err)
+	assert.True(t, report.PacketSize < uncompressed.PacketSize)
+
+	endpoint := "https://example.com/" + strings.Repeat("a", 1000)
+	report, err = EstimateSize(Service("dwn", "DecentralizedWebNode", endpoint))
+	assert.NoError(t, err)
+	assert.False(t, report.Fits())
+
+	_, _, err = CreateUnpublished(Service("dwn", "DecentralizedWebNode", endpoint))
+	assert.IsError(t, err, ErrDocumentTooLarge)
+
+	// the link to a previous DID is estimated without signing with the previous DID's key
+	previousDID, _, err := CreateUnpublished()
+	assert.NoError(t, err)
+
+	withoutKey := previousDID
+	withoutKey.KeyManager = crypto.NewLocalKeyManager()
+
+	withPrevious, err := EstimateSize(PreviousDID(withoutKey))
+	assert.NoError(t, err)
+	assert.Equal(t, "previous did", withPrevious.Parts[len(withPrevious.Parts)-1].Part)
+
+	report, err = EstimateSize()
+	assert.NoError(t, err)
+	assert.True(t, report.PacketSize < withPrevious.PacketSize)
+}
+
+func TestCreateAndResolve_MainlineDHT(t *testing.T) {
+	var nodes []*mainline.Client
+	for i := 0; i < 5; i++ {
+		bootstrap := []string{}
+		if i > 0 {
+			bootstrap = append(bootstrap, nodes[0].Addr().String())
+		}
+
+		node, err := mainline.NewClient(mainline.ListenAddr("127.0.0.1:0"), mainline.BootstrapNodes(bootstrap...), mainline.Timeout(500*time.Millisecond))
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = node.Close() })
+
+		if i > 0 {
+			assert.NoError(t, node.Bootstrap(context.Background()))
+		}
+		nodes = append(nodes, node)
+	}
+
+	bearerDID, err := Create(DHT(nodes[1]), Service("dwn", "DecentralizedWebNode", "https://example.com/dwn"))
+	assert.NoError(t, err)
+
+	resolver := NewResolverWithDHT(nodes[3])
+	result, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, bearerDID.Document.Service, result.Document.Service)
+
+	document := bearerDID.Document
+	document.Service = nil
+	_, err = Update(bearerDID, document, PublishDHT(nodes[2]))
+	assert.NoError(t, err)
+
+	result, err = resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(result.Document.Service))
+}
+
+func TestCreate_VerificationMethodIDs(t *testing.T) {
+	bearerDID, _, err := CreateUnpublished(PrivateKey(dsa.AlgorithmIDSECP256K1, didcore.PurposeAssertion))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(bearerDID.Document.VerificationMethod))
+
+	// every verification method belongs to the DID, the fragment of the identity key is 0 and the fragment
+	// of every other key is its JWK thumbprint
+	assert.Equal(t, bearerDID.URI+"#0", bearerDID.Document.VerificationMethod[0].ID)
+
+	vm := bearerDID.Document.VerificationMethod[1]
+	thumbprint, err := vm.PublicKeyJwk.ComputeThumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, bearerDID.URI+"#"+thumbprint, vm.ID)
+	assert.Equal(t, []string{bearerDID.URI + "#0", vm.ID}, bearerDID.Document.AssertionMethod)
+	assert.Zero(t, bearerDID.KeyAliases)
+}
+
+// arnKeyManager is a KeyManager whose key aliases are ARNs, like those of AWS KMS keys
+type arnKeyManager struct {
+	*crypto.LocalKeyManager
+	thumbprints map[string]string
+}
+
+func (k arnKeyManager) GeneratePrivateKey(algorithmID string) (string, error) {
+	thumbprint, err := k.LocalKeyManager.GeneratePrivateKey(algorithmID)
+	if err != nil {
+		return "", err
+	}
+
+	alias := fmt.Sprintf("arn:aws:kms:us-east-1:111122223333:key/%d", len(k.thumbprints))
+	k.thumbprints[alias] = thumbprint
+	return alias, nil
+}
+
+func (k arnKeyManager) GetPublicKey(keyID string) (jwk.JWK, error) {
+	return k.LocalKeyManager.GetPublicKey(k.thumbprints[keyID])
+}
+
+func (k arnKeyManager) Sign(keyID string, payload []byte) ([]byte, error) {
+	return k.LocalKeyManager.Sign(k.thumbprints[keyID], payload)
+}
+
+func TestCreate_KeyAliases(t *testing.T) {
+	relay := newTestRelay(t)
+	keyMgr := arnKeyManager{LocalKeyManager: crypto.NewLocalKeyManager(), thumbprints: map[string]string{}}
+
+	bearerDID, err := Create(
+		Gateway(relay.URL, http.DefaultClient),
+		KeyManager(keyMgr),
+		PrivateKey(dsa.AlgorithmIDED25519, didcore.PurposeAssertion),
+	)
+	assert.NoError(t, err)
+
+	// the fragment is the thumbprint, not the ARN, which isn't a valid DID URL fragment
+	vm := bearerDID.Document.VerificationMethod[1]
+	thumbprint, err := vm.PublicKeyJwk.ComputeThumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, bearerDID.URI+"#"+thumbprint, vm.ID)
+	assert.Equal(t, map[string]string{
+		bearerDID.URI + "#0": "arn:aws:kms:us-east-1:111122223333:key/0",
+		vm.ID:                "arn:aws:kms:us-east-1:111122223333:key/1",
+	}, bearerDID.KeyAliases)
+
+	signer, _, err := bearerDID.GetSigner(didcore.ID(vm.ID))
+	assert.NoError(t, err)
+
+	signature, err := signer([]byte("hello"))
+	assert.NoError(t, err)
+
+	ok, err := dsa.Verify([]byte("hello"), signature, *vm.PublicKeyJwk)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the identity key is found through its alias too
+	err = Republish(bearerDID, PublishGateway(relay.URL, http.DefaultClient))
+	assert.NoError(t, err)
+}
+
+func TestCreateAndResolve_SECP256R1(t *testing.T) {
+	relay := newTestRelay(t)
+
+	bearerDID, err := Create(
+		Gateway(relay.URL, http.DefaultClient),
+		PrivateKey(dsa.AlgorithmIDSECP256R1, didcore.PurposeAuthentication),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(bearerDID.Document.VerificationMethod))
+
+	result, err := NewResolver(relay.URL, http.DefaultClient).Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, bearerDID.Document.VerificationMethod, result.Document.VerificationMethod)
+	assert.Equal(t, bearerDID.Document.Authentication, result.Document.Authentication)
+
+	vm := result.Document.VerificationMethod[1]
+	assert.Equal(t, "P-256", vm.PublicKeyJwk.CRV)
+
+	// the key is encoded in compressed form
+	records, err := dnscodec.Records(&bearerDID.Document)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(records[2].Value(), "t=2;"))
+
+	signer, selected, err := bearerDID.GetSigner(didcore.ID(vm.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, vm.ID, selected.ID)
+
+	signature, err := signer([]byte("hello"))
+	assert.NoError(t, err)
+
+	ok, err := dsa.Verify([]byte("hello"), signature, *vm.PublicKeyJwk)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCreate_KeyAgreement(t *testing.T) {
+	relay := newTestRelay(t)
+
+	bearerDID, err := Create(
+		Gateway(relay.URL, http.DefaultClient),
+		PrivateKey(ecdh.X25519AlgorithmID, didcore.PurposeKeyAgreement),
+	)
+	assert.NoError(t, err)
+
+	result, err := NewResolver(relay.URL, http.DefaultClient).Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Document.KeyAgreement))
+
+	vm, err := result.Document.SelectVerificationMethod(didcore.PurposeKeyAgreement)
+	assert.NoError(t, err)
+	assert.Equal(t, ecdh.X25519JWACurve, vm.PublicKeyJwk.CRV)
+
+	// the other party derives the same secret from the resolved key
+	peer, err := ecdh.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	assert.NoError(t, err)
+
+	keyID, err := vm.PublicKeyJwk.ComputeThumbprint()
+	assert.NoError(t, err)
+
+	agreer, ok := bearerDID.KeyManager.(crypto.KeyAgreer)
+	assert.True(t, ok)
+
+	secret, err := agreer.DeriveSharedSecret(keyID, ecdh.GetPublicKey(peer))
+	assert.NoError(t, err)
+
+	peerSecret, err := ecdh.DeriveSharedSecret(peer, *vm.PublicKeyJwk)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, peerSecret)
+}
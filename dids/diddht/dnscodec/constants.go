@@ -1,4 +1,4 @@
-package dns
+package dnscodec
 
 const (
 	// Labels for the dns representation of the verification method purposes
@@ -20,6 +20,9 @@ const (
 
 	// Labels for other properties
 
+	// DNSLabelVersion is the DNS representation of the version property of the root record
+	DNSLabelVersion = "v"
+
 	// DNSLabelVerificationMethod is the DNS representation of the verification method property
 	DNSLabelVerificationMethod = "vm"
 
@@ -31,4 +34,10 @@ const (
 
 	// DNSLabelAlsoKnownAs is the DNS representation of the AKA property
 	DNSLabelAlsoKnownAs = "aka"
+
+	// DNSLabelTypes is the DNS representation of the type index record
+	DNSLabelTypes = "typ"
+
+	// DNSLabelPreviousDID is the DNS representation of the previous DID record
+	DNSLabelPreviousDID = "prv"
 )
@@ -0,0 +1,516 @@
+// Package dnscodec converts between DID documents and the DNS packets `did:dht` DIDs are published as.
+//
+// Besides [MarshalDIDDocument] and [UnmarshalDIDDocument], the package exposes the individual TXT records of a
+// packet ([Record], [ParsePacket], [PackRecords]) and their zone file presentation format ([FormatZone],
+// [ParseZone]), which is useful when debugging interoperability with other implementations.
+//
+// Spec: https://did-dht.com/#dids-as-dns-records
+package dnscodec
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/dids/didcore"
+)
+
+// Properties holds the did:dht specific properties of a DID that are not part of its DID Document
+type Properties struct {
+	// Types are the indexed types of the DID. https://did-dht.com/#type-indexing
+	Types []int
+	// PreviousDID links the DID to the DID it replaces. https://did-dht.com/#previous-did
+	PreviousDID *PreviousDID
+	// Deactivated is set when unmarshaling the tombstone of [MarshalDeactivatedDIDDocument]. It is ignored
+	// when marshaling.
+	Deactivated bool
+}
+
+// PreviousDID is a link to a DID that has been replaced, along with the proof that the controller of the
+// previous DID authorized the link
+type PreviousDID struct {
+	// DID is the URI of the previous DID
+	DID string
+	// Signature is the signature over the URI of the current DID, made with the identity key of the previous DID
+	Signature []byte
+}
+
+// MarshalOption is the type returned by each individual option function accepted by [MarshalDIDDocument]
+// and [EstimateSize]
+type MarshalOption func(*marshalOptions)
+
+// marshalOptions is a struct to hold the options used to pack a DID document into a DNS packet
+type marshalOptions struct {
+	Properties
+	uncompressed bool
+}
+
+// Types adds a type index record to the DNS packet
+func Types(types ...int) MarshalOption {
+	return func(o *marshalOptions) {
+		o.Types = types
+	}
+}
+
+// Previous adds a previous DID record to the DNS packet
+func Previous(previous PreviousDID) MarshalOption {
+	return func(o *marshalOptions) {
+		o.PreviousDID = &previous
+	}
+}
+
+// Compression enables or disables DNS name compression (RFC 1035 section 4.1.4). Compression is enabled
+// by default. It replaces repeated name suffixes (e.g. `_did.`) with a 2 byte pointer, which leaves more
+// of the 1000 byte BEP44 budget for the DID Document itself.
+func Compression(enabled bool) MarshalOption {
+	return func(o *marshalOptions) {
+		o.uncompressed = !enabled
+	}
+}
+
+// PartSize is the number of bytes a part of a DID document adds to its DNS packet representation
+type PartSize struct {
+	// Part describes the part of the DID document, e.g. "verification method did:dht:abc#0"
+	Part string
+	// Size is the number of bytes the part adds to the DNS packet
+	Size int
+}
+
+// record is a DNS resource record along with a description of the part of the DID document it encodes
+type record struct {
+	Record
+	part string
+}
+
+// MarshalDIDDocument packs a DID document into a TXT DNS resource records and adds to the DNS message Answers
+func MarshalDIDDocument(d *didcore.Document, opts ...MarshalOption) ([]byte, error) {
+	var o marshalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	records, err := documentRecords(d, o.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	return pack(plain(records), !o.uncompressed)
+}
+
+// Records maps a DID document to the TXT records of its DNS packet representation, without packing them.
+// The records can be inspected or modified, and packed with [PackRecords].
+func Records(d *didcore.Document, opts ...MarshalOption) ([]Record, error) {
+	var o marshalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	records, err := documentRecords(d, o.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	return plain(records), nil
+}
+
+// DocumentFromRecords maps the TXT records of the DNS packet representation of a DID to a DID document,
+// along with the did:dht specific properties of the DID. See [UnmarshalDIDDocument] for the validation
+// that is applied.
+func DocumentFromRecords(records []Record) (*didcore.Document, Properties, error) {
+	decoder, err := newDecoder(records)
+	if err != nil {
+		return nil, Properties{}, err
+	}
+
+	doc, err := decoder.DIDDocument()
+	if err != nil {
+		return nil, Properties{}, err
+	}
+
+	props, err := decoder.Properties()
+	if err != nil {
+		return nil, Properties{}, err
+	}
+
+	return doc, props, nil
+}
+
+// plain drops the part descriptions of the records
+func plain(records []record) []Record {
+	plain := make([]Record, 0, len(records))
+	for _, r := range records {
+		plain = append(plain, r.Record)
+	}
+
+	return plain
+}
+
+// EstimateSize reports how many bytes each part of a DID document adds to its DNS packet representation,
+// in the order the parts are packed. The sum of all sizes is the size of the packet returned by
+// [MarshalDIDDocument] for the same options.
+func EstimateSize(d *didcore.Document, opts ...MarshalOption) ([]PartSize, error) {
+	var o marshalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	records, err := documentRecords(d, o.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	// pack the records one at a time so that each part is charged for what it adds after compression
+	headerSize, err := pack(nil, !o.uncompressed)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := []PartSize{{Part: "header", Size: len(headerSize)}}
+	previous := len(headerSize)
+	for i := range records {
+		packed, err := pack(plain(records[:i+1]), !o.uncompressed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack %s: %w", records[i].part, err)
+		}
+
+		sizes = append(sizes, PartSize{Part: records[i].part, Size: len(packed) - previous})
+		previous = len(packed)
+	}
+
+	return sizes, nil
+}
+
+// documentRecords maps a DID document and its did:dht properties to DNS resource records
+func documentRecords(d *didcore.Document, props Properties) ([]record, error) {
+	var records []record
+	var vmIDToK = make(map[string]string)
+	var vmBEP44Keys []string
+	// get sorted VM IDs
+	sortedIDs := pluckSort(d.VerificationMethod)
+
+	for k, id := range sortedIDs {
+		_k := fmt.Sprintf("k%d", k)
+		vmIDToK[id] = _k
+		vmBEP44Keys = append(vmBEP44Keys, _k)
+	}
+
+	var sToK = make(map[string]string)
+	var sKeys []string
+	for k, v := range d.Service {
+		_k := fmt.Sprintf("s%d", k)
+		sToK[v.ID] = _k
+		sKeys = append(sKeys, _k)
+	}
+
+	// properties are serialized in a fixed order so that the same document always maps to the same packet
+	rootProps := []struct {
+		label  string
+		values []string
+	}{
+		{DNSLabelVersion, []string{"1"}},
+		{"id", []string{d.ID}},
+		{DNSLabelVerificationMethod, vmBEP44Keys},
+		{PurposeAuthentication, methodsToKeys(d.Authentication, vmIDToK)},
+		{PurposeAssertionMethod, methodsToKeys(d.AssertionMethod, vmIDToK)},
+		{PurposeKeyAgreement, methodsToKeys(d.KeyAgreement, vmIDToK)},
+		{PurposeCapabilityInvocation, methodsToKeys(d.CapabilityInvocation, vmIDToK)},
+		{PurposeCapabilityDeletion, methodsToKeys(d.CapabilityDelegation, vmIDToK)},
+		{DNSLabelService, sKeys},
+	}
+
+	var rootPropsSerialized []string
+	for _, prop := range rootProps {
+		if len(prop.values) == 0 {
+			continue
+		}
+		rootPropsSerialized = append(rootPropsSerialized, fmt.Sprintf("%s=%s", prop.label, strings.Join(prop.values, ",")))
+	}
+
+	id := strings.TrimPrefix(d.ID, "did:dht:")
+	records = append(records, record{
+		Record: NewRecord(fmt.Sprintf("_did.%s.", id), strings.Join(rootPropsSerialized, ";")),
+		part:   "root record",
+	})
+
+	// add verification methods to dns message
+	for _, vm := range d.VerificationMethod {
+		// look for the key after the # in the verification method ID
+		key, ok := vmIDToK[vm.ID]
+		if !ok {
+			// TODO handle error
+			continue
+		}
+		buf, err := marshalVerificationMethod(&vm)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record{
+			Record: NewRecord(fmt.Sprintf("_%s._did.", key), buf),
+			part:   "verification method " + vm.ID,
+		})
+	}
+
+	// add services to dns message
+	for _, s := range d.Service {
+		key, ok := sToK[s.ID]
+		if !ok {
+			// TODO handle error
+			continue
+		}
+
+		records = append(records, record{
+			Record: NewRecord(fmt.Sprintf("_%s._did.", key), marshalService(s)),
+			part:   "service " + s.ID,
+		})
+	}
+
+	// controllers and aliases are separate records, see https://did-dht.com/#controller and
+	// https://did-dht.com/#also-known-as
+	if len(d.Controller) > 0 {
+		records = append(records, record{
+			Record: NewRecord(fmt.Sprintf("_%s._did.", DNSLabelController), strings.Join(d.Controller, ",")),
+			part:   "controller",
+		})
+	}
+
+	if len(d.AlsoKnownAs) > 0 {
+		records = append(records, record{
+			Record: NewRecord(fmt.Sprintf("_%s._did.", DNSLabelAlsoKnownAs), strings.Join(d.AlsoKnownAs, ",")),
+			part:   "also known as",
+		})
+	}
+
+	if len(props.Types) > 0 {
+		types := make([]string, 0, len(props.Types))
+		for _, t := range props.Types {
+			types = append(types, strconv.Itoa(t))
+		}
+
+		records = append(records, record{
+			Record: NewRecord(fmt.Sprintf("_%s._did.", DNSLabelTypes), "id="+strings.Join(types, ",")),
+			part:   "type index",
+		})
+	}
+
+	if props.PreviousDID != nil {
+		rawData := fmt.Sprintf("id=%s;s=%s", props.PreviousDID.DID, base64.RawURLEncoding.EncodeToString(props.PreviousDID.Signature))
+		records = append(records, record{
+			Record: NewRecord(fmt.Sprintf("_%s._did.", DNSLabelPreviousDID), rawData),
+			part:   "previous did",
+		})
+	}
+
+	return records, nil
+}
+
+// MarshalDeactivatedDIDDocument packs the tombstone form of a deactivated DID document: a DNS packet
+// containing only the root record, which carries the version property and nothing else. Every other
+// document has at least one more property in its root record, e.g. its id.
+//
+// https://did-dht.com/#deactivate
+func MarshalDeactivatedDIDDocument(didURI string) ([]byte, error) {
+	id := strings.TrimPrefix(didURI, "did:dht:")
+	return pack([]Record{NewRecord(fmt.Sprintf("_did.%s.", id), DNSLabelVersion+"=1")}, true)
+}
+
+// UnmarshalDIDDocument unpacks the TXT DNS resource records and returns a DID document along with
+// the did:dht specific properties of the DID.
+//
+// DNS packets are fetched from untrusted relays, so malformed or adversarial packets are rejected with one of the
+// errors defined in this package: packets larger than [MaxPacketSize], a missing or repeated root record,
+// repeated records, verification methods or services, references to records missing from the packet and
+// records that do not follow the did:dht record format.
+func UnmarshalDIDDocument(payload []byte) (*didcore.Document, Properties, error) {
+	records, err := ParsePacket(payload)
+	if err != nil {
+		return nil, Properties{}, err
+	}
+
+	return DocumentFromRecords(records)
+}
+
+// marshalVerificationMethod maps a verification method to the value of its TXT DNS resource record
+func marshalVerificationMethod(vm *didcore.VerificationMethod) (string, error) {
+	var algID string
+	var err error
+	if vm.PublicKeyJwk.CRV == ecdh.X25519JWACurve {
+		algID, err = ecdh.AlgorithmID(vm.PublicKeyJwk)
+	} else {
+		algID, err = dsa.AlgorithmID(vm.PublicKeyJwk)
+	}
+	if err != nil {
+		return "", err
+	}
+	t, ok := algToDhtIndex(algID)
+	if !ok {
+		return "", errors.New("unsupported algorithm")
+	}
+
+	// the key type index registers secp256r1 keys in compressed form
+	var keyBytes []byte
+	switch algID {
+	case dsa.AlgorithmIDSECP256R1:
+		keyBytes, err = ecdsa.PublicKeyToCompressedBytes(*vm.PublicKeyJwk)
+	case ecdh.X25519AlgorithmID:
+		keyBytes, err = ecdh.PublicKeyToBytes(*vm.PublicKeyJwk)
+	default:
+		keyBytes, err = dsa.PublicKeyToBytes(*vm.PublicKeyJwk)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// TODO: clean this up. smol monkey patch that accommodates
+	// the possibility that vm.ID may or may not have a '#'.
+	// Technically it always should but i can't confirm that given that
+	// this fix is blocking other work. did:dht will be refactored in
+	// a subsequent PR  soon (Moe: 2024-04-16)
+	splitID := strings.Split(vm.ID, "#")
+	fragment := splitID[len(splitID)-1]
+	props := []string{
+		"id=" + fragment,
+		"t=" + t,
+		"k=" + base64.RawURLEncoding.EncodeToString(keyBytes),
+	}
+
+	// TODO: controller should only be set in the TXT record if the value is different from document.id (Moe: 2024-04-16)
+	if len(vm.Controller) > 0 {
+		props = append(props, "c="+vm.Controller)
+	}
+
+	dhtEncodedVM := strings.Join(props, ";")
+
+	return dhtEncodedVM, nil
+
+}
+
+// marshalService maps a service to the value of its TXT DNS resource record
+func marshalService(s didcore.Service) string {
+	return fmt.Sprintf("id=%s;t=%s;se=%s", s.ID, s.Type, strings.Join(s.ServiceEndpoint, ","))
+}
+
+// unmarshalVerificationMethod unpacks the TXT DNS resource encoded verification method
+func unmarshalVerificationMethod(data string, did string, vm *didcore.VerificationMethod) error {
+	propertyMap, err := parseTXTRecordData(data)
+	if err != nil {
+		return err
+	}
+
+	vm.Type = "JsonWebKey"
+
+	var key string
+	var algorithmID string
+	for property, v := range propertyMap {
+		// According to https://did-dht.com/#verification-methods, none of the properties are lists
+		if len(v) != 1 {
+			return fmt.Errorf("%w: %s must be a single value", ErrMalformedRecord, property)
+		}
+
+		switch property {
+		case "id":
+			vm.ID = did + "#" + v[0]
+		case "t": // Index of the key type https://did-dht.com/registry/index.html#key-type-index
+			var ok bool
+			if algorithmID, ok = dhtIndexToAlg(v[0]); !ok {
+				return fmt.Errorf("%w: %s", ErrUnsupportedKeyType, v[0])
+			}
+		case "k": // unpadded base64URL representation of the public key
+			key = v[0]
+		case "c": // the controller is optional
+			vm.Controller = v[0]
+		default:
+			continue
+		}
+	}
+
+	// if controller is omitted from the record, it is assumed that controller is document.ID
+	if vm.Controller == "" {
+		vm.Controller = did
+	}
+
+	if vm.ID == "" || vm.ID == did+"#" || key == "" || algorithmID == "" {
+		return fmt.Errorf("%w: id, t and k are required", ErrMalformedRecord)
+	}
+
+	// RawURLEncoding is the same as URLEncoding but omits padding.
+	// Decoding and reencoding to make sure there is no padding
+	keyBytes, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil {
+		return fmt.Errorf("%w: public key: %w", ErrMalformedRecord, err)
+	}
+
+	bytesToPublicKey := dsa.BytesToPublicKey
+	if ecdh.SupportsAlgorithmID(algorithmID) {
+		bytesToPublicKey = ecdh.BytesToPublicKey
+	}
+
+	j, err := bytesToPublicKey(algorithmID, keyBytes)
+	if err != nil {
+		return fmt.Errorf("%w: public key: %w", ErrMalformedRecord, err)
+	}
+	vm.PublicKeyJwk = &j
+
+	return nil
+}
+
+// unmarshalService unpacks the TXT DNS resource encoded service
+func unmarshalService(data string, s *didcore.Service) error {
+	propertyMap, err := parseTXTRecordData(data)
+	if err != nil {
+		return err
+	}
+	for property, v := range propertyMap {
+		switch property {
+		case "id":
+			s.ID = strings.Join(v, "")
+		case "t":
+			s.Type = strings.Join(v, "")
+		case "se":
+			var validEndpoints []string
+			for _, uri := range v {
+				if _, err := url.ParseRequestURI(uri); err != nil {
+					return fmt.Errorf("%w: invalid service endpoint %q", ErrMalformedRecord, uri)
+				}
+				validEndpoints = append(validEndpoints, uri)
+			}
+			s.ServiceEndpoint = validEndpoints
+		default:
+			continue
+		}
+	}
+
+	if s.ID == "" {
+		return fmt.Errorf("%w: id is required", ErrMalformedRecord)
+	}
+
+	return nil
+}
+
+func pluckSort(hayStack []didcore.VerificationMethod) []string {
+	var ids []string
+	for _, v := range hayStack {
+		ids = append(ids, v.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// methodsToKeys takes a list of method indices and returns the corresonding verification method _kN keys
+func methodsToKeys(methods []string, idToKey map[string]string) []string {
+	var keys []string
+	for _, v := range methods {
+		k, ok := idToKey[v]
+		if ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
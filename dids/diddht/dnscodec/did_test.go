@@ -0,0 +1,333 @@
+package dnscodec
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/dids/didcore"
+	"github.com/decentralized-identity/web5-go/jwk"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func Test_MarshalDIDDocument(t *testing.T) {
+
+	var didDoc didcore.Document
+	assert.NoError(t, json.Unmarshal([]byte(`
+	{
+		"id": "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+		"verificationMethod": [
+		{
+			"id": "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy#0",
+			"type": "JsonWebKey",
+			"controller": "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+			"publicKeyJwk": {
+			"crv": "Ed25519",
+			"kty": "OKP",
+			"kid": "0",
+			"x": "ZR8A7IHnJ5v9-TFcDzI8cZfhGJzSj29LYutpKTLwdoo"
+			}
+		}
+		],
+		"authentication": [
+		"did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy#0"
+		],
+		"assertionMethod": [
+		"did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy#0"
+		],
+		"capabilityInvocation": [
+		"did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy#0"
+		],
+		"capabilityDelegation": [
+		"did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy#0"
+		]
+	}
+	`), &didDoc))
+
+	assert.NotZero(t, didDoc.VerificationMethod)
+	buf, err := MarshalDIDDocument(&didDoc)
+	assert.NoError(t, err)
+
+	assert.NotZero(t, len(buf))
+
+	rec, _ := parseDNSDID(buf)
+	reParsedDoc, err := rec.DIDDocument()
+	assert.NoError(t, err)
+	assert.NotZero(t, reParsedDoc)
+
+	// the DNS packet holds the key material of a JWK, not its other members like kid
+	didDoc.VerificationMethod[0].PublicKeyJwk.KID = ""
+	assert.Equal(t, &didDoc, reParsedDoc)
+}
+
+func Test_MarshalDIDDocument_Properties(t *testing.T) {
+	didDoc := didcore.Document{ID: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy"}
+	previous := PreviousDID{
+		DID:       "did:dht:1wiaaaoagzceggsnwfzmx5cweog5msg4u536mby8sqy3mkp3wyko",
+		Signature: []byte("signature"),
+	}
+
+	buf, err := MarshalDIDDocument(&didDoc, Types(1, 7), Previous(previous))
+	assert.NoError(t, err)
+
+	doc, props, err := UnmarshalDIDDocument(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, didDoc.ID, doc.ID)
+	assert.Equal(t, []int{1, 7}, props.Types)
+	assert.Equal(t, &previous, props.PreviousDID)
+
+	buf, err = MarshalDIDDocument(&didDoc)
+	assert.NoError(t, err)
+
+	_, props, err = UnmarshalDIDDocument(buf)
+	assert.NoError(t, err)
+	assert.Zero(t, props)
+}
+
+func Test_MarshalDIDDocument_ControllerAndAlsoKnownAs(t *testing.T) {
+	didDoc := didcore.Document{
+		ID:          "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+		Controller:  []string{"did:example:abcd"},
+		AlsoKnownAs: []string{"did:example:efgh", "did:example:ijkl"},
+	}
+
+	buf, err := MarshalDIDDocument(&didDoc)
+	assert.NoError(t, err)
+
+	var msg dnsmessage.Message
+	assert.NoError(t, msg.Unpack(buf))
+
+	txt := make(map[string]string)
+	for _, answer := range msg.Answers {
+		txt[answer.Header.Name.String()] = strings.Join(answer.Body.(*dnsmessage.TXTResource).TXT, "")
+	}
+
+	// https://did-dht.com/#controller and https://did-dht.com/#also-known-as
+	assert.Equal(t, "did:example:abcd", txt["_cnt._did."])
+	assert.Equal(t, "did:example:efgh,did:example:ijkl", txt["_aka._did."])
+	root := txt["_did.cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy."]
+	assert.Contains(t, root, "id=")
+	assert.NotContains(t, root, "cnt=")
+	assert.NotContains(t, root, "aka=")
+
+	doc, _, err := UnmarshalDIDDocument(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, didDoc.Controller, doc.Controller)
+	assert.Equal(t, didDoc.AlsoKnownAs, doc.AlsoKnownAs)
+}
+
+func Test_MarshalDeactivatedDIDDocument(t *testing.T) {
+	id := "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy"
+
+	buf, err := MarshalDeactivatedDIDDocument(id)
+	assert.NoError(t, err)
+
+	doc, props, err := UnmarshalDIDDocument(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(doc.VerificationMethod))
+	assert.True(t, props.Deactivated)
+
+	// a document without verification methods is not deactivated as long as it has other properties
+	didDoc := didcore.Document{
+		ID:      id,
+		Service: []didcore.Service{{ID: id + "#dwn", Type: "DecentralizedWebNode", ServiceEndpoint: []string{"https://example.com/dwn"}}},
+	}
+
+	buf, err = MarshalDIDDocument(&didDoc)
+	assert.NoError(t, err)
+
+	doc, props, err = UnmarshalDIDDocument(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(doc.Service))
+	assert.False(t, props.Deactivated)
+}
+
+func Test_MarshalDIDDocument_KeyTypes(t *testing.T) {
+	didDoc := didcore.Document{ID: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy"}
+
+	p256, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	assert.NoError(t, err)
+	p256PublicKey := dsa.GetPublicKey(p256)
+
+	x25519, err := ecdh.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	assert.NoError(t, err)
+	x25519PublicKey := ecdh.GetPublicKey(x25519)
+
+	didDoc.AddVerificationMethod(didcore.VerificationMethod{
+		ID:           didDoc.ID + "#0",
+		Type:         "JsonWebKey",
+		Controller:   didDoc.ID,
+		PublicKeyJwk: &p256PublicKey,
+	}, didcore.Purposes(didcore.PurposeAuthentication))
+	didDoc.AddVerificationMethod(didcore.VerificationMethod{
+		ID:           didDoc.ID + "#1",
+		Type:         "JsonWebKey",
+		Controller:   didDoc.ID,
+		PublicKeyJwk: &x25519PublicKey,
+	}, didcore.Purposes(didcore.PurposeKeyAgreement))
+
+	records, err := Records(&didDoc)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(records[1].Value(), "id=0;t=2;k="))
+	assert.True(t, strings.HasPrefix(records[2].Value(), "id=1;t=3;k="))
+
+	// secp256r1 keys are compressed
+	_, k, _ := strings.Cut(records[1].Value(), "k=")
+	k, _, _ = strings.Cut(k, ";")
+	assert.Equal(t, 44, len(k))
+
+	buf, err := MarshalDIDDocument(&didDoc)
+	assert.NoError(t, err)
+
+	doc, _, err := UnmarshalDIDDocument(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, didDoc.VerificationMethod, doc.VerificationMethod)
+	assert.Equal(t, didDoc.KeyAgreement, doc.KeyAgreement)
+	assert.Equal(t, didDoc.Authentication, doc.Authentication)
+}
+
+// customAlgorithm is secp256k1 under a different curve name, to test registering key types
+type customAlgorithm struct {
+	dsa.Algorithm
+}
+
+func (customAlgorithm) ID() string { return "custom" }
+
+func (customAlgorithm) SupportsKey(key jwk.JWK) bool {
+	return key.CRV == "custom"
+}
+
+func (a customAlgorithm) BytesToPublicKey(input []byte) (jwk.JWK, error) {
+	publicKey, err := a.Algorithm.BytesToPublicKey(input)
+	publicKey.CRV = "custom"
+	return publicKey, err
+}
+
+func (a customAlgorithm) PublicKeyToBytes(publicKey jwk.JWK) ([]byte, error) {
+	publicKey.CRV = dsa.AlgorithmIDSECP256K1
+	return a.Algorithm.PublicKeyToBytes(publicKey)
+}
+
+// registerCustomKeyType registers the custom algorithm once, as the registries are global
+var registerCustomKeyType sync.Once
+
+func Test_RegisterKeyType(t *testing.T) {
+	secp256k1, err := dsa.GetAlgorithm(dsa.AlgorithmIDSECP256K1)
+	assert.NoError(t, err)
+
+	registerCustomKeyType.Do(func() {
+		assert.Error(t, RegisterKeyType("42", "custom"))
+		assert.NoError(t, dsa.Register(customAlgorithm{secp256k1}))
+		assert.NoError(t, RegisterKeyType("42", "custom"))
+	})
+
+	// indices and algorithms can only be registered once
+	assert.Error(t, RegisterKeyType("42", dsa.AlgorithmIDSECP384R1))
+	assert.Error(t, RegisterKeyType("0", "custom"))
+
+	privateKey, err := dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256K1)
+	assert.NoError(t, err)
+	publicKey := dsa.GetPublicKey(privateKey)
+	publicKey.CRV = "custom"
+
+	didDoc := didcore.Document{ID: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy"}
+	didDoc.AddVerificationMethod(didcore.VerificationMethod{
+		ID:           didDoc.ID + "#0",
+		Type:         "JsonWebKey",
+		Controller:   didDoc.ID,
+		PublicKeyJwk: &publicKey,
+	}, didcore.Purposes(didcore.PurposeAuthentication))
+
+	records, err := Records(&didDoc)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(records[1].Value(), "id=0;t=42;k="))
+
+	buf, err := MarshalDIDDocument(&didDoc)
+	assert.NoError(t, err)
+
+	doc, _, err := UnmarshalDIDDocument(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, didDoc.VerificationMethod, doc.VerificationMethod)
+}
+
+func Test_MarshalDIDDocument_LongValues(t *testing.T) {
+	didDoc := didcore.Document{
+		ID: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+		Service: []didcore.Service{{
+			ID:              "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy#dwn",
+			Type:            "DecentralizedWebNode",
+			ServiceEndpoint: []string{"https://example.com/" + strings.Repeat("a", 300)},
+		}},
+	}
+
+	buf, err := MarshalDIDDocument(&didDoc)
+	assert.NoError(t, err)
+
+	doc, _, err := UnmarshalDIDDocument(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, didDoc.Service, doc.Service)
+}
+
+func Test_EstimateSize(t *testing.T) {
+	didDoc := didcore.Document{
+		ID: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+		Service: []didcore.Service{
+			{ID: "#dwn", Type: "DecentralizedWebNode", ServiceEndpoint: []string{"https://example.com/dwn"}},
+			{ID: "#pfi", Type: "PFI", ServiceEndpoint: []string{"https://example.com/pfi"}},
+		},
+	}
+
+	for _, compress := range []bool{true, false} {
+		sizes, err := EstimateSize(&didDoc, Types(1), Compression(compress))
+		assert.NoError(t, err)
+
+		var parts []string
+		var total int
+		for _, size := range sizes {
+			parts = append(parts, size.Part)
+			total += size.Size
+		}
+		assert.Equal(t, []string{"header", "root record", "service #dwn", "service #pfi", "type index"}, parts)
+
+		buf, err := MarshalDIDDocument(&didDoc, Types(1), Compression(compress))
+		assert.NoError(t, err)
+		assert.Equal(t, len(buf), total)
+	}
+
+	compressed, err := MarshalDIDDocument(&didDoc)
+	assert.NoError(t, err)
+
+	uncompressed, err := MarshalDIDDocument(&didDoc, Compression(false))
+	assert.NoError(t, err)
+	assert.True(t, len(compressed) < len(uncompressed))
+
+	doc, _, err := UnmarshalDIDDocument(uncompressed)
+	assert.NoError(t, err)
+	assert.Equal(t, didDoc.Service, doc.Service)
+}
+
+func FuzzUnmarshalDIDDocument(f *testing.F) {
+	seed, err := MarshalDIDDocument(&didcore.Document{
+		ID: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+		Service: []didcore.Service{
+			{ID: "#dwn", Type: "DecentralizedWebNode", ServiceEndpoint: []string{"https://example.com/dwn"}},
+		},
+	}, Types(1), Previous(PreviousDID{DID: "did:dht:abc", Signature: []byte("signature")}))
+	assert.NoError(f, err)
+	f.Add(seed)
+
+	f.Fuzz(func(t *testing.T, packet []byte) {
+		doc, _, err := UnmarshalDIDDocument(packet)
+		if err != nil {
+			return
+		}
+
+		// every accepted document must be encodable
+		_, err = MarshalDIDDocument(doc)
+		assert.NoError(t, err)
+	})
+}
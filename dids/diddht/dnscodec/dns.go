@@ -0,0 +1,277 @@
+package dnscodec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decentralized-identity/web5-go/dids/didcore"
+)
+
+// decoder is used to structure the DNS representation of a DID
+type decoder struct {
+	// zbase32 encoded id
+	id         string
+	rootName   string
+	rootRecord string
+	records    map[string]string
+}
+
+// parseDNSDID takes the bytes of the DNS representation of a DID and creates an internal representation
+// used to create a DID document
+func parseDNSDID(data []byte) (*decoder, error) {
+	records, err := ParsePacket(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDecoder(records)
+}
+
+// newDecoder indexes the records by name, rejecting packets with a missing root record or conflicting records
+func newDecoder(records []Record) (*decoder, error) {
+	didRecord := decoder{
+		records: make(map[string]string),
+	}
+
+	// identical records are collapsed (RFC 2181 section 5), conflicting ones are rejected
+	var hasRoot bool
+	for _, r := range records {
+		if strings.HasPrefix(r.Name, "_did.") {
+			id := strings.TrimSuffix(strings.TrimPrefix(r.Name, "_did."), ".")
+			if hasRoot && (id != didRecord.id || r.Value() != didRecord.rootRecord) {
+				return nil, recordError(r.Name, ErrDuplicateRecord)
+			}
+
+			hasRoot = true
+			didRecord.id = id
+			didRecord.rootName = r.Name
+			didRecord.rootRecord = r.Value()
+			continue
+		}
+
+		// some implementations qualify the names of the other records with the id, e.g. `_k0._did.<id>.`
+		name := r.Name
+		if i := strings.Index(name, "._did."); i >= 0 {
+			name = name[:i] + "._did."
+		}
+
+		if value, ok := didRecord.records[name]; ok && value != r.Value() {
+			return nil, recordError(r.Name, ErrDuplicateRecord)
+		}
+
+		didRecord.records[name] = r.Value()
+	}
+
+	if !hasRoot {
+		return nil, ErrMissingRootRecord
+	}
+
+	return &didRecord, nil
+}
+
+// DIDDocument maps the records to a DID document. Verification methods and services are added in the
+// order they are listed in the root record.
+func (rec *decoder) DIDDocument() (*didcore.Document, error) {
+	rootName := rec.rootName
+	rootProps, err := parseTXTRecordData(rec.rootRecord)
+	if err != nil {
+		return nil, recordError(rootName, err)
+	}
+
+	vmKeys, err := rec.references(rootName, rootProps[DNSLabelVerificationMethod])
+	if err != nil {
+		return nil, err
+	}
+
+	relationshipMap, err := parseVerificationRelationships(rootName, rootProps, vmKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Now we have a did in a dns record. yay
+	document := &didcore.Document{
+		ID: "did:dht:" + rec.id,
+	}
+
+	vmIDs := make(map[string]bool)
+	for _, key := range vmKeys {
+		name := "_" + key + "._did."
+
+		var vMethod didcore.VerificationMethod
+		if err := unmarshalVerificationMethod(rec.records[name], document.ID, &vMethod); err != nil {
+			return nil, recordError(name, err)
+		}
+
+		if vmIDs[vMethod.ID] {
+			return nil, recordError(name, fmt.Errorf("%w: verification method %s", ErrDuplicateID, vMethod.ID))
+		}
+		vmIDs[vMethod.ID] = true
+
+		opts := []didcore.Purpose{}
+		for _, r := range relationshipMap[key] {
+			if o, ok := vmPurposeDNStoDID[r]; ok {
+				opts = append(opts, o)
+			}
+		}
+
+		document.AddVerificationMethod(
+			vMethod,
+			didcore.Purposes(opts...),
+		)
+	}
+
+	serviceKeys, err := rec.references(rootName, rootProps[DNSLabelService])
+	if err != nil {
+		return nil, err
+	}
+
+	serviceIDs := make(map[string]bool)
+	for _, key := range serviceKeys {
+		name := "_" + key + "._did."
+
+		var service didcore.Service
+		if err := unmarshalService(rec.records[name], &service); err != nil {
+			return nil, recordError(name, err)
+		}
+
+		if serviceIDs[service.ID] {
+			return nil, recordError(name, fmt.Errorf("%w: service %s", ErrDuplicateID, service.ID))
+		}
+		serviceIDs[service.ID] = true
+
+		document.AddService(service)
+	}
+
+	// controllers and aliases are separate records as described in https://did-dht.com/#controller and
+	// https://did-dht.com/#also-known-as. previous versions of this package put them in the root record.
+	if data, ok := rec.records["_"+DNSLabelController+"._did."]; ok {
+		document.Controller = strings.Split(data, ",")
+	} else if controllers, ok := rootProps[DNSLabelController]; ok {
+		document.Controller = controllers
+	}
+
+	if data, ok := rec.records["_"+DNSLabelAlsoKnownAs+"._did."]; ok {
+		document.AlsoKnownAs = strings.Split(data, ",")
+	} else if aliases, ok := rootProps[DNSLabelAlsoKnownAs]; ok {
+		document.AlsoKnownAs = aliases
+	}
+
+	return document, nil
+}
+
+// references checks that every key listed in the root record (e.g. k0, s1) is unique and has a
+// corresponding `_<key>._did.` record
+func (rec *decoder) references(rootName string, keys []string) ([]string, error) {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			return nil, recordError(rootName, fmt.Errorf("%w: %s is listed more than once", ErrDuplicateID, key))
+		}
+		seen[key] = true
+
+		if _, ok := rec.records["_"+key+"._did."]; !ok {
+			return nil, recordError(rootName, fmt.Errorf("%w: no record found for %s", ErrDanglingReference, key))
+		}
+	}
+
+	return keys, nil
+}
+
+// Properties returns the did:dht specific properties found in the DNS representation of the DID
+func (rec *decoder) Properties() (Properties, error) {
+	var props Properties
+
+	typesName := "_" + DNSLabelTypes + "._did."
+	if data, ok := rec.records[typesName]; ok {
+		propertyMap, err := parseTXTRecordData(data)
+		if err != nil {
+			return Properties{}, recordError(typesName, err)
+		}
+
+		for _, v := range propertyMap["id"] {
+			t, err := strconv.Atoi(v)
+			if err != nil {
+				return Properties{}, recordError(typesName, fmt.Errorf("%w: type index %s: %w", ErrMalformedRecord, v, err))
+			}
+			props.Types = append(props.Types, t)
+		}
+	}
+
+	previousName := "_" + DNSLabelPreviousDID + "._did."
+	if data, ok := rec.records[previousName]; ok {
+		propertyMap, err := parseTXTRecordData(data)
+		if err != nil {
+			return Properties{}, recordError(previousName, err)
+		}
+
+		if len(propertyMap["id"]) != 1 || len(propertyMap["s"]) != 1 {
+			return Properties{}, recordError(previousName, fmt.Errorf("%w: id and s are required", ErrMalformedRecord))
+		}
+
+		signature, err := base64.RawURLEncoding.DecodeString(propertyMap["s"][0])
+		if err != nil {
+			return Properties{}, recordError(previousName, fmt.Errorf("%w: signature: %w", ErrMalformedRecord, err))
+		}
+
+		props.PreviousDID = &PreviousDID{
+			DID:       propertyMap["id"][0],
+			Signature: signature,
+		}
+	}
+
+	// the tombstone of a deactivated DID is a root record with only the version, and no other records
+	rootProps, err := parseTXTRecordData(rec.rootRecord)
+	if err != nil {
+		return Properties{}, recordError(rec.rootName, err)
+	}
+
+	_, hasVersion := rootProps[DNSLabelVersion]
+	props.Deactivated = len(rec.records) == 0 && len(rootProps) == 1 && hasVersion
+
+	return props, nil
+}
+
+// parseVerificationRelationships maps each verification method key (e.g. k0) to the DNS representation of
+// its verification relationships (e.g. auth, asm), rejecting references to keys missing from the vm list
+func parseVerificationRelationships(rootName string, rootProps map[string][]string, vmKeys []string) (map[string][]string, error) {
+	known := make(map[string]bool, len(vmKeys))
+	for _, k := range vmKeys {
+		known[k] = true
+	}
+
+	// reverse the map to get the relationships
+	var relationshipMap = make(map[string][]string)
+	for purpose := range vmPurposeDNStoDID {
+		for _, k := range rootProps[purpose] {
+			if !known[k] {
+				return nil, recordError(rootName, fmt.Errorf("%w: %s=%s is not a verification method", ErrDanglingReference, purpose, k))
+			}
+
+			relationshipMap[k] = append(relationshipMap[k], purpose)
+		}
+	}
+
+	return relationshipMap, nil
+}
+
+// parseTXTRecordData parses a `key=value1,value2;key=value` record value. Values may contain `=`,
+// keys must not be empty or repeated.
+func parseTXTRecordData(data string) (map[string][]string, error) {
+	var result = make(map[string][]string)
+	for _, field := range strings.Split(data, ";") {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%w: malformed field %q", ErrMalformedRecord, field)
+		}
+
+		if _, ok := result[k]; ok {
+			return nil, fmt.Errorf("%w: field %s is repeated", ErrMalformedRecord, k)
+		}
+
+		result[k] = strings.Split(v, ",")
+	}
+
+	return result, nil
+}
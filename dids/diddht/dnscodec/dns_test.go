@@ -0,0 +1,205 @@
+package dnscodec
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+type DHTTXTResourceOpt func() dnsmessage.Resource
+
+func WithDNSRecord(name, body string) DHTTXTResourceOpt {
+	return func() dnsmessage.Resource {
+		return dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{
+				Name: dnsmessage.MustNewName(name),
+				Type: dnsmessage.TypeTXT,
+				TTL:  7200,
+			},
+			Body: &dnsmessage.TXTResource{
+				TXT: []string{
+					body,
+				},
+			},
+		}
+	}
+}
+func makeDNSMessage(answersOpt ...DHTTXTResourceOpt) dnsmessage.Message {
+
+	answers := []dnsmessage.Resource{}
+	for _, a := range answersOpt {
+		answers = append(answers, a())
+	}
+
+	msg := dnsmessage.Message{
+		Header:  dnsmessage.Header{Response: true, Authoritative: true},
+		Answers: answers,
+	}
+
+	return msg
+}
+func Test_parseDNSDID(t *testing.T) {
+	tests := map[string]struct {
+		msg           dnsmessage.Message
+		expectedError string
+		assertResult  func(t *testing.T, d *decoder)
+	}{
+		"basic did with key": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;auth=k0;asm=k0;inv=k0;del=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"),
+			),
+			assertResult: func(t *testing.T, d *decoder) {
+				t.Helper()
+				assert.False(t, d == nil)
+				expectedRecords := map[string]string{
+					"_k0._did.": "id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE",
+				}
+				assert.Equal(t, "vm=k0;auth=k0;asm=k0;inv=k0;del=k0", d.rootRecord)
+				assert.True(t, reflect.DeepEqual(expectedRecords, d.records))
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf, err := test.msg.Pack()
+			assert.NoError(t, err)
+
+			dhtDidRecord, err := parseDNSDID(buf)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "vm=k0;auth=k0;asm=k0;inv=k0;del=k0", dhtDidRecord.rootRecord)
+
+		})
+	}
+}
+
+func Test_UnmarshalDIDDocument_Rejects(t *testing.T) {
+	const key = "YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"
+
+	tests := map[string]struct {
+		msg         dnsmessage.Message
+		expectedErr error
+	}{
+		"missing root record": {
+			msg:         makeDNSMessage(WithDNSRecord("_k0._did.", "id=0;t=0;k="+key)),
+			expectedErr: ErrMissingRootRecord,
+		},
+		"conflicting root records": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0"),
+				WithDNSRecord("_did.", "vm=k0;auth=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+			),
+			expectedErr: ErrDuplicateRecord,
+		},
+		"conflicting verification method records": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+				WithDNSRecord("_k0._did.", "id=1;t=0;k="+key),
+			),
+			expectedErr: ErrDuplicateRecord,
+		},
+		"duplicate verification method ids": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0,k1"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+				WithDNSRecord("_k1._did.", "id=0;t=0;k="+key),
+			),
+			expectedErr: ErrDuplicateID,
+		},
+		"duplicate service ids": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;srv=s0,s1"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+				WithDNSRecord("_s0._did.", "id=dwn;t=DecentralizedWebNode;se=https://example.com"),
+				WithDNSRecord("_s1._did.", "id=dwn;t=DecentralizedWebNode;se=https://example.org"),
+			),
+			expectedErr: ErrDuplicateID,
+		},
+		"verification method record missing": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0,k1"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+			),
+			expectedErr: ErrDanglingReference,
+		},
+		"relationship references unknown verification method": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;auth=k0,k1"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+			),
+			expectedErr: ErrDanglingReference,
+		},
+		"service record missing": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;srv=s0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+			),
+			expectedErr: ErrDanglingReference,
+		},
+		"unsupported key type": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=99;k="+key),
+			),
+			expectedErr: ErrUnsupportedKeyType,
+		},
+		"malformed public key": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k=AAAA"),
+			),
+			expectedErr: ErrMalformedRecord,
+		},
+		"repeated field": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0;vm=k1"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+			),
+			expectedErr: ErrMalformedRecord,
+		},
+		"oversized packet": {
+			msg: makeDNSMessage(
+				WithDNSRecord("_did.", "vm=k0"),
+				WithDNSRecord("_k0._did.", "id=0;t=0;k="+key),
+				WithDNSRecord("_aka._did.", strings.Repeat("a", 250)),
+				WithDNSRecord("_cnt._did.", strings.Repeat("a", 250)),
+				WithDNSRecord("_s0._did.", strings.Repeat("a", 250)),
+				WithDNSRecord("_s1._did.", strings.Repeat("a", 250)),
+			),
+			expectedErr: ErrPacketTooLarge,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf, err := test.msg.Pack()
+			assert.NoError(t, err)
+
+			_, _, err = UnmarshalDIDDocument(buf)
+			assert.True(t, errors.Is(err, test.expectedErr), "expected %v, got %v", test.expectedErr, err)
+		})
+	}
+
+	_, _, err := UnmarshalDIDDocument([]byte{0, 1, 2})
+	assert.True(t, errors.Is(err, ErrMalformedPacket))
+
+	var recordErr *RecordError
+	msg := makeDNSMessage(WithDNSRecord("_did.", "vm=k0"))
+	buf, err := msg.Pack()
+	assert.NoError(t, err)
+	_, _, err = UnmarshalDIDDocument(buf)
+	assert.True(t, errors.As(err, &recordErr))
+	assert.Equal(t, "_did.", recordErr.Name)
+}
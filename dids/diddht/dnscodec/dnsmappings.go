@@ -0,0 +1,87 @@
+package dnscodec
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/dids/didcore"
+)
+
+// vmPurposeDNStoDID maps the DNS representation of the
+// verification method relationships to the DID representation.
+//
+// https://did-dht.com/#verification-relationship-index
+var vmPurposeDNStoDID = map[string]didcore.Purpose{
+	PurposeAuthentication:       didcore.PurposeAuthentication,
+	PurposeAssertionMethod:      didcore.PurposeAssertion,
+	PurposeKeyAgreement:         didcore.PurposeKeyAgreement,
+	PurposeCapabilityInvocation: didcore.PurposeCapabilityInvocation,
+	PurposeCapabilityDeletion:   didcore.PurposeCapabilityDelegation,
+}
+
+// keyTypes maps the DNS representation of the key type index to the algorithm ID, and back. Key types of
+// signature algorithms registered with [dsa.Register] can be added with [RegisterKeyType].
+//
+// https://did-dht.com/registry/index.html#key-type-index
+var keyTypes = struct {
+	sync.RWMutex
+	indexToAlg map[string]string
+	algToIndex map[string]string
+}{
+	indexToAlg: map[string]string{
+		"0": dsa.AlgorithmIDED25519,
+		"1": dsa.AlgorithmIDSECP256K1,
+		"2": dsa.AlgorithmIDSECP256R1,
+		"3": ecdh.X25519AlgorithmID,
+	},
+	algToIndex: map[string]string{
+		dsa.AlgorithmIDED25519:   "0",
+		dsa.AlgorithmIDSECP256K1: "1",
+		dsa.AlgorithmIDSECP256R1: "2",
+		ecdh.X25519AlgorithmID:   "3",
+	},
+}
+
+// RegisterKeyType adds the given key type index for an algorithm registered with [dsa.Register], so that
+// verification methods using it can be encoded in and decoded from DNS packets. Public keys are encoded
+// with the PublicKeyToBytes of the algorithm.
+func RegisterKeyType(index string, algorithmID string) error {
+	if !dsa.SupportsAlgorithmID(algorithmID) {
+		return fmt.Errorf("unsupported algorithm: %s", algorithmID)
+	}
+
+	keyTypes.Lock()
+	defer keyTypes.Unlock()
+
+	if alg, ok := keyTypes.indexToAlg[index]; ok {
+		return fmt.Errorf("key type index %s is already registered to %s", index, alg)
+	}
+
+	if i, ok := keyTypes.algToIndex[algorithmID]; ok {
+		return fmt.Errorf("%s is already registered to key type index %s", algorithmID, i)
+	}
+
+	keyTypes.indexToAlg[index] = algorithmID
+	keyTypes.algToIndex[algorithmID] = index
+	return nil
+}
+
+// dhtIndexToAlg returns the algorithm ID of the given key type index
+func dhtIndexToAlg(index string) (string, bool) {
+	keyTypes.RLock()
+	defer keyTypes.RUnlock()
+
+	alg, ok := keyTypes.indexToAlg[index]
+	return alg, ok
+}
+
+// algToDhtIndex returns the key type index of the given algorithm ID
+func algToDhtIndex(algorithmID string) (string, bool) {
+	keyTypes.RLock()
+	defer keyTypes.RUnlock()
+
+	index, ok := keyTypes.algToIndex[algorithmID]
+	return index, ok
+}
@@ -0,0 +1,56 @@
+package dnscodec
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMalformedPacket is returned when the input is not a valid DNS packet
+	ErrMalformedPacket = errors.New("malformed dns packet")
+
+	// ErrPacketTooLarge is returned when a DNS packet is larger than [MaxPacketSize] bytes
+	ErrPacketTooLarge = errors.New("dns packet is too large")
+
+	// ErrMissingRootRecord is returned when a DNS packet does not contain the `_did.<id>.` root record
+	ErrMissingRootRecord = errors.New("missing root record")
+
+	// ErrMalformedRecord is returned when the value of a record does not follow the did:dht record format,
+	// or a line of zone text cannot be parsed
+	ErrMalformedRecord = errors.New("malformed record")
+
+	// ErrDuplicateRecord is returned when a DNS packet contains more than one record with the same name
+	ErrDuplicateRecord = errors.New("duplicate record")
+
+	// ErrDuplicateID is returned when two verification methods or two services share the same id
+	ErrDuplicateID = errors.New("duplicate id")
+
+	// ErrDanglingReference is returned when the root record references a verification method or service
+	// record that is not in the DNS packet
+	ErrDanglingReference = errors.New("dangling reference")
+
+	// ErrUnsupportedKeyType is returned when a verification method uses a key type index that is not supported.
+	// https://did-dht.com/registry/index.html#key-type-index
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+)
+
+// RecordError describes why a specific record of a DNS packet was rejected. Err is one of the errors
+// defined in this package, and can be checked with [errors.Is].
+type RecordError struct {
+	// Name is the name of the record, e.g. `_k0._did.`
+	Name string
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %s: %s", e.Name, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// recordError wraps err in a [RecordError] for the record with the given name
+func recordError(name string, err error) error {
+	return &RecordError{Name: name, Err: err}
+}
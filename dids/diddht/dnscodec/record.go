@@ -0,0 +1,140 @@
+package dnscodec
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// MaxPacketSize is the maximum size of a DNS packet that fits into the v value of a BEP44 record
+const MaxPacketSize = 1000
+
+// ttl is the default TTL for DNS records recommended by https://did-dht.com/#note-1
+const ttl = 7200
+
+// maxCharacterStringSize is the maximum length of a single DNS character-string (RFC 1035 section 3.3)
+const maxCharacterStringSize = 255
+
+// Record is a TXT resource record of the DNS representation of a did:dht DID.
+//
+// Spec: https://did-dht.com/#dids-as-dns-records
+type Record struct {
+	// Name is the fully qualified name of the record, e.g. `_k0._did.`
+	Name string
+	// TTL is the time to live of the record in seconds
+	TTL uint32
+	// Strings are the character-strings of the record, each at most 255 bytes long
+	Strings []string
+}
+
+// NewRecord creates a TXT record with the recommended TTL, splitting the value into character-strings
+// of at most 255 bytes
+func NewRecord(name, value string) Record {
+	return Record{Name: name, TTL: ttl, Strings: splitTXT(value)}
+}
+
+// Value returns the value of the record, i.e. its character-strings joined together
+func (r Record) Value() string {
+	return strings.Join(r.Strings, "")
+}
+
+// ParsePacket returns the TXT records in the answer section of a DNS packet. Other sections and
+// resource types are ignored.
+func ParsePacket(packet []byte) ([]Record, error) {
+	if len(packet) > MaxPacketSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes", ErrPacketTooLarge, len(packet), MaxPacketSize)
+	}
+
+	var p dnsmessage.Parser
+	if _, err := p.Start(packet); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPacket, err)
+	}
+
+	// need to skip questions to move the index to the right place to read answers
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPacket, err)
+	}
+
+	var records []Record
+	for {
+		h, err := p.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformedPacket, err)
+		}
+
+		if h.Type != dnsmessage.TypeTXT {
+			if err := p.SkipAnswer(); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrMalformedPacket, err)
+			}
+			continue
+		}
+
+		value, err := p.TXTResource()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformedPacket, err)
+		}
+
+		records = append(records, Record{Name: h.Name.String(), TTL: h.TTL, Strings: value.TXT})
+	}
+
+	return records, nil
+}
+
+// PackRecords packs the records into the answer section of a DNS packet. Only the [Compression] option
+// is taken into account.
+func PackRecords(records []Record, opts ...MarshalOption) ([]byte, error) {
+	var o marshalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return pack(records, !o.uncompressed)
+}
+
+// pack packs the given records into the answer section of a DNS message
+func pack(records []Record, compress bool) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{})
+	if compress {
+		b.EnableCompression()
+	}
+
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range records {
+		name, err := dnsmessage.NewName(r.Name)
+		if err != nil {
+			return nil, recordError(r.Name, fmt.Errorf("%w: %w", ErrMalformedRecord, err))
+		}
+
+		header := dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeTXT, TTL: r.TTL}
+		if err := b.TXTResource(header, dnsmessage.TXTResource{TXT: r.Strings}); err != nil {
+			return nil, recordError(r.Name, fmt.Errorf("%w: %w", ErrMalformedRecord, err))
+		}
+	}
+
+	return b.Finish()
+}
+
+// splitTXT splits a TXT record value into character-strings of at most 255 bytes. Decoders join the
+// character-strings of a record back together, as described in https://did-dht.com/#dids-as-dns-records
+func splitTXT(body string) []string {
+	if len(body) <= maxCharacterStringSize {
+		return []string{body}
+	}
+
+	chunks := make([]string, 0, len(body)/maxCharacterStringSize+1)
+	for len(body) > maxCharacterStringSize {
+		chunks = append(chunks, body[:maxCharacterStringSize])
+		body = body[maxCharacterStringSize:]
+	}
+
+	return append(chunks, body)
+}
@@ -0,0 +1,217 @@
+package dnscodec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decentralized-identity/web5-go/dids/didcore"
+)
+
+// MarshalZone maps a DID document to the zone file presentation format (RFC 1035 section 5.1) of its
+// DNS packet representation, one TXT record per line. This is the form most other did:dht implementations
+// print records in, which makes it convenient for comparing their output.
+func MarshalZone(d *didcore.Document, opts ...MarshalOption) (string, error) {
+	records, err := Records(d, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatZone(records), nil
+}
+
+// UnmarshalZone parses records in zone file presentation format and returns the DID document they
+// represent, along with the did:dht specific properties of the DID. The same validation as
+// [UnmarshalDIDDocument] is applied.
+func UnmarshalZone(zone string) (*didcore.Document, Properties, error) {
+	records, err := ParseZone(zone)
+	if err != nil {
+		return nil, Properties{}, err
+	}
+
+	return DocumentFromRecords(records)
+}
+
+// FormatZone formats records in zone file presentation format, e.g.
+//
+//	_did.<id>.	7200	IN	TXT	"v=1;vm=k0;auth=k0"
+//	_k0._did.	7200	IN	TXT	"id=0;t=0;k=..."
+func FormatZone(records []Record) string {
+	var sb strings.Builder
+	for _, r := range records {
+		sb.WriteString(r.Name)
+		sb.WriteString("\t")
+		sb.WriteString(strconv.FormatUint(uint64(r.TTL), 10))
+		sb.WriteString("\tIN\tTXT")
+		for _, s := range r.Strings {
+			sb.WriteString("\t")
+			sb.WriteString(quote(s))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// ParseZone parses TXT records in zone file presentation format. Each line holds one record made up of
+// a fully qualified name, an optional TTL, an optional IN class, the TXT type and one or more
+// character-strings. Blank lines and comments starting with `;` are ignored.
+func ParseZone(zone string) ([]Record, error) {
+	var records []Record
+	for i, line := range strings.Split(zone, "\n") {
+		tokens, err := tokenize(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrMalformedRecord, i+1, err)
+		}
+
+		if len(tokens) == 0 {
+			continue
+		}
+
+		record, err := parseZoneRecord(tokens)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrMalformedRecord, i+1, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// token is a field of a line of zone text
+type token struct {
+	value  string
+	quoted bool
+}
+
+// parseZoneRecord maps the fields of a line of zone text to a record
+func parseZoneRecord(tokens []token) (Record, error) {
+	name := tokens[0]
+	if name.quoted || !strings.HasSuffix(name.value, ".") {
+		return Record{}, fmt.Errorf("expected a fully qualified name, got %q", name.value)
+	}
+
+	record := Record{Name: name.value, TTL: ttl}
+	rest := tokens[1:]
+
+	if len(rest) > 0 && !rest[0].quoted {
+		if v, err := strconv.ParseUint(rest[0].value, 10, 32); err == nil {
+			record.TTL = uint32(v)
+			rest = rest[1:]
+		}
+	}
+
+	if len(rest) > 0 && !rest[0].quoted && strings.EqualFold(rest[0].value, "IN") {
+		rest = rest[1:]
+	}
+
+	if len(rest) == 0 || rest[0].quoted || !strings.EqualFold(rest[0].value, "TXT") {
+		return Record{}, fmt.Errorf("only TXT records are supported")
+	}
+	rest = rest[1:]
+
+	if len(rest) == 0 {
+		return Record{}, fmt.Errorf("record %s has no value", record.Name)
+	}
+
+	for _, t := range rest {
+		if len(t.value) > maxCharacterStringSize {
+			return Record{}, fmt.Errorf("character-string is longer than %d bytes", maxCharacterStringSize)
+		}
+		record.Strings = append(record.Strings, t.value)
+	}
+
+	return record, nil
+}
+
+// tokenize splits a line of zone text into fields. Quoted fields may contain whitespace and `;`, and
+// support the `\X` and `\DDD` escapes of RFC 1035 section 5.1.
+func tokenize(line string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == ';':
+			return tokens, nil
+		case c == '"':
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(line) {
+					return nil, fmt.Errorf("unterminated quoted string")
+				}
+
+				if line[i] == '"' {
+					i++
+					break
+				}
+
+				if line[i] != '\\' {
+					sb.WriteByte(line[i])
+					i++
+					continue
+				}
+
+				b, n, err := unescape(line[i:])
+				if err != nil {
+					return nil, err
+				}
+				sb.WriteByte(b)
+				i += n
+			}
+			tokens = append(tokens, token{value: sb.String(), quoted: true})
+		default:
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' && line[i] != '\r' && line[i] != ';' && line[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, token{value: line[start:i]})
+		}
+	}
+
+	return tokens, nil
+}
+
+// unescape decodes the escape sequence at the start of s, returning the byte and the length of the sequence
+func unescape(s string) (byte, int, error) {
+	if len(s) < 2 {
+		return 0, 0, fmt.Errorf("incomplete escape sequence")
+	}
+
+	if s[1] < '0' || s[1] > '9' {
+		return s[1], 2, nil
+	}
+
+	if len(s) < 4 {
+		return 0, 0, fmt.Errorf("incomplete escape sequence %q", s)
+	}
+
+	v, err := strconv.ParseUint(s[1:4], 10, 8)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid escape sequence %q", s[:4])
+	}
+
+	return byte(v), 4, nil
+}
+
+// quote formats a character-string as a quoted string, escaping `"`, `\` and non printable bytes
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&sb, "\\%03d", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package dnscodec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/dids/didcore"
+)
+
+func Test_Zone(t *testing.T) {
+	didDoc := didcore.Document{
+		ID: "did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy",
+		Service: []didcore.Service{
+			{ID: "#dwn", Type: "DecentralizedWebNode", ServiceEndpoint: []string{"https://example.com/dwn?a=\"b\""}},
+		},
+	}
+
+	zone, err := MarshalZone(&didDoc, Types(1))
+	assert.NoError(t, err)
+	assert.Contains(t, zone, "_did.cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy.\t7200\tIN\tTXT\t\"v=1;id=did:dht:cwxob5rbhhu3z9x3gfqy6cthqgm6ngrh4k8s615n7pw11czoq4fy;srv=s0\"\n")
+	assert.Contains(t, zone, "_s0._did.\t7200\tIN\tTXT\t\"id=#dwn;t=DecentralizedWebNode;se=https://example.com/dwn?a=\\\"b\\\"\"\n")
+
+	doc, props, err := UnmarshalZone(zone)
+	assert.NoError(t, err)
+	assert.Equal(t, didDoc.Service, doc.Service)
+	assert.Equal(t, []int{1}, props.Types)
+
+	// zone text and packets describe the same records
+	records, err := ParseZone(zone)
+	assert.NoError(t, err)
+
+	packet, err := PackRecords(records)
+	assert.NoError(t, err)
+
+	parsed, err := ParsePacket(packet)
+	assert.NoError(t, err)
+	assert.Equal(t, records, parsed)
+}
+
+func Test_ParseZone(t *testing.T) {
+	records, err := ParseZone(`
+; a comment
+_did.abc. IN TXT "vm=k0;auth=k0" ; trailing comment
+_k0._did.abc.	3600	TXT	"id=0;t=0;" "k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"
+_s0._did. 7200 IN TXT "a\"b\\c\059"
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{
+		{Name: "_did.abc.", TTL: ttl, Strings: []string{"vm=k0;auth=k0"}},
+		{Name: "_k0._did.abc.", TTL: 3600, Strings: []string{"id=0;t=0;", "k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE"}},
+		{Name: "_s0._did.", TTL: ttl, Strings: []string{`a"b\c;`}},
+	}, records)
+
+	malformed := []string{
+		`_did. IN TXT "unterminated`,
+		`_did. IN A 127.0.0.1`,
+		`_did IN TXT "not fully qualified"`,
+		`_did. IN TXT`,
+		`_did. IN TXT "\99"`,
+	}
+
+	for _, zone := range malformed {
+		_, err := ParseZone(zone)
+		assert.True(t, errors.Is(err, ErrMalformedRecord), "expected error for %q", zone)
+	}
+}
+
+func FuzzParseZone(f *testing.F) {
+	f.Add("_did.abc.\t7200\tIN\tTXT\t\"vm=k0;auth=k0\"\n_k0._did.\t7200\tIN\tTXT\t\"id=0;t=0;k=YCcHYL2sYNPDlKaALcEmll2HHyT968M4UWbr-9CFGWE\"\n")
+	f.Add(`_s0._did. TXT "a\"b\\c\059" ; comment`)
+
+	f.Fuzz(func(t *testing.T, zone string) {
+		records, err := ParseZone(zone)
+		if err != nil {
+			return
+		}
+
+		// formatting the parsed records must round trip
+		reparsed, err := ParseZone(FormatZone(records))
+		assert.NoError(t, err)
+		assert.Equal(t, records, reparsed)
+	})
+}
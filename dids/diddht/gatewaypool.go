@@ -0,0 +1,273 @@
+package diddht
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/pkarr"
+	"github.com/tv42/zbase32"
+)
+
+const (
+	defaultPoolRetries     = 2
+	defaultPoolBackoff     = 200 * time.Millisecond
+	defaultPoolGracePeriod = 500 * time.Millisecond
+
+	// backgroundPutTimeout bounds the puts that are still running when a put returns after reaching quorum
+	backgroundPutTimeout = 30 * time.Second
+)
+
+// GatewayPool publishes to and fetches from several Pkarr gateways at once, so that a single slow or
+// unavailable gateway does not fail the whole operation.
+//
+//   - Every request to a gateway is retried with exponential backoff when it fails with a transient error.
+//   - Fetches fan out to all gateways concurrently. Only messages signed by the DID's identity key are
+//     considered, and the one with the highest sequence number wins.
+//   - Puts are sent to all gateways concurrently and return as soon as a quorum of gateways accepted the
+//     message. The puts to the remaining gateways finish in the background.
+//
+// A GatewayPool can be used wherever a single gateway is accepted, see [Gateways], [PublishGateways]
+// and [NewResolverWithGatewayPool].
+type GatewayPool struct {
+	gateways    []gateway
+	retries     int
+	backoff     time.Duration
+	gracePeriod time.Duration
+	quorum      int
+}
+
+// GatewayPoolOption is the type returned from each individual option function accepted by [NewGatewayPool]
+type GatewayPoolOption func(*GatewayPool) error
+
+// Retries sets the number of times a failed request to a gateway is retried, and the delay before the
+// first retry. The delay doubles after every attempt. Defaults to 2 retries and 200ms.
+func Retries(retries int, backoff time.Duration) GatewayPoolOption {
+	return func(p *GatewayPool) error {
+		p.retries = retries
+		p.backoff = backoff
+		return nil
+	}
+}
+
+// FetchGracePeriod sets how long a fetch keeps waiting for the remaining gateways once the first valid
+// message has been received, in case they hold a newer one. Defaults to 500ms. A period of 0 waits for
+// every gateway to respond.
+func FetchGracePeriod(period time.Duration) GatewayPoolOption {
+	return func(p *GatewayPool) error {
+		p.gracePeriod = period
+		return nil
+	}
+}
+
+// Quorum sets the number of gateways that must accept a message for a put to succeed. Defaults to 1.
+// The quorum must be at least 1.
+func Quorum(quorum int) GatewayPoolOption {
+	return func(p *GatewayPool) error {
+		if quorum <= 0 {
+			return fmt.Errorf("quorum must be at least 1, got %d", quorum)
+		}
+
+		p.quorum = quorum
+		return nil
+	}
+}
+
+// NewGatewayPool creates a new [GatewayPool] for the given Pkarr gateway URLs, using the provided HTTP client.
+// It returns an error if one of the options is invalid.
+func NewGatewayPool(gatewayURLs []string, client *http.Client, opts ...GatewayPoolOption) (*GatewayPool, error) {
+	gateways := make([]gateway, 0, len(gatewayURLs))
+	for _, gatewayURL := range gatewayURLs {
+		gateways = append(gateways, pkarr.NewClient(gatewayURL, client))
+	}
+
+	return newGatewayPool(gateways, opts...)
+}
+
+func newGatewayPool(gateways []gateway, opts ...GatewayPoolOption) (*GatewayPool, error) {
+	p := &GatewayPool{
+		gateways:    gateways,
+		retries:     defaultPoolRetries,
+		backoff:     defaultPoolBackoff,
+		gracePeriod: defaultPoolGracePeriod,
+		quorum:      1,
+	}
+
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, fmt.Errorf("invalid gateway pool option: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// Put publishes the signed BEP44 message to every gateway in the pool
+func (p *GatewayPool) Put(didID string, msg *bep44.Message) error {
+	return p.PutWithContext(context.Background(), didID, msg)
+}
+
+// PutWithContext publishes the signed BEP44 message to every gateway in the pool. It returns as soon as
+// the configured quorum of gateways accepted the message, or an error once the quorum can no longer be
+// reached. The remaining puts keep running for up to 30 seconds, even if ctx is canceled.
+func (p *GatewayPool) PutWithContext(ctx context.Context, didID string, msg *bep44.Message) error {
+	if len(p.gateways) == 0 {
+		return errors.New("no gateway provided")
+	}
+
+	if p.quorum > len(p.gateways) {
+		return fmt.Errorf("quorum of %d cannot be reached with %d gateways", p.quorum, len(p.gateways))
+	}
+
+	// the puts that are still running once the quorum is reached outlive this call, so they use their own
+	// bounded context, which is only canceled with ctx before that
+	putCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundPutTimeout)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(p.gateways))
+	for _, g := range p.gateways {
+		wg.Add(1)
+		go func(g gateway) {
+			defer wg.Done()
+			errs <- p.withRetries(putCtx, func() error {
+				return g.PutWithContext(putCtx, didID, msg)
+			})
+		}(g)
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+
+	var accepted int
+	var failures []error
+	for accepted < p.quorum {
+		if len(failures) > len(p.gateways)-p.quorum {
+			return fmt.Errorf("message accepted by %d of %d gateways, quorum is %d: %w", accepted, len(p.gateways), p.quorum, errors.Join(failures...))
+		}
+
+		select {
+		case err := <-errs:
+			if err != nil {
+				failures = append(failures, err)
+				continue
+			}
+			accepted++
+		case <-ctx.Done():
+			cancel()
+			return fmt.Errorf("message accepted by %d of %d gateways, quorum is %d: %w", accepted, len(p.gateways), p.quorum, ctx.Err())
+		}
+	}
+
+	return nil
+}
+
+// Fetch fetches the signed BEP44 message with the highest sequence number from the gateways in the pool
+func (p *GatewayPool) Fetch(didID string) (*bep44.Message, error) {
+	return p.FetchWithContext(context.Background(), didID)
+}
+
+// FetchWithContext fetches the signed BEP44 message with the highest sequence number from the gateways in
+// the pool. Messages that are not signed by the identity key encoded in didID are discarded.
+func (p *GatewayPool) FetchWithContext(ctx context.Context, didID string) (*bep44.Message, error) {
+	if len(p.gateways) == 0 {
+		return nil, errors.New("no gateway provided")
+	}
+
+	publicKeyBytes, err := zbase32.DecodeString(didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode identity key: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		msg *bep44.Message
+		err error
+	}
+
+	results := make(chan result, len(p.gateways))
+	for _, g := range p.gateways {
+		go func(g gateway) {
+			var msg *bep44.Message
+			err := p.withRetries(ctx, func() error {
+				var err error
+				msg, err = g.FetchWithContext(ctx, didID)
+				return err
+			})
+			if err == nil {
+				err = msg.Verify(publicKeyBytes)
+			}
+			results <- result{msg: msg, err: err}
+		}(g)
+	}
+
+	var best *bep44.Message
+	var failures []error
+	var grace <-chan time.Time
+	for pending := len(p.gateways); pending > 0; pending-- {
+		var res result
+		select {
+		case res = <-results:
+		case <-grace:
+			return best, nil
+		}
+
+		if res.err != nil {
+			failures = append(failures, res.err)
+			continue
+		}
+
+		if best == nil || res.msg.Seq > best.Seq {
+			best = res.msg
+		}
+
+		if grace == nil && p.gracePeriod > 0 {
+			timer := time.NewTimer(p.gracePeriod)
+			defer timer.Stop()
+			grace = timer.C
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no gateway returned a valid message: %w", errors.Join(failures...))
+	}
+
+	return best, nil
+}
+
+// withRetries calls fn until it succeeds, fails with a permanent error, or the retries are exhausted.
+func (p *GatewayPool) withRetries(ctx context.Context, fn func() error) error {
+	backoff := p.backoff
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= p.retries || !isTransient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// isTransient reports whether a failed gateway request is worth retrying. A missing or malformed record
+// will not change by asking again.
+func isTransient(err error) bool {
+	return !errors.Is(err, pkarr.ErrNotFound) &&
+		!errors.Is(err, bep44.ErrMalformedMessage) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,263 @@
+package diddht
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/pkarr"
+	"github.com/tv42/zbase32"
+)
+
+// fakeGateway is a gateway that fails a configurable number of times before answering
+type fakeGateway struct {
+	msg      *bep44.Message
+	err      error
+	failures int32
+	delay    time.Duration
+	calls    atomic.Int32
+	puts     atomic.Int32
+}
+
+func (g *fakeGateway) Put(didID string, msg *bep44.Message) error {
+	return g.PutWithContext(context.Background(), didID, msg)
+}
+
+func (g *fakeGateway) PutWithContext(ctx context.Context, _ string, msg *bep44.Message) error {
+	if err := g.respond(ctx); err != nil {
+		return err
+	}
+
+	g.msg = msg
+	g.puts.Add(1)
+	return nil
+}
+
+func (g *fakeGateway) Fetch(didID string) (*bep44.Message, error) {
+	return g.FetchWithContext(context.Background(), didID)
+}
+
+func (g *fakeGateway) FetchWithContext(ctx context.Context, _ string) (*bep44.Message, error) {
+	if err := g.respond(ctx); err != nil {
+		return nil, err
+	}
+
+	return g.msg, nil
+}
+
+func (g *fakeGateway) respond(ctx context.Context) error {
+	call := g.calls.Add(1)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(g.delay):
+	}
+
+	if call <= g.failures {
+		return errors.New("gateway unavailable")
+	}
+
+	return g.err
+}
+
+func newSignedMessage(t *testing.T, privateKey ed25519.PrivateKey, seq int64) *bep44.Message {
+	t.Helper()
+
+	publicKey := privateKey.Public().(ed25519.PublicKey) //nolint:forcetypeassert
+	msg, err := bep44.NewMessage([]byte("dns packet"), seq, publicKey, func(payload []byte) ([]byte, error) {
+		return ed25519.Sign(privateKey, payload), nil
+	})
+	assert.NoError(t, err)
+
+	// round trip to drop the identity key, as a gateway would
+	data, err := msg.Marshal()
+	assert.NoError(t, err)
+
+	var fetched bep44.Message
+	assert.NoError(t, bep44.UnmarshalMessage(data, &fetched))
+	return &fetched
+}
+
+func TestGatewayPool_Fetch(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	didID := zbase32.EncodeToString(publicKey)
+
+	_, otherPrivateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	t.Run("picks the highest valid seq", func(t *testing.T) {
+		pool, err := newGatewayPool([]gateway{
+			&fakeGateway{msg: newSignedMessage(t, privateKey, 10)},
+			&fakeGateway{msg: newSignedMessage(t, privateKey, 12)},
+			&fakeGateway{msg: newSignedMessage(t, otherPrivateKey, 99)},
+			&fakeGateway{err: pkarr.ErrNotFound},
+		}, FetchGracePeriod(0))
+		assert.NoError(t, err)
+
+		msg, err := pool.Fetch(didID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(12), msg.Seq)
+	})
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		flaky := &fakeGateway{msg: newSignedMessage(t, privateKey, 10), failures: 2}
+		pool, err := newGatewayPool([]gateway{flaky}, Retries(2, time.Millisecond))
+		assert.NoError(t, err)
+
+		msg, err := pool.Fetch(didID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), msg.Seq)
+		assert.Equal(t, int32(3), flaky.calls.Load())
+	})
+
+	t.Run("does not retry missing records", func(t *testing.T) {
+		missing := &fakeGateway{err: pkarr.ErrNotFound}
+		pool, err := newGatewayPool([]gateway{missing}, Retries(3, time.Millisecond))
+		assert.NoError(t, err)
+
+		_, err = pool.Fetch(didID)
+		assert.IsError(t, err, pkarr.ErrNotFound)
+		assert.Equal(t, int32(1), missing.calls.Load())
+	})
+
+	t.Run("does not wait for slow gateways once a valid message arrived", func(t *testing.T) {
+		pool, err := newGatewayPool([]gateway{
+			&fakeGateway{msg: newSignedMessage(t, privateKey, 10)},
+			&fakeGateway{msg: newSignedMessage(t, privateKey, 12), delay: 5 * time.Second},
+		}, FetchGracePeriod(10*time.Millisecond))
+		assert.NoError(t, err)
+
+		start := time.Now()
+		msg, err := pool.Fetch(didID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), msg.Seq)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+
+	t.Run("rejects messages not signed by the identity key", func(t *testing.T) {
+		pool, err := newGatewayPool([]gateway{
+			&fakeGateway{msg: newSignedMessage(t, otherPrivateKey, 10)},
+		})
+		assert.NoError(t, err)
+
+		_, err = pool.Fetch(didID)
+		assert.IsError(t, err, bep44.ErrInvalidSignature)
+	})
+}
+
+func TestGatewayPool_Put(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	msg := newSignedMessage(t, privateKey, 10)
+
+	t.Run("quorum reached", func(t *testing.T) {
+		pool, err := newGatewayPool([]gateway{
+			&fakeGateway{},
+			&fakeGateway{},
+			&fakeGateway{failures: 10},
+		}, Quorum(2), Retries(1, time.Millisecond))
+		assert.NoError(t, err)
+
+		assert.NoError(t, pool.Put("id", msg))
+	})
+
+	t.Run("quorum not reached", func(t *testing.T) {
+		pool, err := newGatewayPool([]gateway{
+			&fakeGateway{},
+			&fakeGateway{failures: 10},
+			&fakeGateway{failures: 10},
+		}, Quorum(2), Retries(1, time.Millisecond))
+		assert.NoError(t, err)
+
+		assert.Error(t, pool.Put("id", msg))
+	})
+
+	t.Run("returns once the quorum is reached", func(t *testing.T) {
+		slow := &fakeGateway{delay: 200 * time.Millisecond}
+		pool, err := newGatewayPool([]gateway{&fakeGateway{}, &fakeGateway{}, slow}, Quorum(2))
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		start := time.Now()
+		assert.NoError(t, pool.PutWithContext(ctx, "id", msg))
+		assert.True(t, time.Since(start) < 200*time.Millisecond)
+
+		// the slow gateway still receives the message, even once the caller's context is canceled
+		cancel()
+		assert.Equal(t, int32(0), slow.puts.Load())
+		time.Sleep(400 * time.Millisecond)
+		assert.Equal(t, int32(1), slow.puts.Load())
+	})
+
+	t.Run("fails once the quorum can't be reached", func(t *testing.T) {
+		slow := &fakeGateway{delay: 5 * time.Second}
+		pool, err := newGatewayPool([]gateway{
+			&fakeGateway{failures: 10},
+			&fakeGateway{failures: 10},
+			slow,
+		}, Quorum(2), Retries(0, 0))
+		assert.NoError(t, err)
+
+		start := time.Now()
+		assert.Error(t, pool.Put("id", msg))
+		assert.True(t, time.Since(start) < time.Second)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		slow := &fakeGateway{delay: 5 * time.Second}
+		pool, err := newGatewayPool([]gateway{slow})
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.IsError(t, pool.PutWithContext(ctx, "id", msg), context.Canceled)
+	})
+
+	t.Run("invalid quorum", func(t *testing.T) {
+		_, err := newGatewayPool([]gateway{&fakeGateway{}}, Quorum(0))
+		assert.Error(t, err)
+
+		_, err = NewGatewayPool([]string{"https://diddht.tbddev.org"}, http.DefaultClient, Quorum(-1))
+		assert.Error(t, err)
+	})
+
+	t.Run("quorum larger than the pool", func(t *testing.T) {
+		pool, err := newGatewayPool([]gateway{&fakeGateway{}}, Quorum(2))
+		assert.NoError(t, err)
+		assert.Error(t, pool.Put("id", msg))
+	})
+}
+
+func TestGatewayPool_CreateAndResolve(t *testing.T) {
+	relays := []string{newTestRelay(t).URL, newTestRelay(t).URL, newTestRelay(t).URL}
+	pool, err := NewGatewayPool(relays, http.DefaultClient, Quorum(3))
+	assert.NoError(t, err)
+
+	bearerDID, err := Create(Gateways(pool), Service("dwn", "DecentralizedWebNode", "https://example.com/dwn"))
+	assert.NoError(t, err)
+
+	// every relay received the record
+	for _, relay := range relays {
+		result, err := NewResolver(relay, http.DefaultClient).Resolve(bearerDID.URI)
+		assert.NoError(t, err)
+		assert.Equal(t, bearerDID.Document.Service, result.Document.Service)
+	}
+
+	// only one relay receives the update, the pool still resolves the latest version
+	document := bearerDID.Document
+	document.Service = nil
+	_, err = Update(bearerDID, document, PublishGateway(relays[1], http.DefaultClient))
+	assert.NoError(t, err)
+
+	result, err := NewResolverWithGatewayPool(pool).Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(result.Document.Service))
+}
@@ -1,10 +1,27 @@
+// Package bencode implements the Bencode encoding used by the BitTorrent protocol, the Mainline DHT
+// and BEP44. Values are mapped to and from Go values in the manner of encoding/json:
+//
+//   - byte strings map to string, []byte and byte arrays
+//   - integers map to signed and unsigned integer types
+//   - lists map to slices and arrays
+//   - dictionaries map to maps with string keys and to structs
+//
+// Struct fields are keyed by their name, or by the name given in a `bencode` struct tag. The tag
+// options "omitempty" and "-" behave as they do for encoding/json.
+//
+// Encoding always produces canonical Bencode, with dictionary keys in sorted order. Decoding is strict,
+// and rejects input that is not canonical: integers with leading zeros, negative zero, dictionary keys that
+// are unsorted or repeated and trailing data.
+//
+// More information about Bencode can be found at:
+// https://www.bittorrent.org/beps/bep_0003.html#bencoding
 package bencode
 
 import (
-	"bytes"
-	"errors"
 	"fmt"
-	"strconv"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 const (
@@ -14,249 +31,67 @@ const (
 	EndSuffix        = 'e'
 )
 
-// Marshal encodes the given input into a Bencode formatted byte array.
-// Note: Does not support encoding structs at the moment.
-// More information about Bencode can be found at:
-// https://wiki.theory.org/BitTorrentSpecification#Bencoding
-func Marshal(input any) ([]byte, error) {
-	switch v := input.(type) {
-	case string:
-		encoded := fmt.Sprintf("%d:%s", len(v), v)
-
-		return []byte(encoded), nil
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-		encoded := fmt.Sprintf("%c%d%c", IntegerPrefix, v, EndSuffix)
-
-		return []byte(encoded), nil
-	case []byte:
-		size := fmt.Sprintf("%d:", len(v))
-		encoded := append([]byte(size), v...)
-
-		return encoded, nil
-	case []any:
-		var b []byte
-		b = append(b, ListPrefix)
-
-		for _, item := range v {
-			encoded, err := Marshal(item)
-			if err != nil {
-				return nil, err
-			}
-
-			b = append(b, encoded...)
-		}
-
-		b = append(b, 'e')
-
-		return b, nil
-	case map[string]any:
-		var b []byte
-		b = append(b, 'd')
-
-		for key, value := range v {
-			encodedKey, err := Marshal(key)
-			if err != nil {
-				return nil, err
-			}
-
-			encodedValue, err := Marshal(value)
-			if err != nil {
-				return nil, err
-			}
-
-			b = append(b, encodedKey...)
-			b = append(b, encodedValue...)
-		}
-
-		b = append(b, 'e')
-
-		return b, nil
-	default:
-		return nil, fmt.Errorf("unsupported type: %T", input)
-	}
+// SyntaxError describes input that is not valid, canonical Bencode
+type SyntaxError struct {
+	// Offset is the number of bytes read before the error was found
+	Offset int64
+	msg    string
 }
 
-// Unmarshal decodes a Bencode formatted byte array into the the type of the provided output.
-// More information can be found at:
-// https://wiki.theory.org/BitTorrentSpecification#Bencoding
-func Unmarshal(input []byte, output any) error {
-	switch v := output.(type) {
-	case *string:
-		_, err := unmarshalString(input, v)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal string: %w", err)
-		}
-	case *int:
-		_, err := unmarshalInt(input, v)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal int: %w", err)
-		}
-	case *[]any:
-		_, err := unmarshalList(input, v)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal list: %w", err)
-		}
-	case *map[string]any:
-		_, err := unmarshalDict(input, *v)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal dict: %w", err)
-		}
-	default:
-		return fmt.Errorf("unsupported type: %T", output)
-	}
-
-	return nil
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("bencode: %s at offset %d", e.msg, e.Offset)
 }
 
-// unmarshalValue decodes a Bencode value from a byte slice and returns
-// the decoded value, the # of bytes processed, and an error if any.
-func unmarshalValue(input []byte) (any, int, error) {
-	switch input[0] {
-	case IntegerPrefix:
-		var value int
-		n, err := unmarshalInt(input, &value)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		return value, n, nil
-	case ListPrefix:
-		value := make([]any, 0)
-		n, err := unmarshalList(input, &value)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		return value, n, nil
-	case DictionaryPrefix:
-		value := make(map[string]any)
-		n, err := unmarshalDict(input, value)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		return value, n, nil
-	default:
-		var value string
-		n, err := unmarshalString(input, &value)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		return value, n, nil
-	}
+// UnmarshalTypeError describes a Bencode value that cannot be stored in a Go value of a given type
+type UnmarshalTypeError struct {
+	// Value is the kind of Bencode value, e.g. "integer" or "list"
+	Value string
+	Type  reflect.Type
+	// Offset is the number of bytes read before the value
+	Offset int64
 }
 
-// unmarshalString decodes a Bencode string from a byte slice.
-// It returns the total bytes processed, and an error if any.
-func unmarshalString(input []byte, output *string) (int, error) {
-	// Find the colon index, which separates the length part from the data part.
-	colonIndex := bytes.IndexByte(input, ':')
-	if colonIndex == -1 {
-		return 0, errors.New("colon not found in input")
-	}
-
-	// Extract the length part and convert it to an integer.
-	lengthPart := input[:colonIndex]
-	length, err := strconv.Atoi(string(lengthPart))
-	if err != nil {
-		return 0, fmt.Errorf("failed to convert length: %w", err)
-	}
-
-	// Calculate the start and end of the actual string data.
-	start := colonIndex + 1
-	end := start + length
-
-	// Check if the calculated end exceeds the input length.
-	if end > len(input) {
-		return 0, errors.New("data length exceeds input length")
-	}
-
-	// Extract and return the actual string data.
-	*output = string(input[start:end])
-	return end, nil // end is the total bytes processed.
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("bencode: cannot unmarshal %s into Go value of type %s at offset %d", e.Value, e.Type, e.Offset)
 }
 
-// unmarshalInt decodes a Bencode integer from a byte slice.
-// It returns the total bytes processed, and an error if any.
-func unmarshalInt(input []byte, output *int) (int, error) {
-	if input[0] != IntegerPrefix {
-		return 0, fmt.Errorf("input does not start with %q", IntegerPrefix)
-	}
-
-	// Find the suffix byte which marks the end of the integer.
-	endIndex := bytes.IndexByte(input, EndSuffix)
-	if endIndex == -1 {
-		return 0, errors.New("end byte not found in input")
-	}
-
-	// Extract the data between prefix and suffix bytes and convert it to an integer.
-	intPart := input[1:endIndex]
-	str := string(intPart)
-	value, err := strconv.Atoi(str)
-	if err != nil {
-		return 0, fmt.Errorf("failed to convert %s into int: %w", str, err)
-	}
-
-	// Assign the decoded integer to the output.
-	*output = value
-	return endIndex + 1, nil // endIndex + 1 is the total bytes processed.
+// field is a struct field mapped to a dictionary key
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
 }
 
-// unmarshalList decodes a Bencode list from a byte slice.
-// It returns the total bytes processed, and an error if any.
-func unmarshalList(input []byte, output *[]any) (int, error) {
-	// Iterate over the input bytes and decode each list item.
-	i := 1 // Skip the prefix byte.
-	for i < len(input) {
-		// Check if we have reached the end of the list.
-		if input[i] == EndSuffix {
-			return i + 1, nil // i + 1 is the total bytes processed.
-		}
+// structFields returns the fields of a struct type that map to dictionary keys, sorted by key
+func structFields(t reflect.Type) ([]field, error) {
+	var fields []field
+	seen := make(map[string]bool)
 
-		value, n, err := unmarshalValue(input[i:])
-		if err != nil {
-			return 0, fmt.Errorf("failed to decode list item: %w", err)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
 		}
 
-		*output = append(*output, value)
-		i += n
-	}
-
-	return i, errors.New("unexpected end of input")
-}
-
-// unmarshalDict decodes a Bencode dictionary from a byte slice.
-// It returns the total bytes processed, and an error if any.
-func unmarshalDict(input []byte, output map[string]any) (int, error) {
-	if input[0] != DictionaryPrefix {
-		return 0, errors.New("input does not start with 'd'")
-	}
-
-	// Iterate over the input bytes and decode each key-value pair.
-	i := 1 // Skip the prefix byte.
-	for i < len(input) {
-		// Check if we have reached the end of the dictionary.
-		if input[i] == EndSuffix {
-			return i + 1, nil // i + 1 is the total bytes processed.
+		tag := sf.Tag.Get("bencode")
+		if tag == "-" {
+			continue
 		}
 
-		// Decode the key.
-		var key string
-		n, err := unmarshalString(input[i:], &key)
-		if err != nil {
-			return 0, fmt.Errorf("failed to decode key: %w", err)
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
 		}
-		i += n
 
-		value, n, err := unmarshalValue(input[i:])
-		if err != nil {
-			return 0, fmt.Errorf("failed to decode value: %w", err)
+		if seen[name] {
+			return nil, fmt.Errorf("bencode: struct %s has more than one field with key %q", t, name)
 		}
+		seen[name] = true
 
-		output[key] = value
-		i += n
+		fields = append(fields, field{name: name, index: i, omitEmpty: opts == "omitempty"})
 	}
 
-	return i, errors.New("unexpected end of input")
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+
+	return fields, nil
 }
@@ -1,6 +1,10 @@
 package bencode_test
 
 import (
+	"errors"
+	"io"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
@@ -67,6 +71,27 @@ func TestMarshal_Dict(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestMarshal_DictSortedKeys(t *testing.T) {
+	input := map[string]any{
+		"y": "q",
+		"a": map[string]any{"target": "x", "id": "y"},
+		"t": "aa",
+		"q": "get",
+	}
+
+	actual, err := bencode.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("d1:ad2:id1:y6:target1:xe1:q3:get1:t2:aa1:y1:qe"), actual)
+}
+
+func TestUnmarshal_Truncated(t *testing.T) {
+	inputs := []string{"", "d", "d1:a", "l", "i", "-1:a", "d1:al"}
+	for _, input := range inputs {
+		output := make(map[string]any)
+		assert.Error(t, bencode.Unmarshal([]byte(input), &output), "input %q", input)
+	}
+}
+
 func TestUnmarshal_String(t *testing.T) {
 	input := []byte("4:spam")
 	var output string
@@ -156,3 +181,161 @@ func TestUnmarshal_Dict(t *testing.T) {
 		}
 	}
 }
+
+type peer struct {
+	ID      []byte   `bencode:"id"`
+	Port    uint16   `bencode:"port"`
+	Seq     int64    `bencode:"seq,omitempty"`
+	Name    string   `bencode:"name,omitempty"`
+	Key     [4]byte  `bencode:"k"`
+	Values  []string `bencode:"values,omitempty"`
+	Next    *peer    `bencode:"next"`
+	Ignored string   `bencode:"-"`
+}
+
+func TestMarshal_Struct(t *testing.T) {
+	input := peer{
+		ID:      []byte{0, 1},
+		Port:    6881,
+		Key:     [4]byte{'a', 'b', 'c', 'd'},
+		Next:    &peer{ID: []byte{}, Seq: -5},
+		Ignored: "x",
+	}
+
+	actual, err := bencode.Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, "d2:id2:\x00\x011:k4:abcd4:nextd2:id0:1:k4:\x00\x00\x00\x004:porti0e3:seqi-5ee4:porti6881ee", string(actual))
+
+	var output peer
+	assert.NoError(t, bencode.Unmarshal(actual, &output))
+	input.Ignored = ""
+	assert.Equal(t, input, output)
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+	inputs := []any{nil, 1.5, true, map[int]any{1: "a"}, []any{nil}}
+	for _, input := range inputs {
+		_, err := bencode.Marshal(input)
+		assert.Error(t, err, "input %v", input)
+	}
+}
+
+func TestUnmarshal_Int64(t *testing.T) {
+	var i int64
+	assert.NoError(t, bencode.Unmarshal([]byte("i-9223372036854775808e"), &i))
+	assert.Equal(t, int64(math.MinInt64), i)
+
+	var u uint64
+	assert.NoError(t, bencode.Unmarshal([]byte("i18446744073709551615e"), &u))
+	assert.Equal(t, uint64(math.MaxUint64), u)
+
+	var small int8
+	var typeErr *bencode.UnmarshalTypeError
+	assert.True(t, errors.As(bencode.Unmarshal([]byte("i128e"), &small), &typeErr))
+	assert.True(t, errors.As(bencode.Unmarshal([]byte("i-1e"), &u), &typeErr))
+}
+
+func TestUnmarshal_Interface(t *testing.T) {
+	var output any
+	assert.NoError(t, bencode.Unmarshal([]byte("d1:ai-3e1:bl2:xyee"), &output))
+	assert.Equal(t, any(map[string]any{"a": int64(-3), "b": []any{"xy"}}), output)
+}
+
+func TestUnmarshal_NonCanonical(t *testing.T) {
+	inputs := []string{
+		"i03e",
+		"i-0e",
+		"i-03e",
+		"ie",
+		"i-e",
+		"i1-e",
+		"i+1e",
+		"01:a",
+		"d1:b0:1:a0:e",
+		"d1:a0:1:a0:e",
+		"di1e0:e",
+		"i1ei2e",
+		"4:spamx",
+		"i123456789012345678901234e",
+		"99999999999999999999999:a",
+	}
+
+	for _, input := range inputs {
+		var output any
+		var syntaxErr *bencode.SyntaxError
+		err := bencode.Unmarshal([]byte(input), &output)
+		assert.True(t, errors.As(err, &syntaxErr), "input %q: %v", input, err)
+	}
+}
+
+func TestUnmarshal_TypeMismatch(t *testing.T) {
+	var s string
+	assert.Error(t, bencode.Unmarshal([]byte("i1e"), &s))
+
+	var key [4]byte
+	assert.Error(t, bencode.Unmarshal([]byte("3:abc"), &key))
+
+	var b []byte
+	assert.Error(t, bencode.Unmarshal([]byte("li1ee"), &b))
+
+	assert.Error(t, bencode.Unmarshal([]byte("i1e"), nil))
+	assert.Error(t, bencode.Unmarshal([]byte("i1e"), s))
+}
+
+func TestUnmarshal_UnknownKeys(t *testing.T) {
+	var output peer
+	assert.NoError(t, bencode.Unmarshal([]byte("d5:extrali1ee2:id1:x4:porti1ee"), &output))
+	assert.Equal(t, peer{ID: []byte("x"), Port: 1}, output)
+
+	assert.Error(t, bencode.Unmarshal([]byte("d5:extrai01e2:id1:xe"), &output))
+}
+
+func TestUnmarshal_DeeplyNested(t *testing.T) {
+	input := strings.Repeat("l", 10000) + strings.Repeat("e", 10000)
+
+	var output any
+	assert.Error(t, bencode.Unmarshal([]byte(input), &output))
+}
+
+func TestDecoder(t *testing.T) {
+	dec := bencode.NewDecoder(strings.NewReader("i1e4:spamd1:ai2ee"))
+
+	var i int
+	assert.NoError(t, dec.Decode(&i))
+	assert.Equal(t, 1, i)
+
+	var s string
+	assert.NoError(t, dec.Decode(&s))
+	assert.Equal(t, "spam", s)
+
+	var m map[string]int
+	assert.NoError(t, dec.Decode(&m))
+	assert.Equal(t, map[string]int{"a": 2}, m)
+	assert.Equal(t, int64(17), dec.InputOffset())
+
+	assert.Equal(t, io.EOF, dec.Decode(&s))
+
+	// a value cut off by the end of the stream is an error, not io.EOF
+	dec = bencode.NewDecoder(strings.NewReader("l4:spa"))
+	var l []string
+	err := dec.Decode(&l)
+	assert.Error(t, err)
+	assert.NotEqual(t, io.EOF, err)
+}
+
+// FuzzUnmarshal checks that any input accepted by the decoder is canonical, i.e. encodes back to the same bytes
+func FuzzUnmarshal(f *testing.F) {
+	f.Add([]byte("d1:ad2:id1:y6:target1:xe1:q3:get1:t2:aa1:y1:qe"))
+	f.Add([]byte("li-1ei0e0:le"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var output any
+		if err := bencode.Unmarshal(data, &output); err != nil {
+			return
+		}
+
+		encoded, err := bencode.Marshal(output)
+		assert.NoError(t, err)
+		assert.Equal(t, data, encoded)
+	})
+}
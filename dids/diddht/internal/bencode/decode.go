@@ -0,0 +1,466 @@
+package bencode
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// maxDepth bounds the nesting of lists and dictionaries, so deeply nested input cannot exhaust the stack
+const maxDepth = 512
+
+// maxIntegerDigits bounds the length of integers and byte string lengths. It fits any 64 bit integer.
+const maxIntegerDigits = 20
+
+// Unmarshal decodes the canonical Bencode value in data and stores the result in the value pointed to by
+// output. When decoding into an interface value, byte strings are stored as string, integers as int64,
+// lists as []any and dictionaries as map[string]any. Dictionary keys that do not match a struct field
+// are ignored. Data following the value is an error.
+func Unmarshal(data []byte, output any) error {
+	r := bytes.NewReader(data)
+	d := &decodeState{r: r}
+
+	if err := d.decode(output); err != nil {
+		if errors.Is(err, io.EOF) {
+			return d.syntaxError("unexpected end of input")
+		}
+		return err
+	}
+
+	if r.Len() > 0 {
+		return d.syntaxError("trailing data after top-level value")
+	}
+
+	return nil
+}
+
+// Decoder reads a stream of Bencode values from an input stream
+type Decoder struct {
+	d decodeState
+}
+
+// NewDecoder returns a decoder that reads from r. The decoder buffers its input, and may read data
+// from r beyond the values it decodes.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{d: decodeState{r: bufio.NewReader(r)}}
+}
+
+// Decode reads the next Bencode value from the input and stores it in the value pointed to by output,
+// following the rules of [Unmarshal]. It returns io.EOF when the input ends before the start of a value.
+func (dec *Decoder) Decode(output any) error {
+	return dec.d.decode(output)
+}
+
+// InputOffset returns the number of bytes of input consumed so far
+func (dec *Decoder) InputOffset() int64 {
+	return dec.d.offset
+}
+
+// decodeState holds the state of a decoder reading from r
+type decodeState struct {
+	r interface {
+		io.Reader
+		io.ByteScanner
+	}
+	offset int64
+	depth  int
+}
+
+// decode decodes a single top-level value into output, which must be a non-nil pointer
+func (d *decodeState) decode(output any) error {
+	rv := reflect.ValueOf(output)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("bencode: unmarshal requires a non-nil pointer, got %T", output)
+	}
+
+	d.depth = 0
+
+	// the end of input is only io.EOF before the first byte of a value
+	if _, err := d.peek(); err != nil {
+		return err
+	}
+
+	return d.value(rv)
+}
+
+func (d *decodeState) syntaxError(msg string) error {
+	return &SyntaxError{Offset: d.offset, msg: msg}
+}
+
+// readByte reads the next byte, treating the end of input as an error
+func (d *decodeState) readByte() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, d.syntaxError("unexpected end of input")
+		}
+		return 0, err
+	}
+
+	d.offset++
+	return c, nil
+}
+
+// peek returns the next byte without consuming it
+func (d *decodeState) peek() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	return c, d.r.UnreadByte()
+}
+
+// value decodes the next value into v
+func (d *decodeState) value(v reflect.Value) error {
+	// allocate pointers until reaching a value that can be set
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Interface {
+		if v.NumMethod() > 0 {
+			return fmt.Errorf("bencode: cannot unmarshal into non-empty interface %s", v.Type())
+		}
+
+		value, err := d.valueInterface()
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	c, err := d.peek()
+	if err != nil {
+		return d.syntaxError("unexpected end of input")
+	}
+
+	switch {
+	case c == IntegerPrefix:
+		return d.integer(v)
+	case c == ListPrefix:
+		return d.list(v)
+	case c == DictionaryPrefix:
+		return d.dict(v)
+	case c >= '0' && c <= '9':
+		return d.byteString(v)
+	default:
+		return d.syntaxError(fmt.Sprintf("invalid character %q looking for beginning of value", c))
+	}
+}
+
+// valueInterface decodes the next value into its generic Go representation
+func (d *decodeState) valueInterface() (any, error) {
+	var value any
+
+	c, err := d.peek()
+	if err != nil {
+		return nil, d.syntaxError("unexpected end of input")
+	}
+
+	switch {
+	case c == IntegerPrefix:
+		var i int64
+		err = d.integer(reflect.ValueOf(&i).Elem())
+		value = i
+	case c == ListPrefix:
+		l := make([]any, 0)
+		err = d.list(reflect.ValueOf(&l).Elem())
+		value = l
+	case c == DictionaryPrefix:
+		m := make(map[string]any)
+		err = d.dict(reflect.ValueOf(&m).Elem())
+		value = m
+	case c >= '0' && c <= '9':
+		var s string
+		err = d.byteString(reflect.ValueOf(&s).Elem())
+		value = s
+	default:
+		return nil, d.syntaxError(fmt.Sprintf("invalid character %q looking for beginning of value", c))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// readDigits reads a canonical decimal integer terminated by end. Leading zeros and negative zero are
+// rejected, and a sign is only accepted if signed is set.
+func (d *decodeState) readDigits(end byte, signed bool) (string, error) {
+	var digits []byte
+	for {
+		c, err := d.readByte()
+		if err != nil {
+			return "", err
+		}
+
+		if c == end {
+			break
+		}
+
+		if len(digits) > maxIntegerDigits {
+			return "", d.syntaxError("integer is too long")
+		}
+
+		if (c < '0' || c > '9') && (c != '-' || !signed || len(digits) > 0) {
+			return "", d.syntaxError(fmt.Sprintf("invalid character %q in integer", c))
+		}
+
+		digits = append(digits, c)
+	}
+
+	s := string(digits)
+	switch {
+	case s == "" || s == "-":
+		return "", d.syntaxError("missing digits in integer")
+	case s == "-0":
+		return "", d.syntaxError("negative zero is not canonical")
+	case len(s) > 1 && s[0] == '0', len(s) > 2 && s[0] == '-' && s[1] == '0':
+		return "", d.syntaxError("leading zeros are not canonical")
+	}
+
+	return s, nil
+}
+
+// readString reads a byte string
+func (d *decodeState) readString() ([]byte, error) {
+	digits, err := d.readDigits(':', false)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return nil, d.syntaxError("byte string length is out of range")
+	}
+
+	// the buffer grows as data arrives, so a large length alone cannot cause a large allocation
+	var buf bytes.Buffer
+	read, err := io.CopyN(&buf, d.r, n)
+	d.offset += read
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, d.syntaxError("unexpected end of input")
+		}
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// integer decodes an integer into v
+func (d *decodeState) integer(v reflect.Value) error {
+	start := d.offset
+	if _, err := d.readByte(); err != nil {
+		return err
+	}
+
+	digits, err := d.readDigits(EndSuffix, true)
+	if err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(digits, 10, v.Type().Bits())
+		if err != nil {
+			return &UnmarshalTypeError{Value: "integer " + digits, Type: v.Type(), Offset: start}
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(digits, 10, v.Type().Bits())
+		if err != nil {
+			return &UnmarshalTypeError{Value: "integer " + digits, Type: v.Type(), Offset: start}
+		}
+		v.SetUint(u)
+	default:
+		return &UnmarshalTypeError{Value: "integer", Type: v.Type(), Offset: start}
+	}
+
+	return nil
+}
+
+// byteString decodes a byte string into v
+func (d *decodeState) byteString(v reflect.Value) error {
+	start := d.offset
+	b, err := d.readString()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(string(b))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(b)
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		if len(b) != v.Len() {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("byte string of length %d", len(b)), Type: v.Type(), Offset: start}
+		}
+		reflect.Copy(v, reflect.ValueOf(b))
+	default:
+		return &UnmarshalTypeError{Value: "byte string", Type: v.Type(), Offset: start}
+	}
+
+	return nil
+}
+
+// enter consumes the prefix of a list or dictionary, bounding how deeply they are nested
+func (d *decodeState) enter() error {
+	if d.depth >= maxDepth {
+		return d.syntaxError("exceeded maximum nesting depth")
+	}
+	d.depth++
+
+	_, err := d.readByte()
+	return err
+}
+
+// more consumes the suffix of a list or dictionary, reporting whether it has more elements
+func (d *decodeState) more() (bool, error) {
+	c, err := d.peek()
+	if err != nil {
+		return false, d.syntaxError("unexpected end of input")
+	}
+
+	if c != EndSuffix {
+		return true, nil
+	}
+
+	d.depth--
+	_, err = d.readByte()
+	return false, err
+}
+
+// list decodes a list into v
+func (d *decodeState) list(v reflect.Value) error {
+	start := d.offset
+	isByteArray := (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.Uint8
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || isByteArray {
+		return &UnmarshalTypeError{Value: "list", Type: v.Type(), Offset: start}
+	}
+
+	if err := d.enter(); err != nil {
+		return err
+	}
+
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+
+	i := 0
+	for {
+		more, err := d.more()
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		} else if i >= v.Len() {
+			return &UnmarshalTypeError{Value: "list longer than the array", Type: v.Type(), Offset: start}
+		}
+
+		if err := d.value(v.Index(i)); err != nil {
+			return err
+		}
+		i++
+	}
+
+	// elements of an array missing from the list are zeroed
+	for ; v.Kind() == reflect.Array && i < v.Len(); i++ {
+		v.Index(i).SetZero()
+	}
+
+	return nil
+}
+
+// dict decodes a dictionary into v
+func (d *decodeState) dict(v reflect.Value) error {
+	start := d.offset
+
+	var fields map[string]int
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	case v.Kind() == reflect.Struct:
+		list, err := structFields(v.Type())
+		if err != nil {
+			return err
+		}
+
+		fields = make(map[string]int, len(list))
+		for _, f := range list {
+			fields[f.name] = f.index
+		}
+	default:
+		return &UnmarshalTypeError{Value: "dictionary", Type: v.Type(), Offset: start}
+	}
+
+	if err := d.enter(); err != nil {
+		return err
+	}
+
+	var prev []byte
+	for i := 0; ; i++ {
+		more, err := d.more()
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+
+		if c, _ := d.peek(); c < '0' || c > '9' {
+			return d.syntaxError("dictionary keys must be byte strings")
+		}
+
+		key, err := d.readString()
+		if err != nil {
+			return err
+		}
+
+		if i > 0 && bytes.Compare(prev, key) >= 0 {
+			return d.syntaxError(fmt.Sprintf("dictionary key %q is not in sorted order or repeated", key))
+		}
+		prev = key
+
+		if v.Kind() == reflect.Map {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(string(key)).Convert(v.Type().Key()), elem)
+			continue
+		}
+
+		index, ok := fields[string(key)]
+		if !ok {
+			// unknown keys are skipped, but must still be valid
+			if _, err := d.valueInterface(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := d.value(v.Field(index)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package bencode
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Marshal returns the canonical Bencode encoding of v. Floats, booleans, channels and functions cannot be
+// encoded. Nil pointers and interfaces are omitted from dictionaries, and are an error anywhere else.
+func Marshal(v any) ([]byte, error) {
+	var e encoder
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+
+	return e.buf, nil
+}
+
+// encoder appends the encoding of values to a buffer
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		return fmt.Errorf("unsupported value: nil")
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		e.bytes([]byte(v.String()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf = append(e.buf, IntegerPrefix)
+		e.buf = strconv.AppendInt(e.buf, v.Int(), 10)
+		e.buf = append(e.buf, EndSuffix)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.buf = append(e.buf, IntegerPrefix)
+		e.buf = strconv.AppendUint(e.buf, v.Uint(), 10)
+		e.buf = append(e.buf, EndSuffix)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.bytes(byteSlice(v))
+			return nil
+		}
+
+		e.buf = append(e.buf, ListPrefix)
+		for i := 0; i < v.Len(); i++ {
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, EndSuffix)
+	case reflect.Map:
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("unsupported value: nil %s", v.Type())
+		}
+
+		return e.encode(v.Elem())
+	default:
+		return fmt.Errorf("unsupported type: %s", v.Type())
+	}
+
+	return nil
+}
+
+// bytes appends a byte string
+func (e *encoder) bytes(b []byte) {
+	e.buf = strconv.AppendInt(e.buf, int64(len(b)), 10)
+	e.buf = append(e.buf, ':')
+	e.buf = append(e.buf, b...)
+}
+
+// encodeMap appends a dictionary with the entries of a map in sorted key order
+func (e *encoder) encodeMap(v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported type: %s, dictionary keys must be strings", v.Type())
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	e.buf = append(e.buf, DictionaryPrefix)
+	for _, key := range keys {
+		value := v.MapIndex(key)
+		if isNil(value) {
+			continue
+		}
+
+		e.bytes([]byte(key.String()))
+		if err := e.encode(value); err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, EndSuffix)
+
+	return nil
+}
+
+// encodeStruct appends a dictionary with the fields of a struct in sorted key order
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	fields, err := structFields(v.Type())
+	if err != nil {
+		return err
+	}
+
+	e.buf = append(e.buf, DictionaryPrefix)
+	for _, f := range fields {
+		value := v.Field(f.index)
+		if isNil(value) || (f.omitEmpty && isEmpty(value)) {
+			continue
+		}
+
+		e.bytes([]byte(f.name))
+		if err := e.encode(value); err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, EndSuffix)
+
+	return nil
+}
+
+// byteSlice returns the contents of a byte slice or byte array
+func byteSlice(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
+
+// isNil reports whether v is a nil pointer or interface
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// isEmpty reports whether v is omitted from a dictionary by the omitempty tag option
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
@@ -1,11 +1,31 @@
 package bep44
 
 import (
+	"crypto/ed25519"
 	"encoding/binary"
 	"errors"
 	"fmt"
+
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bencode"
 )
 
+var (
+	// ErrMalformedMessage is returned when a BEP44 message cannot be decoded because it is
+	// truncated or otherwise does not conform to the Pkarr relay wire format.
+	ErrMalformedMessage = errors.New("malformed bep44 message")
+
+	// ErrInvalidSignature is returned when the signature of a BEP44 message does not verify
+	// against the public key it is expected to be signed by.
+	ErrInvalidSignature = errors.New("invalid bep44 message signature")
+
+	// ErrPayloadTooLarge is returned when the bencoded seq and v values of a BEP44 message exceed
+	// [MaxPayloadSize] bytes.
+	ErrPayloadTooLarge = errors.New("bencoded payload is too large")
+)
+
+// MaxPayloadSize is the maximum number of bytes the bencoded seq and v values of a BEP44 message can take up.
+const MaxPayloadSize = 1000
+
 // Message Represents a BEP44 message, which is used for storing and retrieving data in the Mainline DHT
 // network.
 //
@@ -44,8 +64,7 @@ func NewMessage(dnsPayload []byte, seq int64, publicKeyBytes []byte, signer Sign
 		return nil, fmt.Errorf("failed to bencode payload: %w", err)
 	}
 
-	// remove the 1st (d) and last (e) byte from the bencoded bytes to conform to the BEP44 spec
-	// and sign the payload
+	// sign the payload
 	signedBytes, err := signer(bencodedBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign: %w", err)
@@ -80,11 +99,11 @@ func (msg *Message) Marshal() ([]byte, error) {
 // UnmarshalMessage decodes the given byte slice into a BEP44 message.
 func UnmarshalMessage(data []byte, b *Message) error {
 	if len(data) < 72 {
-		return fmt.Errorf("pkarr response must be at least 72 bytes but got: %d", len(data))
+		return fmt.Errorf("%w: pkarr response must be at least 72 bytes but got: %d", ErrMalformedMessage, len(data))
 	}
 
 	if len(data) > 1072 {
-		return fmt.Errorf("pkarr response is larger than 1072 bytes, got: %d", len(data))
+		return fmt.Errorf("%w: pkarr response is larger than 1072 bytes, got: %d", ErrMalformedMessage, len(data))
 	}
 
 	b.sig = data[:64]
@@ -94,14 +113,62 @@ func UnmarshalMessage(data []byte, b *Message) error {
 	return nil
 }
 
+// Verify checks that the message signature is a valid Ed25519 signature, produced by the given
+// public key, over the bencoded seq and v values of the message. On success, the public key is
+// recorded as the message's identity key.
+func (msg *Message) Verify(publicKeyBytes []byte) error {
+	if len(publicKeyBytes) != ed25519.PublicKeySize {
+		return fmt.Errorf("public key must be %d bytes but got: %d", ed25519.PublicKeySize, len(publicKeyBytes))
+	}
+
+	if len(msg.sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: signature must be %d bytes but got: %d", ErrInvalidSignature, ed25519.SignatureSize, len(msg.sig))
+	}
+
+	bencodedBytes, err := bencodeBepPayload(msg.Seq, msg.V)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedMessage, err)
+	}
+
+	if !ed25519.Verify(publicKeyBytes, bencodedBytes, msg.sig) {
+		return ErrInvalidSignature
+	}
+
+	msg.k = publicKeyBytes
+
+	return nil
+}
+
+// signedPayload holds the values of a mutable item that are covered by its signature.
+// https://www.bittorrent.org/beps/bep_0044.html#signature-verification
+type signedPayload struct {
+	Seq int64  `bencode:"seq"`
+	V   []byte `bencode:"v"`
+}
+
+// bencodeBepPayload returns the bencoded seq and v values that a mutable item signature is computed
+// over. This is the bencoded dictionary of the values with its surrounding `d` and `e` removed.
 func bencodeBepPayload(seq int64, v []byte) ([]byte, error) {
 	if len(v) == 0 {
 		return nil, errors.New("v cannot be empty")
 	}
 
-	re := fmt.Sprintf("3:seqi%de1:v%d:%s", seq, len(v), v)
-	if len(re) > 1000 {
-		return nil, errors.New("bencoded payload is too large")
+	dict, err := bencode.Marshal(signedPayload{Seq: seq, V: v})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := dict[1 : len(dict)-1]
+	if len(payload) > MaxPayloadSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes (v is %d bytes)", ErrPayloadTooLarge, len(payload), MaxPayloadSize, len(v))
 	}
-	return []byte(re), nil
+
+	return payload, nil
+}
+
+// PayloadSize returns the number of bytes the bencoded seq and v values of a BEP44 message take up,
+// given the sequence number and the length of v.
+func PayloadSize(seq int64, vLen int) int {
+	dict, _ := bencode.Marshal(signedPayload{Seq: seq, V: make([]byte, vLen)})
+	return len(dict) - 2
 }
@@ -67,3 +67,104 @@ func Test_newSignedBEP44Message(t *testing.T) {
 		})
 	}
 }
+
+func TestMessage_Verify(t *testing.T) {
+	payload := []byte(`v=1,b=2,c=3`)
+
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	otherPubKey, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	signer := func(payload []byte) ([]byte, error) {
+		return ed25519.Sign(privKey, payload), nil
+	}
+
+	tests := map[string]struct {
+		tamper    func(data []byte) []byte
+		publicKey []byte
+		wantErr   error
+	}{
+		"good - untouched message": {
+			publicKey: pubKey,
+		},
+		"bad - signed by a different key": {
+			publicKey: otherPubKey,
+			wantErr:   ErrInvalidSignature,
+		},
+		"bad - tampered seq": {
+			tamper: func(data []byte) []byte {
+				data[71]++
+				return data
+			},
+			publicKey: pubKey,
+			wantErr:   ErrInvalidSignature,
+		},
+		"bad - tampered v": {
+			tamper: func(data []byte) []byte {
+				data[len(data)-1] = '4'
+				return data
+			},
+			publicKey: pubKey,
+			wantErr:   ErrInvalidSignature,
+		},
+		"bad - truncated v": {
+			tamper: func(data []byte) []byte {
+				return data[:len(data)-2]
+			},
+			publicKey: pubKey,
+			wantErr:   ErrInvalidSignature,
+		},
+		"bad - empty v": {
+			tamper: func(data []byte) []byte {
+				return data[:72]
+			},
+			publicKey: pubKey,
+			wantErr:   ErrMalformedMessage,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			msg, err := NewMessage(payload, 42, pubKey, signer)
+			assert.NoError(t, err)
+
+			data, err := msg.Marshal()
+			assert.NoError(t, err)
+
+			if tt.tamper != nil {
+				data = tt.tamper(data)
+			}
+
+			var decoded Message
+			assert.NoError(t, UnmarshalMessage(data, &decoded))
+
+			err = decoded.Verify(tt.publicKey)
+			if tt.wantErr != nil {
+				assert.IsError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(42), decoded.Seq)
+			assert.Equal(t, []byte(pubKey), decoded.k)
+		})
+	}
+}
+
+func TestUnmarshalMessage_Truncated(t *testing.T) {
+	var msg Message
+	err := UnmarshalMessage(make([]byte, 64), &msg)
+	assert.IsError(t, err, ErrMalformedMessage)
+}
+
+func Test_bencodeBepPayload(t *testing.T) {
+	payload, err := bencodeBepPayload(1700000000, []byte("dns packet"))
+	assert.NoError(t, err)
+	assert.Equal(t, "3:seqi1700000000e1:v10:dns packet", string(payload))
+	assert.Equal(t, len(payload), PayloadSize(1700000000, len("dns packet")))
+
+	_, err = bencodeBepPayload(1, make([]byte, MaxPayloadSize))
+	assert.IsError(t, err, ErrPayloadTooLarge)
+}
@@ -2,15 +2,21 @@ package pkarr
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
 )
 
+// ErrNotFound is returned by [Client.Fetch] when the relay has no record for the requested identifier.
+var ErrNotFound = errors.New("record not found")
+
 // Client is a client for publishing and fetching BEP44 messages to and from a Pkarr relay server.
 type Client struct {
 	relay  string
@@ -104,6 +110,10 @@ func (r *Client) FetchWithContext(ctx context.Context, didID string) (*bep44.Mes
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("failed to get message: %w", ErrNotFound)
+	}
+
 	if res.StatusCode != http.StatusOK {
 		// TODO log err
 		return nil, fmt.Errorf("failed to get message: %s", res.Status)
@@ -126,3 +136,38 @@ func (r *Client) FetchWithContext(ctx context.Context, didID string) (*bep44.Mes
 	// Return the BEP44 message.
 	return &bep44Message, nil
 }
+
+// FetchByType fetches the DIDs indexed under the given type from a did:dht gateway.
+// https://did-dht.com/#get-dids-by-type
+func (r *Client) FetchByType(ctx context.Context, typeIndex int) ([]string, error) {
+	typesURL, err := url.JoinPath(r.relay, "did", "types", strconv.Itoa(typeIndex))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, typesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return []string{}, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get dids by type: %s", res.Status)
+	}
+
+	var dids []string
+	if err := json.NewDecoder(res.Body).Decode(&dids); err != nil {
+		return nil, fmt.Errorf("failed to decode dids by type: %w", err)
+	}
+
+	return dids, nil
+}
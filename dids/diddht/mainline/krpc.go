@@ -0,0 +1,182 @@
+package mainline
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bencode"
+)
+
+// KRPC error codes. https://www.bittorrent.org/beps/bep_0005.html#errors
+const (
+	errorCodeGeneric       = 201
+	errorCodeServer        = 202
+	errorCodeProtocol      = 203
+	errorCodeMethodUnknown = 204
+)
+
+// BEP44 error codes. https://www.bittorrent.org/beps/bep_0044.html#errors
+const (
+	errorCodeMessageTooBig    = 205
+	errorCodeInvalidSignature = 206
+	errorCodeSeqLessThanCurr  = 302
+)
+
+// idSize is the size of node IDs and DHT keys
+const idSize = 20
+
+// compactNodeSize is the size of a node in the compact IPv4 node info format: 20 byte ID, 4 byte IP, 2 byte port
+const compactNodeSize = idSize + 4 + 2
+
+// nodeID identifies a node, or the target of a lookup, in the 160 bit DHT key space
+type nodeID [idSize]byte
+
+// randomID returns a random node ID
+func randomID() (nodeID, error) {
+	var id nodeID
+	if _, err := rand.Read(id[:]); err != nil {
+		return nodeID{}, err
+	}
+
+	return id, nil
+}
+
+// distance returns the XOR distance between two IDs, as a comparable array
+func (id nodeID) distance(other nodeID) nodeID {
+	var d nodeID
+	for i := range id {
+		d[i] = id[i] ^ other[i]
+	}
+
+	return d
+}
+
+// closer reports whether a is closer to the target than b
+func closer(target, a, b nodeID) bool {
+	da, db := target.distance(a), target.distance(b)
+	return bytes.Compare(da[:], db[:]) < 0
+}
+
+// node is a DHT node along with its UDP address
+type node struct {
+	id   nodeID
+	addr *net.UDPAddr
+}
+
+// encodeNodes encodes nodes in the compact IPv4 node info format. IPv6 nodes are skipped.
+func encodeNodes(nodes []node) []byte {
+	buf := make([]byte, 0, len(nodes)*compactNodeSize)
+	for _, n := range nodes {
+		ip := n.addr.IP.To4()
+		if ip == nil {
+			continue
+		}
+
+		buf = append(buf, n.id[:]...)
+		buf = append(buf, ip...)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n.addr.Port))
+	}
+
+	return buf
+}
+
+// decodeNodes decodes nodes in the compact IPv4 node info format
+func decodeNodes(data []byte) ([]node, error) {
+	if len(data)%compactNodeSize != 0 {
+		return nil, fmt.Errorf("compact node info must be a multiple of %d bytes, got %d", compactNodeSize, len(data))
+	}
+
+	nodes := make([]node, 0, len(data)/compactNodeSize)
+	for i := 0; i < len(data); i += compactNodeSize {
+		var n node
+		copy(n.id[:], data[i:i+idSize])
+
+		port := binary.BigEndian.Uint16(data[i+idSize+4 : i+compactNodeSize])
+		if port == 0 {
+			continue
+		}
+
+		ip := make(net.IP, 4)
+		copy(ip, data[i+idSize:i+idSize+4])
+		n.addr = &net.UDPAddr{IP: ip, Port: int(port)}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
+}
+
+// message is a decoded KRPC message
+type message map[string]any
+
+// decodeMessage decodes a bencoded KRPC message
+func decodeMessage(data []byte) (message, error) {
+	msg := make(map[string]any)
+	if err := bencode.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// bytes returns the byte string stored under key
+func (m message) bytes(key string) ([]byte, bool) {
+	v, ok := m[key].(string)
+	return []byte(v), ok
+}
+
+// string returns the string stored under key
+func (m message) string(key string) string {
+	v, _ := m[key].(string)
+	return v
+}
+
+// int returns the integer stored under key
+func (m message) int(key string) (int64, bool) {
+	v, ok := m[key].(int64)
+	return v, ok
+}
+
+// dict returns the dictionary stored under key
+func (m message) dict(key string) (message, bool) {
+	v, ok := m[key].(map[string]any)
+	return v, ok
+}
+
+// id returns the node ID stored under key
+func (m message) id(key string) (nodeID, bool) {
+	v, ok := m.bytes(key)
+	if !ok || len(v) != idSize {
+		return nodeID{}, false
+	}
+
+	var id nodeID
+	copy(id[:], v)
+	return id, true
+}
+
+// KRPCError is an error returned by a DHT node in response to a query
+type KRPCError struct {
+	Code    int
+	Message string
+}
+
+func (e *KRPCError) Error() string {
+	return fmt.Sprintf("krpc error %d: %s", e.Code, e.Message)
+}
+
+// decodeError decodes the `e` list of a KRPC error message
+func decodeError(msg message) error {
+	list, ok := msg["e"].([]any)
+	if !ok || len(list) != 2 {
+		return errors.New("malformed krpc error")
+	}
+
+	code, _ := list[0].(int64)
+	text, _ := list[1].(string)
+
+	return &KRPCError{Code: int(code), Message: text}
+}
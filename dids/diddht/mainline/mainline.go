@@ -0,0 +1,586 @@
+// Package mainline implements a client for the BitTorrent mainline DHT, which `did:dht` uses as its registry.
+// It stores and retrieves BEP44 mutable items by Ed25519 key over UDP, without going through a Pkarr gateway.
+//
+// A [Client] is also a DHT node: it answers the ping, find_node, get and put queries of other nodes, which
+// allows a small cluster of clients to act as a private DHT, e.g. on loopback in tests.
+//
+// Specs:
+//   - https://www.bittorrent.org/beps/bep_0005.html
+//   - https://www.bittorrent.org/beps/bep_0044.html
+package mainline
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // sha1 is the hash function used by the DHT to derive keys
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bencode"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/pkarr"
+	"github.com/tv42/zbase32"
+)
+
+// DefaultBootstrapNodes are well known entry points into the public mainline DHT
+var DefaultBootstrapNodes = []string{
+	"router.bittorrent.com:6881",
+	"dht.transmissionbt.com:6881",
+	"dht.libtorrent.org:25401",
+	"router.utorrent.com:6881",
+}
+
+const (
+	// k is the number of closest nodes an item is stored on
+	k = 8
+	// alpha is the number of nodes queried concurrently during a lookup
+	alpha = 3
+	// maxLookupRounds bounds the number of rounds of an iterative lookup
+	maxLookupRounds = 16
+	// maxRoutingTableSize bounds the number of nodes kept in the routing table
+	maxRoutingTableSize = 512
+	// maxPacketSize is the largest UDP datagram read from the network
+	maxPacketSize  = 2048
+	defaultTimeout = 2 * time.Second
+)
+
+// Client is a mainline DHT node that gets and puts BEP44 mutable items. It implements the same methods as
+// the Pkarr gateway client, so it can be used to create and resolve `did:dht` DIDs directly on the DHT.
+type Client struct {
+	conn      *net.UDPConn
+	id        nodeID
+	bootstrap []string
+	timeout   time.Duration
+	secret    []byte
+
+	mu      sync.Mutex
+	pending map[string]pendingQuery
+	table   map[string]node
+	items   map[nodeID]item
+
+	done chan struct{}
+}
+
+// ClientOption is the type returned from each individual option function accepted by [NewClient]
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	listenAddr string
+	bootstrap  []string
+	timeout    time.Duration
+}
+
+// ListenAddr sets the UDP address the client listens on. Defaults to `:0`, i.e. a random port on all interfaces.
+func ListenAddr(addr string) ClientOption {
+	return func(o *clientOptions) {
+		o.listenAddr = addr
+	}
+}
+
+// BootstrapNodes sets the `host:port` addresses of the nodes used to join the DHT. Defaults to
+// [DefaultBootstrapNodes].
+func BootstrapNodes(nodes ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.bootstrap = nodes
+	}
+}
+
+// Timeout sets how long the client waits for a node to respond to a query. Defaults to 2s.
+func Timeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// NewClient creates a new [Client] and starts listening for UDP packets. The client must be closed with
+// [Client.Close] once it is no longer needed.
+func NewClient(opts ...ClientOption) (*Client, error) {
+	o := clientOptions{
+		listenAddr: ":0",
+		bootstrap:  DefaultBootstrapNodes,
+		timeout:    defaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", o.listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve listen address: %w", err)
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate node id: %w", err)
+	}
+
+	secret, err := randomID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token secret: %w", err)
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	c := &Client{
+		conn:      conn,
+		id:        id,
+		bootstrap: o.bootstrap,
+		timeout:   o.timeout,
+		secret:    secret[:],
+		pending:   make(map[string]pendingQuery),
+		table:     make(map[string]node),
+		items:     make(map[nodeID]item),
+		done:      make(chan struct{}),
+	}
+
+	go c.readLoop()
+
+	return c, nil
+}
+
+// Addr returns the UDP address the client listens on
+func (c *Client) Addr() *net.UDPAddr {
+	return c.conn.LocalAddr().(*net.UDPAddr) //nolint:forcetypeassert
+}
+
+// Close stops the client from sending and answering queries
+func (c *Client) Close() error {
+	select {
+	case <-c.done:
+		return nil
+	default:
+		close(c.done)
+	}
+
+	return c.conn.Close()
+}
+
+// Bootstrap joins the DHT by looking up the client's own ID, which fills the routing table with the nodes
+// closest to it and announces the client to them. Calling Bootstrap is optional, every lookup starts from
+// the bootstrap nodes if the routing table is empty.
+func (c *Client) Bootstrap(ctx context.Context) error {
+	result := c.lookup(ctx, c.id, "find_node", nil)
+	if len(result.responders) == 0 {
+		return errors.New("no bootstrap node responded")
+	}
+
+	return nil
+}
+
+// Put stores the signed BEP44 message on the DHT nodes closest to the DID's identity key
+func (c *Client) Put(didID string, msg *bep44.Message) error {
+	return c.PutWithContext(context.Background(), didID, msg)
+}
+
+// PutWithContext stores the signed BEP44 message on the DHT nodes closest to the DID's identity key.
+// It succeeds if at least one node accepted the message.
+func (c *Client) PutWithContext(ctx context.Context, didID string, msg *bep44.Message) error {
+	publicKey, err := zbase32.DecodeString(didID)
+	if err != nil {
+		return fmt.Errorf("failed to decode identity key: %w", err)
+	}
+
+	body, err := msg.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode bep44 message: %w", err)
+	}
+
+	// a lookup collects the write tokens of the closest nodes
+	result := c.lookup(ctx, mutableTarget(publicKey), "get", nil)
+	if len(result.responders) == 0 {
+		return errors.New("no dht node responded")
+	}
+
+	args := map[string]any{
+		"token": "",
+		"k":     publicKey,
+		"seq":   msg.Seq,
+		"sig":   body[:64],
+		"v":     msg.V,
+	}
+
+	responders := result.responders
+	if len(responders) > k {
+		responders = responders[:k]
+	}
+
+	errs := make(chan error, len(responders))
+	for _, r := range responders {
+		go func(r responder) {
+			putArgs := make(map[string]any, len(args))
+			for key, value := range args {
+				putArgs[key] = value
+			}
+			putArgs["token"] = r.token
+
+			_, err := c.query(ctx, r.node.addr, "put", putArgs)
+			errs <- err
+		}(r)
+	}
+
+	var accepted int
+	var failures []error
+	for range responders {
+		if err := <-errs; err != nil {
+			failures = append(failures, err)
+			continue
+		}
+		accepted++
+	}
+
+	if accepted == 0 {
+		return fmt.Errorf("no dht node accepted the message: %w", errors.Join(failures...))
+	}
+
+	return nil
+}
+
+// Fetch retrieves the BEP44 message with the highest sequence number stored for the DID's identity key
+func (c *Client) Fetch(didID string) (*bep44.Message, error) {
+	return c.FetchWithContext(context.Background(), didID)
+}
+
+// FetchWithContext retrieves the BEP44 message with the highest sequence number stored for the DID's
+// identity key. Messages that are not signed by the identity key are discarded.
+func (c *Client) FetchWithContext(ctx context.Context, didID string) (*bep44.Message, error) {
+	publicKey, err := zbase32.DecodeString(didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode identity key: %w", err)
+	}
+
+	result := c.lookup(ctx, mutableTarget(publicKey), "get", publicKey)
+	if result.item == nil {
+		return nil, fmt.Errorf("failed to get message: %w", pkarr.ErrNotFound)
+	}
+
+	return result.item, nil
+}
+
+// mutableTarget returns the DHT key of the mutable item stored under the given public key, without salt
+func mutableTarget(publicKey []byte) nodeID {
+	return sha1.Sum(publicKey) //nolint:gosec
+}
+
+// responder is a node that responded to a lookup query, along with the write token it handed out
+type responder struct {
+	node  node
+	token string
+}
+
+// lookupResult holds the nodes that responded to a lookup, closest first, and the most recent valid item found
+type lookupResult struct {
+	responders []responder
+	item       *bep44.Message
+}
+
+// lookup iteratively queries the nodes closest to target. For get lookups, publicKey is used to verify
+// the items returned by the nodes.
+func (c *Client) lookup(ctx context.Context, target nodeID, method string, publicKey []byte) lookupResult {
+	type candidate struct {
+		node    node
+		known   bool
+		queried bool
+	}
+
+	candidates := make(map[string]*candidate)
+	for _, n := range c.closest(target, k) {
+		candidates[n.addr.String()] = &candidate{node: n, known: true}
+	}
+
+	if len(candidates) == 0 {
+		for _, b := range c.bootstrap {
+			addr, err := net.ResolveUDPAddr("udp", b)
+			if err != nil {
+				continue
+			}
+			candidates[addr.String()] = &candidate{node: node{addr: addr}}
+		}
+	}
+
+	var result lookupResult
+	type response struct {
+		candidate *candidate
+		msg       message
+	}
+
+	for round := 0; round < maxLookupRounds; round++ {
+		// nodes with an unknown ID (bootstrap nodes) are queried first, then the closest unqueried nodes
+		ordered := make([]*candidate, 0, len(candidates))
+		for _, cand := range candidates {
+			ordered = append(ordered, cand)
+		}
+		sort.Slice(ordered, func(i, j int) bool {
+			if ordered[i].known != ordered[j].known {
+				return !ordered[i].known
+			}
+			return closer(target, ordered[i].node.id, ordered[j].node.id)
+		})
+
+		// the lookup converges once the k closest known nodes have all been queried
+		var batch []*candidate
+		var closest int
+		for _, cand := range ordered {
+			if cand.known {
+				if closest == k {
+					break
+				}
+				closest++
+			}
+
+			if !cand.queried && len(batch) < alpha {
+				batch = append(batch, cand)
+			}
+		}
+
+		if len(batch) == 0 || ctx.Err() != nil {
+			break
+		}
+
+		responses := make(chan response, len(batch))
+		for _, cand := range batch {
+			cand.queried = true
+			go func(cand *candidate) {
+				msg, err := c.query(ctx, cand.node.addr, method, map[string]any{"target": string(target[:])})
+				if err != nil {
+					msg = nil
+				}
+				responses <- response{candidate: cand, msg: msg}
+			}(cand)
+		}
+
+		for range batch {
+			res := <-responses
+			if res.msg == nil {
+				delete(candidates, res.candidate.node.addr.String())
+				continue
+			}
+
+			id, _ := res.msg.id("id")
+			res.candidate.node.id = id
+			res.candidate.known = true
+			result.responders = append(result.responders, responder{node: res.candidate.node, token: res.msg.string("token")})
+
+			if nodes, ok := res.msg.bytes("nodes"); ok {
+				decoded, _ := decodeNodes(nodes)
+				for _, n := range decoded {
+					if n.id == c.id {
+						continue
+					}
+					if _, ok := candidates[n.addr.String()]; !ok {
+						candidates[n.addr.String()] = &candidate{node: n, known: true}
+					}
+				}
+			}
+
+			if publicKey != nil {
+				if msg := decodeItem(res.msg, publicKey); msg != nil && (result.item == nil || msg.Seq > result.item.Seq) {
+					result.item = msg
+				}
+			}
+		}
+	}
+
+	sort.Slice(result.responders, func(i, j int) bool {
+		return closer(target, result.responders[i].node.id, result.responders[j].node.id)
+	})
+
+	return result
+}
+
+// decodeItem returns the BEP44 message in a get response, or nil if there is none or it is not signed by publicKey
+func decodeItem(msg message, publicKey []byte) *bep44.Message {
+	sig, ok := msg.bytes("sig")
+	if !ok {
+		return nil
+	}
+
+	seq, ok := msg.int("seq")
+	if !ok {
+		return nil
+	}
+
+	v, ok := msg.bytes("v")
+	if !ok {
+		return nil
+	}
+
+	data := make([]byte, 0, len(sig)+8+len(v))
+	data = append(data, sig...)
+	data = binary.BigEndian.AppendUint64(data, uint64(seq))
+	data = append(data, v...)
+
+	var item bep44.Message
+	if err := bep44.UnmarshalMessage(data, &item); err != nil {
+		return nil
+	}
+
+	if err := item.Verify(publicKey); err != nil {
+		return nil
+	}
+
+	return &item
+}
+
+// pendingQuery is a query waiting for its response. Only a response from the queried address is accepted.
+type pendingQuery struct {
+	addr      *net.UDPAddr
+	responses chan message
+}
+
+// txSize is the size of the random transaction IDs, which makes responses to queries of this client hard to
+// spoof for nodes that do not see the queries
+const txSize = 4
+
+// query sends a KRPC query to addr and waits for the response
+func (c *Client) query(ctx context.Context, addr *net.UDPAddr, method string, args map[string]any) (message, error) {
+	args["id"] = string(c.id[:])
+
+	responses := make(chan message, 1)
+	tx, err := c.addPending(pendingQuery{addr: addr, responses: responses})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, tx)
+		c.mu.Unlock()
+	}()
+
+	packet, err := bencode.Marshal(map[string]any{"t": tx, "y": "q", "q": method, "a": args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	if _, err := c.conn.WriteToUDP(packet, addr); err != nil {
+		return nil, fmt.Errorf("failed to send query: %w", err)
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		return nil, errors.New("client closed")
+	case <-timer.C:
+		return nil, fmt.Errorf("%s query to %s timed out", method, addr)
+	case msg := <-responses:
+		if msg.string("y") == "e" {
+			return nil, decodeError(msg)
+		}
+
+		r, ok := msg.dict("r")
+		if !ok {
+			return nil, errors.New("malformed krpc response")
+		}
+
+		if id, ok := r.id("id"); ok {
+			c.addNode(node{id: id, addr: addr})
+		}
+
+		return r, nil
+	}
+}
+
+// addPending registers a pending query under a new random transaction ID
+func (c *Client) addPending(pending pendingQuery) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		var tx [txSize]byte
+		if _, err := rand.Read(tx[:]); err != nil {
+			return "", fmt.Errorf("failed to generate transaction id: %w", err)
+		}
+
+		if _, ok := c.pending[string(tx[:])]; !ok {
+			c.pending[string(tx[:])] = pending
+			return string(tx[:]), nil
+		}
+	}
+}
+
+// readLoop reads packets until the client is closed, answering queries and dispatching responses
+func (c *Client) readLoop() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := c.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-c.done:
+				return
+			default:
+				continue
+			}
+		}
+
+		msg, err := decodeMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+
+		switch msg.string("y") {
+		case "q":
+			c.handleQuery(addr, msg)
+		case "r", "e":
+			c.mu.Lock()
+			pending, ok := c.pending[msg.string("t")]
+			c.mu.Unlock()
+
+			// responses from any other address than the queried one are dropped
+			if ok && pending.addr.IP.Equal(addr.IP) && pending.addr.Port == addr.Port {
+				select {
+				case pending.responses <- msg:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// addNode adds a node that has been seen responding, or querying, to the routing table
+func (c *Client) addNode(n node) {
+	if n.id == c.id {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.table[n.addr.String()]; !ok && len(c.table) >= maxRoutingTableSize {
+		return
+	}
+
+	c.table[n.addr.String()] = n
+}
+
+// closest returns up to count nodes from the routing table, closest to target first
+func (c *Client) closest(target nodeID, count int) []node {
+	c.mu.Lock()
+	nodes := make([]node, 0, len(c.table))
+	for _, n := range c.table {
+		nodes = append(nodes, n)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return closer(target, nodes[i].id, nodes[j].id)
+	})
+
+	if len(nodes) > count {
+		nodes = nodes[:count]
+	}
+
+	return nodes
+}
@@ -0,0 +1,209 @@
+package mainline
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bencode"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/pkarr"
+	"github.com/tv42/zbase32"
+)
+
+// newCluster starts n clients on loopback, all bootstrapped from the first one
+func newCluster(t *testing.T, n int) []*Client {
+	t.Helper()
+
+	seed, err := NewClient(ListenAddr("127.0.0.1:0"), BootstrapNodes(), Timeout(500*time.Millisecond))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = seed.Close() })
+
+	nodes := []*Client{seed}
+	for i := 1; i < n; i++ {
+		nodes = append(nodes, newClient(t, seed))
+	}
+
+	return nodes
+}
+
+// newClient starts a client on loopback that joins the DHT through the given node
+func newClient(t *testing.T, bootstrap *Client) *Client {
+	t.Helper()
+
+	c, err := NewClient(ListenAddr("127.0.0.1:0"), BootstrapNodes(bootstrap.Addr().String()), Timeout(500*time.Millisecond))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	assert.NoError(t, c.Bootstrap(context.Background()))
+	return c
+}
+
+func newSignedMessage(t *testing.T, privateKey ed25519.PrivateKey, seq int64, v string) *bep44.Message {
+	t.Helper()
+
+	publicKey := privateKey.Public().(ed25519.PublicKey) //nolint:forcetypeassert
+	msg, err := bep44.NewMessage([]byte(v), seq, publicKey, func(payload []byte) ([]byte, error) {
+		return ed25519.Sign(privateKey, payload), nil
+	})
+	assert.NoError(t, err)
+
+	return msg
+}
+
+func TestClient_PutFetch(t *testing.T) {
+	nodes := newCluster(t, 10)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	didID := zbase32.EncodeToString(publicKey)
+
+	assert.NoError(t, nodes[1].Put(didID, newSignedMessage(t, privateKey, 1, "dns packet")))
+
+	// a client that only knows a different node finds the item
+	reader := newClient(t, nodes[5])
+	msg, err := reader.Fetch(didID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), msg.Seq)
+	assert.Equal(t, []byte("dns packet"), msg.V)
+
+	// newer versions replace older ones
+	assert.NoError(t, nodes[2].Put(didID, newSignedMessage(t, privateKey, 2, "updated dns packet")))
+
+	msg, err = reader.Fetch(didID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), msg.Seq)
+	assert.Equal(t, []byte("updated dns packet"), msg.V)
+
+	// stale versions do not replace newer ones
+	_ = nodes[3].Put(didID, newSignedMessage(t, privateKey, 1, "dns packet"))
+
+	msg, err = reader.Fetch(didID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), msg.Seq)
+}
+
+func TestClient_PutOlderSeq(t *testing.T) {
+	nodes := newCluster(t, 3)
+
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	didID := zbase32.EncodeToString(privateKey.Public().(ed25519.PublicKey)) //nolint:forcetypeassert
+
+	// with fewer than k nodes, every other node stores the item
+	assert.NoError(t, nodes[1].Put(didID, newSignedMessage(t, privateKey, 2, "dns packet")))
+
+	err = nodes[1].Put(didID, newSignedMessage(t, privateKey, 1, "dns packet"))
+	var krpcErr *KRPCError
+	assert.True(t, errors.As(err, &krpcErr))
+	assert.Equal(t, errorCodeSeqLessThanCurr, krpcErr.Code)
+}
+
+func TestClient_FetchNotFound(t *testing.T) {
+	nodes := newCluster(t, 4)
+
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	_, err = nodes[1].Fetch(zbase32.EncodeToString(publicKey))
+	assert.IsError(t, err, pkarr.ErrNotFound)
+}
+
+func TestClient_PutInvalidSignature(t *testing.T) {
+	nodes := newCluster(t, 4)
+
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	_, otherPrivateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	// signed by a key that does not match the DID
+	err = nodes[1].Put(zbase32.EncodeToString(publicKey), newSignedMessage(t, otherPrivateKey, 1, "dns packet"))
+	var krpcErr *KRPCError
+	assert.True(t, errors.As(err, &krpcErr))
+	assert.Equal(t, errorCodeInvalidSignature, krpcErr.Code)
+}
+
+func TestClient_NoBootstrapNodes(t *testing.T) {
+	c, err := NewClient(ListenAddr("127.0.0.1:0"), BootstrapNodes(), Timeout(100*time.Millisecond))
+	assert.NoError(t, err)
+	defer c.Close()
+
+	assert.Error(t, c.Bootstrap(context.Background()))
+}
+
+func TestClient_DropsResponsesFromOtherAddresses(t *testing.T) {
+	c, err := NewClient(ListenAddr("127.0.0.1:0"), BootstrapNodes(), Timeout(time.Second))
+	assert.NoError(t, err)
+	defer c.Close()
+
+	target, err := net.ListenUDP("udp", mustResolve(t, "127.0.0.1:0"))
+	assert.NoError(t, err)
+	defer target.Close()
+
+	spoofer, err := net.ListenUDP("udp", mustResolve(t, "127.0.0.1:0"))
+	assert.NoError(t, err)
+	defer spoofer.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.query(context.Background(), target.LocalAddr().(*net.UDPAddr), "ping", map[string]any{}) //nolint:forcetypeassert
+		errs <- err
+	}()
+
+	buf := make([]byte, maxPacketSize)
+	n, from, err := target.ReadFromUDP(buf)
+	assert.NoError(t, err)
+
+	query, err := decodeMessage(buf[:n])
+	assert.NoError(t, err)
+	assert.Equal(t, txSize, len(query.string("t")))
+
+	response, err := bencode.Marshal(map[string]any{"t": query.string("t"), "y": "r", "r": map[string]any{"id": string(make([]byte, idSize))}})
+	assert.NoError(t, err)
+
+	// a response with the right transaction ID from another address is ignored
+	_, err = spoofer.WriteToUDP(response, from)
+	assert.NoError(t, err)
+
+	select {
+	case err := <-errs:
+		t.Fatalf("query completed with a spoofed response: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_, err = target.WriteToUDP(response, from)
+	assert.NoError(t, err)
+	assert.NoError(t, <-errs)
+}
+
+func Test_compactNodes(t *testing.T) {
+	nodes := []node{
+		{id: nodeID{1}, addr: mustResolve(t, "127.0.0.1:6881")},
+		{id: nodeID{2}, addr: mustResolve(t, "10.0.0.1:1")},
+	}
+
+	decoded, err := decodeNodes(encodeNodes(nodes))
+	assert.NoError(t, err)
+	assert.Equal(t, len(nodes), len(decoded))
+	for i := range nodes {
+		assert.Equal(t, nodes[i].id, decoded[i].id)
+		assert.Equal(t, nodes[i].addr.String(), decoded[i].addr.String())
+	}
+
+	_, err = decodeNodes(make([]byte, compactNodeSize+1))
+	assert.Error(t, err)
+}
+
+func mustResolve(t *testing.T, addr string) *net.UDPAddr {
+	t.Helper()
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	assert.NoError(t, err)
+	return udpAddr
+}
@@ -0,0 +1,162 @@
+package mainline
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"net"
+
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bencode"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+)
+
+// maxItems bounds the number of mutable items a client stores for other nodes
+const maxItems = 10000
+
+// maxValueSize is the maximum size of the v value of a mutable item
+const maxValueSize = 1000
+
+// item is a BEP44 mutable item stored for another node
+type item struct {
+	k   []byte
+	seq int64
+	sig []byte
+	v   []byte
+}
+
+// handleQuery answers a KRPC query sent by another node
+func (c *Client) handleQuery(addr *net.UDPAddr, msg message) {
+	tx := msg.string("t")
+
+	args, ok := msg.dict("a")
+	if !ok {
+		c.sendError(addr, tx, errorCodeProtocol, "missing arguments")
+		return
+	}
+
+	id, ok := args.id("id")
+	if !ok {
+		c.sendError(addr, tx, errorCodeProtocol, "invalid node id")
+		return
+	}
+
+	// read-only nodes do not answer queries, so they are not added to the routing table
+	if ro, _ := msg.int("ro"); ro != 1 {
+		c.addNode(node{id: id, addr: addr})
+	}
+
+	response := map[string]any{"id": string(c.id[:])}
+
+	switch msg.string("q") {
+	case "ping":
+	case "find_node":
+		target, ok := args.id("target")
+		if !ok {
+			c.sendError(addr, tx, errorCodeProtocol, "invalid target")
+			return
+		}
+
+		response["nodes"] = encodeNodes(c.closest(target, k))
+	case "get":
+		target, ok := args.id("target")
+		if !ok {
+			c.sendError(addr, tx, errorCodeProtocol, "invalid target")
+			return
+		}
+
+		response["token"] = c.token(addr)
+		response["nodes"] = encodeNodes(c.closest(target, k))
+
+		c.mu.Lock()
+		stored, ok := c.items[target]
+		c.mu.Unlock()
+
+		// the item is only returned if it is newer than the one the querying node already has
+		if seq, hasSeq := args.int("seq"); ok && (!hasSeq || stored.seq > seq) {
+			response["k"] = stored.k
+			response["seq"] = stored.seq
+			response["sig"] = stored.sig
+			response["v"] = stored.v
+		}
+	case "put":
+		if code, text := c.store(addr, args); code != 0 {
+			c.sendError(addr, tx, code, text)
+			return
+		}
+	default:
+		c.sendError(addr, tx, errorCodeMethodUnknown, "method unknown")
+		return
+	}
+
+	c.send(addr, map[string]any{"t": tx, "y": "r", "r": response})
+}
+
+// store validates and stores the mutable item of a put query. It returns a KRPC error code and message
+// if the item is rejected.
+func (c *Client) store(addr *net.UDPAddr, args message) (int, string) {
+	if token := args.string("token"); !hmac.Equal([]byte(token), []byte(c.token(addr))) {
+		return errorCodeProtocol, "invalid token"
+	}
+
+	publicKey, _ := args.bytes("k")
+	sig, _ := args.bytes("sig")
+	v, _ := args.bytes("v")
+	seq, ok := args.int("seq")
+	if !ok || len(v) == 0 {
+		return errorCodeProtocol, "seq and v are required"
+	}
+
+	if len(v) > maxValueSize {
+		return errorCodeMessageTooBig, "message (v field) too big"
+	}
+
+	msg, err := bep44.NewMessage(v, seq, publicKey, func([]byte) ([]byte, error) { return sig, nil })
+	if err != nil {
+		return errorCodeMessageTooBig, "message (v field) too big"
+	}
+
+	if err := msg.Verify(publicKey); err != nil {
+		return errorCodeInvalidSignature, "invalid signature"
+	}
+
+	target := mutableTarget(publicKey)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, exists := c.items[target]
+	if exists && current.seq > seq {
+		return errorCodeSeqLessThanCurr, "sequence number less than current"
+	}
+
+	if !exists && len(c.items) >= maxItems {
+		return errorCodeServer, "storage full"
+	}
+
+	c.items[target] = item{k: publicKey, seq: seq, sig: sig, v: v}
+
+	return 0, ""
+}
+
+// token returns the write token handed out to the node at addr. A put is only accepted with the token
+// of the node's IP address, which proves the node is not spoofing its address.
+func (c *Client) token(addr *net.UDPAddr) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(addr.IP)
+
+	return string(mac.Sum(nil)[:8])
+}
+
+// sendError sends a KRPC error message
+func (c *Client) sendError(addr *net.UDPAddr, tx string, code int, text string) {
+	c.send(addr, map[string]any{"t": tx, "y": "e", "e": []any{code, text}})
+}
+
+// send sends a KRPC message, dropping it if it cannot be encoded or sent
+func (c *Client) send(addr *net.UDPAddr, msg map[string]any) {
+	packet, err := bencode.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	_, _ = c.conn.WriteToUDP(packet, addr)
+}
@@ -0,0 +1,157 @@
+// Package relay implements a [Pkarr relay] that can be used to publish and resolve `did:dht` DIDs without
+// depending on a third party gateway, e.g. for self hosting or for tests using [net/http/httptest].
+//
+// The relay validates every BEP44 message it receives (size, signature and sequence number) before
+// storing it. Storage is pluggable through the [Storage] interface. [MemoryStorage] and [FileStorage]
+// are provided.
+//
+// [Pkarr relay]: https://github.com/Nuhvi/pkarr/blob/main/design/relays.md
+package relay
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+	"github.com/tv42/zbase32"
+)
+
+// maxBodySize is the largest body a relay accepts: a 64 byte signature, an 8 byte sequence number
+// and a 1000 byte v value.
+const maxBodySize = 1072
+
+// ErrNotFound is returned by a [Storage] when no record is stored for the given key.
+var ErrNotFound = errors.New("record not found")
+
+// Storage is the interface used by [Handler] to persist records. Records are stored as received by the
+// relay: the signature, followed by the big-endian sequence number and the v value.
+type Storage interface {
+	// Get returns the record stored for the given z-base-32 encoded public key, or [ErrNotFound]
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put stores the record for the given z-base-32 encoded public key, replacing any existing record
+	Put(ctx context.Context, key string, record []byte) error
+}
+
+// Handler is an [http.Handler] that implements the PUT and GET endpoints of the Pkarr relay API.
+type Handler struct {
+	storage Storage
+
+	// mu serializes puts so that the sequence number check and the write happen atomically
+	mu sync.Mutex
+}
+
+// NewHandler creates a new [Handler] that stores records in the given storage.
+func NewHandler(storage Storage) *Handler {
+	return &Handler{storage: storage}
+}
+
+// ServeHTTP handles `PUT /:key` and `GET /:key` requests as described in the Pkarr relay design.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.Trim(r.URL.Path, "/")
+	if key == "" || strings.Contains(key, "/") {
+		http.Error(w, "expected a single z-base-32 encoded public key in the path", http.StatusNotFound)
+		return
+	}
+
+	publicKey, err := zbase32.DecodeString(key)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		http.Error(w, "invalid public key", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.get(w, r, key)
+	case http.MethodPut:
+		h.put(w, r, key, publicKey)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, key string) {
+	record, err := h.storage.Get(r.Context(), key)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, "failed to read record", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(record)
+}
+
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, key string, publicKey []byte) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	var msg bep44.Message
+	if err := bep44.UnmarshalMessage(body, &msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := msg.Verify(publicKey); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current, err := h.storage.Get(r.Context(), key)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		http.Error(w, "failed to read record", http.StatusInternalServerError)
+		return
+	default:
+		if err := checkSeq(current, &msg, body); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+	}
+
+	if err := h.storage.Put(r.Context(), key, body); err != nil {
+		http.Error(w, "failed to store record", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// checkSeq ensures that the incoming message does not roll back the stored record. Publishing the same
+// record twice is allowed, publishing a different record with the same sequence number is not.
+func checkSeq(current []byte, incoming *bep44.Message, body []byte) error {
+	var stored bep44.Message
+	if err := bep44.UnmarshalMessage(current, &stored); err != nil {
+		// the stored record is unreadable. let the valid incoming message replace it
+		return nil
+	}
+
+	switch {
+	case incoming.Seq > stored.Seq:
+		return nil
+	case incoming.Seq == stored.Seq && bytes.Equal(current, body):
+		return nil
+	case incoming.Seq == stored.Seq:
+		return fmt.Errorf("a different record with seq %d is already stored", stored.Seq)
+	default:
+		return fmt.Errorf("seq %d is lower than the stored seq %d", incoming.Seq, stored.Seq)
+	}
+}
@@ -0,0 +1,162 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/dids/didcore"
+	"github.com/decentralized-identity/web5-go/dids/diddht"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+	"github.com/tv42/zbase32"
+)
+
+func TestHandler_CreateAndResolve(t *testing.T) {
+	fileStorage, err := NewFileStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	storages := map[string]Storage{
+		"memory": NewMemoryStorage(),
+		"file":   fileStorage,
+	}
+
+	for name, storage := range storages {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(NewHandler(storage))
+			defer server.Close()
+
+			bearerDID, err := diddht.Create(
+				diddht.Gateway(server.URL, http.DefaultClient),
+				diddht.Service("dwn", "DecentralizedWebNode", "https://example.com/dwn"),
+			)
+			assert.NoError(t, err)
+
+			resolver := diddht.NewResolver(server.URL, http.DefaultClient)
+			result, err := resolver.Resolve(bearerDID.URI)
+			assert.NoError(t, err)
+			assert.Equal(t, bearerDID.Document.Service, result.Document.Service)
+
+			document := bearerDID.Document
+			document.AddService(didcore.Service{ID: "#pfi", Type: "PFI", ServiceEndpoint: []string{"https://example.com/pfi"}})
+			_, err = diddht.Update(bearerDID, document, diddht.PublishGateway(server.URL, http.DefaultClient))
+			assert.NoError(t, err)
+
+			result, err = resolver.Resolve(bearerDID.URI)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, len(result.Document.Service))
+		})
+	}
+}
+
+func TestHandler_Put(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	key := zbase32.EncodeToString(publicKey)
+
+	_, otherPrivateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	newRecord := func(seq int64, v []byte, signingKey ed25519.PrivateKey) []byte {
+		msg, err := bep44.NewMessage(v, seq, publicKey, func(payload []byte) ([]byte, error) {
+			return ed25519.Sign(signingKey, payload), nil
+		})
+		assert.NoError(t, err)
+
+		body, err := msg.Marshal()
+		assert.NoError(t, err)
+		return body
+	}
+
+	tests := map[string]struct {
+		stored         []byte
+		path           string
+		body           []byte
+		expectedStatus int
+	}{
+		"valid record": {
+			body:           newRecord(10, []byte("hello"), privateKey),
+			expectedStatus: http.StatusOK,
+		},
+		"higher seq replaces stored record": {
+			stored:         newRecord(10, []byte("hello"), privateKey),
+			body:           newRecord(11, []byte("hello again"), privateKey),
+			expectedStatus: http.StatusOK,
+		},
+		"republishing the stored record": {
+			stored:         newRecord(10, []byte("hello"), privateKey),
+			body:           newRecord(10, []byte("hello"), privateKey),
+			expectedStatus: http.StatusOK,
+		},
+		"lower seq": {
+			stored:         newRecord(10, []byte("hello"), privateKey),
+			body:           newRecord(9, []byte("rollback"), privateKey),
+			expectedStatus: http.StatusConflict,
+		},
+		"different record with the same seq": {
+			stored:         newRecord(10, []byte("hello"), privateKey),
+			body:           newRecord(10, []byte("conflict"), privateKey),
+			expectedStatus: http.StatusConflict,
+		},
+		"signed by another key": {
+			body:           newRecord(10, []byte("hello"), otherPrivateKey),
+			expectedStatus: http.StatusBadRequest,
+		},
+		"truncated record": {
+			body:           newRecord(10, []byte("hello"), privateKey)[:70],
+			expectedStatus: http.StatusBadRequest,
+		},
+		"oversized record": {
+			body:           append(newRecord(10, []byte("hello"), privateKey), make([]byte, 1001)...),
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid key": {
+			path:           "/not-zbase32",
+			body:           newRecord(10, []byte("hello"), privateKey),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := NewMemoryStorage()
+			if tt.stored != nil {
+				assert.NoError(t, storage.Put(context.Background(), key, tt.stored))
+			}
+
+			path := tt.path
+			if path == "" {
+				path = "/" + key
+			}
+
+			req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(tt.body))
+			res := httptest.NewRecorder()
+			NewHandler(storage).ServeHTTP(res, req)
+			assert.Equal(t, tt.expectedStatus, res.Code, res.Body.String())
+
+			stored, err := storage.Get(context.Background(), key)
+			switch {
+			case tt.expectedStatus == http.StatusOK:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.body, stored)
+			case tt.stored != nil:
+				assert.Equal(t, tt.stored, stored)
+			default:
+				assert.IsError(t, err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestHandler_GetNotFound(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/"+zbase32.EncodeToString(publicKey), nil)
+	res := httptest.NewRecorder()
+	NewHandler(NewMemoryStorage()).ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
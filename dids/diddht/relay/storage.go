@@ -0,0 +1,110 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MemoryStorage is an implementation of [Storage] that keeps records in memory.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	records map[string][]byte
+}
+
+// NewMemoryStorage returns a new, empty instance of [MemoryStorage]
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		records: make(map[string][]byte),
+	}
+}
+
+// Get returns the record stored for the given key
+func (s *MemoryStorage) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return append([]byte(nil), record...), nil
+}
+
+// Put stores the record for the given key
+func (s *MemoryStorage) Put(_ context.Context, key string, record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = append([]byte(nil), record...)
+	return nil
+}
+
+// FileStorage is an implementation of [Storage] that keeps each record in its own file, named after
+// the record's key, within a directory. Records are written atomically.
+type FileStorage struct {
+	dir string
+}
+
+// NewFileStorage returns a new instance of [FileStorage] that stores records in dir. The directory is
+// created if it does not exist.
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &FileStorage{dir: dir}, nil
+}
+
+// Get returns the record stored for the given key
+func (s *FileStorage) Get(_ context.Context, key string) ([]byte, error) {
+	record, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record: %w", err)
+	}
+
+	return record, nil
+}
+
+// Put stores the record for the given key
+func (s *FileStorage) Put(_ context.Context, key string, record []byte) error {
+	// write to a temporary file first and rename it so readers never observe a partial record
+	tmp, err := os.CreateTemp(s.dir, "."+key+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(record); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return fmt.Errorf("failed to store record: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key))
+}
@@ -2,15 +2,24 @@ package diddht
 
 import (
 	"context"
+	"crypto/ed25519"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/decentralized-identity/web5-go/dids/did"
 	"github.com/decentralized-identity/web5-go/dids/didcore"
-	"github.com/decentralized-identity/web5-go/dids/diddht/internal/dns"
+	"github.com/decentralized-identity/web5-go/dids/diddht/dnscodec"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
 	"github.com/decentralized-identity/web5-go/dids/diddht/internal/pkarr"
+	"github.com/decentralized-identity/web5-go/dids/diddht/mainline"
 	"github.com/tv42/zbase32"
 )
 
+// ErrorCodeInvalidSignature is the resolution error code returned when the record fetched from
+// the relay is truncated, or its BEP44 signature does not verify against the DID's identity key.
+const ErrorCodeInvalidSignature = "invalidSignature"
+
 // DefaultResolver uses the default Pkarr gateway client: https://diddht.tbddev.org
 func DefaultResolver() *Resolver {
 	return &Resolver{
@@ -32,6 +41,22 @@ func NewResolver(relayURL string, client *http.Client) *Resolver {
 	}
 }
 
+// NewResolverWithGatewayPool creates a new Resolver instance that fetches DIDs from every gateway in the
+// pool and resolves the most recent validly signed version.
+func NewResolverWithGatewayPool(pool *GatewayPool) *Resolver {
+	return &Resolver{
+		relay: pool,
+	}
+}
+
+// NewResolverWithDHT creates a new Resolver instance that fetches DIDs directly from the mainline DHT,
+// without going through a Pkarr gateway.
+func NewResolverWithDHT(client *mainline.Client) *Resolver {
+	return &Resolver{
+		relay: client,
+	}
+}
+
 // Resolve resolves a DID using the DHT method
 func (r *Resolver) Resolve(uri string) (didcore.ResolutionResult, error) {
 	return r.ResolveWithContext(context.Background(), uri)
@@ -58,8 +83,7 @@ func (r *Resolver) ResolveWithContext(ctx context.Context, uri string) (didcore.
 		return didcore.ResolutionResultWithError("invalidPublicKey"), didcore.ResolutionError{Code: "invalidPublicKey"}
 	}
 
-	if len(identifier) == 0 {
-		// return nil, fmt.Errorf("no bytes decoded from zbase32 identifier %s", did.ID)
+	if len(identifier) != ed25519.PublicKeySize {
 		// TODO log err
 		return didcore.ResolutionResultWithError("invalidPublicKey"), didcore.ResolutionError{Code: "invalidPublicKey"}
 	}
@@ -68,16 +92,52 @@ func (r *Resolver) ResolveWithContext(ctx context.Context, uri string) (didcore.
 	bep44Message, err := r.relay.FetchWithContext(ctx, did.ID)
 	if err != nil {
 		// TODO log err
+		if errors.Is(err, bep44.ErrMalformedMessage) || errors.Is(err, bep44.ErrInvalidSignature) {
+			return didcore.ResolutionResultWithError(ErrorCodeInvalidSignature), didcore.ResolutionError{Code: ErrorCodeInvalidSignature}
+		}
+
 		return didcore.ResolutionResultWithError("notFound"), didcore.ResolutionError{Code: "notFound"}
 	}
 
+	// 4. relays are untrusted, so verify the record was signed by the identity key
+	if err := bep44Message.Verify(identifier); err != nil {
+		// TODO log err
+		return didcore.ResolutionResultWithError(ErrorCodeInvalidSignature), didcore.ResolutionError{Code: ErrorCodeInvalidSignature}
+	}
+
 	// get the dns payload from the bep44 message
 	bep44MessagePayload := bep44Message.V
-	document, err := dns.UnmarshalDIDDocument(bep44MessagePayload)
+	document, props, err := dnscodec.UnmarshalDIDDocument(bep44MessagePayload)
 	if err != nil {
 		// TODO log err
 		return didcore.ResolutionResultWithError("invalidDid"), didcore.ResolutionError{Code: "invalidDid"}
 	}
 
-	return didcore.ResolutionResultWithDocument(*document), nil
+	result := didcore.ResolutionResultWithDocument(*document)
+	result.DocumentMetadata.VersionID = strconv.FormatInt(bep44Message.Seq, 10)
+	result.DocumentMetadata.Types = props.Types
+
+	// only expose the previous DID if its identity key authorized the link
+	if props.PreviousDID != nil && verifyPreviousDID(*props.PreviousDID, document.ID) {
+		result.DocumentMetadata.PreviousDID = props.PreviousDID.DID
+	}
+
+	result.DocumentMetadata.Deactivated = props.Deactivated
+
+	return result, nil
+}
+
+// verifyPreviousDID checks that the previous DID record was signed by the identity key of the previous DID
+func verifyPreviousDID(previous dnscodec.PreviousDID, didURI string) bool {
+	previousDID, err := did.Parse(previous.DID)
+	if err != nil || previousDID.Method != "dht" {
+		return false
+	}
+
+	publicKey, err := zbase32.DecodeString(previousDID.ID)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+
+	return ed25519.Verify(publicKey, []byte(didURI), previous.Signature)
 }
@@ -112,6 +112,8 @@ func Test_resolve(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotZero(t, res.Document)
 			assert.Equal(t, res.Document.ID, did)
+			assert.Equal(t, "1706093846", res.DocumentMetadata.VersionID)
+			assert.Equal(t, []int{7, 6}, res.DocumentMetadata.Types)
 		})
 	}
 }
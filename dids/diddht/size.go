@@ -0,0 +1,84 @@
+package diddht
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/dids/diddht/dnscodec"
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/bep44"
+)
+
+// ErrDocumentTooLarge is returned when the DNS packet representation of a DID Document does not fit into
+// the 1000 byte limit of a BEP44 record. Use [EstimateSize] to find out which parts of the document take up
+// the most space.
+var ErrDocumentTooLarge = bep44.ErrPayloadTooLarge
+
+// SizeReport describes how much of the 1000 byte BEP44 record budget a DID Document takes up
+type SizeReport struct {
+	// Parts lists the number of bytes each part of the DID Document adds to the DNS packet, in the order
+	// the parts are packed, e.g. "header", "root record", "verification method did:dht:abc#0" or "service #dwn"
+	Parts []dnscodec.PartSize
+	// PacketSize is the size of the DNS packet the DID Document is mapped to
+	PacketSize int
+	// PayloadSize is the size of the bencoded seq and v values signed and stored in the BEP44 record
+	PayloadSize int
+	// MaxPayloadSize is the maximum PayloadSize accepted by the DHT
+	MaxPayloadSize int
+}
+
+// Fits reports whether the DID Document fits into a BEP44 record
+func (r SizeReport) Fits() bool {
+	return r.PayloadSize <= r.MaxPayloadSize
+}
+
+// EstimateSize reports how many bytes the DID Document that [Create] would produce for the same options takes
+// up, broken down by document part. Keys are generated in a throwaway key manager, so any [KeyManager] or
+// [Gateway] option is ignored and nothing is published. The link to a [PreviousDID] is estimated with a
+// placeholder signature, so the previous DID's key is not used. The estimate assumes a sequence number of the
+// current unix time.
+func EstimateSize(opts ...CreateOption) (SizeReport, error) {
+	o := createOptions{
+		privateKeys: []verificationMethodOption{},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	o.keyManager = crypto.NewLocalKeyManager()
+
+	previousDID := o.previousDID
+	o.previousDID = nil
+
+	bdid, _, marshalOpts, err := newDocument(context.Background(), o)
+	if err != nil {
+		return SizeReport{}, err
+	}
+
+	if previousDID != nil {
+		if previousDID.Method != "dht" {
+			return SizeReport{}, fmt.Errorf("previous did must be a did:dht, got: %s", previousDID.Method)
+		}
+
+		// the link is signed by the Ed25519 identity key of the previous DID, so its signature has a fixed size
+		placeholder := dnscodec.PreviousDID{DID: previousDID.URI, Signature: make([]byte, ed25519.SignatureSize)}
+		marshalOpts = append(marshalOpts, dnscodec.Previous(placeholder))
+	}
+
+	parts, err := dnscodec.EstimateSize(&bdid.Document, marshalOpts...)
+	if err != nil {
+		return SizeReport{}, fmt.Errorf("failed to marshal did document to dns packet: %w", err)
+	}
+
+	report := SizeReport{Parts: parts, MaxPayloadSize: bep44.MaxPayloadSize}
+	for _, part := range parts {
+		report.PacketSize += part.Size
+	}
+
+	report.PayloadSize = bep44.PayloadSize(time.Now().Unix(), report.PacketSize)
+
+	return report, nil
+}
@@ -0,0 +1,34 @@
+package diddht
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/decentralized-identity/web5-go/dids/diddht/internal/pkarr"
+)
+
+// Types from the did:dht type index that can be passed to [Types] and [FetchDIDsByType].
+//
+// https://did-dht.com/registry/#indexed-types
+const (
+	TypeDiscoverable           = 0
+	TypeOrganization           = 1
+	TypeGovernmentOrganization = 2
+	TypeCorporation            = 3
+	TypeLocalBusiness          = 4
+	TypeSoftwarePackage        = 5
+	TypeWebApp                 = 6
+	TypeFinancialInstitution   = 7
+)
+
+// FetchDIDsByType returns the DIDs a did:dht gateway has indexed under the given type.
+func FetchDIDsByType(gatewayURL string, client *http.Client, typeIndex int) ([]string, error) {
+	return FetchDIDsByTypeWithContext(context.Background(), gatewayURL, client, typeIndex)
+}
+
+// FetchDIDsByTypeWithContext returns the DIDs a did:dht gateway has indexed under the given type.
+//
+// Spec: https://did-dht.com/#get-dids-by-type
+func FetchDIDsByTypeWithContext(ctx context.Context, gatewayURL string, client *http.Client, typeIndex int) ([]string, error) {
+	return pkarr.NewClient(gatewayURL, client).FetchByType(ctx, typeIndex)
+}
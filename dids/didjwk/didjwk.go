@@ -8,6 +8,7 @@ import (
 
 	"github.com/decentralized-identity/web5-go/crypto"
 	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
 	"github.com/decentralized-identity/web5-go/dids/did"
 	"github.com/decentralized-identity/web5-go/dids/didcore"
 	"github.com/decentralized-identity/web5-go/jwk"
@@ -132,6 +133,13 @@ func createDocument(did did.DID, publicKey jwk.JWK) didcore.Document {
 		PublicKeyJwk: &publicKey,
 	}
 
+	// key agreement only keys cannot be used for any other relationship
+	// https://github.com/quartzjer/did-jwk/blob/main/spec.md#to-create-the-did-url
+	if publicKey.CRV == ecdh.X25519JWACurve {
+		doc.AddVerificationMethod(vm, didcore.Purposes("keyAgreement"))
+		return doc
+	}
+
 	doc.AddVerificationMethod(
 		vm,
 		didcore.Purposes("assertionMethod", "authentication", "capabilityInvocation", "capabilityDelegation"),
@@ -7,6 +7,8 @@ import (
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/decentralized-identity/web5-go"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
 	"github.com/decentralized-identity/web5-go/dids/didcore"
 	"github.com/decentralized-identity/web5-go/dids/didjwk"
 	"github.com/decentralized-identity/web5-go/jwk"
@@ -85,3 +87,44 @@ func TestVector_Resolve(t *testing.T) {
 		})
 	}
 }
+
+func TestCreate_NIST(t *testing.T) {
+	for _, algorithmID := range []string{dsa.AlgorithmIDSECP256R1, dsa.AlgorithmIDSECP384R1} {
+		bearerDID, err := didjwk.Create(didjwk.AlgorithmID(algorithmID))
+		assert.NoError(t, err)
+
+		resolver := &didjwk.Resolver{}
+		result, err := resolver.Resolve(bearerDID.URI)
+		assert.NoError(t, err)
+
+		vm := result.Document.VerificationMethod[0]
+		id, err := dsa.AlgorithmID(vm.PublicKeyJwk)
+		assert.NoError(t, err)
+		assert.Equal(t, algorithmID, id)
+
+		signer, _, err := bearerDID.GetSigner(nil)
+		assert.NoError(t, err)
+
+		signature, err := signer([]byte("hello"))
+		assert.NoError(t, err)
+
+		ok, err := dsa.Verify([]byte("hello"), signature, *vm.PublicKeyJwk)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestCreate_X25519(t *testing.T) {
+	bearerDID, err := didjwk.Create(didjwk.AlgorithmID(ecdh.X25519AlgorithmID))
+	assert.NoError(t, err)
+
+	resolver := &didjwk.Resolver{}
+	result, err := resolver.Resolve(bearerDID.URI)
+	assert.NoError(t, err)
+
+	// X25519 keys can only be used for key agreement
+	document := result.Document
+	assert.Equal(t, []string{bearerDID.URI + "#0"}, document.KeyAgreement)
+	assert.Equal(t, 0, len(document.Authentication))
+	assert.Equal(t, 0, len(document.AssertionMethod))
+}
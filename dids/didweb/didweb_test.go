@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
 	"github.com/decentralized-identity/web5-go/dids/did"
 	"github.com/decentralized-identity/web5-go/dids/didcore"
 	"github.com/decentralized-identity/web5-go/dids/didweb"
@@ -121,3 +122,50 @@ func TestTransformID(t *testing.T) {
 		})
 	}
 }
+
+func TestCreate_NIST(t *testing.T) {
+	bearerDID, err := didweb.Create(
+		"localhost:8080",
+		didweb.PrivateKey(dsa.AlgorithmIDSECP256R1, didcore.PurposeAuthentication),
+		didweb.PrivateKey(dsa.AlgorithmIDSECP384R1, didcore.PurposeAssertion),
+	)
+	assert.NoError(t, err)
+
+	document := bearerDID.Document
+	// the keys are added alongside the default Ed25519 key
+	assert.Equal(t, 3, len(document.VerificationMethod))
+	assert.Equal(t, "P-256", document.VerificationMethod[1].PublicKeyJwk.CRV)
+	assert.Equal(t, "P-384", document.VerificationMethod[2].PublicKeyJwk.CRV)
+
+	signer, vm, err := bearerDID.GetSigner(didcore.PurposeAssertion)
+	assert.NoError(t, err)
+
+	signature, err := signer([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, 96, len(signature))
+
+	ok, err := dsa.Verify([]byte("hello"), signature, *vm.PublicKeyJwk)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCreate_MLDSA(t *testing.T) {
+	bearerDID, err := didweb.Create("localhost:8080", didweb.PrivateKey(dsa.AlgorithmIDMLDSA44, didcore.PurposeAssertion))
+	assert.NoError(t, err)
+
+	vm := bearerDID.Document.VerificationMethod[1]
+	assert.Equal(t, "AKP", vm.PublicKeyJwk.KTY)
+	assert.Equal(t, "ML-DSA-44", vm.PublicKeyJwk.ALG)
+	assert.Equal(t, "", vm.PublicKeyJwk.PRIV)
+
+	signer, vm, err := bearerDID.GetSigner(didcore.PurposeAssertion)
+	assert.NoError(t, err)
+
+	signature, err := signer([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2420, len(signature))
+
+	ok, err := dsa.Verify([]byte("hello"), signature, *vm.PublicKeyJwk)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
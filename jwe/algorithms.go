@@ -0,0 +1,291 @@
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Key management algorithms. https://datatracker.ietf.org/doc/html/rfc7518#section-4.6 and
+// https://datatracker.ietf.org/doc/html/draft-madden-jose-ecdh-1pu-04
+const (
+	AlgorithmECDHESA256KW  = "ECDH-ES+A256KW"
+	AlgorithmECDH1PUA256KW = "ECDH-1PU+A256KW"
+)
+
+// Content encryption algorithms. https://datatracker.ietf.org/doc/html/rfc7518#section-5 and
+// https://datatracker.ietf.org/doc/html/draft-amringer-jose-chacha-02
+const (
+	EncryptionA256GCM      = "A256GCM"
+	EncryptionXC20P        = "XC20P"
+	EncryptionA256CBCHS512 = "A256CBC-HS512"
+)
+
+// contentEncryption encrypts the payload of a JWE with a content encryption key
+type contentEncryption interface {
+	keySize() int
+	encrypt(cek, plaintext, aad []byte) (iv, ciphertext, tag []byte, err error)
+	decrypt(cek, iv, ciphertext, tag, aad []byte) ([]byte, error)
+}
+
+// getContentEncryption returns the implementation of the given enc header value
+func getContentEncryption(enc string) (contentEncryption, error) {
+	switch enc {
+	case EncryptionA256GCM:
+		return aead{size: 32, newAEAD: newGCM}, nil
+	case EncryptionXC20P:
+		return aead{size: chacha20poly1305.KeySize, newAEAD: chacha20poly1305.NewX}, nil
+	case EncryptionA256CBCHS512:
+		return cbcHMAC{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported content encryption algorithm: %s", enc)
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// aead is content encryption with an AEAD cipher that appends its tag to the ciphertext
+type aead struct {
+	size    int
+	newAEAD func(key []byte) (cipher.AEAD, error)
+}
+
+func (a aead) keySize() int {
+	return a.size
+}
+
+func (a aead) encrypt(cek, plaintext, aad []byte) ([]byte, []byte, []byte, error) {
+	c, err := a.newAEAD(cek)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	iv := make([]byte, c.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, nil, err
+	}
+
+	sealed := c.Seal(nil, iv, plaintext, aad)
+	split := len(sealed) - c.Overhead()
+
+	return iv, sealed[:split], sealed[split:], nil
+}
+
+func (a aead) decrypt(cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+	c, err := a.newAEAD(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != c.NonceSize() || len(tag) != c.Overhead() {
+		return nil, errors.New("invalid iv or tag size")
+	}
+
+	sealed := append(append([]byte{}, ciphertext...), tag...)
+	return c.Open(nil, iv, sealed, aad)
+}
+
+// cbcHMAC is AES_256_CBC_HMAC_SHA_512 content encryption.
+// https://datatracker.ietf.org/doc/html/rfc7518#section-5.2
+type cbcHMAC struct{}
+
+func (cbcHMAC) keySize() int {
+	return 64
+}
+
+func (c cbcHMAC) encrypt(cek, plaintext, aad []byte) ([]byte, []byte, []byte, error) {
+	if len(cek) != c.keySize() {
+		return nil, nil, nil, errors.New("invalid content encryption key size")
+	}
+
+	block, err := aes.NewCipher(cek[32:])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// PKCS #7 padding
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	ciphertext := append(append([]byte{}, plaintext...), make([]byte, padding)...)
+	for i := len(plaintext); i < len(ciphertext); i++ {
+		ciphertext[i] = byte(padding)
+	}
+
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+
+	return iv, ciphertext, c.tag(cek[:32], aad, iv, ciphertext), nil
+}
+
+func (c cbcHMAC) decrypt(cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+	if len(cek) != c.keySize() {
+		return nil, errors.New("invalid content encryption key size")
+	}
+
+	if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid iv or ciphertext size")
+	}
+
+	// the tag is checked before decrypting, so padding errors cannot be used as an oracle
+	if !hmac.Equal(tag, c.tag(cek[:32], aad, iv, ciphertext)) {
+		return nil, errors.New("message authentication failed")
+	}
+
+	block, err := aes.NewCipher(cek[32:])
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errors.New("invalid padding")
+	}
+
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("invalid padding")
+		}
+	}
+
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// tag computes the authentication tag: the first half of HMAC-SHA-512(AAD || IV || ciphertext || AL), where
+// AL is the number of bits in AAD as a 64 bit big-endian integer
+func (cbcHMAC) tag(macKey, aad, iv, ciphertext []byte) []byte {
+	mac := hmac.New(sha512.New, macKey)
+	mac.Write(aad)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	mac.Write(binary.BigEndian.AppendUint64(nil, uint64(len(aad))*8))
+
+	return mac.Sum(nil)[:32]
+}
+
+// concatKDF derives a key of keySize bytes from a shared secret, with the Concat KDF of NIST SP 800-56A
+// section 5.8.1 as profiled by https://datatracker.ietf.org/doc/html/rfc7518#section-4.6.2. For ECDH-1PU in
+// key wrapping mode, the tag of the content encryption is appended to SuppPubInfo.
+func concatKDF(z []byte, alg string, apu, apv []byte, keySize int, ccTag []byte) []byte {
+	var otherInfo []byte
+	otherInfo = appendLengthPrefixed(otherInfo, []byte(alg))
+	otherInfo = appendLengthPrefixed(otherInfo, apu)
+	otherInfo = appendLengthPrefixed(otherInfo, apv)
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(keySize*8))
+	if ccTag != nil {
+		otherInfo = appendLengthPrefixed(otherInfo, ccTag)
+	}
+
+	var key []byte
+	for counter := uint32(1); len(key) < keySize; counter++ {
+		h := sha256.New()
+		h.Write(binary.BigEndian.AppendUint32(nil, counter))
+		h.Write(z)
+		h.Write(otherInfo)
+		key = h.Sum(key)
+	}
+
+	return key[:keySize]
+}
+
+func appendLengthPrefixed(b []byte, data []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+// keyWrapIV is the default initial value of the AES Key Wrap algorithm
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// wrapKey wraps a key with the AES Key Wrap algorithm. https://datatracker.ietf.org/doc/html/rfc3394#section-2.2.1
+func wrapKey(kek, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, errors.New("key to wrap must be a multiple of 8 bytes and at least 16 bytes")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(key) / 8
+	r := make([]byte, len(key))
+	copy(r, key)
+
+	a := make([]byte, 8)
+	copy(a, keyWrapIV)
+
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 0; i < n; i++ {
+			copy(buf, a)
+			copy(buf[8:], r[i*8:i*8+8])
+			block.Encrypt(buf, buf)
+
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(buf[:8])^t)
+			copy(r[i*8:], buf[8:])
+		}
+	}
+
+	return append(a, r...), nil
+}
+
+// unwrapKey unwraps a key wrapped with the AES Key Wrap algorithm, checking its integrity.
+// https://datatracker.ietf.org/doc/html/rfc3394#section-2.2.2
+func unwrapKey(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errors.New("wrapped key must be a multiple of 8 bytes and at least 24 bytes")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	copy(a, wrapped[:8])
+
+	r := make([]byte, n*8)
+	copy(r, wrapped[8:])
+
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n - 1; i >= 0; i-- {
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(buf, binary.BigEndian.Uint64(a)^t)
+			copy(buf[8:], r[i*8:i*8+8])
+			block.Decrypt(buf, buf)
+
+			copy(a, buf[:8])
+			copy(r[i*8:], buf[8:])
+		}
+	}
+
+	if subtle.ConstantTimeCompare(a, keyWrapIV) != 1 {
+		return nil, errors.New("key unwrap integrity check failed")
+	}
+
+	return r, nil
+}
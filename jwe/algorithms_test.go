@@ -0,0 +1,64 @@
+package jwe
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestWrapKey(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc3394#section-4.6
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F")
+	expected, _ := hex.DecodeString("28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21")
+
+	wrapped, err := wrapKey(kek, key)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, wrapped)
+
+	unwrapped, err := unwrapKey(kek, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	wrapped[0] ^= 1
+	_, err = unwrapKey(kek, wrapped)
+	assert.Error(t, err)
+}
+
+func TestConcatKDF(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc7518#appendix-C
+	z := []byte{
+		158, 86, 217, 29, 129, 113, 53, 211, 114, 131, 66, 131, 191, 132, 38, 156,
+		251, 49, 110, 163, 218, 128, 106, 72, 246, 218, 167, 121, 140, 254, 144, 196,
+	}
+	expected := []byte{86, 170, 141, 234, 248, 35, 109, 32, 92, 34, 40, 205, 113, 167, 16, 26}
+
+	key := concatKDF(z, "A128GCM", []byte("Alice"), []byte("Bob"), 16, nil)
+	assert.Equal(t, expected, key)
+}
+
+func TestContentEncryption(t *testing.T) {
+	for _, enc := range []string{EncryptionA256GCM, EncryptionXC20P, EncryptionA256CBCHS512} {
+		t.Run(enc, func(t *testing.T) {
+			c, err := getContentEncryption(enc)
+			assert.NoError(t, err)
+
+			cek := make([]byte, c.keySize())
+			aad := []byte("aad")
+
+			iv, ciphertext, tag, err := c.encrypt(cek, []byte("hello"), aad)
+			assert.NoError(t, err)
+
+			plaintext, err := c.decrypt(cek, iv, ciphertext, tag, aad)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("hello"), plaintext)
+
+			_, err = c.decrypt(cek, iv, ciphertext, tag, []byte("other"))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := getContentEncryption("A128GCM")
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+package jwe
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/dids"
+	_did "github.com/decentralized-identity/web5-go/dids/did"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// Decrypt parses and decrypts a JWE in compact or JSON serialization with the keyAgreement keys of the
+// given DID. See [JWE.Decrypt] for details.
+func Decrypt(jwe string, did _did.BearerDID) ([]byte, error) {
+	parsed, err := Parse(jwe)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed.Decrypt(did)
+}
+
+// Decrypt decrypts the JWE with the first recipient whose kid references a keyAgreement verification
+// method of the given DID. The key manager of the DID must implement [crypto.KeyAgreer]. When the JWE
+// was encrypted with ECDH-1PU, the sender DID is resolved from the skid header to authenticate the sender.
+func (j JWE) Decrypt(did _did.BearerDID) ([]byte, error) {
+	agreer, ok := did.KeyManager.(crypto.KeyAgreer)
+	if !ok {
+		return nil, errors.New("key manager does not support key agreement")
+	}
+
+	keys := keyAgreementKeys(did.Document)
+
+	var errs []error
+	for _, recipient := range j.Recipients {
+		header, err := j.recipientHeader(recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if key.ID != header.KID {
+				continue
+			}
+
+			keyID, err := did.KeyAlias(key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute key alias: %w", err)
+			}
+
+			payload, err := j.decrypt(header, recipient, agreer, keyID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to decrypt with %s: %w", key.ID, err))
+				continue
+			}
+
+			return payload, nil
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return nil, fmt.Errorf("no recipient of the JWE matches a keyAgreement key of %s", did.URI)
+}
+
+// decrypt unwraps the content encryption key of a recipient and decrypts the content with it
+func (j JWE) decrypt(header Header, recipient Recipient, agreer crypto.KeyAgreer, keyID string) ([]byte, error) {
+	contentEnc, err := getContentEncryption(header.ENC)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.EPK == nil {
+		return nil, errors.New("missing epk header")
+	}
+
+	apu, err := base64.RawURLEncoding.DecodeString(header.APU)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode apu: %w", err)
+	}
+
+	apv, err := base64.RawURLEncoding.DecodeString(header.APV)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode apv: %w", err)
+	}
+
+	z, err := agreer.DeriveSharedSecret(keyID, *header.EPK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+	}
+
+	var ccTag []byte
+	switch header.ALG {
+	case AlgorithmECDHESA256KW:
+	case AlgorithmECDH1PUA256KW:
+		if header.ENC != EncryptionA256CBCHS512 {
+			return nil, fmt.Errorf("%s requires %s content encryption", header.ALG, EncryptionA256CBCHS512)
+		}
+
+		senderKey, err := resolveSenderKey(header.SKID)
+		if err != nil {
+			return nil, err
+		}
+
+		if senderKey.CRV != header.EPK.CRV {
+			return nil, errors.New("sender key and epk are on different curves")
+		}
+
+		zs, err := agreer.DeriveSharedSecret(keyID, senderKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive sender shared secret: %w", err)
+		}
+
+		z = append(z, zs...)
+		ccTag = j.Tag
+	default:
+		return nil, fmt.Errorf("unsupported key management algorithm: %s", header.ALG)
+	}
+
+	kek := concatKDF(z, header.ALG, apu, apv, kekSize, ccTag)
+	cek, err := unwrapKey(kek, recipient.EncryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap content encryption key: %w", err)
+	}
+
+	if len(cek) != contentEnc.keySize() {
+		return nil, errors.New("invalid content encryption key size")
+	}
+
+	return contentEnc.decrypt(cek, j.IV, j.Ciphertext, j.Tag, j.aad())
+}
+
+// resolveSenderKey resolves the keyAgreement key referenced by the skid header of an ECDH-1PU JWE
+func resolveSenderKey(skid string) (jwk.JWK, error) {
+	if skid == "" {
+		return jwk.JWK{}, errors.New("missing skid header")
+	}
+
+	did, err := _did.Parse(skid)
+	if err != nil {
+		return jwk.JWK{}, fmt.Errorf("invalid skid %s: %w", skid, err)
+	}
+
+	result, err := dids.Resolve(did.URI)
+	if err != nil {
+		return jwk.JWK{}, fmt.Errorf("failed to resolve sender %s: %w", did.URI, err)
+	}
+
+	for _, key := range keyAgreementKeys(result.Document) {
+		if key.ID == skid {
+			return *key.PublicKeyJwk, nil
+		}
+	}
+
+	return jwk.JWK{}, fmt.Errorf("sender %s has no keyAgreement verification method %s", did.URI, skid)
+}
@@ -0,0 +1,326 @@
+package jwe
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/decentralized-identity/web5-go/crypto"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
+	"github.com/decentralized-identity/web5-go/crypto/dsa/ecdsa"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	"github.com/decentralized-identity/web5-go/dids"
+	_did "github.com/decentralized-identity/web5-go/dids/did"
+	"github.com/decentralized-identity/web5-go/dids/didcore"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// kekSize is the size of the AES-256 key wrapping keys derived for each recipient
+const kekSize = 32
+
+// options that encrypt function can take
+type encryptOpts struct {
+	enc    string
+	sender *_did.BearerDID
+	typ    string
+	cty    string
+}
+
+// EncryptOpt is a type that represents an option that can be passed to [Encrypt].
+type EncryptOpt func(opts *encryptOpts)
+
+// ContentEncryption is an option that can be passed to [Encrypt]. It sets the content encryption
+// algorithm, which defaults to [EncryptionA256GCM], or [EncryptionA256CBCHS512] when a [Sender] is set.
+func ContentEncryption(enc string) EncryptOpt {
+	return func(opts *encryptOpts) {
+		opts.enc = enc
+	}
+}
+
+// Sender is an option that can be passed to [Encrypt]. It authenticates the JWE as coming from the
+// given DID, by using ECDH-1PU+A256KW with the first keyAgreement key of the DID. The key manager of the
+// DID must implement [crypto.KeyAgreer].
+//
+// ECDH-1PU in key wrapping mode requires a committing content encryption algorithm, so only
+// [EncryptionA256CBCHS512] can be used with it.
+// https://datatracker.ietf.org/doc/html/draft-madden-jose-ecdh-1pu-04#section-2.1
+func Sender(did _did.BearerDID) EncryptOpt {
+	return func(opts *encryptOpts) {
+		opts.sender = &did
+	}
+}
+
+// Type is an option that can be passed to [Encrypt]. It is used to set the `typ` JWE header value
+func Type(typ string) EncryptOpt {
+	return func(opts *encryptOpts) {
+		opts.typ = typ
+	}
+}
+
+// ContentType is an option that can be passed to [Encrypt]. It is used to set the `cty` JWE header value
+func ContentType(cty string) EncryptOpt {
+	return func(opts *encryptOpts) {
+		opts.cty = cty
+	}
+}
+
+// Encrypt encrypts the payload to the given recipients. A recipient is either a DID, in which case the
+// payload is encrypted to each of its keyAgreement keys, or a DID URL that references one of its
+// keyAgreement verification methods.
+//
+// All recipient keys must be on the same curve, as they share a single ephemeral key. The curve is that of
+// the sender's key if a [Sender] is set, or else of the first recipient key. Keys of the recipients on other
+// curves are skipped.
+func Encrypt(payload []byte, recipients []string, opts ...EncryptOpt) (JWE, error) {
+	o := encryptOpts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	alg := AlgorithmECDHESA256KW
+	if o.sender != nil {
+		alg = AlgorithmECDH1PUA256KW
+	}
+
+	if o.enc == "" {
+		o.enc = EncryptionA256GCM
+		if o.sender != nil {
+			o.enc = EncryptionA256CBCHS512
+		}
+	}
+
+	if o.sender != nil && o.enc != EncryptionA256CBCHS512 {
+		return JWE{}, fmt.Errorf("%s requires %s content encryption", alg, EncryptionA256CBCHS512)
+	}
+
+	contentEnc, err := getContentEncryption(o.enc)
+	if err != nil {
+		return JWE{}, err
+	}
+
+	var sender senderKey
+	if o.sender != nil {
+		if sender, err = getSenderKey(*o.sender); err != nil {
+			return JWE{}, err
+		}
+	}
+
+	keys, err := resolveRecipientKeys(recipients, sender.key.PublicKeyJwk)
+	if err != nil {
+		return JWE{}, err
+	}
+
+	ephemeralKey, err := generateEphemeralKey(keys[0].PublicKeyJwk.CRV)
+	if err != nil {
+		return JWE{}, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	epk := dsa.GetPublicKey(ephemeralKey)
+	if ephemeralKey.CRV == ecdh.X25519JWACurve {
+		epk = ecdh.GetPublicKey(ephemeralKey)
+	}
+
+	kids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		kids = append(kids, key.ID)
+	}
+
+	apu := []byte(sender.key.ID)
+	apv := agreementPartyVInfo(kids)
+
+	protected := Header{
+		ALG:  alg,
+		ENC:  o.enc,
+		EPK:  &epk,
+		APU:  base64.RawURLEncoding.EncodeToString(apu),
+		APV:  base64.RawURLEncoding.EncodeToString(apv),
+		SKID: sender.key.ID,
+		TYP:  o.typ,
+		CTY:  o.cty,
+	}
+
+	// with a single recipient key, the key ID is protected so the JWE can be compact serialized
+	if len(keys) == 1 {
+		protected.KID = keys[0].ID
+	}
+
+	encodedProtected, err := protected.Encode()
+	if err != nil {
+		return JWE{}, fmt.Errorf("failed to encode header: %w", err)
+	}
+
+	cek := make([]byte, contentEnc.keySize())
+	if _, err := rand.Read(cek); err != nil {
+		return JWE{}, fmt.Errorf("failed to generate content encryption key: %w", err)
+	}
+
+	jwe := JWE{Protected: protected, protected: encodedProtected}
+
+	jwe.IV, jwe.Ciphertext, jwe.Tag, err = contentEnc.encrypt(cek, payload, jwe.aad())
+	if err != nil {
+		return JWE{}, fmt.Errorf("failed to encrypt payload: %w", err)
+	}
+
+	for _, key := range keys {
+		z, err := ecdh.DeriveSharedSecret(ephemeralKey, *key.PublicKeyJwk)
+		if err != nil {
+			return JWE{}, fmt.Errorf("failed to derive shared secret for %s: %w", key.ID, err)
+		}
+
+		// ECDH-1PU binds the content to the key wrapping by including the tag in the key derivation
+		var ccTag []byte
+		if o.sender != nil {
+			zs, err := sender.agreer.DeriveSharedSecret(sender.keyID, *key.PublicKeyJwk)
+			if err != nil {
+				return JWE{}, fmt.Errorf("failed to derive sender shared secret for %s: %w", key.ID, err)
+			}
+
+			z = append(z, zs...)
+			ccTag = jwe.Tag
+		}
+
+		kek := concatKDF(z, alg, apu, apv, kekSize, ccTag)
+		encryptedKey, err := wrapKey(kek, cek)
+		if err != nil {
+			return JWE{}, fmt.Errorf("failed to wrap content encryption key for %s: %w", key.ID, err)
+		}
+
+		recipient := Recipient{EncryptedKey: encryptedKey}
+		if len(keys) > 1 {
+			recipient.Header.KID = key.ID
+		}
+
+		jwe.Recipients = append(jwe.Recipients, recipient)
+	}
+
+	return jwe, nil
+}
+
+// senderKey is the keyAgreement key of the sender of an ECDH-1PU JWE
+type senderKey struct {
+	key    didcore.VerificationMethod
+	keyID  string
+	agreer crypto.KeyAgreer
+}
+
+// getSenderKey returns the first keyAgreement key of the sender DID
+func getSenderKey(did _did.BearerDID) (senderKey, error) {
+	agreer, ok := did.KeyManager.(crypto.KeyAgreer)
+	if !ok {
+		return senderKey{}, errors.New("sender key manager does not support key agreement")
+	}
+
+	keys := keyAgreementKeys(did.Document)
+	if len(keys) == 0 {
+		return senderKey{}, fmt.Errorf("sender %s has no keyAgreement verification methods", did.URI)
+	}
+
+	keyID, err := did.KeyAlias(keys[0])
+	if err != nil {
+		return senderKey{}, fmt.Errorf("failed to compute key alias: %w", err)
+	}
+
+	return senderKey{key: keys[0], keyID: keyID, agreer: agreer}, nil
+}
+
+// resolveRecipientKeys resolves the keyAgreement keys of the recipients. Keys are restricted to the curve of
+// the sender key if there is one, or else of the first recipient key.
+func resolveRecipientKeys(recipients []string, senderKey *jwk.JWK) ([]didcore.VerificationMethod, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+
+	var curve string
+	if senderKey != nil {
+		curve = senderKey.CRV
+	}
+
+	var keys []didcore.VerificationMethod
+	seen := make(map[string]bool)
+
+	for _, recipient := range recipients {
+		did, err := _did.Parse(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %s: %w", recipient, err)
+		}
+
+		result, err := dids.Resolve(did.URI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve recipient %s: %w", recipient, err)
+		}
+
+		found := false
+		for _, key := range keyAgreementKeys(result.Document) {
+			if did.Fragment != "" && key.ID != did.URI+"#"+did.Fragment {
+				continue
+			}
+
+			if curve == "" {
+				curve = key.PublicKeyJwk.CRV
+			}
+
+			if key.PublicKeyJwk.CRV != curve {
+				continue
+			}
+
+			found = true
+			if !seen[key.ID] {
+				seen[key.ID] = true
+				keys = append(keys, key)
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("recipient %s has no %s keyAgreement verification method", recipient, curve)
+		}
+	}
+
+	return keys, nil
+}
+
+// keyAgreementKeys returns the keyAgreement verification methods of a DID document, with absolute IDs
+func keyAgreementKeys(document didcore.Document) []didcore.VerificationMethod {
+	var keys []didcore.VerificationMethod
+	for _, ref := range document.KeyAgreement {
+		id := document.GetAbsoluteResourceID(ref)
+		for _, vm := range document.VerificationMethod {
+			if document.GetAbsoluteResourceID(vm.ID) == id && vm.PublicKeyJwk != nil {
+				vm.ID = id
+				keys = append(keys, vm)
+				break
+			}
+		}
+	}
+
+	return keys
+}
+
+// generateEphemeralKey generates a key pair on the given curve
+func generateEphemeralKey(crv string) (jwk.JWK, error) {
+	switch crv {
+	case ecdh.X25519JWACurve:
+		return ecdh.GeneratePrivateKey(ecdh.X25519AlgorithmID)
+	case ecdsa.SECP256R1JWACurve:
+		return dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256R1)
+	case ecdsa.SECP384R1JWACurve:
+		return dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP384R1)
+	case ecdsa.SECP256K1JWACurve:
+		return dsa.GeneratePrivateKey(dsa.AlgorithmIDSECP256K1)
+	default:
+		return jwk.JWK{}, fmt.Errorf("unsupported key agreement curve: %s", crv)
+	}
+}
+
+// agreementPartyVInfo returns the SHA-256 digest of the sorted recipient key IDs joined with `.`, which
+// binds the JWE to its set of recipients. This follows DIDComm Messaging.
+// https://identity.foundation/didcomm-messaging/spec/v2.1/#ecdh-1pu-key-wrapping-and-common-protected-headers
+func agreementPartyVInfo(kids []string) []byte {
+	sorted := append([]string{}, kids...)
+	sort.Strings(sorted)
+
+	digest := sha256.Sum256([]byte(strings.Join(sorted, ".")))
+	return digest[:]
+}
@@ -0,0 +1,310 @@
+// Package jwe implements encryption to DIDs with JSON Web Encryption (https://datatracker.ietf.org/doc/html/rfc7516).
+//
+// Payloads are encrypted to the keyAgreement verification methods of the recipient DIDs, either anonymously
+// with ECDH-ES+A256KW, or authenticated by the sender's keyAgreement key with ECDH-1PU+A256KW. Decryption
+// uses the keys held by the key manager of a [github.com/decentralized-identity/web5-go/dids/did.BearerDID],
+// which must implement [github.com/decentralized-identity/web5-go/crypto.KeyAgreer].
+package jwe
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// Header represents a JWE header. See [Specification] for more details.
+//
+// [Specification]: https://datatracker.ietf.org/doc/html/rfc7516#section-4
+type Header struct {
+	// Key management algorithm https://datatracker.ietf.org/doc/html/rfc7516#section-4.1.1
+	ALG string `json:"alg,omitempty"`
+	// Content encryption algorithm https://datatracker.ietf.org/doc/html/rfc7516#section-4.1.2
+	ENC string `json:"enc,omitempty"`
+	// Ephemeral public key https://datatracker.ietf.org/doc/html/rfc7518#section-4.6.1.1
+	EPK *jwk.JWK `json:"epk,omitempty"`
+	// Base64url encoded agreement PartyUInfo https://datatracker.ietf.org/doc/html/rfc7518#section-4.6.1.2
+	APU string `json:"apu,omitempty"`
+	// Base64url encoded agreement PartyVInfo https://datatracker.ietf.org/doc/html/rfc7518#section-4.6.1.3
+	APV string `json:"apv,omitempty"`
+	// Sender key ID https://datatracker.ietf.org/doc/html/draft-madden-jose-ecdh-1pu-04#section-2.2.1
+	SKID string `json:"skid,omitempty"`
+	// Recipient key ID https://datatracker.ietf.org/doc/html/rfc7516#section-4.1.6
+	KID string `json:"kid,omitempty"`
+	// Type https://datatracker.ietf.org/doc/html/rfc7516#section-4.1.11
+	TYP string `json:"typ,omitempty"`
+	// Content type https://datatracker.ietf.org/doc/html/rfc7516#section-4.1.12
+	CTY string `json:"cty,omitempty"`
+}
+
+// Encode returns the base64url encoded header.
+func (h Header) Encode() (string, error) {
+	bytes, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// DecodeHeader decodes the base64url encoded JWE header into a [Header]
+func DecodeHeader(base64UrlEncodedHeader string) (Header, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(base64UrlEncodedHeader)
+	if err != nil {
+		return Header{}, err
+	}
+
+	var header Header
+	if err := json.Unmarshal(bytes, &header); err != nil {
+		return Header{}, err
+	}
+
+	return header, nil
+}
+
+// Recipient holds the content encryption key of a JWE, encrypted to a single recipient key
+type Recipient struct {
+	// Header is the per-recipient unprotected header, which holds the recipient key ID
+	// when there is more than one recipient
+	Header       Header
+	EncryptedKey []byte
+}
+
+// JWE is an encrypted payload along with the content encryption key encrypted to each of its recipients
+type JWE struct {
+	Protected   Header
+	Unprotected Header
+	Recipients  []Recipient
+	IV          []byte
+	Ciphertext  []byte
+	Tag         []byte
+	AAD         []byte
+
+	// protected is the base64url encoded protected header, exactly as it was serialized
+	protected string
+}
+
+// Compact returns the [compact serialization] of the JWE. Only JWEs with a single recipient and no
+// unprotected headers or additional authenticated data can be serialized in compact form.
+//
+// [compact serialization]: https://datatracker.ietf.org/doc/html/rfc7516#section-7.1
+func (j JWE) Compact() (string, error) {
+	if len(j.Recipients) != 1 {
+		return "", fmt.Errorf("compact serialization requires exactly 1 recipient, got %d", len(j.Recipients))
+	}
+
+	if j.Unprotected != (Header{}) || j.Recipients[0].Header != (Header{}) || len(j.AAD) > 0 {
+		return "", errors.New("compact serialization cannot hold unprotected headers or aad")
+	}
+
+	parts := []string{
+		j.protected,
+		base64.RawURLEncoding.EncodeToString(j.Recipients[0].EncryptedKey),
+		base64.RawURLEncoding.EncodeToString(j.IV),
+		base64.RawURLEncoding.EncodeToString(j.Ciphertext),
+		base64.RawURLEncoding.EncodeToString(j.Tag),
+	}
+
+	return strings.Join(parts, "."), nil
+}
+
+// jsonJWE is the general JSON serialization of a JWE. The flattened members are only used when parsing.
+// https://datatracker.ietf.org/doc/html/rfc7516#section-7.2
+type jsonJWE struct {
+	Protected    string          `json:"protected,omitempty"`
+	Unprotected  *Header         `json:"unprotected,omitempty"`
+	Recipients   []jsonRecipient `json:"recipients,omitempty"`
+	Header       *Header         `json:"header,omitempty"`
+	EncryptedKey string          `json:"encrypted_key,omitempty"`
+	AAD          string          `json:"aad,omitempty"`
+	IV           string          `json:"iv"`
+	Ciphertext   string          `json:"ciphertext"`
+	Tag          string          `json:"tag"`
+}
+
+type jsonRecipient struct {
+	Header       *Header `json:"header,omitempty"`
+	EncryptedKey string  `json:"encrypted_key,omitempty"`
+}
+
+// MarshalJSON returns the [general JSON serialization] of the JWE
+//
+// [general JSON serialization]: https://datatracker.ietf.org/doc/html/rfc7516#section-7.2.1
+func (j JWE) MarshalJSON() ([]byte, error) {
+	out := jsonJWE{
+		Protected:  j.protected,
+		IV:         base64.RawURLEncoding.EncodeToString(j.IV),
+		Ciphertext: base64.RawURLEncoding.EncodeToString(j.Ciphertext),
+		Tag:        base64.RawURLEncoding.EncodeToString(j.Tag),
+	}
+
+	if j.Unprotected != (Header{}) {
+		out.Unprotected = &j.Unprotected
+	}
+
+	if len(j.AAD) > 0 {
+		out.AAD = base64.RawURLEncoding.EncodeToString(j.AAD)
+	}
+
+	for _, r := range j.Recipients {
+		recipient := jsonRecipient{EncryptedKey: base64.RawURLEncoding.EncodeToString(r.EncryptedKey)}
+		if r.Header != (Header{}) {
+			header := r.Header
+			recipient.Header = &header
+		}
+		out.Recipients = append(out.Recipients, recipient)
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON parses the general or flattened JSON serialization of a JWE
+func (j *JWE) UnmarshalJSON(data []byte) error {
+	var in jsonJWE
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	parsed := JWE{protected: in.Protected}
+
+	var err error
+	if in.Protected != "" {
+		if parsed.Protected, err = DecodeHeader(in.Protected); err != nil {
+			return fmt.Errorf("malformed JWE. Failed to decode protected header: %w", err)
+		}
+	}
+
+	if in.Unprotected != nil {
+		parsed.Unprotected = *in.Unprotected
+	}
+
+	recipients := in.Recipients
+	if recipients == nil {
+		// flattened serialization
+		recipients = []jsonRecipient{{Header: in.Header, EncryptedKey: in.EncryptedKey}}
+	} else if in.Header != nil || in.EncryptedKey != "" {
+		return errors.New("malformed JWE. recipients cannot be combined with header or encrypted_key")
+	}
+
+	for _, r := range recipients {
+		var recipient Recipient
+		if r.Header != nil {
+			recipient.Header = *r.Header
+		}
+
+		if recipient.EncryptedKey, err = base64.RawURLEncoding.DecodeString(r.EncryptedKey); err != nil {
+			return fmt.Errorf("malformed JWE. Failed to decode encrypted_key: %w", err)
+		}
+
+		parsed.Recipients = append(parsed.Recipients, recipient)
+	}
+
+	fields := []struct {
+		name  string
+		value string
+		dst   *[]byte
+	}{
+		{"aad", in.AAD, &parsed.AAD},
+		{"iv", in.IV, &parsed.IV},
+		{"ciphertext", in.Ciphertext, &parsed.Ciphertext},
+		{"tag", in.Tag, &parsed.Tag},
+	}
+
+	for _, f := range fields {
+		if *f.dst, err = base64.RawURLEncoding.DecodeString(f.value); err != nil {
+			return fmt.Errorf("malformed JWE. Failed to decode %s: %w", f.name, err)
+		}
+	}
+
+	*j = parsed
+	return nil
+}
+
+// Parse parses a JWE in either compact or JSON serialization
+func Parse(jwe string) (JWE, error) {
+	jwe = strings.TrimSpace(jwe)
+	if strings.HasPrefix(jwe, "{") {
+		var parsed JWE
+		if err := json.Unmarshal([]byte(jwe), &parsed); err != nil {
+			return JWE{}, err
+		}
+
+		return parsed, nil
+	}
+
+	parts := strings.Split(jwe, ".")
+	if len(parts) != 5 {
+		return JWE{}, fmt.Errorf("malformed JWE. Expected 5 parts, got %d", len(parts))
+	}
+
+	header, err := DecodeHeader(parts[0])
+	if err != nil {
+		return JWE{}, fmt.Errorf("malformed JWE. Failed to decode header: %w", err)
+	}
+
+	var decoded [4][]byte
+	for i, part := range parts[1:] {
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return JWE{}, fmt.Errorf("malformed JWE. Failed to decode part %d: %w", i+2, err)
+		}
+	}
+
+	return JWE{
+		Protected:  header,
+		Recipients: []Recipient{{EncryptedKey: decoded[0]}},
+		IV:         decoded[1],
+		Ciphertext: decoded[2],
+		Tag:        decoded[3],
+		protected:  parts[0],
+	}, nil
+}
+
+// aad returns the additional authenticated data of the content encryption
+// https://datatracker.ietf.org/doc/html/rfc7516#section-5.1
+func (j JWE) aad() []byte {
+	if len(j.AAD) == 0 {
+		return []byte(j.protected)
+	}
+
+	return []byte(j.protected + "." + base64.RawURLEncoding.EncodeToString(j.AAD))
+}
+
+// recipientHeader returns the JOSE header of a recipient: the union of the protected, shared unprotected
+// and per-recipient headers, which must not share any parameters.
+// https://datatracker.ietf.org/doc/html/rfc7516#section-7.2.1
+func (j JWE) recipientHeader(r Recipient) (Header, error) {
+	merged := make(map[string]json.RawMessage)
+	for _, h := range []Header{j.Protected, j.Unprotected, r.Header} {
+		bytes, err := json.Marshal(h)
+		if err != nil {
+			return Header{}, err
+		}
+
+		var params map[string]json.RawMessage
+		if err := json.Unmarshal(bytes, &params); err != nil {
+			return Header{}, err
+		}
+
+		for name, value := range params {
+			if _, ok := merged[name]; ok {
+				return Header{}, fmt.Errorf("malformed JWE. Header parameter %s is repeated", name)
+			}
+			merged[name] = value
+		}
+	}
+
+	bytes, err := json.Marshal(merged)
+	if err != nil {
+		return Header{}, err
+	}
+
+	var header Header
+	if err := json.Unmarshal(bytes, &header); err != nil {
+		return Header{}, err
+	}
+
+	return header, nil
+}
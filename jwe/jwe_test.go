@@ -0,0 +1,164 @@
+package jwe_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/ecdh"
+	_did "github.com/decentralized-identity/web5-go/dids/did"
+	"github.com/decentralized-identity/web5-go/dids/didjwk"
+	"github.com/decentralized-identity/web5-go/jwe"
+)
+
+func newX25519DID(t *testing.T) _did.BearerDID {
+	t.Helper()
+
+	bearerDID, err := didjwk.Create(didjwk.AlgorithmID(ecdh.X25519AlgorithmID))
+	assert.NoError(t, err)
+
+	return bearerDID
+}
+
+func TestEncrypt(t *testing.T) {
+	recipient := newX25519DID(t)
+	payload := []byte("hello")
+
+	for _, enc := range []string{jwe.EncryptionA256GCM, jwe.EncryptionXC20P, jwe.EncryptionA256CBCHS512} {
+		t.Run(enc, func(t *testing.T) {
+			encrypted, err := jwe.Encrypt(payload, []string{recipient.URI}, jwe.ContentEncryption(enc), jwe.Type("application/didcomm-encrypted+json"))
+			assert.NoError(t, err)
+
+			assert.Equal(t, jwe.AlgorithmECDHESA256KW, encrypted.Protected.ALG)
+			assert.Equal(t, enc, encrypted.Protected.ENC)
+			assert.Equal(t, recipient.URI+"#0", encrypted.Protected.KID)
+			assert.Equal(t, "application/didcomm-encrypted+json", encrypted.Protected.TYP)
+			assert.Equal(t, "", encrypted.Protected.EPK.D)
+
+			compact, err := encrypted.Compact()
+			assert.NoError(t, err)
+			assert.Equal(t, 5, len(strings.Split(compact, ".")))
+
+			decrypted, err := jwe.Decrypt(compact, recipient)
+			assert.NoError(t, err)
+			assert.Equal(t, payload, decrypted)
+		})
+	}
+}
+
+func TestEncrypt_MultipleRecipients(t *testing.T) {
+	alice := newX25519DID(t)
+	bob := newX25519DID(t)
+	payload := []byte("hello")
+
+	encrypted, err := jwe.Encrypt(payload, []string{alice.URI, bob.URI + "#0"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(encrypted.Recipients))
+	assert.Equal(t, "", encrypted.Protected.KID)
+
+	_, err = encrypted.Compact()
+	assert.Error(t, err)
+
+	serialized, err := json.Marshal(encrypted)
+	assert.NoError(t, err)
+
+	for _, recipient := range []_did.BearerDID{alice, bob} {
+		decrypted, err := jwe.Decrypt(string(serialized), recipient)
+		assert.NoError(t, err)
+		assert.Equal(t, payload, decrypted)
+	}
+}
+
+func TestEncrypt_Sender(t *testing.T) {
+	alice := newX25519DID(t)
+	bob := newX25519DID(t)
+	payload := []byte("hello")
+
+	encrypted, err := jwe.Encrypt(payload, []string{bob.URI}, jwe.Sender(alice))
+	assert.NoError(t, err)
+	assert.Equal(t, jwe.AlgorithmECDH1PUA256KW, encrypted.Protected.ALG)
+	assert.Equal(t, jwe.EncryptionA256CBCHS512, encrypted.Protected.ENC)
+	assert.Equal(t, alice.URI+"#0", encrypted.Protected.SKID)
+
+	decrypted, err := encrypted.Decrypt(bob)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, decrypted)
+
+	_, err = jwe.Encrypt(payload, []string{bob.URI}, jwe.Sender(alice), jwe.ContentEncryption(jwe.EncryptionA256GCM))
+	assert.Error(t, err)
+}
+
+func TestEncrypt_NoKeyAgreement(t *testing.T) {
+	recipient, err := didjwk.Create()
+	assert.NoError(t, err)
+
+	_, err = jwe.Encrypt([]byte("hello"), []string{recipient.URI})
+	assert.Error(t, err)
+
+	_, err = jwe.Encrypt([]byte("hello"), nil)
+	assert.Error(t, err)
+}
+
+func TestDecrypt_WrongRecipient(t *testing.T) {
+	recipient := newX25519DID(t)
+	other := newX25519DID(t)
+
+	encrypted, err := jwe.Encrypt([]byte("hello"), []string{recipient.URI})
+	assert.NoError(t, err)
+
+	_, err = encrypted.Decrypt(other)
+	assert.Error(t, err)
+}
+
+func TestDecrypt_Tampered(t *testing.T) {
+	recipient := newX25519DID(t)
+
+	encrypted, err := jwe.Encrypt([]byte("hello"), []string{recipient.URI})
+	assert.NoError(t, err)
+
+	encrypted.Ciphertext[0] ^= 1
+	_, err = encrypted.Decrypt(recipient)
+	assert.Error(t, err)
+}
+
+func TestParse_Flattened(t *testing.T) {
+	recipient := newX25519DID(t)
+	payload := []byte("hello")
+
+	encrypted, err := jwe.Encrypt(payload, []string{recipient.URI})
+	assert.NoError(t, err)
+
+	serialized, err := json.Marshal(encrypted)
+	assert.NoError(t, err)
+
+	var general map[string]any
+	assert.NoError(t, json.Unmarshal(serialized, &general))
+
+	// move the only recipient to the top level
+	recipients := general["recipients"].([]any)
+	general["encrypted_key"] = recipients[0].(map[string]any)["encrypted_key"]
+	delete(general, "recipients")
+
+	flattened, err := json.Marshal(general)
+	assert.NoError(t, err)
+
+	decrypted, err := jwe.Decrypt(string(flattened), recipient)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, decrypted)
+}
+
+func TestParse_Bad(t *testing.T) {
+	badInput := []string{
+		"",
+		"a.b.c",
+		"!!.b.c.d.e",
+		`{"recipients":[{}],"encrypted_key":"a","iv":"","ciphertext":"","tag":""}`,
+		`{"iv":"!!","ciphertext":"","tag":""}`,
+	}
+
+	for _, input := range badInput {
+		_, err := jwe.Parse(input)
+		assert.Error(t, err, "expected error for input: %s", input)
+	}
+}
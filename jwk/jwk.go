@@ -1,16 +1,41 @@
-// Package jwk implements a subset of the JSON Web Key spec (https://tools.ietf.org/html/rfc7517)
+// Package jwk implements the JSON Web Key spec (https://tools.ietf.org/html/rfc7517), along with
+// thumbprints (RFC 7638 and RFC 9278), JWK Sets, validation and conversion to and from standard library
+// keys, PEM/DER and multibase/multicodec encodings.
 package jwk
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"sort"
 )
 
-// JWK represents a JSON Web Key as per RFC7517 (https://tools.ietf.org/html/rfc7517)
-// Note that this is a subset of the spec. There are a handful of properties that the
-// spec allows for that are not represented here at the moment. This is because we
-// only need a subset of the spec for our purposes.
+// Key types (https://www.rfc-editor.org/rfc/rfc7518#section-6.1 and https://www.rfc-editor.org/rfc/rfc8037)
+const (
+	keyTypeEC  = "EC"
+	keyTypeOKP = "OKP"
+	keyTypeRSA = "RSA"
+	keyTypeOct = "oct"
+	// keyTypeAKP is the Algorithm Key Pair key type of post-quantum keys such as ML-DSA keys
+	// (https://datatracker.ietf.org/doc/draft-ietf-cose-dilithium/)
+	keyTypeAKP = "AKP"
+)
+
+// Curves (https://www.iana.org/assignments/jose/jose.xhtml#web-key-elliptic-curve)
+const (
+	curveP256      = "P-256"
+	curveP384      = "P-384"
+	curveP521      = "P-521"
+	curveSECP256K1 = "secp256k1"
+	curveEd25519   = "Ed25519"
+	curveX25519    = "X25519"
+)
+
+// JWK represents a JSON Web Key as per RFC7517 (https://tools.ietf.org/html/rfc7517), with the key type
+// specific members of RFC 7518 and RFC 8037. JWK is comparable: the members whose values are arrays or
+// objects are kept in Extended, behind a pointer.
 type JWK struct {
 	ALG string `json:"alg,omitempty"`
 	KTY string `json:"kty,omitempty"`
@@ -18,18 +43,183 @@ type JWK struct {
 	D   string `json:"d,omitempty"`
 	X   string `json:"x,omitempty"`
 	Y   string `json:"y,omitempty"`
+
+	// USE is the intended use of a public key: "sig" or "enc"
+	USE string `json:"use,omitempty"`
+	KID string `json:"kid,omitempty"`
+	// X5U is a URL of the X.509 certificate chain of the key
+	X5U string `json:"x5u,omitempty"`
+	// X5T is the SHA-1 thumbprint of the DER certificate of the key
+	X5T string `json:"x5t,omitempty"`
+	// X5TS256 is the SHA-256 thumbprint of the DER certificate of the key
+	X5TS256 string `json:"x5t#S256,omitempty"`
+
+	// RSA members (https://www.rfc-editor.org/rfc/rfc7518#section-6.3)
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// K is the key value of a symmetric key (https://www.rfc-editor.org/rfc/rfc7518#section-6.4)
+	K string `json:"k,omitempty"`
+
+	// Algorithm Key Pair members (https://datatracker.ietf.org/doc/draft-ietf-cose-dilithium/). Their
+	// encoding depends on alg, e.g. ML-DSA keys have the encoded public key as PUB and the seed as PRIV.
+	PUB  string `json:"pub,omitempty"`
+	PRIV string `json:"priv,omitempty"`
+
+	// Extended holds the key_ops and x5c members and the members that are not represented by a field. It
+	// is nil if the JWK has none of them. Two JWKs with Extended members are only equal with == if they
+	// share them, see [JWK.Equal] to compare keys.
+	Extended *ExtendedMembers `json:"-"`
 }
 
-// ComputeThumbprint computes the JWK thumbprint as per RFC7638 (https://tools.ietf.org/html/rfc7638)
-func (j JWK) ComputeThumbprint() (string, error) {
-	thumbprintPayload := map[string]interface{}{
-		"crv": j.CRV,
-		"kty": j.KTY,
-		"x":   j.X,
+// ExtendedMembers are the members of a JWK whose values are arrays or objects
+type ExtendedMembers struct {
+	// KeyOps are the operations the key is intended for, e.g. "sign" and "verify"
+	KeyOps []string `json:"key_ops,omitempty"`
+	// X5C is the X.509 certificate chain of the key, as standard base64 DER certificates. The first
+	// certificate holds the key.
+	X5C []string `json:"x5c,omitempty"`
+	// Extra holds the members that are not represented by a field, e.g. the "oth" RSA member or private
+	// application members, so that decoding and encoding a JWK doesn't lose them
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// extended returns the extended members of the JWK, which are empty if it has none
+func (j JWK) extended() ExtendedMembers {
+	if j.Extended == nil {
+		return ExtendedMembers{}
 	}
 
-	if j.Y != "" {
-		thumbprintPayload["y"] = j.Y
+	return *j.Extended
+}
+
+// fields is JWK without its methods, to use the default JSON encoding
+type fields JWK
+
+// MarshalJSON encodes the JWK, including its extended members
+func (j JWK) MarshalJSON() ([]byte, error) {
+	encoded, err := json.Marshal(fields(j))
+	if err != nil {
+		return nil, err
+	}
+
+	if j.Extended == nil {
+		return encoded, nil
+	}
+
+	extended, err := json.Marshal(j.Extended)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(encoded[:len(encoded)-1])
+	if len(extended) > 2 {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(extended[1 : len(extended)-1])
+	}
+
+	names := make([]string, 0, len(j.Extended.Extra))
+	for name := range j.Extended.Extra {
+		if knownMembers[name] {
+			return nil, fmt.Errorf("extra member %s is a known member", name)
+		}
+
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(j.Extended.Extra[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid extra member %s: %w", name, err)
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes the JWK, keeping key_ops, x5c and unknown members in Extended
+func (j *JWK) UnmarshalJSON(data []byte) error {
+	var decoded fields
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var extended ExtendedMembers
+	if err := json.Unmarshal(data, &extended); err != nil {
+		return err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	for name, value := range members {
+		if knownMembers[name] {
+			continue
+		}
+
+		if extended.Extra == nil {
+			extended.Extra = make(map[string]json.RawMessage)
+		}
+
+		extended.Extra[name] = value
+	}
+
+	if len(extended.KeyOps) > 0 || len(extended.X5C) > 0 || len(extended.Extra) > 0 {
+		decoded.Extended = &extended
+	}
+
+	*j = JWK(decoded)
+	return nil
+}
+
+// knownMembers are the members represented by a field of JWK
+var knownMembers = map[string]bool{
+	"alg": true, "kty": true, "crv": true, "d": true, "x": true, "y": true,
+	"use": true, "key_ops": true, "kid": true, "x5u": true, "x5c": true, "x5t": true, "x5t#S256": true,
+	"n": true, "e": true, "p": true, "q": true, "dp": true, "dq": true, "qi": true, "k": true,
+	"pub": true, "priv": true,
+}
+
+// ComputeThumbprint computes the JWK thumbprint as per RFC7638 (https://tools.ietf.org/html/rfc7638)
+func (j JWK) ComputeThumbprint() (string, error) {
+	var thumbprintPayload map[string]interface{}
+	switch j.KTY {
+	case keyTypeRSA:
+		thumbprintPayload = map[string]interface{}{"e": j.E, "kty": j.KTY, "n": j.N}
+	case keyTypeOct:
+		thumbprintPayload = map[string]interface{}{"k": j.K, "kty": j.KTY}
+	case keyTypeAKP:
+		thumbprintPayload = map[string]interface{}{"alg": j.ALG, "kty": j.KTY, "pub": j.PUB}
+	default:
+		thumbprintPayload = map[string]interface{}{"crv": j.CRV, "kty": j.KTY, "x": j.X}
+		if j.Y != "" {
+			thumbprintPayload["y"] = j.Y
+		}
 	}
 
 	bytes, err := json.Marshal(thumbprintPayload)
@@ -42,3 +232,85 @@ func (j JWK) ComputeThumbprint() (string, error) {
 
 	return thumbprint, nil
 }
+
+// thumbprintURIPrefix is the prefix of SHA-256 thumbprint URIs
+const thumbprintURIPrefix = "urn:ietf:params:oauth:jwk-thumbprint:sha-256:"
+
+// ComputeThumbprintURI computes the JWK thumbprint URI as per RFC 9278
+// (https://www.rfc-editor.org/rfc/rfc9278), e.g.
+// urn:ietf:params:oauth:jwk-thumbprint:sha-256:NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs
+func (j JWK) ComputeThumbprintURI() (string, error) {
+	thumbprint, err := j.ComputeThumbprint()
+	if err != nil {
+		return "", err
+	}
+
+	return thumbprintURIPrefix + thumbprint, nil
+}
+
+// Equal reports whether j and other hold the same key: their thumbprints and private key members are equal.
+// Metadata like alg, kid, use, key_ops and the x5* members is not compared.
+func (j JWK) Equal(other JWK) bool {
+	thumbprint, err := j.ComputeThumbprint()
+	if err != nil {
+		return false
+	}
+
+	otherThumbprint, err := other.ComputeThumbprint()
+	if err != nil {
+		return false
+	}
+
+	return thumbprint == otherThumbprint &&
+		j.D == other.D && j.P == other.P && j.Q == other.Q && j.DP == other.DP && j.DQ == other.DQ &&
+		j.QI == other.QI && j.K == other.K && j.PRIV == other.PRIV &&
+		bytes.Equal(j.extended().Extra["oth"], other.extended().Extra["oth"])
+}
+
+// IsPrivate returns true if the JWK holds private key members
+func (j JWK) IsPrivate() bool {
+	return j.D != "" || j.P != "" || j.Q != "" || j.DP != "" || j.DQ != "" || j.QI != "" || j.K != "" ||
+		j.PRIV != "" || j.extended().Extra["oth"] != nil
+}
+
+// Public returns the JWK without its private key members. Symmetric keys have no public part, so their
+// public JWK has no key value.
+func (j JWK) Public() JWK {
+	public := j
+	public.D, public.P, public.Q, public.DP, public.DQ, public.QI, public.K = "", "", "", "", "", "", ""
+	public.PRIV = ""
+
+	public.Extended = nil
+	if j.Extended == nil {
+		return public
+	}
+
+	extended := ExtendedMembers{X5C: j.Extended.X5C}
+	for name, value := range j.Extended.Extra {
+		if name == "oth" {
+			continue
+		}
+
+		if extended.Extra == nil {
+			extended.Extra = make(map[string]json.RawMessage)
+		}
+
+		extended.Extra[name] = value
+	}
+
+	// private key operations don't apply to the public key
+	for _, op := range j.Extended.KeyOps {
+		if publicKeyOps[op] {
+			extended.KeyOps = append(extended.KeyOps, op)
+		}
+	}
+
+	if len(extended.KeyOps) > 0 || len(extended.X5C) > 0 || len(extended.Extra) > 0 {
+		public.Extended = &extended
+	}
+
+	return public
+}
+
+// publicKeyOps are the key operations that can be performed with a public key
+var publicKeyOps = map[string]bool{"verify": true, "encrypt": true, "wrapKey": true}
@@ -1 +1,231 @@
 package jwk_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+// rsaKey is the RSA key of the RFC 7638 thumbprint example (https://www.rfc-editor.org/rfc/rfc7638#section-3.1)
+var rsaKey = jwk.JWK{
+	KTY: "RSA",
+	N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3o" +
+		"knjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHz" +
+		"u6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8" +
+		"awapJzKnqDKgw",
+	E:   "AQAB",
+	ALG: "RS256",
+	KID: "2011-04-29",
+}
+
+func TestComputeThumbprint_RFC7638(t *testing.T) {
+	thumbprint, err := rsaKey.ComputeThumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+
+	uri, err := rsaKey.ComputeThumbprintURI()
+	assert.NoError(t, err)
+	assert.Equal(t, "urn:ietf:params:oauth:jwk-thumbprint:sha-256:NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", uri)
+}
+
+func TestComputeThumbprint_Symmetric(t *testing.T) {
+	key := jwk.JWK{KTY: "oct", K: "GawgguFyGrWKav7AX4VKUg", KID: "ignored"}
+
+	thumbprint, err := key.ComputeThumbprint()
+	assert.NoError(t, err)
+
+	other, err := jwk.JWK{KTY: "oct", K: "GawgguFyGrWKav7AX4VKUg"}.ComputeThumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, other, thumbprint)
+}
+
+func TestComputeThumbprint_AKP(t *testing.T) {
+	key := jwk.JWK{KTY: "AKP", ALG: "ML-DSA-44", PUB: "AAAA", PRIV: "AQID"}
+
+	thumbprint, err := key.ComputeThumbprint()
+	assert.NoError(t, err)
+
+	// the thumbprint of AKP keys covers alg, kty and pub
+	digest := sha256.Sum256([]byte(`{"alg":"ML-DSA-44","kty":"AKP","pub":"AAAA"}`))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(digest[:]), thumbprint)
+
+	public, err := key.Public().ComputeThumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, thumbprint, public)
+
+	other, err := jwk.JWK{KTY: "AKP", ALG: "ML-DSA-65", PUB: "AAAA"}.ComputeThumbprint()
+	assert.NoError(t, err)
+	assert.NotEqual(t, thumbprint, other)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	// the member order of existing keys is unchanged, as did:jwk URIs depend on it
+	key := jwk.JWK{ALG: "EdDSA", KTY: "OKP", CRV: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	encoded, err := json.Marshal(key)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"alg":"EdDSA","kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`, string(encoded))
+
+	key.Extended = &jwk.ExtendedMembers{Extra: map[string]json.RawMessage{"kid": json.RawMessage(`"duplicate"`)}}
+	_, err = json.Marshal(key)
+	assert.Error(t, err)
+}
+
+func TestUnmarshalJSON_KeepsUnknownMembers(t *testing.T) {
+	input := `{"kty":"EC","crv":"P-256","x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",` +
+		`"y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0","use":"sig","key_ops":["verify"],"kid":"1",` +
+		`"x5t#S256":"abc","custom":{"nested":[1,2]},"ext":true}`
+
+	var key jwk.JWK
+	err := json.Unmarshal([]byte(input), &key)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "sig", key.USE)
+	assert.Equal(t, []string{"verify"}, key.Extended.KeyOps)
+	assert.Equal(t, "1", key.KID)
+	assert.Equal(t, "abc", key.X5TS256)
+	assert.Equal(t, 2, len(key.Extended.Extra))
+	assert.Equal(t, `{"nested":[1,2]}`, string(key.Extended.Extra["custom"]))
+
+	encoded, err := json.Marshal(key)
+	assert.NoError(t, err)
+
+	var roundTripped jwk.JWK
+	err = json.Unmarshal(encoded, &roundTripped)
+	assert.NoError(t, err)
+	assert.Equal(t, key, roundTripped)
+
+	var expected, actual map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(input), &expected))
+	assert.NoError(t, json.Unmarshal(encoded, &actual))
+	assert.Equal(t, expected, actual)
+}
+
+func TestPublic(t *testing.T) {
+	key := jwk.JWK{
+		KTY: "RSA",
+		N:   "n",
+		E:   "AQAB",
+		D:   "d",
+		P:   "p",
+		Q:   "q",
+		DP:  "dp",
+		DQ:  "dq",
+		QI:  "qi",
+		Extended: &jwk.ExtendedMembers{
+			KeyOps: []string{"sign", "verify"},
+			Extra:  map[string]json.RawMessage{"oth": json.RawMessage(`[]`), "custom": json.RawMessage(`1`)},
+		},
+	}
+	assert.True(t, key.IsPrivate())
+
+	public := key.Public()
+	assert.False(t, public.IsPrivate())
+	assert.Equal(t, jwk.JWK{
+		KTY: "RSA",
+		N:   "n",
+		E:   "AQAB",
+		Extended: &jwk.ExtendedMembers{
+			KeyOps: []string{"verify"},
+			Extra:  map[string]json.RawMessage{"custom": json.RawMessage(`1`)},
+		},
+	}, public)
+}
+
+func TestPublic_AKP(t *testing.T) {
+	key := jwk.JWK{ALG: "ML-DSA-44", KTY: "AKP", PUB: "AAAA", PRIV: "AQID"}
+	assert.True(t, key.IsPrivate())
+
+	encoded, err := json.Marshal(key)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"alg":"ML-DSA-44","kty":"AKP","pub":"AAAA","priv":"AQID"}`, string(encoded))
+
+	public := key.Public()
+	assert.False(t, public.IsPrivate())
+	assert.Equal(t, jwk.JWK{ALG: "ML-DSA-44", KTY: "AKP", PUB: "AAAA"}, public)
+}
+
+func TestEqual(t *testing.T) {
+	key := jwk.JWK{
+		KTY: "EC",
+		CRV: "secp256k1",
+		X:   "3Xq3MLB9lpC4N7b7mGc4uaVHz0-Y9oL8HhbZl8sH5OI",
+		Y:   "Lc0spJD7vkcjrppfN_wUZONMYYPo9T4kKfEuy9J8o9Y",
+		D:   "d",
+	}
+
+	// metadata is not part of the key
+	other := key
+	other.ALG = "ES256K"
+	other.KID = "0"
+	other.Extended = &jwk.ExtendedMembers{
+		KeyOps: []string{"sign"},
+		Extra:  map[string]json.RawMessage{"custom": json.RawMessage(`1`)},
+	}
+	assert.True(t, key.Equal(other))
+
+	assert.False(t, key.Equal(key.Public()))
+	assert.True(t, key.Public().Equal(other.Public()))
+
+	other.Y = "other"
+	assert.False(t, key.Equal(other))
+
+	// the alg of AKP keys is part of their thumbprint
+	akpKey := jwk.JWK{ALG: "ML-DSA-44", KTY: "AKP", PUB: "AAAA"}
+	assert.True(t, akpKey.Equal(jwk.JWK{ALG: "ML-DSA-44", KTY: "AKP", PUB: "AAAA", KID: "0"}))
+	assert.False(t, akpKey.Equal(jwk.JWK{ALG: "ML-DSA-65", KTY: "AKP", PUB: "AAAA"}))
+
+	rsaWithOth := rsaKey
+	rsaWithOth.Extended = &jwk.ExtendedMembers{Extra: map[string]json.RawMessage{"oth": json.RawMessage(`[]`)}}
+	assert.True(t, rsaKey.Equal(rsaKey))
+	assert.False(t, rsaKey.Equal(rsaWithOth))
+}
+
+func TestJWK_Comparable(t *testing.T) {
+	key := jwk.JWK{KTY: "OKP", CRV: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+
+	var decoded jwk.JWK
+	err := json.Unmarshal([]byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`), &decoded)
+	assert.NoError(t, err)
+	assert.True(t, key == decoded)
+
+	seen := map[jwk.JWK]bool{key: true}
+	assert.True(t, seen[decoded])
+
+	// extended members are compared by pointer, Equal compares the keys
+	err = json.Unmarshal([]byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","key_ops":["verify"]}`), &decoded)
+	assert.NoError(t, err)
+	assert.False(t, key == decoded)
+	assert.True(t, key.Equal(decoded))
+}
+
+func TestSet(t *testing.T) {
+	input := `{"keys":[` +
+		`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","kid":"a"},` +
+		`{"kty":"oct","k":"GawgguFyGrWKav7AX4VKUg","kid":"b"},` +
+		`{"kty":"unknown","kid":"c","custom":true}]}`
+
+	var set jwk.Set
+	err := json.Unmarshal([]byte(input), &set)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(set.Keys))
+
+	key, ok := set.LookupKeyID("c")
+	assert.True(t, ok)
+	assert.Equal(t, "unknown", key.KTY)
+	assert.Equal(t, "true", string(key.Extended.Extra["custom"]))
+
+	_, ok = set.LookupKeyID("d")
+	assert.False(t, ok)
+
+	public := set.Public()
+	assert.Equal(t, 2, len(public.Keys))
+	_, ok = public.LookupKeyID("b")
+	assert.False(t, ok)
+
+	err = json.Unmarshal([]byte(`{}`), &set)
+	assert.Error(t, err)
+}
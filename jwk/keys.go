@@ -0,0 +1,454 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// ecCurve is an elliptic curve of EC keys
+type ecCurve struct {
+	name  string
+	curve elliptic.Curve
+	// ecdh is nil for secp256k1, which the standard library doesn't support
+	ecdh ecdh.Curve
+	size int
+}
+
+var ecCurves = map[string]ecCurve{
+	curveP256:      {name: curveP256, curve: elliptic.P256(), ecdh: ecdh.P256(), size: 32},
+	curveP384:      {name: curveP384, curve: elliptic.P384(), ecdh: ecdh.P384(), size: 48},
+	curveP521:      {name: curveP521, curve: elliptic.P521(), ecdh: ecdh.P521(), size: 66},
+	curveSECP256K1: {name: curveSECP256K1, curve: secp256k1.S256(), size: 32},
+}
+
+// ecCurveOf returns the EC curve of a standard library curve
+func ecCurveOf(curve elliptic.Curve) (ecCurve, error) {
+	if curve == nil {
+		return ecCurve{}, errors.New("missing curve")
+	}
+
+	// curves of other secp256k1 implementations are accepted too
+	c, ok := ecCurves[curve.Params().Name]
+	if !ok {
+		return ecCurve{}, fmt.Errorf("unsupported curve: %s", curve.Params().Name)
+	}
+
+	return c, nil
+}
+
+// uncompressed returns the uncompressed SEC 1 encoding of a public key, checking the point is on the curve
+func (c ecCurve) uncompressed(x []byte, y []byte) ([]byte, error) {
+	if len(x) != c.size || len(y) != c.size {
+		return nil, fmt.Errorf("x and y must be %d bytes", c.size)
+	}
+
+	point := append([]byte{0x04}, x...)
+	point = append(point, y...)
+
+	if c.ecdh == nil {
+		if _, err := secp256k1.ParsePubKey(point); err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+	} else if _, err := c.ecdh.NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return point, nil
+}
+
+// decompress returns the uncompressed SEC 1 encoding of a compressed or uncompressed public key
+func (c ecCurve) decompress(point []byte) ([]byte, error) {
+	if len(point) == 1+c.size && (point[0] == 0x02 || point[0] == 0x03) {
+		if c.ecdh == nil {
+			key, err := secp256k1.ParsePubKey(point)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key: %w", err)
+			}
+
+			return key.SerializeUncompressed(), nil
+		}
+
+		x, y := elliptic.UnmarshalCompressed(c.curve, point)
+		if x == nil {
+			return nil, errors.New("invalid public key: invalid compressed point")
+		}
+
+		point = make([]byte, 1+2*c.size)
+		point[0] = 0x04
+		x.FillBytes(point[1 : 1+c.size])
+		y.FillBytes(point[1+c.size:])
+	}
+
+	if len(point) != 1+2*c.size || point[0] != 0x04 {
+		return nil, errors.New("invalid public key")
+	}
+
+	return c.uncompressed(point[1:1+c.size], point[1+c.size:])
+}
+
+// compress returns the compressed SEC 1 encoding of an uncompressed public key
+func (c ecCurve) compress(point []byte) []byte {
+	compressed := make([]byte, 1+c.size)
+	compressed[0] = 0x02 | point[len(point)-1]&1
+	copy(compressed[1:], point[1:1+c.size])
+	return compressed
+}
+
+// jwk returns the public key JWK of an uncompressed public key
+func (c ecCurve) jwk(point []byte) JWK {
+	return JWK{
+		KTY: keyTypeEC,
+		CRV: c.name,
+		X:   base64.RawURLEncoding.EncodeToString(point[1 : 1+c.size]),
+		Y:   base64.RawURLEncoding.EncodeToString(point[1+c.size:]),
+	}
+}
+
+// publicFromPrivate derives the uncompressed public key of a private key
+func (c ecCurve) publicFromPrivate(d []byte) ([]byte, error) {
+	if len(d) != c.size {
+		return nil, fmt.Errorf("d must be %d bytes", c.size)
+	}
+
+	if c.ecdh == nil {
+		var scalar secp256k1.ModNScalar
+		if overflow := scalar.SetByteSlice(d); overflow || scalar.IsZero() {
+			return nil, errors.New("invalid private key")
+		}
+
+		return secp256k1.NewPrivateKey(&scalar).PubKey().SerializeUncompressed(), nil
+	}
+
+	// ecdh validates that d is in range
+	key, err := c.ecdh.NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	return key.PublicKey().Bytes(), nil
+}
+
+// ToPublicKey converts the public key of the JWK to the standard library type of its key type:
+// [ed25519.PublicKey] for Ed25519, [*ecdsa.PublicKey] for EC keys, [*ecdh.PublicKey] for X25519 and
+// [*rsa.PublicKey] for RSA. secp256k1 keys use the curve of
+// [github.com/decred/dcrd/dcrec/secp256k1/v4.S256], as the standard library has none.
+func (j JWK) ToPublicKey() (crypto.PublicKey, error) {
+	switch j.KTY {
+	case keyTypeEC:
+		c, ok := ecCurves[j.CRV]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve: %s", j.CRV)
+		}
+
+		x, y, err := decodeMembers(j.X, "x", j.Y, "y")
+		if err != nil {
+			return nil, err
+		}
+
+		point, err := c.uncompressed(x, y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: c.curve,
+			X:     new(big.Int).SetBytes(point[1 : 1+c.size]),
+			Y:     new(big.Int).SetBytes(point[1+c.size:]),
+		}, nil
+	case keyTypeOKP:
+		x, err := decodeMember(j.X, "x")
+		if err != nil {
+			return nil, err
+		}
+
+		switch j.CRV {
+		case curveEd25519:
+			if len(x) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("x must be %d bytes", ed25519.PublicKeySize)
+			}
+
+			return ed25519.PublicKey(x), nil
+		case curveX25519:
+			return ecdh.X25519().NewPublicKey(x)
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", j.CRV)
+		}
+	case keyTypeRSA:
+		n, e, err := decodeMembers(j.N, "n", j.E, "e")
+		if err != nil {
+			return nil, err
+		}
+
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 2 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid public exponent")
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", j.KTY)
+	}
+}
+
+// ToPrivateKey converts the private key of the JWK to the standard library type of its key type:
+// [ed25519.PrivateKey] for Ed25519, [*ecdsa.PrivateKey] for EC keys, [*ecdh.PrivateKey] for X25519 and
+// [*rsa.PrivateKey] for RSA. See [JWK.ToPublicKey] for secp256k1 keys.
+func (j JWK) ToPrivateKey() (crypto.PrivateKey, error) {
+	d, err := decodeMember(j.D, "d")
+	if err != nil {
+		return nil, err
+	}
+
+	switch j.KTY {
+	case keyTypeEC:
+		publicKey, err := j.ToPublicKey()
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PrivateKey{PublicKey: *publicKey.(*ecdsa.PublicKey), D: new(big.Int).SetBytes(d)}
+		if err := checkECPrivateKey(key, d); err != nil {
+			return nil, err
+		}
+
+		return key, nil
+	case keyTypeOKP:
+		switch j.CRV {
+		case curveEd25519:
+			key, err := ed25519PrivateKey(d)
+			if err != nil {
+				return nil, err
+			}
+
+			if j.X != "" && j.X != base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey)) {
+				return nil, errors.New("d does not match x")
+			}
+
+			return key, nil
+		case curveX25519:
+			key, err := ecdh.X25519().NewPrivateKey(d)
+			if err != nil {
+				return nil, err
+			}
+
+			if j.X != "" && j.X != base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()) {
+				return nil, errors.New("d does not match x")
+			}
+
+			return key, nil
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", j.CRV)
+		}
+	case keyTypeRSA:
+		return j.toRSAPrivateKey(d)
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", j.KTY)
+	}
+}
+
+// toRSAPrivateKey converts an RSA private key JWK with its two primes
+func (j JWK) toRSAPrivateKey(d []byte) (*rsa.PrivateKey, error) {
+	if j.extended().Extra["oth"] != nil {
+		return nil, errors.New("RSA keys with more than two primes are not supported")
+	}
+
+	publicKey, err := j.ToPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	p, q, err := decodeMembers(j.P, "p", j.Q, "q")
+	if err != nil {
+		return nil, err
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: *publicKey.(*rsa.PublicKey),
+		D:         new(big.Int).SetBytes(d),
+		Primes:    []*big.Int{new(big.Int).SetBytes(p), new(big.Int).SetBytes(q)},
+	}
+
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	key.Precompute()
+	return key, nil
+}
+
+// checkECPrivateKey checks that d is of the size of the curve and matches the public key
+func checkECPrivateKey(key *ecdsa.PrivateKey, d []byte) error {
+	c, err := ecCurveOf(key.Curve)
+	if err != nil {
+		return err
+	}
+
+	point, err := c.publicFromPrivate(d)
+	if err != nil {
+		return err
+	}
+
+	if key.X.Cmp(new(big.Int).SetBytes(point[1:1+c.size])) != 0 ||
+		key.Y.Cmp(new(big.Int).SetBytes(point[1+c.size:])) != 0 {
+		return errors.New("d does not match x and y")
+	}
+
+	return nil
+}
+
+// ed25519PrivateKey returns the Ed25519 private key of d, which is the seed in RFC 8037, or the seed
+// followed by the public key in keys generated by web5-go
+func ed25519PrivateKey(d []byte) (ed25519.PrivateKey, error) {
+	switch len(d) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(d), nil
+	case ed25519.PrivateKeySize:
+		key := ed25519.NewKeyFromSeed(d[:ed25519.SeedSize])
+		if !key.Equal(ed25519.PrivateKey(d)) {
+			return nil, errors.New("d does not match its public key")
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("d must be %d or %d bytes", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// FromPublicKey converts a public key of a type returned by [JWK.ToPublicKey] to a JWK
+func FromPublicKey(publicKey crypto.PublicKey) (JWK, error) {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		if len(key) != ed25519.PublicKeySize {
+			return JWK{}, errors.New("invalid public key")
+		}
+
+		return JWK{KTY: keyTypeOKP, CRV: curveEd25519, X: base64.RawURLEncoding.EncodeToString(key)}, nil
+	case *ecdsa.PublicKey:
+		c, err := ecCurveOf(key.Curve)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		if key.X == nil || key.Y == nil || key.X.Sign() < 0 || key.Y.Sign() < 0 ||
+			key.X.BitLen() > 8*c.size || key.Y.BitLen() > 8*c.size {
+			return JWK{}, errors.New("invalid public key")
+		}
+
+		point, err := c.uncompressed(key.X.FillBytes(make([]byte, c.size)), key.Y.FillBytes(make([]byte, c.size)))
+		if err != nil {
+			return JWK{}, err
+		}
+
+		return c.jwk(point), nil
+	case *ecdh.PublicKey:
+		if key.Curve() != ecdh.X25519() {
+			return JWK{}, errors.New("unsupported ecdh curve, use an ecdsa key for NIST curves")
+		}
+
+		return JWK{KTY: keyTypeOKP, CRV: curveX25519, X: base64.RawURLEncoding.EncodeToString(key.Bytes())}, nil
+	case *rsa.PublicKey:
+		if key.N == nil || key.N.Sign() <= 0 || key.E < 2 {
+			return JWK{}, errors.New("invalid public key")
+		}
+
+		return JWK{
+			KTY: keyTypeRSA,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// FromPrivateKey converts a private key of a type returned by [JWK.ToPrivateKey] to a JWK. The d member of
+// Ed25519 keys is the seed followed by the public key, like keys generated by web5-go.
+func FromPrivateKey(privateKey crypto.PrivateKey) (JWK, error) {
+	var key JWK
+	var err error
+
+	switch privateKey := privateKey.(type) {
+	case ed25519.PrivateKey:
+		if len(privateKey) != ed25519.PrivateKeySize {
+			return JWK{}, errors.New("invalid private key")
+		}
+
+		key, err = FromPublicKey(privateKey.Public())
+		key.D = base64.RawURLEncoding.EncodeToString(privateKey)
+	case *ecdsa.PrivateKey:
+		c, curveErr := ecCurveOf(privateKey.Curve)
+		if curveErr != nil {
+			return JWK{}, curveErr
+		}
+
+		if privateKey.D == nil || privateKey.D.Sign() <= 0 || privateKey.D.BitLen() > 8*c.size {
+			return JWK{}, errors.New("invalid private key")
+		}
+
+		key, err = FromPublicKey(&privateKey.PublicKey)
+		key.D = base64.RawURLEncoding.EncodeToString(privateKey.D.FillBytes(make([]byte, c.size)))
+	case *ecdh.PrivateKey:
+		key, err = FromPublicKey(privateKey.PublicKey())
+		key.D = base64.RawURLEncoding.EncodeToString(privateKey.Bytes())
+	case *rsa.PrivateKey:
+		if len(privateKey.Primes) != 2 {
+			return JWK{}, errors.New("RSA keys with more than two primes are not supported")
+		}
+
+		privateKey.Precompute()
+		key, err = FromPublicKey(&privateKey.PublicKey)
+		key.D = base64.RawURLEncoding.EncodeToString(privateKey.D.Bytes())
+		key.P = base64.RawURLEncoding.EncodeToString(privateKey.Primes[0].Bytes())
+		key.Q = base64.RawURLEncoding.EncodeToString(privateKey.Primes[1].Bytes())
+		key.DP = base64.RawURLEncoding.EncodeToString(privateKey.Precomputed.Dp.Bytes())
+		key.DQ = base64.RawURLEncoding.EncodeToString(privateKey.Precomputed.Dq.Bytes())
+		key.QI = base64.RawURLEncoding.EncodeToString(privateKey.Precomputed.Qinv.Bytes())
+	default:
+		return JWK{}, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+
+	if err != nil {
+		return JWK{}, err
+	}
+
+	return key, nil
+}
+
+// decodeMember decodes a required base64url member
+func decodeMember(value string, name string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%s must be set", name)
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+
+	return decoded, nil
+}
+
+// decodeMembers decodes two required base64url members
+func decodeMembers(value1 string, name1 string, value2 string, name2 string) ([]byte, []byte, error) {
+	decoded1, err := decodeMember(value1, name1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decoded2, err := decodeMember(value2, name2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return decoded1, decoded2, nil
+}
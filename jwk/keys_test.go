@@ -0,0 +1,105 @@
+package jwk_test
+
+import (
+	"crypto"
+	_ecdh "crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/jwk"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestFromPrivateKey_RoundTrip(t *testing.T) {
+	generators := map[string]func() (crypto.PrivateKey, error){
+		"Ed25519": func() (crypto.PrivateKey, error) {
+			_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+			return privateKey, err
+		},
+		"secp256k1": func() (crypto.PrivateKey, error) {
+			privateKey, err := secp256k1.GeneratePrivateKey()
+			if err != nil {
+				return nil, err
+			}
+			return privateKey.ToECDSA(), nil
+		},
+		"P-256": func() (crypto.PrivateKey, error) { return ecdsa.GenerateKey(elliptic.P256(), rand.Reader) },
+		"P-384": func() (crypto.PrivateKey, error) { return ecdsa.GenerateKey(elliptic.P384(), rand.Reader) },
+		"P-521": func() (crypto.PrivateKey, error) { return ecdsa.GenerateKey(elliptic.P521(), rand.Reader) },
+		"X25519": func() (crypto.PrivateKey, error) {
+			return _ecdh.X25519().GenerateKey(rand.Reader)
+		},
+		"RSA": func() (crypto.PrivateKey, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+	}
+
+	for name, generate := range generators {
+		t.Run(name, func(t *testing.T) {
+			privateKey, err := generate()
+			assert.NoError(t, err)
+
+			privateJWK, err := jwk.FromPrivateKey(privateKey)
+			assert.NoError(t, err)
+			assert.True(t, privateJWK.IsPrivate())
+
+			converted, err := privateJWK.ToPrivateKey()
+			assert.NoError(t, err)
+
+			roundTripped, err := jwk.FromPrivateKey(converted)
+			assert.NoError(t, err)
+			assert.Equal(t, privateJWK, roundTripped)
+
+			publicJWK := privateJWK.Public()
+			publicKey, err := publicJWK.ToPublicKey()
+			assert.NoError(t, err)
+
+			roundTripped, err = jwk.FromPublicKey(publicKey)
+			assert.NoError(t, err)
+			assert.Equal(t, publicJWK, roundTripped)
+
+			// the private key and public key of the standard library types match
+			signer, ok := converted.(interface{ Public() crypto.PublicKey })
+			assert.True(t, ok)
+			assert.True(t, publicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(signer.Public()))
+		})
+	}
+}
+
+func TestToPrivateKey_Ed25519Seed(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// RFC 8037 keys only hold the seed
+	privateJWK, err := jwk.FromPrivateKey(privateKey)
+	assert.NoError(t, err)
+	privateJWK.D = base64.RawURLEncoding.EncodeToString(privateKey.Seed())
+
+	converted, err := privateJWK.ToPrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PrivateKey(privateKey), converted)
+}
+
+func TestFromPublicKey_Unsupported(t *testing.T) {
+	p224Key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	assert.NoError(t, err)
+
+	_, err = jwk.FromPrivateKey(p224Key)
+	assert.Error(t, err)
+
+	_, err = jwk.FromPublicKey(&p224Key.PublicKey)
+	assert.Error(t, err)
+
+	p256Key, err := _ecdh.P256().GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	_, err = jwk.FromPublicKey(p256Key.PublicKey())
+	assert.Error(t, err)
+
+	_, err = jwk.JWK{KTY: "EC", CRV: "P-224"}.ToPublicKey()
+	assert.Error(t, err)
+}
@@ -0,0 +1,298 @@
+package jwk
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Multicodec codes of keys (https://github.com/multiformats/multicodec/blob/master/table.csv)
+const (
+	codecEd25519Public    = 0xed
+	codecSECP256K1Public  = 0xe7
+	codecX25519Public     = 0xec
+	codecP256Public       = 0x1200
+	codecP384Public       = 0x1201
+	codecP521Public       = 0x1202
+	codecRSAPublic        = 0x1205
+	codecEd25519Private   = 0x1300
+	codecSECP256K1Private = 0x1301
+	codecX25519Private    = 0x1302
+	codecRSAPrivate       = 0x1305
+	codecP256Private      = 0x1306
+	codecP384Private      = 0x1307
+	codecP521Private      = 0x1308
+)
+
+// ecCodecs are the public and private key multicodec codes of EC curves
+var ecCodecs = map[string][2]uint64{
+	curveP256:      {codecP256Public, codecP256Private},
+	curveP384:      {codecP384Public, codecP384Private},
+	curveP521:      {codecP521Public, codecP521Private},
+	curveSECP256K1: {codecSECP256K1Public, codecSECP256K1Private},
+}
+
+// Multibase prefixes (https://github.com/multiformats/multibase)
+const (
+	multibaseBase58BTC = 'z'
+	multibaseBase64URL = 'u'
+	multibaseBase16    = 'f'
+)
+
+// ToMultibase encodes the key material of the JWK as a multicodec key, in base58btc multibase, like the
+// public keys of did:key and the publicKeyMultibase member of Multikey verification methods
+// (https://www.w3.org/TR/controller-document/#multikey). EC public keys are compressed, Ed25519
+// private keys are the 32 byte seed and RSA keys are PKCS #1 DER.
+func (j JWK) ToMultibase() (string, error) {
+	codec, key, err := j.multicodec()
+	if err != nil {
+		return "", err
+	}
+
+	encoded := binary.AppendUvarint(nil, codec)
+	encoded = append(encoded, key...)
+
+	return string(multibaseBase58BTC) + base58Encode(encoded), nil
+}
+
+// multicodec returns the multicodec code and the encoded key of the JWK
+func (j JWK) multicodec() (uint64, []byte, error) {
+	switch j.KTY {
+	case keyTypeEC:
+		codecs, ok := ecCodecs[j.CRV]
+		if !ok {
+			return 0, nil, fmt.Errorf("unsupported curve: %s", j.CRV)
+		}
+
+		c := ecCurves[j.CRV]
+
+		if j.D != "" {
+			if _, err := j.ToPrivateKey(); err != nil {
+				return 0, nil, err
+			}
+
+			d, err := decodeMember(j.D, "d")
+			return codecs[1], d, err
+		}
+
+		x, y, err := decodeMembers(j.X, "x", j.Y, "y")
+		if err != nil {
+			return 0, nil, err
+		}
+
+		point, err := c.uncompressed(x, y)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return codecs[0], c.compress(point), nil
+	case keyTypeOKP:
+		if j.D != "" {
+			privateKey, err := j.ToPrivateKey()
+			if err != nil {
+				return 0, nil, err
+			}
+
+			d, err := decodeMember(j.D, "d")
+			if j.CRV == curveEd25519 {
+				return codecEd25519Private, privateKey.(ed25519.PrivateKey).Seed(), err
+			}
+
+			return codecX25519Private, d, err
+		}
+
+		if _, err := j.ToPublicKey(); err != nil {
+			return 0, nil, err
+		}
+
+		x, err := decodeMember(j.X, "x")
+		if j.CRV == curveEd25519 {
+			return codecEd25519Public, x, err
+		}
+
+		return codecX25519Public, x, err
+	case keyTypeRSA:
+		if j.D != "" {
+			privateKey, err := j.ToPrivateKey()
+			if err != nil {
+				return 0, nil, err
+			}
+
+			return codecRSAPrivate, x509.MarshalPKCS1PrivateKey(privateKey.(*rsa.PrivateKey)), nil
+		}
+
+		publicKey, err := j.ToPublicKey()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return codecRSAPublic, x509.MarshalPKCS1PublicKey(publicKey.(*rsa.PublicKey)), nil
+	default:
+		return 0, nil, fmt.Errorf("unsupported key type: %s", j.KTY)
+	}
+}
+
+// FromMultibase decodes a multibase multicodec key, see [JWK.ToMultibase]. base58btc, base64url and base16
+// multibase encodings are supported.
+func FromMultibase(encoded string) (JWK, error) {
+	if encoded == "" {
+		return JWK{}, errors.New("empty multibase value")
+	}
+
+	var decoded []byte
+	var err error
+
+	switch encoded[0] {
+	case multibaseBase58BTC:
+		decoded, err = base58Decode(encoded[1:])
+	case multibaseBase64URL:
+		decoded, err = base64.RawURLEncoding.DecodeString(encoded[1:])
+	case multibaseBase16:
+		decoded, err = hex.DecodeString(encoded[1:])
+	default:
+		return JWK{}, fmt.Errorf("unsupported multibase encoding: %q", encoded[0])
+	}
+
+	if err != nil {
+		return JWK{}, fmt.Errorf("failed to decode multibase value: %w", err)
+	}
+
+	codec, n := binary.Uvarint(decoded)
+	if n <= 0 {
+		return JWK{}, errors.New("invalid multicodec code")
+	}
+
+	return fromMulticodec(codec, decoded[n:])
+}
+
+// fromMulticodec converts a key encoded as per its multicodec code to a JWK
+func fromMulticodec(codec uint64, key []byte) (JWK, error) {
+	for crv, codecs := range ecCodecs {
+		c := ecCurves[crv]
+
+		switch codec {
+		case codecs[0]:
+			point, err := c.decompress(key)
+			if err != nil {
+				return JWK{}, err
+			}
+
+			return c.jwk(point), nil
+		case codecs[1]:
+			point, err := c.publicFromPrivate(key)
+			if err != nil {
+				return JWK{}, err
+			}
+
+			privateKey := c.jwk(point)
+			privateKey.D = base64.RawURLEncoding.EncodeToString(key)
+			return privateKey, nil
+		}
+	}
+
+	switch codec {
+	case codecEd25519Public, codecX25519Public:
+		crv := curveEd25519
+		if codec == codecX25519Public {
+			crv = curveX25519
+		}
+
+		publicKey := JWK{KTY: keyTypeOKP, CRV: crv, X: base64.RawURLEncoding.EncodeToString(key)}
+		if _, err := publicKey.ToPublicKey(); err != nil {
+			return JWK{}, err
+		}
+
+		return publicKey, nil
+	case codecEd25519Private:
+		if len(key) != ed25519.SeedSize {
+			return JWK{}, fmt.Errorf("private key must be %d bytes", ed25519.SeedSize)
+		}
+
+		return FromPrivateKey(ed25519.NewKeyFromSeed(key))
+	case codecX25519Private:
+		privateKey, err := ecdh.X25519().NewPrivateKey(key)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		return FromPrivateKey(privateKey)
+	case codecRSAPublic:
+		publicKey, err := x509.ParsePKCS1PublicKey(key)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		return FromPublicKey(publicKey)
+	case codecRSAPrivate:
+		privateKey, err := x509.ParsePKCS1PrivateKey(key)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		return FromPrivateKey(privateKey)
+	default:
+		return JWK{}, fmt.Errorf("unsupported multicodec code: 0x%x", codec)
+	}
+}
+
+// base58Alphabet is the bitcoin base58 alphabet
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode encodes data in base58btc
+func base58Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var encoded []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+
+	// leading zero bytes are encoded as leading 1s
+	for i := 0; i < zeros; i++ {
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
+
+// base58Decode decodes base58btc data
+func base58Decode(encoded string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(encoded) && encoded[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(encoded); i++ {
+		digit := strings.IndexByte(base58Alphabet, encoded[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", encoded[i])
+		}
+
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
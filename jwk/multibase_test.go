@@ -0,0 +1,73 @@
+package jwk_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/jwk"
+)
+
+func TestMultibase(t *testing.T) {
+	// well known prefixes of did:key public keys (https://w3c-ccg.github.io/did-method-key/)
+	prefixes := map[string]string{
+		"Ed25519":   "z6Mk",
+		"X25519":    "z6LS",
+		"secp256k1": "zQ3s",
+		"P-256":     "zDn",
+		"P-384":     "z82",
+	}
+
+	for name, key := range privateKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := key.ToMultibase()
+			assert.NoError(t, err)
+
+			decoded, err := jwk.FromMultibase(encoded)
+			assert.NoError(t, err)
+			assert.Equal(t, key, decoded)
+
+			encoded, err = key.Public().ToMultibase()
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(encoded, prefixes[name]))
+
+			decoded, err = jwk.FromMultibase(encoded)
+			assert.NoError(t, err)
+			assert.Equal(t, key.Public(), decoded)
+		})
+	}
+}
+
+func TestFromMultibase_DIDKey(t *testing.T) {
+	// did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp of the did:key spec
+	key, err := jwk.FromMultibase("z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp")
+	assert.NoError(t, err)
+	assert.Equal(t, jwk.JWK{KTY: "OKP", CRV: "Ed25519", X: "O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik"}, key)
+
+	encoded, err := key.ToMultibase()
+	assert.NoError(t, err)
+	assert.Equal(t, "z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp", encoded)
+
+	// the same key in base16
+	fromBase16, err := jwk.FromMultibase("fed013b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29")
+	assert.NoError(t, err)
+	assert.Equal(t, key, fromBase16)
+}
+
+func TestFromMultibase_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":               "",
+		"unsupported base":    "m7QE",
+		"invalid base58":      "z0OIl",
+		"unsupported codec":   "f5501",
+		"short Ed25519 key":   "fed0100",
+		"invalid P-256 point": "f802402" + strings.Repeat("ff", 32),
+	}
+
+	for name, encoded := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := jwk.FromMultibase(encoded)
+			assert.Error(t, err)
+		})
+	}
+}
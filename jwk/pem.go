@@ -0,0 +1,240 @@
+package jwk
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// PEM block types
+const (
+	pemTypePublicKey  = "PUBLIC KEY"
+	pemTypePrivateKey = "PRIVATE KEY"
+)
+
+// ASN.1 object identifiers of EC keys on secp256k1, which crypto/x509 doesn't support
+// (https://www.rfc-editor.org/rfc/rfc5480#section-2.1.1 and https://www.secg.org/sec2-v2.pdf)
+var (
+	oidPublicKeyECDSA  = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveS256K = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// subjectPublicKeyInfo is the PKIX encoding of a public key (https://www.rfc-editor.org/rfc/rfc5280#section-4.1)
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// pkcs8 is the PKCS #8 encoding of a private key (https://www.rfc-editor.org/rfc/rfc5208#section-5)
+type pkcs8 struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// ecPrivateKey is the SEC 1 encoding of an EC private key (https://www.rfc-editor.org/rfc/rfc5915#section-3)
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// ToDER encodes the JWK as a PKIX public key if it is a public key, or as a PKCS #8 private key if it is a
+// private key. Only the key material is encoded: members like kid and alg are not.
+func (j JWK) ToDER() ([]byte, error) {
+	if j.KTY == keyTypeEC && j.CRV == curveSECP256K1 {
+		return j.secp256k1ToDER()
+	}
+
+	if j.D == "" {
+		publicKey, err := j.ToPublicKey()
+		if err != nil {
+			return nil, err
+		}
+
+		return x509.MarshalPKIXPublicKey(publicKey)
+	}
+
+	privateKey, err := j.ToPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.MarshalPKCS8PrivateKey(privateKey)
+}
+
+// secp256k1ToDER encodes a secp256k1 JWK, see [JWK.ToDER]
+func (j JWK) secp256k1ToDER() ([]byte, error) {
+	c := ecCurves[curveSECP256K1]
+
+	x, y, err := decodeMembers(j.X, "x", j.Y, "y")
+	if err != nil {
+		return nil, err
+	}
+
+	point, err := c.uncompressed(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	curveOID, err := asn1.Marshal(oidNamedCurveS256K)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: curveOID}}
+
+	if j.D == "" {
+		return asn1.Marshal(subjectPublicKeyInfo{
+			Algorithm: algorithm,
+			PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+		})
+	}
+
+	if _, err := j.ToPrivateKey(); err != nil {
+		return nil, err
+	}
+
+	d, err := decodeMember(j.D, "d")
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := asn1.Marshal(ecPrivateKey{
+		Version:    1,
+		PrivateKey: d,
+		PublicKey:  asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(pkcs8{Algorithm: algorithm, PrivateKey: privateKey})
+}
+
+// FromDER decodes a PKIX public key, or a PKCS #8, SEC 1 or PKCS #1 private key
+func FromDER(der []byte) (JWK, error) {
+	if key, ok := secp256k1FromDER(der); ok {
+		return key, nil
+	}
+
+	if publicKey, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return FromPublicKey(publicKey)
+	}
+
+	if privateKey, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return FromPrivateKey(privateKey)
+	}
+
+	if privateKey, err := x509.ParseECPrivateKey(der); err == nil {
+		return FromPrivateKey(privateKey)
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return FromPrivateKey(privateKey)
+	}
+
+	if publicKey, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return FromPublicKey(publicKey)
+	}
+
+	return JWK{}, errors.New("unsupported or invalid DER encoded key")
+}
+
+// secp256k1FromDER decodes a secp256k1 key, see [FromDER]. ok is false if der is not a secp256k1 key.
+func secp256k1FromDER(der []byte) (key JWK, ok bool) {
+	c := ecCurves[curveSECP256K1]
+
+	var spki subjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &spki); err == nil && len(rest) == 0 && isSECP256K1(spki.Algorithm) {
+		point, err := c.decompress(spki.PublicKey.RightAlign())
+		if err != nil {
+			return JWK{}, false
+		}
+
+		return c.jwk(point), true
+	}
+
+	var private pkcs8
+	if rest, err := asn1.Unmarshal(der, &private); err == nil && len(rest) == 0 && isSECP256K1(private.Algorithm) {
+		der = private.PrivateKey
+	}
+
+	var sec1 ecPrivateKey
+	rest, err := asn1.Unmarshal(der, &sec1)
+	if err != nil || len(rest) != 0 || sec1.Version != 1 || len(sec1.PrivateKey) > c.size {
+		return JWK{}, false
+	}
+
+	// the curve is either in the PKCS #8 algorithm or in the SEC 1 private key
+	if !sec1.NamedCurveOID.Equal(oidNamedCurveS256K) && !isSECP256K1(private.Algorithm) {
+		return JWK{}, false
+	}
+
+	d := make([]byte, c.size)
+	copy(d[c.size-len(sec1.PrivateKey):], sec1.PrivateKey)
+
+	point, err := c.publicFromPrivate(d)
+	if err != nil {
+		return JWK{}, false
+	}
+
+	key = c.jwk(point)
+	key.D = base64.RawURLEncoding.EncodeToString(d)
+	return key, true
+}
+
+// isSECP256K1 returns true if the algorithm is an EC key on secp256k1
+func isSECP256K1(algorithm pkix.AlgorithmIdentifier) bool {
+	var curveOID asn1.ObjectIdentifier
+	if !algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return false
+	}
+
+	if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &curveOID); err != nil {
+		return false
+	}
+
+	return curveOID.Equal(oidNamedCurveS256K)
+}
+
+// ToPEM encodes the JWK as a "PUBLIC KEY" or "PRIVATE KEY" PEM block, see [JWK.ToDER]
+func (j JWK) ToPEM() ([]byte, error) {
+	der, err := j.ToDER()
+	if err != nil {
+		return nil, err
+	}
+
+	blockType := pemTypePublicKey
+	if j.D != "" {
+		blockType = pemTypePrivateKey
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), nil
+}
+
+// FromPEM decodes the first PEM block of data, which can be a "PUBLIC KEY", "PRIVATE KEY",
+// "EC PRIVATE KEY", "RSA PRIVATE KEY" or "RSA PUBLIC KEY" block
+func FromPEM(data []byte) (JWK, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return JWK{}, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case pemTypePublicKey, pemTypePrivateKey, "EC PRIVATE KEY", "RSA PRIVATE KEY", "RSA PUBLIC KEY":
+	default:
+		return JWK{}, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+
+	if len(block.Headers) != 0 {
+		return JWK{}, errors.New("encrypted PEM blocks are not supported")
+	}
+
+	return FromDER(block.Bytes)
+}
@@ -0,0 +1,53 @@
+package jwk
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Set is a JWK Set as per RFC 7517 section 5 (https://www.rfc-editor.org/rfc/rfc7517#section-5)
+type Set struct {
+	Keys []JWK `json:"keys"`
+}
+
+// UnmarshalJSON decodes the JWK Set. Keys of unsupported key types are kept rather than rejected, as
+// required by RFC 7517 section 5.
+func (s *Set) UnmarshalJSON(data []byte) error {
+	var decoded struct {
+		Keys *[]JWK `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if decoded.Keys == nil {
+		return errors.New("missing keys member")
+	}
+
+	s.Keys = *decoded.Keys
+	return nil
+}
+
+// LookupKeyID returns the key with the given key id
+func (s Set) LookupKeyID(kid string) (JWK, bool) {
+	for _, key := range s.Keys {
+		if key.KID == kid {
+			return key, true
+		}
+	}
+
+	return JWK{}, false
+}
+
+// Public returns the set with the public keys of its keys, leaving out symmetric keys
+func (s Set) Public() Set {
+	public := Set{Keys: make([]JWK, 0, len(s.Keys))}
+	for _, key := range s.Keys {
+		if key.KTY != keyTypeOct {
+			public.Keys = append(public.Keys, key.Public())
+		}
+	}
+
+	return public
+}
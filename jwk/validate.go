@@ -0,0 +1,257 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // x5t is a SHA-1 thumbprint
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// keyTypeMembers are the key material members of each key type
+var keyTypeMembers = map[string][]string{
+	keyTypeEC:  {"crv", "x", "y", "d"},
+	keyTypeOKP: {"crv", "x", "d"},
+	keyTypeRSA: {"n", "e", "d", "p", "q", "dp", "dq", "qi"},
+	keyTypeOct: {"k"},
+	keyTypeAKP: {"pub", "priv"},
+}
+
+// akpKeySizes are the sizes of the pub and priv members of AKP keys, by alg
+var akpKeySizes = map[string][2]int{
+	"ML-DSA-44": {1312, 32},
+	"ML-DSA-65": {1952, 32},
+	"ML-DSA-87": {2592, 32},
+}
+
+// useKeyOps are the key operations that are consistent with each public key use
+var useKeyOps = map[string]map[string]bool{
+	"sig": {"sign": true, "verify": true},
+	"enc": {"encrypt": true, "decrypt": true, "wrapKey": true, "unwrapKey": true, "deriveKey": true, "deriveBits": true},
+}
+
+// Validate checks that the JWK is well formed:
+//   - the members required by its key type are set, and no members of other key types are
+//   - EC public keys are points on their curve, and Ed25519 and X25519 public keys are 32 bytes
+//   - private keys match their public key. RSA private keys must include their primes.
+//   - use and key_ops are consistent, and key_ops has no duplicates
+//   - the first x5c certificate holds the key, and x5t and x5t#S256 are its thumbprints
+//
+// Validation is limited to EC keys on P-256, P-384, P-521 and secp256k1, Ed25519 and X25519 OKP keys, RSA
+// keys, symmetric keys and ML-DSA AKP keys. The priv member of AKP keys is only checked for its size.
+func (j JWK) Validate() error {
+	members, ok := keyTypeMembers[j.KTY]
+	if !ok {
+		return fmt.Errorf("unsupported key type: %q", j.KTY)
+	}
+
+	for name, value := range j.keyMembers() {
+		if value != "" && !contains(members, name) {
+			return fmt.Errorf("%s keys must not have member %s", j.KTY, name)
+		}
+	}
+
+	if err := j.validateKey(); err != nil {
+		return err
+	}
+
+	if err := j.validateKeyOps(); err != nil {
+		return err
+	}
+
+	return j.validateCertificates()
+}
+
+// ValidatePublic is like [JWK.Validate], and also checks that the JWK holds no private key members
+func (j JWK) ValidatePublic() error {
+	if j.IsPrivate() {
+		return errors.New("public key must not have private key members")
+	}
+
+	return j.Validate()
+}
+
+// validateKey checks the key material
+func (j JWK) validateKey() error {
+	switch j.KTY {
+	case keyTypeOct:
+		if _, err := decodeMember(j.K, "k"); err != nil {
+			return err
+		}
+
+		return nil
+	case keyTypeAKP:
+		return j.validateAKPKey()
+	case keyTypeEC, keyTypeOKP:
+		if j.CRV == "" {
+			return errors.New("crv must be set")
+		}
+	}
+
+	if _, err := j.Public().ToPublicKey(); err != nil {
+		return err
+	}
+
+	if j.D == "" {
+		if j.P != "" || j.Q != "" || j.DP != "" || j.DQ != "" || j.QI != "" {
+			return errors.New("d must be set")
+		}
+
+		return nil
+	}
+
+	// ToPrivateKey checks that the private key matches the public key
+	privateKey, err := j.ToPrivateKey()
+	if err != nil {
+		return err
+	}
+
+	if rsaKey, ok := privateKey.(*rsa.PrivateKey); ok {
+		precomputed := map[string]*big.Int{
+			"dp": rsaKey.Precomputed.Dp,
+			"dq": rsaKey.Precomputed.Dq,
+			"qi": rsaKey.Precomputed.Qinv,
+		}
+
+		for name, value := range map[string]string{"dp": j.DP, "dq": j.DQ, "qi": j.QI} {
+			if value == "" {
+				continue
+			}
+
+			decoded, err := decodeMember(value, name)
+			if err != nil {
+				return err
+			}
+
+			if new(big.Int).SetBytes(decoded).Cmp(precomputed[name]) != 0 {
+				return fmt.Errorf("%s does not match the private key", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAKPKey checks the sizes of the key material of AKP keys, which depend on their alg
+func (j JWK) validateAKPKey() error {
+	sizes, ok := akpKeySizes[j.ALG]
+	if !ok {
+		return fmt.Errorf("unsupported alg for AKP keys: %q", j.ALG)
+	}
+
+	pub, err := decodeMember(j.PUB, "pub")
+	if err != nil {
+		return err
+	}
+
+	if len(pub) != sizes[0] {
+		return fmt.Errorf("pub must be %d bytes for %s", sizes[0], j.ALG)
+	}
+
+	if j.PRIV == "" {
+		return nil
+	}
+
+	priv, err := decodeMember(j.PRIV, "priv")
+	if err != nil {
+		return err
+	}
+
+	if len(priv) != sizes[1] {
+		return fmt.Errorf("priv must be %d bytes for %s", sizes[1], j.ALG)
+	}
+
+	return nil
+}
+
+// validateKeyOps checks that use and key_ops are consistent
+func (j JWK) validateKeyOps() error {
+	keyOps := j.extended().KeyOps
+	seen := make(map[string]bool, len(keyOps))
+	for _, op := range keyOps {
+		if seen[op] {
+			return fmt.Errorf("duplicate key operation %s", op)
+		}
+
+		seen[op] = true
+
+		if ops, ok := useKeyOps[j.USE]; ok && !ops[op] {
+			return fmt.Errorf("key operation %s is inconsistent with use %s", op, j.USE)
+		}
+	}
+
+	return nil
+}
+
+// validateCertificates checks that the first certificate of x5c holds the key, and matches x5t and x5t#S256
+func (j JWK) validateCertificates() error {
+	x5c := j.extended().X5C
+	if len(x5c) == 0 {
+		return nil
+	}
+
+	certificates := make([][]byte, len(x5c))
+	for i, encoded := range x5c {
+		// x5c uses standard base64, not base64url
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode x5c certificate %d: %w", i, err)
+		}
+
+		certificates[i] = der
+	}
+
+	certificate, err := x509.ParseCertificate(certificates[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse x5c certificate: %w", err)
+	}
+
+	publicKey, err := j.Public().ToPublicKey()
+	if err != nil {
+		return err
+	}
+
+	if !publicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(certificate.PublicKey) {
+		return errors.New("x5c certificate does not hold the key")
+	}
+
+	if j.X5T != "" {
+		thumbprint := sha1.Sum(certificates[0]) //nolint:gosec // x5t is a SHA-1 thumbprint
+		if j.X5T != base64.RawURLEncoding.EncodeToString(thumbprint[:]) {
+			return errors.New("x5t does not match the x5c certificate")
+		}
+	}
+
+	if j.X5TS256 != "" {
+		thumbprint := sha256.Sum256(certificates[0])
+		if j.X5TS256 != base64.RawURLEncoding.EncodeToString(thumbprint[:]) {
+			return errors.New("x5t#S256 does not match the x5c certificate")
+		}
+	}
+
+	return nil
+}
+
+// keyMembers returns the key material members of the JWK
+func (j JWK) keyMembers() map[string]string {
+	return map[string]string{
+		"crv": j.CRV, "x": j.X, "y": j.Y, "d": j.D,
+		"n": j.N, "e": j.E, "p": j.P, "q": j.Q, "dp": j.DP, "dq": j.DQ, "qi": j.QI,
+		"k":   j.K,
+		"pub": j.PUB, "priv": j.PRIV,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
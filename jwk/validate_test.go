@@ -0,0 +1,178 @@
+package jwk_test
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/jwk"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// privateKeys returns a private key JWK of each supported key type and curve
+func privateKeys(t *testing.T) map[string]jwk.JWK {
+	t.Helper()
+
+	keys := map[string]jwk.JWK{}
+	add := func(name string, privateKey any, err error) {
+		assert.NoError(t, err)
+		key, err := jwk.FromPrivateKey(privateKey)
+		assert.NoError(t, err)
+		keys[name] = key
+	}
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	add("Ed25519", ed25519Key, err)
+
+	x25519Key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	add("X25519", x25519Key, err)
+
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		add(curve.Params().Name, ecKey, err)
+	}
+
+	secp256k1Key, err := secp256k1.GeneratePrivateKey()
+	add("secp256k1", secp256k1Key.ToECDSA(), err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	add("RSA", rsaKey, err)
+
+	return keys
+}
+
+func TestValidate(t *testing.T) {
+	for name, key := range privateKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, key.Validate())
+			assert.Error(t, key.ValidatePublic())
+			assert.NoError(t, key.Public().ValidatePublic())
+		})
+	}
+
+	assert.NoError(t, rsaKey.ValidatePublic())
+	assert.NoError(t, jwk.JWK{KTY: "oct", K: "GawgguFyGrWKav7AX4VKUg"}.Validate())
+}
+
+func TestValidate_AKP(t *testing.T) {
+	pub := base64.RawURLEncoding.EncodeToString(make([]byte, 1312))
+	priv := base64.RawURLEncoding.EncodeToString(make([]byte, 32))
+
+	key := jwk.JWK{ALG: "ML-DSA-44", KTY: "AKP", PUB: pub, PRIV: priv}
+	assert.NoError(t, key.Validate())
+	assert.Error(t, key.ValidatePublic())
+	assert.NoError(t, key.Public().ValidatePublic())
+
+	tests := map[string]jwk.JWK{
+		"missing alg":      {KTY: "AKP", PUB: pub},
+		"unsupported alg":  {ALG: "ML-DSA-1", KTY: "AKP", PUB: pub},
+		"missing pub":      {ALG: "ML-DSA-44", KTY: "AKP", PRIV: priv},
+		"pub of other alg": {ALG: "ML-DSA-65", KTY: "AKP", PUB: pub},
+		"short priv":       {ALG: "ML-DSA-44", KTY: "AKP", PUB: pub, PRIV: "AAAA"},
+		"AKP with x":       {ALG: "ML-DSA-44", KTY: "AKP", PUB: pub, X: "AAAA"},
+		"EC with pub":      {KTY: "EC", CRV: "P-256", PUB: pub},
+	}
+
+	for name, key := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, key.Validate())
+		})
+	}
+}
+
+func TestValidate_Errors(t *testing.T) {
+	keys := privateKeys(t)
+	p256 := keys["P-256"]
+
+	otherPrivateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	other, err := jwk.FromPublicKey(&otherPrivateKey.PublicKey)
+	assert.NoError(t, err)
+
+	offCurve := p256
+	offCurve.Y = other.Y
+
+	mismatched := p256
+	mismatched.X, mismatched.Y = other.X, other.Y
+
+	rsaWithoutPrimes := keys["RSA"]
+	rsaWithoutPrimes.P, rsaWithoutPrimes.Q = "", ""
+
+	rsaWrongDP := keys["RSA"]
+	rsaWrongDP.DP = rsaWrongDP.DQ
+
+	okpWithY := keys["Ed25519"].Public()
+	okpWithY.Y = p256.Y
+
+	withUse := p256.Public()
+	withUse.USE = "enc"
+	withUse.Extended = &jwk.ExtendedMembers{KeyOps: []string{"verify"}}
+
+	duplicateOps := p256.Public()
+	duplicateOps.Extended = &jwk.ExtendedMembers{KeyOps: []string{"verify", "verify"}}
+
+	tests := map[string]jwk.JWK{
+		"missing kty":          {CRV: "P-256", X: p256.X, Y: p256.Y},
+		"missing crv":          {KTY: "EC", X: p256.X, Y: p256.Y},
+		"missing y":            {KTY: "EC", CRV: "P-256", X: p256.X},
+		"unsupported curve":    {KTY: "EC", CRV: "P-192", X: p256.X, Y: p256.Y},
+		"point not on curve":   offCurve.Public(),
+		"d doesn't match":      mismatched,
+		"OKP with y":           okpWithY,
+		"short Ed25519 x":      {KTY: "OKP", CRV: "Ed25519", X: "AAAA"},
+		"RSA without primes":   rsaWithoutPrimes,
+		"RSA wrong dp":         rsaWrongDP,
+		"RSA primes without d": {KTY: "RSA", N: rsaKey.N, E: rsaKey.E, P: "AQAB"},
+		"oct without k":        {KTY: "oct"},
+		"oct with x":           {KTY: "oct", K: "GawgguFyGrWKav7AX4VKUg", X: p256.X},
+		"use and key_ops":      withUse,
+		"duplicate key_ops":    duplicateOps,
+	}
+
+	for name, key := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, key.Validate())
+		})
+	}
+}
+
+func TestValidate_Certificate(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "web5"}}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+
+	key, err := jwk.FromPublicKey(&privateKey.PublicKey)
+	assert.NoError(t, err)
+
+	thumbprint := sha256.Sum256(certificate)
+	key.Extended = &jwk.ExtendedMembers{X5C: []string{base64.StdEncoding.EncodeToString(certificate)}}
+	key.X5TS256 = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+	assert.NoError(t, key.Validate())
+
+	wrongThumbprint := key
+	wrongThumbprint.X5TS256 = base64.RawURLEncoding.EncodeToString(thumbprint[1:])
+	assert.Error(t, wrongThumbprint.Validate())
+
+	otherPrivateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	otherKey, err := jwk.FromPublicKey(&otherPrivateKey.PublicKey)
+	assert.NoError(t, err)
+
+	otherKey.Extended = key.Extended
+	assert.Error(t, otherKey.Validate())
+}
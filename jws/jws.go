@@ -1,6 +1,7 @@
 package jws
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -11,6 +12,7 @@ import (
 	"github.com/decentralized-identity/web5-go/dids"
 	_did "github.com/decentralized-identity/web5-go/dids/did"
 	"github.com/decentralized-identity/web5-go/dids/didcore"
+	"github.com/decentralized-identity/web5-go/jwk"
 )
 
 // Decode decodes the given JWS string into a [Decoded] type
@@ -171,12 +173,17 @@ func Type(typ string) SignOpt {
 // if no purpose is provided, the default is "assertionMethod". Passing Detached(true)
 // will return a compact JWS with detached content
 func Sign(payload []byte, did _did.BearerDID, opts ...SignOpt) (string, error) {
+	return SignWithContext(context.Background(), payload, did, opts...)
+}
+
+// SignWithContext is like [Sign], but signs with the given context.
+func SignWithContext(ctx context.Context, payload []byte, did _did.BearerDID, opts ...SignOpt) (string, error) {
 	o := signOpts{selector: nil, detached: false}
 	for _, opt := range opts {
 		opt(&o)
 	}
 
-	sign, verificationMethod, err := did.GetSigner(o.selector)
+	sign, verificationMethod, err := did.GetSignerWithContext(ctx, o.selector)
 	if err != nil {
 		return "", fmt.Errorf("failed to get signer: %w", err)
 	}
@@ -260,9 +267,21 @@ func (jws Decoded) Verify() error {
 		return fmt.Errorf("kid does not match any verification method %w", err)
 	}
 
+	// the alg header is only trusted if it is the key's own alg or, for keys without alg, the default
+	// algorithm of their curve. A plain secp256k1 key only verifies ES256K, not ES256K-R or SS256K.
+	publicKey := *verificationMethod.PublicKeyJwk
+	jwa, err := dsa.GetJWA(publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	if jwa != jws.Header.ALG {
+		return fmt.Errorf("failed to verify signature: alg %s does not match the %s key of kid", jws.Header.ALG, jwa)
+	}
+
 	toVerify := jws.Parts[0] + "." + jws.Parts[1]
 
-	verified, err := dsa.Verify([]byte(toVerify), jws.Signature, *verificationMethod.PublicKeyJwk)
+	verified, err := dsa.Verify([]byte(toVerify), jws.Signature, publicKey)
 	if err != nil {
 		return fmt.Errorf("failed to verify signature: %w", err)
 	}
@@ -274,6 +293,25 @@ func (jws Decoded) Verify() error {
 	return nil
 }
 
+// RecoverPublicKey recovers the public key of the signer from the signature, for JWSs signed with an
+// algorithm whose signatures allow it, like ES256K-R. This allows checking the signer against a known public
+// key or address without resolving the DID of kid.
+//
+// # Note
+//
+// A public key can be recovered from any well formed signature, so the JWS is only valid if the recovered
+// public key is the expected one.
+func (jws Decoded) RecoverPublicKey() (jwk.JWK, error) {
+	algorithm, err := dsa.GetAlgorithmForJWA(jws.Header.ALG)
+	if err != nil {
+		return jwk.JWK{}, err
+	}
+
+	toVerify := jws.Parts[0] + "." + jws.Parts[1]
+
+	return dsa.RecoverPublicKey(algorithm.ID(), []byte(toVerify), jws.Signature)
+}
+
 // Header represents a JWS (JSON Web Signature) header. See [Specification] for more details.
 // [Specification]: https://datatracker.ietf.org/doc/html/rfc7515#section-4
 type Header struct {
@@ -1,6 +1,7 @@
 package jws_test
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
 	"github.com/decentralized-identity/web5-go/dids/didjwk"
 	"github.com/decentralized-identity/web5-go/dids/didweb"
 	"github.com/decentralized-identity/web5-go/jws"
@@ -215,6 +217,17 @@ func TestSign(t *testing.T) {
 	assert.Contains(t, header.KID, did.URI, "expected kid to match did key id")
 }
 
+func TestSignWithContext_Canceled(t *testing.T) {
+	did, err := didjwk.Create()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = jws.SignWithContext(ctx, []byte("hi"), did)
+	assert.IsError(t, err, context.Canceled)
+}
+
 func TestSign_Detached(t *testing.T) {
 	did, err := didjwk.Create()
 	assert.NoError(t, err)
@@ -320,3 +333,115 @@ func TestVerify_Detached(t *testing.T) {
 
 	assert.Equal(t, payload, decoded.Payload)
 }
+
+func TestVerify_Recoverable(t *testing.T) {
+	did, err := didjwk.Create(didjwk.AlgorithmID(dsa.AlgorithmIDSECP256K1Recoverable))
+	assert.NoError(t, err)
+
+	compactJWS, err := jws.Sign([]byte("hi"), did)
+	assert.NoError(t, err)
+
+	decoded, err := jws.Verify(compactJWS)
+	assert.NoError(t, err)
+	assert.Equal(t, "ES256K-R", decoded.Header.ALG)
+	assert.Equal(t, 65, len(decoded.Signature))
+
+	publicKey, err := decoded.RecoverPublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, *did.Document.VerificationMethod[0].PublicKeyJwk, publicKey)
+}
+
+func TestVerify_Schnorr(t *testing.T) {
+	did, err := didjwk.Create(didjwk.AlgorithmID(dsa.AlgorithmIDSECP256K1Schnorr))
+	assert.NoError(t, err)
+
+	compactJWS, err := jws.Sign([]byte("hi"), did)
+	assert.NoError(t, err)
+
+	decoded, err := jws.Verify(compactJWS)
+	assert.NoError(t, err)
+	assert.Equal(t, "SS256K", decoded.Header.ALG)
+
+	_, err = decoded.RecoverPublicKey()
+	assert.Error(t, err)
+}
+
+func TestVerify_AlgMismatch(t *testing.T) {
+	did, err := didjwk.Create(didjwk.AlgorithmID(dsa.AlgorithmIDSECP256K1Recoverable))
+	assert.NoError(t, err)
+
+	compactJWS, err := jws.Sign([]byte("hi"), did)
+	assert.NoError(t, err)
+
+	// the key of the DID is restricted to ES256K-R by its alg
+	parts := strings.Split(compactJWS, ".")
+	header, err := jws.Header{ALG: "ES256K", KID: did.Document.VerificationMethod[0].ID}.Encode()
+	assert.NoError(t, err)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+
+	tampered := header + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature[:64])
+	_, err = jws.Verify(tampered)
+	assert.Error(t, err)
+}
+
+func TestVerify_MLDSA(t *testing.T) {
+	did, err := didjwk.Create(didjwk.AlgorithmID(dsa.AlgorithmIDMLDSA65))
+	assert.NoError(t, err)
+
+	compactJWS, err := jws.Sign([]byte("hi"), did)
+	assert.NoError(t, err)
+
+	decoded, err := jws.Verify(compactJWS)
+	assert.NoError(t, err)
+	assert.Equal(t, "ML-DSA-65", decoded.Header.ALG)
+	assert.Equal(t, []byte("hi"), decoded.Payload)
+
+	// the key of the DID is restricted to ML-DSA-65 by its alg
+	parts := strings.Split(compactJWS, ".")
+	header, err := jws.Header{ALG: "ML-DSA-44", KID: decoded.Header.KID}.Encode()
+	assert.NoError(t, err)
+
+	_, err = jws.Verify(header + "." + parts[1] + "." + parts[2])
+	assert.Error(t, err)
+}
+
+func TestVerify_AlgConfusion(t *testing.T) {
+	for _, algorithmID := range []string{dsa.AlgorithmIDSECP256K1Recoverable, dsa.AlgorithmIDSECP256K1Schnorr} {
+		t.Run(algorithmID, func(t *testing.T) {
+			privateKey, err := dsa.GeneratePrivateKey(algorithmID)
+			assert.NoError(t, err)
+
+			// a did:jwk for the same key without alg, i.e. a plain ES256K key
+			publicKey := dsa.GetPublicKey(privateKey)
+			publicKey.ALG = ""
+			publicKeyBytes, err := json.Marshal(publicKey)
+			assert.NoError(t, err)
+
+			kid := "did:jwk:" + base64.RawURLEncoding.EncodeToString(publicKeyBytes) + "#0"
+
+			header, err := jws.Header{ALG: privateKey.ALG, KID: kid}.Encode()
+			assert.NoError(t, err)
+
+			payload := base64.RawURLEncoding.EncodeToString([]byte("hi"))
+			signature, err := dsa.Sign([]byte(header+"."+payload), privateKey)
+			assert.NoError(t, err)
+
+			_, err = jws.Verify(header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(signature))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "does not match")
+
+			// the same key verifies ES256K
+			privateKey.ALG = ""
+			header, err = jws.Header{ALG: "ES256K", KID: kid}.Encode()
+			assert.NoError(t, err)
+
+			signature, err = dsa.Sign([]byte(header+"."+payload), privateKey)
+			assert.NoError(t, err)
+
+			_, err = jws.Verify(header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(signature))
+			assert.NoError(t, err)
+		})
+	}
+}
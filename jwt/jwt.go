@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -90,6 +91,11 @@ func Type(t string) SignOpt {
 //
 // claims.Issuer will be overridden to the value of did.URI within this function
 func Sign(claims Claims, did did.BearerDID, opts ...SignOpt) (string, error) {
+	return SignWithContext(context.Background(), claims, did, opts...)
+}
+
+// SignWithContext is like [Sign], but signs with the given context.
+func SignWithContext(ctx context.Context, claims Claims, did did.BearerDID, opts ...SignOpt) (string, error) {
 	o := signOpts{selector: nil, typ: ""}
 	for _, opt := range opts {
 		opt(&o)
@@ -113,7 +119,7 @@ func Sign(claims Claims, did did.BearerDID, opts ...SignOpt) (string, error) {
 		return "", fmt.Errorf("failed to marshal jwt claims: %w", err)
 	}
 
-	return jws.Sign(payload, did, jwsOpts...)
+	return jws.SignWithContext(ctx, payload, did, jwsOpts...)
 }
 
 // Verify verifies a JWT (JSON Web Token) as per the spec https://datatracker.ietf.org/doc/html/rfc7519
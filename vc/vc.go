@@ -1,6 +1,7 @@
 package vc
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -213,6 +214,11 @@ func Create[T CredentialSubject](claims T, opts ...CreateOption) DataModel[T] {
 //
 // [vc-jwt]: https://www.w3.org/TR/vc-data-model/#json-web-token
 func (vc DataModel[T]) Sign(bearerDID did.BearerDID, opts ...jwt.SignOpt) (string, error) {
+	return vc.SignWithContext(context.Background(), bearerDID, opts...)
+}
+
+// SignWithContext is like [DataModel.Sign], but signs with the given context.
+func (vc DataModel[T]) SignWithContext(ctx context.Context, bearerDID did.BearerDID, opts ...jwt.SignOpt) (string, error) {
 	vc.Issuer = bearerDID.URI
 	jwtClaims := jwt.Claims{
 		Issuer:  vc.Issuer,
@@ -241,5 +247,5 @@ func (vc DataModel[T]) Sign(bearerDID did.BearerDID, opts ...jwt.SignOpt) (strin
 
 	// typ must be set to "JWT" as per the spec
 	opts = append(opts, jwt.Type("JWT"))
-	return jwt.Sign(jwtClaims, bearerDID, opts...)
+	return jwt.SignWithContext(ctx, jwtClaims, bearerDID, opts...)
 }
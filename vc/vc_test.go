@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/alecthomas/assert/v2"
+	"github.com/decentralized-identity/web5-go/crypto/dsa"
 	"github.com/decentralized-identity/web5-go/dids/didjwk"
 	"github.com/decentralized-identity/web5-go/vc"
 )
@@ -95,3 +96,18 @@ func TestSign(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotZero(t, decoded)
 }
+
+func TestSign_MLDSA(t *testing.T) {
+	issuer, err := didjwk.Create(didjwk.AlgorithmID(dsa.AlgorithmIDMLDSA44))
+	assert.NoError(t, err)
+
+	cred := vc.Create(vc.Claims{"id": "did:example:subject", "name": "Randy McRando"})
+
+	vcJWT, err := cred.Sign(issuer)
+	assert.NoError(t, err)
+
+	decoded, err := vc.Verify[vc.Claims](vcJWT)
+	assert.NoError(t, err)
+	assert.Equal(t, "ML-DSA-44", decoded.JWT.Header.ALG)
+	assert.Equal(t, issuer.URI, decoded.VC.Issuer)
+}